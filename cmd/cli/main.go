@@ -1,15 +1,28 @@
 package main
 
 import (
+	"database/sql"
+	"errors"
+	"io"
 	"log"
+	"log/slog"
+	"os"
 
+	"github.com/spf13/pflag"
+
+	"github.com/spie/fskick/internal/checks"
 	"github.com/spie/fskick/internal/cli/commands"
+	"github.com/spie/fskick/internal/client"
 	"github.com/spie/fskick/internal/config"
 	"github.com/spie/fskick/internal/db"
+	"github.com/spie/fskick/internal/dumps"
 	"github.com/spie/fskick/internal/games"
+	"github.com/spie/fskick/internal/logging"
 	"github.com/spie/fskick/internal/passwords"
 	"github.com/spie/fskick/internal/players"
 	"github.com/spie/fskick/internal/seasons"
+	"github.com/spie/fskick/internal/streaks"
+	"github.com/spie/fskick/internal/tournaments"
 	"github.com/spie/fskick/internal/users"
 	"github.com/spie/fskick/migrations"
 )
@@ -17,21 +30,54 @@ import (
 var version string = "development"
 
 func main() {
-	cfg, err := config.LoadCliConfig()
+	configFlags, err := parseConfigFlags()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	cfg, err := config.Load(configFlags)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	conn, err := db.OpenDbConnection(cfg.DbConfig)
+	logger, err := logging.New(os.Stderr, cfg.LogFormat, cfg.LogLevel)
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer conn.Close()
+	slog.SetDefault(logger)
 
-	err = db.MigrateFS(conn, migrations.FS, ".")
+	if cfg.Remote.URL != "" {
+		err = runRemote(cfg, configFlags)
+	} else {
+		err = runLocal(cfg, configFlags, logger)
+	}
 	if err != nil {
 		log.Fatal(err)
 	}
+}
+
+func runLocal(cfg config.AppConfig, configFlags *pflag.FlagSet, logger *slog.Logger) error {
+	sqlConn, err := db.OpenDbConnection(cfg.DbConfig)
+	if err != nil {
+		return err
+	}
+	defer sqlConn.Close()
+
+	driver := cfg.DbConfig.Driver()
+	migrate := func() error {
+		if !cfg.AutoMigrate {
+			return nil
+		}
+
+		return db.MigrateFS(sqlConn, driver, migrations.FS, migrations.Dir(driver))
+	}
+
+	migrator, err := db.NewMigrator(sqlConn, driver, migrations.FS, migrations.Dir(driver))
+	if err != nil {
+		return err
+	}
+
+	conn := db.WithQueryLogger(sqlConn, cfg.DbConfig, logger)
 
 	passwordService := passwords.NewPasswordService()
 
@@ -40,7 +86,8 @@ func main() {
 
 	gamesRepository := games.NewGamesRepository(conn)
 	attendanceRepository := games.NewAttendanceRepository(conn)
-	gamesManager := games.NewManager(gamesRepository, attendanceRepository, seasonManager)
+	gamesManager := games.NewManager(gamesRepository, attendanceRepository, seasonManager, cfg.Points).
+		WithDuplicateWindow(cfg.DuplicateWindow)
 
 	playersRepository := players.NewPlayerRepository(conn)
 	playersManager := players.NewManager(playersRepository)
@@ -48,51 +95,188 @@ func main() {
 	usersRepository := users.NewUsersRepository(conn)
 	usersManager := users.NewManager(usersRepository, playersManager, passwordService)
 
-	rootCommand := createCommands(seasonManager, gamesManager, playersManager, usersManager)
+	tournamentsRepository := tournaments.NewTournamentsRepository(conn)
+	tournamentsManager := tournaments.NewManager(tournamentsRepository, gamesManager, playersManager)
 
-	if err := rootCommand.Execute(); err != nil {
-		log.Fatal(err)
+	rootCommand := createCommands(
+		cfg,
+		configFlags,
+		migrate,
+		managers{
+			seasons:     seasonManager,
+			games:       gamesManager,
+			players:     playersManager,
+			streaks:     streaks.NewManager(attendanceRepository),
+			tournaments: tournamentsManager,
+		},
+		localTools{
+			usersManager:  usersManager,
+			importer:      games.NewImporter(conn),
+			dumpsManager:  dumps.NewManager(conn),
+			conn:          sqlConn,
+			driver:        driver,
+			migrator:      migrator,
+			checksManager: checks.NewManager(checks.NewChecksRepository(conn)),
+		},
+	)
+
+	return rootCommand.Execute()
+}
+
+// runRemote runs the commands against the api of the server at the remote url. Nothing is
+// migrated and the commands needing the local database are disabled.
+func runRemote(cfg config.AppConfig, configFlags *pflag.FlagSet) error {
+	remote, err := client.New(cfg.Remote.URL, cfg.Remote.Token)
+	if err != nil {
+		return err
 	}
+
+	rootCommand := createCommands(
+		cfg,
+		configFlags,
+		func() error { return nil },
+		managers{
+			seasons:     client.NewSeasonsManager(remote),
+			games:       client.NewGamesManager(remote),
+			players:     client.NewPlayersManager(remote),
+			streaks:     client.NewStreaksManager(remote),
+			tournaments: client.NewTournamentsManager(remote),
+		},
+		localTools{},
+	)
+
+	return rootCommand.Execute()
+}
+
+// parseConfigFlags parses the config flags before the commands, the config is needed to create
+// them. The flags of the commands are left to cobra.
+func parseConfigFlags() (*pflag.FlagSet, error) {
+	configFlags := config.NewFlagSet("fskick")
+	configFlags.ParseErrorsWhitelist.UnknownFlags = true
+	configFlags.SetOutput(io.Discard)
+	configFlags.Usage = func() {}
+
+	err := configFlags.Parse(os.Args[1:])
+	if err != nil && !errors.Is(err, pflag.ErrHelp) {
+		return nil, err
+	}
+
+	return configFlags, nil
+}
+
+type managers struct {
+	seasons     commands.SeasonsManager
+	games       commands.GamesManager
+	players     commands.PlayersManager
+	streaks     commands.StreaksManager
+	tournaments commands.TournamentsManager
+}
+
+// localTools work directly with the database, they are zero in remote mode.
+type localTools struct {
+	usersManager  users.Manager
+	importer      games.Importer
+	dumpsManager  dumps.Manager
+	conn          *sql.DB
+	driver        string
+	migrator      db.Migrator
+	checksManager checks.Manager
+}
+
+func (tools localTools) remote() bool {
+	return tools.conn == nil
 }
 
 func createCommands(
-	seasonsManager seasons.Manager,
-	gamesManager games.Manager,
-	playersManager players.Manager,
-	usersManager users.Manager,
+	cfg config.AppConfig,
+	configFlags *pflag.FlagSet,
+	migrate func() error,
+	managers managers,
+	tools localTools,
 ) commands.Command {
-	createPlayer := commands.NewCreatePlayerCommand(playersManager)
-	getPlayers := commands.NewGetPlayersCommand(gamesManager)
+	createPlayer := commands.NewCreatePlayerCommand(managers.players)
+	updatePlayer := commands.NewUpdatePlayerCommand(managers.players)
+	getPlayers := commands.NewGetPlayersCommand(managers.games)
 	playersCommand := commands.NewPlayersCommand()
 	playersCommand.AddCommand(createPlayer)
+	playersCommand.AddCommand(updatePlayer)
 	playersCommand.AddCommand(getPlayers)
 
-	createSeason := commands.NewCreateSeasonCommand(seasonsManager)
-	getSeason := commands.NewGetSeasonsCommand(seasonsManager)
-	activateSeason := commands.NewActivateSeasonCommand(seasonsManager)
-	tableCommand := commands.NewGetTableCommand(gamesManager, seasonsManager)
+	createSeason := commands.NewCreateSeasonCommand(managers.seasons)
+	getSeason := commands.NewGetSeasonsCommand(managers.seasons)
+	activateSeason := commands.NewActivateSeasonCommand(managers.seasons)
+	seasonRules := commands.NewSeasonRulesCommand(managers.seasons)
+	tableCommand := commands.NewGetTableCommand(managers.games, managers.seasons)
 	seasonsCommand := commands.NewSeasonsCommand()
 	seasonsCommand.AddCommand(createSeason)
 	seasonsCommand.AddCommand(getSeason)
 	seasonsCommand.AddCommand(activateSeason)
+	seasonsCommand.AddCommand(seasonRules)
 	seasonsCommand.AddCommand(tableCommand)
 
-	createGame := commands.NewCreateGameCommand(gamesManager, playersManager)
+	createGame := commands.NewCreateGameCommand(managers.games, managers.players)
+	listGames := commands.NewListGamesCommand(managers.games, managers.players, managers.seasons)
+	importGames := commands.NewImportGamesCommand(tools.importer)
 	gamesCommands := commands.NewGamesCommand()
 	gamesCommands.AddCommand(createGame)
+	gamesCommands.AddCommand(listGames)
+	gamesCommands.AddCommand(importGames)
+	gamesCommands.AddCommand(commands.NewMatchPlayersCommand(managers.games, managers.players))
+
+	tournamentsCommand := commands.NewTournamentsCommand()
+	tournamentsCommand.AddCommand(commands.NewCreateTournamentCommand(managers.tournaments))
+	tournamentsCommand.AddCommand(commands.NewGetTournamentsCommand(managers.tournaments))
+	tournamentsCommand.AddCommand(commands.NewRegisterTeamCommand(managers.tournaments))
+	tournamentsCommand.AddCommand(commands.NewScheduleTournamentCommand(managers.tournaments))
+	tournamentsCommand.AddCommand(commands.NewShowTournamentCommand(managers.tournaments))
+	tournamentsCommand.AddCommand(commands.NewRecordResultCommand(managers.tournaments))
+
+	playCommand := commands.NewPlayCommand(managers.games, managers.players, managers.seasons, managers.streaks)
 
-	createUserFromPlayer := commands.NewCreateUserFromPlayerCommand(usersManager)
+	createUserFromPlayer := commands.NewCreateUserFromPlayerCommand(tools.usersManager)
 	usersCommand := commands.NewUsersCommand()
 	usersCommand.AddCommand(createUserFromPlayer)
+	usersCommand.AddCommand(commands.NewCreateApiTokenCommand(tools.usersManager))
+
+	exportCommand := commands.NewExportCommand(tools.dumpsManager)
+	importDumpCommand := commands.NewImportDumpCommand(tools.dumpsManager)
+
+	migrateCommand := commands.NewMigrateCommand()
+	migrateCommand.AddCommand(commands.NewMigrateStatusCommand(tools.migrator))
+	migrateCommand.AddCommand(commands.NewMigrateUpCommand(tools.migrator))
+	migrateCommand.AddCommand(commands.NewMigrateDownCommand(tools.migrator))
+	migrateCommand.AddCommand(commands.NewMigrateRedoCommand(tools.migrator))
+	dbCommand := commands.NewDbCommand()
+	dbCommand.AddCommand(migrateCommand)
+	dbCommand.AddCommand(commands.NewBackupCommand(tools.conn, tools.driver))
+	dbCommand.AddCommand(commands.NewVacuumCommand(tools.conn))
+	dbCommand.AddCommand(commands.NewCheckCommand(tools.checksManager, tools.conn, tools.driver))
+
+	configCommand := commands.NewConfigCommand()
+	configCommand.AddCommand(commands.NewShowConfigCommand(cfg))
 
 	versionCommand := commands.NewVersionCommand(version)
 
-	rootCommand := commands.NewRootCommand()
+	if tools.remote() {
+		commands.DisableRemote(importGames)
+		commands.DisableRemote(usersCommand)
+		commands.DisableRemote(exportCommand)
+		commands.DisableRemote(importDumpCommand)
+		commands.DisableRemote(dbCommand)
+	}
+
+	rootCommand := commands.NewRootCommand(migrate, configFlags)
 	rootCommand.AddCommand(versionCommand)
 	rootCommand.AddCommand(playersCommand)
 	rootCommand.AddCommand(seasonsCommand)
 	rootCommand.AddCommand(gamesCommands)
+	rootCommand.AddCommand(tournamentsCommand)
+	rootCommand.AddCommand(playCommand)
 	rootCommand.AddCommand(usersCommand)
+	rootCommand.AddCommand(exportCommand)
+	rootCommand.AddCommand(importDumpCommand)
+	rootCommand.AddCommand(dbCommand)
+	rootCommand.AddCommand(configCommand)
 
 	return rootCommand
 }
@@ -1,17 +1,31 @@
 package main
 
 import (
-	"fmt"
+	"context"
+	"errors"
 	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/pflag"
 
 	"github.com/spie/fskick/cmd/server/static"
+	"github.com/spie/fskick/internal/avatars"
+	"github.com/spie/fskick/internal/cache"
 	"github.com/spie/fskick/internal/config"
 	"github.com/spie/fskick/internal/db"
 	"github.com/spie/fskick/internal/games"
+	"github.com/spie/fskick/internal/logging"
+	"github.com/spie/fskick/internal/metrics"
+	"github.com/spie/fskick/internal/passwords"
 	"github.com/spie/fskick/internal/players"
 	"github.com/spie/fskick/internal/seasons"
 	"github.com/spie/fskick/internal/server"
 	"github.com/spie/fskick/internal/streaks"
+	"github.com/spie/fskick/internal/tournaments"
+	"github.com/spie/fskick/internal/users"
 	"github.com/spie/fskick/internal/views"
 	"github.com/spie/fskick/migrations"
 )
@@ -20,33 +34,84 @@ import (
 //go:generate npx tailwindcss build -c tailwind.config.js -i ./static/css/style.css -o ./static/css/tailwind.css -m
 
 func main() {
-	cfg, err := config.LoadServerConfig()
+	err := run()
 	if err != nil {
 		log.Fatal(err)
 	}
+}
 
-	conn, err := db.OpenDbConnection(cfg.DbConfig)
+// run serves until the server stops. Errors are returned instead of exiting, so the database
+// is closed before main exits.
+func run() error {
+	configFlags := config.NewFlagSet("server")
+	err := configFlags.Parse(os.Args[1:])
+	if errors.Is(err, pflag.ErrHelp) {
+		return nil
+	}
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	defer conn.Close()
 
-	err = db.MigrateFS(conn, migrations.FS, ".")
+	cfg, err := config.Load(configFlags)
 	if err != nil {
-		log.Fatal(err)
+		return err
+	}
+
+	logger, err := logging.New(os.Stderr, cfg.LogFormat, cfg.LogLevel)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+
+	sqlConn, err := db.OpenDbConnection(cfg.DbConfig)
+	if err != nil {
+		return err
+	}
+	defer sqlConn.Close()
+
+	// without auto migration pending migrations are reported by the readiness probe
+	driver := cfg.DbConfig.Driver()
+	if cfg.AutoMigrate {
+		err = db.MigrateFS(sqlConn, driver, migrations.FS, migrations.Dir(driver))
+		if err != nil {
+			return err
+		}
+	}
+
+	migrator, err := db.NewMigrator(sqlConn, driver, migrations.FS, migrations.Dir(driver))
+	if err != nil {
+		return err
 	}
 
+	appMetrics := metrics.New()
+	conn := db.WithQueryObserver(db.WithQueryLogger(sqlConn, cfg.DbConfig, logger), appMetrics.ObserveQuery)
+
+	statsCache := createStatsCache(cfg)
+
 	seasonsRepository := seasons.NewSeasonsRepository(conn)
-	seasonManager := seasons.NewManager(seasonsRepository)
+	seasonManager := seasons.NewManager(seasonsRepository).WithCache(statsCache)
 
 	gamesRepository := games.NewGamesRepository(conn)
 	attendanceRepository := games.NewAttendanceRepository(conn)
-	gamesManager := games.NewManager(gamesRepository, attendanceRepository, seasonManager)
+	gamesManager := games.NewManager(gamesRepository, attendanceRepository, seasonManager, cfg.Points).
+		WithCache(statsCache).
+		WithDuplicateWindow(cfg.DuplicateWindow)
 
 	playersRepository := players.NewPlayerRepository(conn)
-	playersManager := players.NewManager(playersRepository)
+	playersManager := players.NewManager(playersRepository).WithCache(statsCache)
 
-	streaksManager := streaks.NewManager(attendanceRepository)
+	streaksManager := streaks.NewManager(attendanceRepository).WithCache(statsCache)
+
+	tournamentsRepository := tournaments.NewTournamentsRepository(conn)
+	tournamentsManager := tournaments.NewManager(tournamentsRepository, gamesManager, playersManager)
+
+	usersRepository := users.NewUsersRepository(conn)
+	usersManager := users.NewManager(usersRepository, playersManager, passwords.NewPasswordService())
+
+	avatarsManager, err := createAvatarsManager(cfg, conn, playersManager)
+	if err != nil {
+		return err
+	}
 
 	gamesViews := server.NewGamesViews()
 	gamesViews.SeasonsTable = views.NewSeasonTable()
@@ -55,6 +120,12 @@ func main() {
 	gamesViews.SeasonsTableUpdate = views.NewSeasonsTableUpdate()
 	gamesViews.PlayersTableUpdate = views.NewPlayersTableUpdate()
 	gamesViews.FavoriteTeamUpdate = views.NewFavoriteTeamUpdate()
+	gamesViews.GamesPage = views.NewGamesPage()
+	gamesViews.GamesRows = views.NewGamesRows()
+	gamesViews.GameInfo = views.NewGameInfo()
+	gamesViews.NewGame = views.NewNewGame()
+	gamesViews.NewGameForm = views.NewNewGameForm()
+	gamesViews.GameCreated = views.NewGameCreated()
 	gamesController := server.NewGamesController(
 		gamesManager,
 		seasonManager,
@@ -69,15 +140,48 @@ func main() {
 	streaksViews.StreaksPage = views.NewStreaksPage()
 	streaksController := server.NewStreaksController(streaksManager, streaksViews)
 
+	playersController := server.NewPlayersController(playersManager, avatarsManager)
+
+	tournamentsViews := server.NewTournamentsViews()
+	tournamentsViews.TournamentsPage = views.NewTournamentsPage()
+	tournamentsViews.TournamentPage = views.NewTournamentPage()
+	tournamentsViews.TournamentBracket = views.NewTournamentBracket()
+	tournamentsController := server.NewTournamentsController(tournamentsManager, tournamentsViews)
+
+	authenticator := server.NewAuthenticator(usersManager).WithAdmins(cfg.Admins)
+
+	err = appMetrics.Register(metrics.NewStatsCollector(gamesManager, seasonManager, playersManager, streaksManager))
+	if err != nil {
+		return err
+	}
+	if statsCache != nil {
+		err = appMetrics.Register(metrics.NewCacheCollector(statsCache))
+		if err != nil {
+			return err
+		}
+	}
+
+	healthController := server.NewHealthController(sqlConn, migrator)
+
 	imprintView := views.NewImprintView()
 	imprintController := server.NewImprintController(cfg.ImprintText, imprintView)
 
 	s := server.New(cfg.ApiHost)
+	s.SetTimeouts(server.Timeouts(cfg.HttpTimeouts))
+	s.SetQueryTimeout(cfg.QueryTimeout)
+	s.SetMetrics(appMetrics)
 
 	s.Get("/", gamesController.SeasonsTable)
 	s.Get("/players", gamesController.PlayersTable)
 	s.Get("/players/{player}", gamesController.PlayerInfo)
+	s.Get("/games", gamesController.Games)
+	s.Get("/games/new", authenticator.Authenticated(gamesController.NewGame))
+	s.Post("/games", server.SameOrigin(authenticator.Authenticated(gamesController.SubmitGame)))
+	s.Get("/games/{game}", gamesController.GameInfo)
 	s.Get("/streaks", streaksController.StreaksPage)
+	s.Get("/tournaments", tournamentsController.TournamentsPage)
+	s.Get("/tournaments/{tournament}", tournamentsController.TournamentPage)
+	s.Get("/tournaments/{tournament}/bracket", tournamentsController.TournamentBracket)
 	s.Get("/imprint", imprintController.Imprint)
 
 	s.Get("/table/seasons", gamesController.SeasonsTableUpdate)
@@ -85,22 +189,83 @@ func main() {
 	s.Get("/table/players/{player}", gamesController.PlayersTableUpdate)
 	s.Get("/table/players/{player}/team", gamesController.FavoriteTeamUpdate)
 	s.Get("/table/players/{player}/oponents", gamesController.FavoriteOponentsUpdate)
+	s.Get("/table/games", gamesController.GamesUpdate)
 	s.Get("/streaks/current", streaksController.CurrentStreaks)
+	s.Get("/avatars/{avatar}", playersController.Avatar)
 
 	s.Get("/api/seasons", seasonsController.GetSeasons)
+	s.Post("/api/seasons", authenticator.Admin(seasonsController.CreateSeason))
+	s.Post("/api/seasons/{season}/activate", authenticator.Admin(seasonsController.ActivateSeason))
+	s.Put("/api/seasons/{season}/rules", authenticator.Admin(seasonsController.UpdateRules))
 	s.Get("/api/seasons/table", gamesController.GetSeasonsTable)
 	s.Get("/api/seasons/table/{season}", gamesController.GetSeasonsTable)
 	s.Get("/api/players", gamesController.GetPlayers)
+	s.Post("/api/players", authenticator.Authenticated(playersController.CreatePlayer))
+	s.Get("/api/players/lookup", playersController.LookupPlayer)
+	s.Get("/api/players/profiles", playersController.GetProfiles)
+	s.Put("/api/players/{player}/profile", authenticator.Authenticated(playersController.UpdateProfile))
 	s.Get("/api/players/{player}/team", gamesController.GetFavoriteTeam)
 	s.Get("/api/players/{player}", gamesController.GetPlayers)
+	s.Get("/api/games", gamesController.ListGames)
 	s.Get("/api/games/count", gamesController.GetGamesCount)
+	s.Get("/api/matchmaking", gamesController.MatchPlayers)
+	s.Get("/api/games/{game}", gamesController.GetGame)
+	s.Post("/api/games", authenticator.Authenticated(gamesController.CreateGame))
+	s.Get("/api/streaks/current", streaksController.GetCurrentStreaks)
+	s.Post("/api/players/{player}/avatar", authenticator.Authenticated(playersController.UploadAvatar))
+	s.Get("/api/tournaments", tournamentsController.GetTournaments)
+	s.Post("/api/tournaments", authenticator.Authenticated(tournamentsController.CreateTournament))
+	s.Get("/api/tournaments/{tournament}", tournamentsController.GetTournament)
+	s.Post("/api/tournaments/{tournament}/teams", authenticator.Authenticated(tournamentsController.RegisterTeam))
+	s.Post("/api/tournaments/{tournament}/schedule", authenticator.Authenticated(tournamentsController.ScheduleTournament))
+	s.Post(
+		"/api/tournaments/{tournament}/matches/{match}/result",
+		authenticator.Authenticated(tournamentsController.RecordResult),
+	)
+
+	s.Get("/metrics", appMetrics.Handler().ServeHTTP)
+	s.Probe("/healthz", healthController.Healthz)
+	s.Probe("/readyz", healthController.Readyz)
 
 	s.HandleStatic(static.Dir)
 
-	fmt.Printf("Starting the server on %s...\n", cfg.ApiHost)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	err = s.Run()
+	logger.Info("starting the server", slog.String("addr", cfg.ApiHost))
+
+	err = s.Run(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
+	}
+
+	logger.Info("server stopped")
+
+	return nil
+}
+
+// createStatsCache returns the cache shared by the managers, or nil if caching is disabled.
+func createStatsCache(cfg config.AppConfig) *cache.Cache {
+	if cfg.StatsCacheTTL <= 0 {
+		return nil
+	}
+
+	return cache.New(cfg.StatsCacheTTL)
+}
+
+func createAvatarsManager(
+	cfg config.AppConfig,
+	conn db.Connection,
+	playersManager players.Manager,
+) (avatars.Manager, error) {
+	if cfg.AvatarStorage != "directory" {
+		return avatars.NewManager(avatars.NewAvatarsRepository(conn), playersManager), nil
+	}
+
+	avatarsDirectory, err := avatars.NewAvatarsDirectory(cfg.AvatarDirectory)
+	if err != nil {
+		return avatars.Manager{}, err
 	}
+
+	return avatars.NewManager(avatarsDirectory, playersManager), nil
 }
@@ -0,0 +1,141 @@
+// Offline queue of the game form. Games submitted without a connection are kept in the local
+// storage and sent again when the browser is back online. Every submission gets an idempotency
+// key, so a game sent twice is only created once.
+(function () {
+  const storageKey = "fskick:queued-games";
+  const formSelector = "form[data-offline-queue]";
+
+  if ("serviceWorker" in navigator) {
+    navigator.serviceWorker.register("/static/js/sw.js", { scope: "/" });
+  }
+
+  function queuedGames() {
+    try {
+      return JSON.parse(localStorage.getItem(storageKey)) || [];
+    } catch {
+      return [];
+    }
+  }
+
+  function saveQueuedGames(games) {
+    localStorage.setItem(storageKey, JSON.stringify(games));
+    showStatus(games.length, 0, 0);
+  }
+
+  function showStatus(queued, dropped, duplicates) {
+    const status = document.getElementById("offline-queue");
+    if (!status) {
+      return;
+    }
+
+    const messages = [];
+    if (queued > 0) {
+      messages.push(`${queued} game(s) waiting, they are saved when you are back online.`);
+    }
+    if (dropped > 0) {
+      messages.push(`${dropped} queued game(s) were invalid and not saved.`);
+    }
+    if (duplicates > 0) {
+      messages.push(`${duplicates} queued game(s) were already recorded and not saved again, check the games.`);
+    }
+
+    status.textContent = messages.join(" ");
+    status.hidden = messages.length === 0;
+  }
+
+  function newKey() {
+    if (crypto.randomUUID) {
+      return crypto.randomUUID();
+    }
+
+    return Date.now().toString(36) + Math.random().toString(36).slice(2);
+  }
+
+  function localDateTime(date) {
+    const pad = (n) => String(n).padStart(2, "0");
+
+    return `${date.getFullYear()}-${pad(date.getMonth() + 1)}-${pad(date.getDate())}T${pad(date.getHours())}:${pad(date.getMinutes())}`;
+  }
+
+  // the key is kept until the game is saved or queued, retries of htmx use the same key
+  document.addEventListener(
+    "submit",
+    (event) => {
+      const key = event.target.matches(formSelector) && event.target.querySelector("input[name=idempotencyKey]");
+      if (key && !key.value) {
+        key.value = newKey();
+      }
+    },
+    true,
+  );
+
+  document.addEventListener("htmx:sendError", (event) => {
+    const form = event.detail.elt;
+    if (!form.matches(formSelector)) {
+      return;
+    }
+
+    // the game is saved later, without a date it would be played then
+    const playedAt = form.querySelector("input[name=playedAt]");
+    if (!playedAt.value) {
+      playedAt.value = localDateTime(new Date());
+    }
+
+    const games = queuedGames();
+    games.push({ body: new URLSearchParams(new FormData(form)).toString() });
+    saveQueuedGames(games);
+
+    form.reset();
+    form.querySelector("input[name=idempotencyKey]").value = "";
+  });
+
+  let sending = false;
+
+  async function sendQueuedGames() {
+    if (sending || !navigator.onLine) {
+      return;
+    }
+
+    sending = true;
+    let dropped = 0;
+    let duplicates = 0;
+    try {
+      let games = queuedGames();
+      while (games.length > 0) {
+        let response;
+        try {
+          response = await fetch("/games", {
+            method: "POST",
+            headers: { "Content-Type": "application/x-www-form-urlencoded" },
+            body: games[0].body,
+            credentials: "same-origin",
+          });
+        } catch {
+          break;
+        }
+
+        // invalid and duplicate games would block the queue, they are dropped
+        if (response.status === 422) {
+          dropped++;
+        } else if (response.status === 409) {
+          duplicates++;
+        } else if (!response.ok) {
+          break;
+        }
+
+        games = games.slice(1);
+        saveQueuedGames(games);
+      }
+
+      showStatus(games.length, dropped, duplicates);
+    } finally {
+      sending = false;
+    }
+  }
+
+  window.addEventListener("online", sendQueuedGames);
+  document.addEventListener("DOMContentLoaded", () => {
+    showStatus(queuedGames().length, 0, 0);
+    sendQueuedGames();
+  });
+})();
@@ -0,0 +1,70 @@
+// Service worker of the web ui. Static files come from the cache and are updated in the
+// background, pages come from the network with the cached page as fallback when offline.
+const cacheName = "fskick-v1";
+// pages are cached when visited, a failing page would stop the installation
+const precachedUrls = [
+  "/static/css/tailwind.css",
+  "/static/js/htmx.min.js",
+  "/static/js/offline.js",
+  "/static/manifest.webmanifest",
+  "/static/img/icon-192.png",
+];
+const uncachedPaths = ["/metrics", "/healthz", "/readyz"];
+
+self.addEventListener("install", (event) => {
+  event.waitUntil(
+    caches.open(cacheName)
+      .then((cache) => cache.addAll(precachedUrls))
+      .then(() => self.skipWaiting()),
+  );
+});
+
+self.addEventListener("activate", (event) => {
+  event.waitUntil(
+    caches.keys()
+      .then((keys) => Promise.all(keys.filter((key) => key !== cacheName).map((key) => caches.delete(key))))
+      .then(() => self.clients.claim()),
+  );
+});
+
+self.addEventListener("fetch", (event) => {
+  const request = event.request;
+  const url = new URL(request.url);
+  if (request.method !== "GET" || url.origin !== self.location.origin) {
+    return;
+  }
+  if (url.pathname.startsWith("/api/") || uncachedPaths.includes(url.pathname)) {
+    return;
+  }
+
+  if (url.pathname.startsWith("/static/")) {
+    event.respondWith(
+      caches.match(request).then((cached) => {
+        const fetched = fetchAndCache(request);
+        if (cached) {
+          fetched.catch(() => {});
+
+          return cached;
+        }
+
+        return fetched;
+      }),
+    );
+    return;
+  }
+
+  event.respondWith(
+    fetchAndCache(request).catch(() => caches.match(request).then((cached) => cached || caches.match("/"))),
+  );
+});
+
+function fetchAndCache(request) {
+  return fetch(request).then((response) => {
+    if (response.ok) {
+      const copy = response.clone();
+      caches.open(cacheName).then((cache) => cache.put(request, copy));
+    }
+
+    return response;
+  });
+}
@@ -2,5 +2,5 @@ package static
 
 import "embed"
 
-//go:embed css js
+//go:embed css js img manifest.webmanifest
 var Dir embed.FS
@@ -0,0 +1,61 @@
+package avatars
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+type AvatarsDirectory struct {
+	dir string
+}
+
+func NewAvatarsDirectory(dir string) (AvatarsDirectory, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return AvatarsDirectory{}, fmt.Errorf("create avatars directory: %w", err)
+	}
+
+	return AvatarsDirectory{dir: dir}, nil
+}
+
+func (directory AvatarsDirectory) StoreAvatar(_ context.Context, name string, _ string, data []byte) error {
+	err := os.WriteFile(directory.path(name), data, 0o644)
+	if err != nil {
+		return fmt.Errorf("write avatar file: %w", err)
+	}
+
+	return nil
+}
+
+func (directory AvatarsDirectory) LoadAvatar(_ context.Context, name string) ([]byte, error) {
+	data, err := os.ReadFile(directory.path(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrAvatarNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read avatar file: %w", err)
+	}
+
+	return data, nil
+}
+
+func (directory AvatarsDirectory) DeleteAvatar(_ context.Context, name string) error {
+	err := os.Remove(directory.path(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrAvatarNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("delete avatar file: %w", err)
+	}
+
+	return nil
+}
+
+// path only uses the base name, so avatar names can never point outside of the directory.
+func (directory AvatarsDirectory) path(name string) string {
+	return filepath.Join(directory.dir, filepath.Base(name))
+}
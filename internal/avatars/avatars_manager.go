@@ -0,0 +1,115 @@
+package avatars
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"io"
+
+	_ "image/gif"
+	_ "image/png"
+
+	"github.com/spie/fskick/internal/players"
+	"github.com/spie/fskick/internal/uuid"
+)
+
+const (
+	AvatarSize        = 256
+	AvatarContentType = "image/jpeg"
+	// MaxImageDimension limits width and height of uploads, small files can declare huge
+	// images which would take gigabytes of memory to decode.
+	MaxImageDimension = 4096
+)
+
+var (
+	ErrAvatarNotFound = errors.New("avatar not found")
+	ErrInvalidImage   = errors.New("avatar has to be a jpeg, png or gif image")
+	ErrImageTooLarge  = errors.New("avatar image is too large")
+)
+
+type avatarStorage interface {
+	StoreAvatar(ctx context.Context, name string, contentType string, data []byte) error
+	LoadAvatar(ctx context.Context, name string) ([]byte, error)
+	DeleteAvatar(ctx context.Context, name string) error
+}
+
+type playersManager interface {
+	SetAvatar(ctx context.Context, player players.Player, avatar string) (players.Player, error)
+}
+
+type Manager struct {
+	storage        avatarStorage
+	playersManager playersManager
+}
+
+func NewManager(storage avatarStorage, playersManager playersManager) Manager {
+	return Manager{
+		storage:        storage,
+		playersManager: playersManager,
+	}
+}
+
+func (manager Manager) UploadAvatar(ctx context.Context, player players.Player, upload io.Reader) (players.Player, error) {
+	// the header read by DecodeConfig is replayed for Decode
+	var header bytes.Buffer
+	config, _, err := image.DecodeConfig(io.TeeReader(upload, &header))
+	if err != nil {
+		return players.Player{}, fmt.Errorf("%w: %w", ErrInvalidImage, err)
+	}
+	if config.Width > MaxImageDimension || config.Height > MaxImageDimension {
+		return players.Player{}, fmt.Errorf(
+			"%w: %dx%d, at most %dx%d",
+			ErrImageTooLarge,
+			config.Width,
+			config.Height,
+			MaxImageDimension,
+			MaxImageDimension,
+		)
+	}
+
+	img, _, err := image.Decode(io.MultiReader(&header, upload))
+	if err != nil {
+		return players.Player{}, fmt.Errorf("%w: %w", ErrInvalidImage, err)
+	}
+
+	var avatar bytes.Buffer
+	err = jpeg.Encode(&avatar, resizeAvatar(img, AvatarSize), &jpeg.Options{Quality: 85})
+	if err != nil {
+		return players.Player{}, fmt.Errorf("encode avatar: %w", err)
+	}
+
+	name, err := uuid.GenerateUuidString()
+	if err != nil {
+		return players.Player{}, fmt.Errorf("create avatar name: %w", err)
+	}
+	name = name + ".jpg"
+
+	err = manager.storage.StoreAvatar(ctx, name, AvatarContentType, avatar.Bytes())
+	if err != nil {
+		return players.Player{}, fmt.Errorf("store avatar: %w", err)
+	}
+
+	previousAvatar := player.Avatar
+
+	player, err = manager.playersManager.SetAvatar(ctx, player, name)
+	if err != nil {
+		manager.storage.DeleteAvatar(ctx, name)
+		return players.Player{}, err
+	}
+
+	if previousAvatar != "" {
+		err = manager.storage.DeleteAvatar(ctx, previousAvatar)
+		if err != nil && !errors.Is(err, ErrAvatarNotFound) {
+			return players.Player{}, fmt.Errorf("delete previous avatar: %w", err)
+		}
+	}
+
+	return player, nil
+}
+
+func (manager Manager) GetAvatar(ctx context.Context, name string) ([]byte, error) {
+	return manager.storage.LoadAvatar(ctx, name)
+}
@@ -0,0 +1,136 @@
+package avatars
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/spie/fskick/internal/players"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockAvatarStorage struct {
+	stored  map[string][]byte
+	deleted []string
+	err     error
+}
+
+func (storage *mockAvatarStorage) StoreAvatar(_ context.Context, name string, contentType string, data []byte) error {
+	if storage.err != nil {
+		return storage.err
+	}
+
+	storage.stored[name] = data
+
+	return nil
+}
+
+func (storage *mockAvatarStorage) LoadAvatar(_ context.Context, name string) ([]byte, error) {
+	data, ok := storage.stored[name]
+	if !ok {
+		return nil, ErrAvatarNotFound
+	}
+
+	return data, nil
+}
+
+func (storage *mockAvatarStorage) DeleteAvatar(_ context.Context, name string) error {
+	storage.deleted = append(storage.deleted, name)
+
+	return nil
+}
+
+type mockPlayersManager struct {
+	err error
+}
+
+func (manager mockPlayersManager) SetAvatar(_ context.Context, player players.Player, avatar string) (players.Player, error) {
+	player.Avatar = avatar
+
+	return player, manager.err
+}
+
+func createPng(width int, height int) []byte {
+	var buf bytes.Buffer
+	png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height)))
+
+	return buf.Bytes()
+}
+
+func TestAvatarsManager_UploadAvatar(t *testing.T) {
+	tests := map[string]struct {
+		player         players.Player
+		upload         []byte
+		playersManager mockPlayersManager
+		assertions     []func(t *testing.T, player players.Player, storage *mockAvatarStorage, err error)
+	}{
+		"with resized avatar stored": {
+			player: players.Player{Name: "test_player", Avatar: "previous.jpg"},
+			upload: createPng(800, 600),
+			assertions: []func(t *testing.T, player players.Player, storage *mockAvatarStorage, err error){
+				func(t *testing.T, player players.Player, storage *mockAvatarStorage, err error) {
+					assert.NoError(t, err)
+					assert.True(t, strings.HasSuffix(player.Avatar, ".jpg"))
+					assert.Equal(t, []string{"previous.jpg"}, storage.deleted)
+
+					img, err := jpeg.Decode(bytes.NewReader(storage.stored[player.Avatar]))
+					assert.NoError(t, err)
+					assert.Equal(t, AvatarSize, img.Bounds().Dx())
+					assert.Equal(t, AvatarSize, img.Bounds().Dy())
+				},
+			},
+		},
+		"with invalid image": {
+			player: players.Player{Name: "test_player"},
+			upload: []byte("no image"),
+			assertions: []func(t *testing.T, player players.Player, storage *mockAvatarStorage, err error){
+				func(t *testing.T, player players.Player, storage *mockAvatarStorage, err error) {
+					assert.Zero(t, player)
+					assert.ErrorIs(t, err, ErrInvalidImage)
+					assert.Empty(t, storage.stored)
+				},
+			},
+		},
+		"with too large image": {
+			player: players.Player{Name: "test_player"},
+			upload: createPng(MaxImageDimension+1, 1),
+			assertions: []func(t *testing.T, player players.Player, storage *mockAvatarStorage, err error){
+				func(t *testing.T, player players.Player, storage *mockAvatarStorage, err error) {
+					assert.Zero(t, player)
+					assert.ErrorIs(t, err, ErrImageTooLarge)
+					assert.Empty(t, storage.stored)
+				},
+			},
+		},
+		"with error on player update": {
+			player:         players.Player{Name: "test_player"},
+			upload:         createPng(10, 10),
+			playersManager: mockPlayersManager{err: errors.New("some error")},
+			assertions: []func(t *testing.T, player players.Player, storage *mockAvatarStorage, err error){
+				func(t *testing.T, player players.Player, storage *mockAvatarStorage, err error) {
+					assert.Zero(t, player)
+					assert.ErrorContains(t, err, "some error")
+					assert.Len(t, storage.deleted, 1)
+				},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			storage := &mockAvatarStorage{stored: map[string][]byte{}}
+			manager := NewManager(storage, tt.playersManager)
+
+			player, err := manager.UploadAvatar(context.Background(), tt.player, bytes.NewReader(tt.upload))
+
+			for _, assertion := range tt.assertions {
+				assertion(t, player, storage, err)
+			}
+		})
+	}
+}
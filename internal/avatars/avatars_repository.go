@@ -0,0 +1,59 @@
+package avatars
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spie/fskick/internal/db"
+)
+
+type AvatarsRepository struct {
+	conn db.Connection
+}
+
+func NewAvatarsRepository(conn db.Connection) AvatarsRepository {
+	return AvatarsRepository{conn: conn}
+}
+
+func (repository AvatarsRepository) StoreAvatar(ctx context.Context, name string, contentType string, data []byte) error {
+	_, err := repository.conn.ExecContext(
+		ctx,
+		`INSERT INTO avatars (name, content_type, data, created_at)
+		VALUES ($1, $2, $3, $4)`,
+		name,
+		contentType,
+		data,
+		time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("insert avatar: %w", err)
+	}
+
+	return nil
+}
+
+func (repository AvatarsRepository) LoadAvatar(ctx context.Context, name string) ([]byte, error) {
+	var data []byte
+	err := repository.conn.
+		QueryRowContext(ctx, "SELECT data FROM avatars WHERE name = $1", name).
+		Scan(&data)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, ErrAvatarNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query avatar: %w", err)
+	}
+
+	return data, nil
+}
+
+func (repository AvatarsRepository) DeleteAvatar(ctx context.Context, name string) error {
+	_, err := repository.conn.ExecContext(ctx, "DELETE FROM avatars WHERE name = $1", name)
+	if err != nil {
+		return fmt.Errorf("delete avatar: %w", err)
+	}
+
+	return nil
+}
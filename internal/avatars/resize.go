@@ -0,0 +1,24 @@
+package avatars
+
+import (
+	"image"
+
+	"golang.org/x/image/draw"
+)
+
+// resizeAvatar crops the largest centered square out of the image and scales it to size x size.
+func resizeAvatar(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	edge := min(bounds.Dx(), bounds.Dy())
+	crop := image.Rect(
+		bounds.Min.X+(bounds.Dx()-edge)/2,
+		bounds.Min.Y+(bounds.Dy()-edge)/2,
+		bounds.Min.X+(bounds.Dx()-edge)/2+edge,
+		bounds.Min.Y+(bounds.Dy()-edge)/2+edge,
+	)
+
+	avatar := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.CatmullRom.Scale(avatar, avatar.Bounds(), img, crop, draw.Src, nil)
+
+	return avatar
+}
@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Stats counts the lookups answered from the cache and the ones that had to be loaded.
+type Stats struct {
+	Hits    uint64
+	Misses  uint64
+	Entries int
+}
+
+type entry struct {
+	value     any
+	expiresAt time.Time
+}
+
+// Cache is an in-memory cache for computed results. All entries are dropped on Invalidate,
+// entries also expire after the ttl to pick up changes made by other processes, like the
+// cli. A nil *Cache caches nothing.
+type Cache struct {
+	mutex      sync.RWMutex
+	entries    map[string]entry
+	generation uint64
+	ttl        time.Duration
+	hits       atomic.Uint64
+	misses     atomic.Uint64
+	now        func() time.Time
+}
+
+func New(ttl time.Duration) *Cache {
+	return &Cache{
+		entries: map[string]entry{},
+		ttl:     ttl,
+		now:     time.Now,
+	}
+}
+
+// Invalidate drops all entries. Results loaded while invalidating are not stored.
+func (cache *Cache) Invalidate() {
+	if cache == nil {
+		return
+	}
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	cache.entries = map[string]entry{}
+	cache.generation++
+}
+
+func (cache *Cache) Stats() Stats {
+	if cache == nil {
+		return Stats{}
+	}
+
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+
+	return Stats{
+		Hits:    cache.hits.Load(),
+		Misses:  cache.misses.Load(),
+		Entries: len(cache.entries),
+	}
+}
+
+func (cache *Cache) get(key string) (any, uint64, bool) {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+
+	entry, ok := cache.entries[key]
+	if !ok || !cache.now().Before(entry.expiresAt) {
+		cache.misses.Add(1)
+		return nil, cache.generation, false
+	}
+
+	cache.hits.Add(1)
+
+	return entry.value, cache.generation, true
+}
+
+func (cache *Cache) set(key string, value any, generation uint64) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if generation != cache.generation {
+		return
+	}
+
+	cache.entries[key] = entry{value: value, expiresAt: cache.now().Add(cache.ttl)}
+}
+
+// Remember returns the cached value of key or loads and caches it. Errors are not cached.
+func Remember[T any](cache *Cache, key string, load func() (T, error)) (T, error) {
+	if cache == nil {
+		return load()
+	}
+
+	value, generation, ok := cache.get(key)
+	if ok {
+		return value.(T), nil
+	}
+
+	loaded, err := load()
+	if err != nil {
+		return loaded, err
+	}
+
+	cache.set(key, loaded, generation)
+
+	return loaded, nil
+}
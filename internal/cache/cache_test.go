@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemember(t *testing.T) {
+	tests := map[string]struct {
+		run        func(cache *Cache, load func() (int, error)) (int, error)
+		assertions []func(t *testing.T, value int, err error, loads int, stats Stats)
+	}{
+		"loads on first lookup": {
+			run: func(cache *Cache, load func() (int, error)) (int, error) {
+				return Remember(cache, "key", load)
+			},
+			assertions: []func(t *testing.T, value int, err error, loads int, stats Stats){
+				func(t *testing.T, value int, err error, loads int, stats Stats) {
+					assert.NoError(t, err)
+					assert.Equal(t, 1, value)
+					assert.Equal(t, 1, loads)
+					assert.Equal(t, Stats{Hits: 0, Misses: 1, Entries: 1}, stats)
+				},
+			},
+		},
+		"returns cached value": {
+			run: func(cache *Cache, load func() (int, error)) (int, error) {
+				Remember(cache, "key", load)
+				return Remember(cache, "key", load)
+			},
+			assertions: []func(t *testing.T, value int, err error, loads int, stats Stats){
+				func(t *testing.T, value int, err error, loads int, stats Stats) {
+					assert.NoError(t, err)
+					assert.Equal(t, 1, value)
+					assert.Equal(t, 1, loads)
+					assert.Equal(t, Stats{Hits: 1, Misses: 1, Entries: 1}, stats)
+				},
+			},
+		},
+		"loads again after invalidate": {
+			run: func(cache *Cache, load func() (int, error)) (int, error) {
+				Remember(cache, "key", load)
+				cache.Invalidate()
+				return Remember(cache, "key", load)
+			},
+			assertions: []func(t *testing.T, value int, err error, loads int, stats Stats){
+				func(t *testing.T, value int, err error, loads int, stats Stats) {
+					assert.NoError(t, err)
+					assert.Equal(t, 2, value)
+					assert.Equal(t, 2, loads)
+					assert.Equal(t, Stats{Hits: 0, Misses: 2, Entries: 1}, stats)
+				},
+			},
+		},
+		"loads again after ttl": {
+			run: func(cache *Cache, load func() (int, error)) (int, error) {
+				Remember(cache, "key", load)
+				now := cache.now()
+				cache.now = func() time.Time { return now.Add(time.Minute) }
+				return Remember(cache, "key", load)
+			},
+			assertions: []func(t *testing.T, value int, err error, loads int, stats Stats){
+				func(t *testing.T, value int, err error, loads int, stats Stats) {
+					assert.NoError(t, err)
+					assert.Equal(t, 2, value)
+					assert.Equal(t, 2, loads)
+				},
+			},
+		},
+		"does not store value loaded during invalidate": {
+			run: func(cache *Cache, load func() (int, error)) (int, error) {
+				Remember(cache, "key", func() (int, error) {
+					cache.Invalidate()
+					return load()
+				})
+				return Remember(cache, "key", load)
+			},
+			assertions: []func(t *testing.T, value int, err error, loads int, stats Stats){
+				func(t *testing.T, value int, err error, loads int, stats Stats) {
+					assert.NoError(t, err)
+					assert.Equal(t, 2, value)
+					assert.Equal(t, 2, loads)
+				},
+			},
+		},
+		"does not cache errors": {
+			run: func(cache *Cache, load func() (int, error)) (int, error) {
+				Remember(cache, "key", func() (int, error) {
+					return 0, errors.New("some error")
+				})
+				return Remember(cache, "key", load)
+			},
+			assertions: []func(t *testing.T, value int, err error, loads int, stats Stats){
+				func(t *testing.T, value int, err error, loads int, stats Stats) {
+					assert.NoError(t, err)
+					assert.Equal(t, 1, value)
+					assert.Equal(t, 1, loads)
+				},
+			},
+		},
+		"without cache": {
+			run: func(cache *Cache, load func() (int, error)) (int, error) {
+				var noCache *Cache
+				Remember(noCache, "key", load)
+				return Remember(noCache, "key", load)
+			},
+			assertions: []func(t *testing.T, value int, err error, loads int, stats Stats){
+				func(t *testing.T, value int, err error, loads int, stats Stats) {
+					assert.NoError(t, err)
+					assert.Equal(t, 2, value)
+					assert.Equal(t, 2, loads)
+					assert.Equal(t, Stats{}, stats)
+				},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cache := New(time.Second)
+			loads := 0
+			load := func() (int, error) {
+				loads++
+				return loads, nil
+			}
+
+			value, err := tt.run(cache, load)
+
+			for _, assertion := range tt.assertions {
+				assertion(t, value, err, loads, cache.Stats())
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type OrphanedAttendance struct {
+	ID            uint
+	UUID          string
+	GameID        uint
+	PlayerID      uint
+	MissingGame   bool
+	MissingPlayer bool
+}
+
+// GameTeams holds the team sizes of a game.
+type GameTeams struct {
+	GameUUID string
+	PlayedAt time.Time
+	Team1    int
+	Team2    int
+}
+
+// HasEmptyTeam reports if nobody played in team 1 or team 2.
+func (game GameTeams) HasEmptyTeam() bool {
+	return game.Team1 == 0 || game.Team2 == 0
+}
+
+// RepeatedAttendance is a player attending a game more than once, in the same team or in both.
+type RepeatedAttendance struct {
+	GameUUID   string
+	PlayerName string
+	Count      int
+	BothTeams  bool
+}
+
+type Report struct {
+	OrphanedAttendances []OrphanedAttendance
+	InvalidTeams        []GameTeams
+	RepeatedAttendances []RepeatedAttendance
+	ActiveSeasons       []string
+}
+
+func (report Report) HasMultipleActiveSeasons() bool {
+	return len(report.ActiveSeasons) > 1
+}
+
+// EmptyTeamGamesCount counts the games which are deleted by a fix with FixOptions.DeleteGames.
+func (report Report) EmptyTeamGamesCount() int {
+	count := 0
+	for _, game := range report.InvalidTeams {
+		if game.HasEmptyTeam() {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (report Report) ProblemsCount() int {
+	count := len(report.OrphanedAttendances) + len(report.InvalidTeams) + len(report.RepeatedAttendances)
+	if report.HasMultipleActiveSeasons() {
+		count++
+	}
+
+	return count
+}
+
+// FixReport counts the rows changed by the fix.
+type FixReport struct {
+	DeletedAttendances int64
+	DeletedGames       int64
+	DeactivatedSeasons int64
+}
+
+// FixOptions selects the repairs which can not be undone.
+type FixOptions struct {
+	DeleteGames bool
+}
+
+type Manager struct {
+	repository ChecksRepository
+}
+
+func NewManager(repository ChecksRepository) Manager {
+	return Manager{repository: repository}
+}
+
+func (manager Manager) Check(ctx context.Context) (Report, error) {
+	report, err := check(ctx, manager.repository)
+	if err != nil {
+		return Report{}, fmt.Errorf("check: %w", err)
+	}
+
+	return report, nil
+}
+
+// Fix repairs the problems in one transaction: orphaned attendances are deleted, repeated
+// attendances are deleted except the first one and only the latest created active season stays
+// active. Games with an empty team are only deleted with options.DeleteGames, callers should
+// confirm it or back up the database first. Games with teams of different sizes can not be
+// repaired automatically and stay untouched.
+func (manager Manager) Fix(ctx context.Context, options FixOptions) (FixReport, error) {
+	report, err := manager.repository.Fix(ctx, options)
+	if err != nil {
+		return FixReport{}, fmt.Errorf("fix: %w", err)
+	}
+
+	return report, nil
+}
+
+func check(ctx context.Context, repository ChecksRepository) (Report, error) {
+	var report Report
+	var err error
+
+	report.OrphanedAttendances, err = repository.FindOrphanedAttendances(ctx)
+	if err != nil {
+		return Report{}, err
+	}
+
+	report.InvalidTeams, err = repository.FindGamesWithInvalidTeams(ctx)
+	if err != nil {
+		return Report{}, err
+	}
+
+	report.RepeatedAttendances, err = repository.FindRepeatedAttendances(ctx)
+	if err != nil {
+		return Report{}, err
+	}
+
+	report.ActiveSeasons, err = repository.FindActiveSeasonNames(ctx)
+	if err != nil {
+		return Report{}, err
+	}
+
+	return report, nil
+}
@@ -0,0 +1,49 @@
+package checks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReport(t *testing.T) {
+	tests := map[string]struct {
+		report     Report
+		assertions []func(t *testing.T, report Report)
+	}{
+		"without problems": {
+			report: Report{ActiveSeasons: []string{"2024"}},
+			assertions: []func(t *testing.T, report Report){
+				func(t *testing.T, report Report) {
+					assert.Equal(t, 0, report.ProblemsCount())
+					assert.Equal(t, 0, report.EmptyTeamGamesCount())
+					assert.False(t, report.HasMultipleActiveSeasons())
+				},
+			},
+		},
+		"with empty and unbalanced teams": {
+			report: Report{
+				InvalidTeams: []GameTeams{
+					{GameUUID: "empty", Team1: 2, Team2: 0},
+					{GameUUID: "unbalanced", Team1: 2, Team2: 1},
+				},
+				ActiveSeasons: []string{"2024", "2025"},
+			},
+			assertions: []func(t *testing.T, report Report){
+				func(t *testing.T, report Report) {
+					assert.Equal(t, 3, report.ProblemsCount())
+					assert.Equal(t, 1, report.EmptyTeamGamesCount())
+					assert.True(t, report.HasMultipleActiveSeasons())
+				},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			for _, assertion := range tt.assertions {
+				assertion(t, tt.report)
+			}
+		})
+	}
+}
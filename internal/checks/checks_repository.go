@@ -0,0 +1,245 @@
+package checks
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/spie/fskick/internal/db"
+)
+
+type ChecksRepository struct {
+	conn db.Connection
+}
+
+func NewChecksRepository(conn db.Connection) ChecksRepository {
+	return ChecksRepository{conn: conn}
+}
+
+// FindOrphanedAttendances returns the attendances of games or players that do not exist.
+func (repository ChecksRepository) FindOrphanedAttendances(ctx context.Context) ([]OrphanedAttendance, error) {
+	rows, err := repository.conn.QueryContext(
+		ctx,
+		`SELECT a.id, COALESCE(a.uuid, ''), a.game_id, a.player_id, g.id IS NULL, p.id IS NULL
+		FROM attendances a
+		LEFT JOIN games g ON g.id = a.game_id
+		LEFT JOIN players p ON p.id = a.player_id
+		WHERE g.id IS NULL OR p.id IS NULL
+		ORDER BY a.id ASC`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query orphaned attendances: %w", err)
+	}
+	defer rows.Close()
+
+	attendances := []OrphanedAttendance{}
+	for rows.Next() {
+		var attendance OrphanedAttendance
+		err = rows.Scan(
+			&attendance.ID,
+			&attendance.UUID,
+			&attendance.GameID,
+			&attendance.PlayerID,
+			&attendance.MissingGame,
+			&attendance.MissingPlayer,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan orphaned attendances: %w", err)
+		}
+
+		attendances = append(attendances, attendance)
+	}
+
+	return attendances, nil
+}
+
+// FindGamesWithInvalidTeams returns the games with an empty team or teams of different sizes.
+func (repository ChecksRepository) FindGamesWithInvalidTeams(ctx context.Context) ([]GameTeams, error) {
+	rows, err := repository.conn.QueryContext(
+		ctx,
+		`SELECT
+			g.uuid,
+			g.played_at,
+			COALESCE(SUM(CASE WHEN a.team = 1 THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN a.team = 2 THEN 1 ELSE 0 END), 0)
+		FROM games g
+		LEFT JOIN attendances a ON a.game_id = g.id
+		GROUP BY g.id, g.uuid, g.played_at
+		HAVING COALESCE(SUM(CASE WHEN a.team = 1 THEN 1 ELSE 0 END), 0)
+			!= COALESCE(SUM(CASE WHEN a.team = 2 THEN 1 ELSE 0 END), 0)
+			OR COUNT(a.id) = 0
+		ORDER BY g.played_at ASC, g.id ASC`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query games with invalid teams: %w", err)
+	}
+	defer rows.Close()
+
+	games := []GameTeams{}
+	for rows.Next() {
+		var game GameTeams
+		var playedAt sql.NullTime
+		err = rows.Scan(&game.GameUUID, &playedAt, &game.Team1, &game.Team2)
+		if err != nil {
+			return nil, fmt.Errorf("scan games with invalid teams: %w", err)
+		}
+
+		game.PlayedAt = playedAt.Time
+		games = append(games, game)
+	}
+
+	return games, nil
+}
+
+// FindRepeatedAttendances returns the players attending a game more than once.
+func (repository ChecksRepository) FindRepeatedAttendances(ctx context.Context) ([]RepeatedAttendance, error) {
+	rows, err := repository.conn.QueryContext(
+		ctx,
+		`SELECT g.uuid, p.name, COUNT(a.id), COUNT(DISTINCT a.team)
+		FROM attendances a
+		JOIN games g ON g.id = a.game_id
+		JOIN players p ON p.id = a.player_id
+		GROUP BY a.game_id, a.player_id, g.uuid, p.name
+		HAVING COUNT(a.id) > 1
+		ORDER BY a.game_id ASC, p.name ASC`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query repeated attendances: %w", err)
+	}
+	defer rows.Close()
+
+	attendances := []RepeatedAttendance{}
+	for rows.Next() {
+		var attendance RepeatedAttendance
+		var teams int
+		err = rows.Scan(&attendance.GameUUID, &attendance.PlayerName, &attendance.Count, &teams)
+		if err != nil {
+			return nil, fmt.Errorf("scan repeated attendances: %w", err)
+		}
+
+		attendance.BothTeams = teams > 1
+		attendances = append(attendances, attendance)
+	}
+
+	return attendances, nil
+}
+
+func (repository ChecksRepository) FindActiveSeasonNames(ctx context.Context) ([]string, error) {
+	rows, err := repository.conn.QueryContext(ctx, "SELECT name FROM seasons WHERE active = true ORDER BY id ASC")
+	if err != nil {
+		return nil, fmt.Errorf("query active seasons: %w", err)
+	}
+	defer rows.Close()
+
+	names := []string{}
+	for rows.Next() {
+		var name string
+		err = rows.Scan(&name)
+		if err != nil {
+			return nil, fmt.Errorf("scan active seasons: %w", err)
+		}
+
+		names = append(names, name)
+	}
+
+	return names, nil
+}
+
+// Fix runs the repairs in one transaction.
+func (repository ChecksRepository) Fix(ctx context.Context, options FixOptions) (FixReport, error) {
+	report := FixReport{}
+
+	err := db.Transaction(ctx, repository.conn, func(tx db.Connection) error {
+		txRepository := NewChecksRepository(tx)
+
+		orphaned, err := txRepository.DeleteOrphanedAttendances(ctx)
+		if err != nil {
+			return err
+		}
+
+		repeated, err := txRepository.DeleteRepeatedAttendances(ctx)
+		if err != nil {
+			return err
+		}
+		report.DeletedAttendances = orphaned + repeated
+
+		if options.DeleteGames {
+			report.DeletedGames, err = txRepository.DeleteGamesWithEmptyTeams(ctx)
+			if err != nil {
+				return err
+			}
+		}
+
+		report.DeactivatedSeasons, err = txRepository.DeactivateOlderActiveSeasons(ctx)
+
+		return err
+	})
+	if err != nil {
+		return FixReport{}, err
+	}
+
+	return report, nil
+}
+
+func (repository ChecksRepository) DeleteOrphanedAttendances(ctx context.Context) (int64, error) {
+	result, err := repository.conn.ExecContext(
+		ctx,
+		`DELETE FROM attendances
+		WHERE game_id NOT IN (SELECT id FROM games) OR player_id NOT IN (SELECT id FROM players)`,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("delete orphaned attendances: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// DeleteRepeatedAttendances keeps the first attendance of a player in a game and deletes the
+// later ones.
+func (repository ChecksRepository) DeleteRepeatedAttendances(ctx context.Context) (int64, error) {
+	result, err := repository.conn.ExecContext(
+		ctx,
+		`DELETE FROM attendances
+		WHERE id NOT IN (SELECT MIN(id) FROM attendances GROUP BY game_id, player_id)`,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("delete repeated attendances: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// DeleteGamesWithEmptyTeams deletes the games missing team 1 or team 2, together with the
+// attendances of the remaining team.
+func (repository ChecksRepository) DeleteGamesWithEmptyTeams(ctx context.Context) (int64, error) {
+	emptyTeamGames := `SELECT g.id
+		FROM games g
+		WHERE NOT EXISTS (SELECT 1 FROM attendances a WHERE a.game_id = g.id AND a.team = 1)
+		OR NOT EXISTS (SELECT 1 FROM attendances a WHERE a.game_id = g.id AND a.team = 2)`
+
+	_, err := repository.conn.ExecContext(ctx, fmt.Sprintf("DELETE FROM attendances WHERE game_id IN (%s)", emptyTeamGames))
+	if err != nil {
+		return 0, fmt.Errorf("delete attendances of games with empty teams: %w", err)
+	}
+
+	result, err := repository.conn.ExecContext(ctx, fmt.Sprintf("DELETE FROM games WHERE id IN (%s)", emptyTeamGames))
+	if err != nil {
+		return 0, fmt.Errorf("delete games with empty teams: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// DeactivateOlderActiveSeasons keeps the latest created active season active.
+func (repository ChecksRepository) DeactivateOlderActiveSeasons(ctx context.Context) (int64, error) {
+	result, err := repository.conn.ExecContext(
+		ctx,
+		`UPDATE seasons SET active = false
+		WHERE active = true AND id != (SELECT MAX(id) FROM seasons WHERE active = true)`,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("deactivate older active seasons: %w", err)
+	}
+
+	return result.RowsAffected()
+}
@@ -6,7 +6,7 @@ import (
 
 	"github.com/jedib0t/go-pretty/v6/table"
 
-	"github.com/spie/fskick/internal/games"
+	"github.com/spie/fskick/internal/tables"
 )
 
 func Print(output string) {
@@ -47,33 +47,7 @@ func transformEntries(entries [][]string) []table.Row {
 	return rows
 }
 
-func CreateTableHead(gamesCount int, playersCount int) []string {
-	return []string{
-		fmt.Sprintf("Position (%d)", playersCount),
-		"Name",
-		"Points Ratio",
-		"Points",
-		"Wins",
-		fmt.Sprintf("Games (%d)", gamesCount),
-		"Win Ratio",
-		"Games Ratio",
-	}
-}
-
-func CreateTableEntries(gamesCount int, playerStats []games.PlayerStats) [][]string {
-	tableEntries := make([][]string, len(playerStats))
-	for i, playerStats := range playerStats {
-		tableEntries[i] = []string{
-			fmt.Sprint(playerStats.Position),
-			playerStats.Name,
-			fmt.Sprintf("%0.2f", playerStats.PointsRatio),
-			fmt.Sprint(playerStats.Points),
-			fmt.Sprint(playerStats.Wins),
-			fmt.Sprint(playerStats.Games),
-			fmt.Sprintf("%0.2f", (float32(playerStats.Wins) / float32(playerStats.Games))),
-			fmt.Sprintf("%0.2f", (float32(playerStats.Games) / float32(gamesCount))),
-		}
-	}
-
-	return tableEntries
+// PrintFormatted prints the table in the given format of the tables package.
+func PrintFormatted(t tables.Table, format string) error {
+	return tables.Render(os.Stdout, t, format)
 }
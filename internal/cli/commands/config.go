@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/spie/fskick/internal/cli"
+	"github.com/spie/fskick/internal/config"
+	"github.com/spie/fskick/internal/tables"
+)
+
+type configCommand struct {
+	command
+}
+
+func NewConfigCommand() *configCommand {
+	configCommand := configCommand{command: newCommand(&cobra.Command{
+		Use:   "config",
+		Short: "Commands to inspect the config",
+		Long:  "All commands to inspect the config read from the defaults, the config file, the environment and the flags.",
+		// Overrides the root's hook, showing the config must not touch the database.
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return nil
+		},
+	})}
+
+	return &configCommand
+}
+
+type showConfigCommand struct {
+	command
+	cfg config.AppConfig
+}
+
+func NewShowConfigCommand(cfg config.AppConfig) *showConfigCommand {
+	showConfigCommand := &showConfigCommand{cfg: cfg}
+
+	cc := &cobra.Command{
+		Use:   "show",
+		Short: "Show the effective config",
+		Long:  "Show the effective value of every setting and where it came from. Passwords are redacted.",
+		Args:  cobra.NoArgs,
+		RunE:  showConfigCommand.showConfig,
+	}
+
+	addOutputFlag(cc)
+
+	showConfigCommand.command = newCommand(cc)
+
+	return showConfigCommand
+}
+
+func (showConfigCommand *showConfigCommand) showConfig(cmd *cobra.Command, _ []string) error {
+	output, err := getOutputFormat(cmd)
+	if err != nil {
+		return err
+	}
+
+	settingsTable := tables.Table{
+		Columns: []tables.Column{
+			{Key: "setting", Title: "Setting"},
+			{Key: "value", Title: "Value"},
+			{Key: "source", Title: "Source"},
+			{Key: "env", Title: "Env"},
+			{Key: "flag", Title: "Flag"},
+		},
+	}
+	for _, setting := range showConfigCommand.cfg.Settings {
+		settingsTable.Rows = append(
+			settingsTable.Rows,
+			[]any{setting.Key, setting.Redacted(), setting.Source, setting.Env, "--" + setting.Flag},
+		)
+	}
+
+	if output == tables.FormatTable {
+		file := showConfigCommand.cfg.File
+		if file == "" {
+			file = "none"
+		}
+		cli.Print(fmt.Sprintf("Config file: %s", file))
+	}
+
+	return cli.PrintFormatted(settingsTable, output)
+}
@@ -0,0 +1,385 @@
+package commands
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/spie/fskick/internal/checks"
+	"github.com/spie/fskick/internal/cli"
+	"github.com/spie/fskick/internal/db"
+)
+
+type dbCommand struct {
+	command
+}
+
+func NewDbCommand() *dbCommand {
+	dbCommand := dbCommand{command: newCommand(&cobra.Command{
+		Use:   "db",
+		Short: "Commands to maintain the database",
+		Long:  "All commands to maintain the database like running migrations, creating backups and checking the data.",
+		// Overrides the root's hook, the db commands must not migrate the database implicitly.
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return nil
+		},
+	})}
+
+	return &dbCommand
+}
+
+type migrateCommand struct {
+	command
+	migrator db.Migrator
+}
+
+func NewMigrateCommand() *migrateCommand {
+	migrateCommand := migrateCommand{command: newCommand(&cobra.Command{
+		Use:   "migrate",
+		Short: "Commands to run migrations",
+		Long:  "Commands to show the migration status and apply or roll back migrations",
+	})}
+
+	return &migrateCommand
+}
+
+func NewMigrateStatusCommand(migrator db.Migrator) *migrateCommand {
+	migrateCommand := &migrateCommand{migrator: migrator}
+
+	migrateCommand.command = newCommand(&cobra.Command{
+		Use:   "status",
+		Short: "Show the migration status",
+		Long:  "Show all migrations and whether they are applied",
+		RunE:  migrateCommand.status,
+	})
+
+	return migrateCommand
+}
+
+func NewMigrateUpCommand(migrator db.Migrator) *migrateCommand {
+	migrateCommand := &migrateCommand{migrator: migrator}
+
+	migrateCommand.command = newCommand(&cobra.Command{
+		Use:   "up",
+		Short: "Apply all pending migrations",
+		Long:  "Apply all pending migrations",
+		RunE:  migrateCommand.up,
+	})
+
+	return migrateCommand
+}
+
+func NewMigrateDownCommand(migrator db.Migrator) *migrateCommand {
+	migrateCommand := &migrateCommand{migrator: migrator}
+
+	migrateCommand.command = newCommand(&cobra.Command{
+		Use:   "down",
+		Short: "Roll back the latest migration",
+		Long: "Roll back the latest applied migration. Other commands and the server apply it again, " +
+			"unless db.auto_migrate is false.",
+		RunE: migrateCommand.down,
+	})
+
+	return migrateCommand
+}
+
+func NewMigrateRedoCommand(migrator db.Migrator) *migrateCommand {
+	migrateCommand := &migrateCommand{migrator: migrator}
+
+	migrateCommand.command = newCommand(&cobra.Command{
+		Use:   "redo",
+		Short: "Roll back and apply the latest migration again",
+		Long:  "Roll back the latest applied migration and apply it again",
+		RunE:  migrateCommand.redo,
+	})
+
+	return migrateCommand
+}
+
+func (command *migrateCommand) status(cmd *cobra.Command, args []string) error {
+	statuses, err := command.migrator.Status(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	entries := make([][]string, len(statuses))
+	for i, status := range statuses {
+		state, appliedAt := "Pending", ""
+		if status.Applied {
+			state, appliedAt = "Applied", status.AppliedAt.Local().Format("2006-01-02 15:04")
+		}
+
+		entries[i] = []string{fmt.Sprint(status.Version), status.Name, state, appliedAt}
+	}
+
+	cli.PrintTable([]string{"Version", "Name", "Status", "Applied At"}, entries)
+
+	return nil
+}
+
+func (command *migrateCommand) up(cmd *cobra.Command, args []string) error {
+	results, err := command.migrator.Up(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	if len(results) == 0 {
+		cli.Print("No pending migrations")
+		return nil
+	}
+
+	printMigrationResults(results)
+
+	return nil
+}
+
+func (command *migrateCommand) down(cmd *cobra.Command, args []string) error {
+	result, err := command.migrator.Down(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	printMigrationResults([]db.MigrationResult{result})
+
+	return nil
+}
+
+func (command *migrateCommand) redo(cmd *cobra.Command, args []string) error {
+	results, err := command.migrator.Redo(cmd.Context())
+	printMigrationResults(results)
+
+	return err
+}
+
+func printMigrationResults(results []db.MigrationResult) {
+	entries := make([][]string, len(results))
+	for i, result := range results {
+		entries[i] = []string{result.Direction, fmt.Sprint(result.Version), result.Name, result.Duration.String()}
+	}
+
+	cli.PrintTable([]string{"Direction", "Version", "Name", "Duration"}, entries)
+}
+
+type backupCommand struct {
+	command
+	conn   *sql.DB
+	driver string
+}
+
+func NewBackupCommand(conn *sql.DB, driver string) *backupCommand {
+	backupCommand := &backupCommand{conn: conn, driver: driver}
+
+	cc := &cobra.Command{
+		Use:   "backup <path>",
+		Short: "Back up the database to a file",
+		Long:  "Copy the sqlite3 database to a new file while it may be in use. Postgres databases are backed up with pg_dump.",
+		Args:  cobra.ExactArgs(1),
+		RunE:  backupCommand.backup,
+	}
+
+	backupCommand.command = newCommand(cc)
+
+	return backupCommand
+}
+
+func (command *backupCommand) backup(cmd *cobra.Command, args []string) error {
+	err := db.Backup(cmd.Context(), command.conn, command.driver, args[0])
+	if err != nil {
+		return err
+	}
+
+	cli.Print(fmt.Sprintf("Database backed up to %s", args[0]))
+
+	return nil
+}
+
+type vacuumCommand struct {
+	command
+	conn *sql.DB
+}
+
+func NewVacuumCommand(conn *sql.DB) *vacuumCommand {
+	vacuumCommand := &vacuumCommand{conn: conn}
+
+	cc := &cobra.Command{
+		Use:   "vacuum",
+		Short: "Reclaim unused space of the database",
+		Long:  "Rebuild the database to reclaim the space of deleted data",
+		RunE:  vacuumCommand.vacuum,
+	}
+
+	vacuumCommand.command = newCommand(cc)
+
+	return vacuumCommand
+}
+
+func (command *vacuumCommand) vacuum(cmd *cobra.Command, args []string) error {
+	err := db.Vacuum(cmd.Context(), command.conn)
+	if err != nil {
+		return err
+	}
+
+	cli.Print("Database vacuumed")
+
+	return nil
+}
+
+type checkCommand struct {
+	command
+	checksManager checks.Manager
+	conn          *sql.DB
+	driver        string
+}
+
+func NewCheckCommand(checksManager checks.Manager, conn *sql.DB, driver string) *checkCommand {
+	checkCommand := &checkCommand{checksManager: checksManager, conn: conn, driver: driver}
+
+	cc := &cobra.Command{
+		Use:   "check",
+		Short: "Check the data for inconsistencies",
+		Long: "Report orphaned attendances, games with empty or unbalanced teams, players attending a game more than once " +
+			"and multiple active seasons. With --fix the problems are repaired, except unbalanced teams. " +
+			"Games with an empty team are only deleted after a confirmation or a backup with --backup.",
+		RunE: checkCommand.check,
+	}
+
+	cc.Flags().Bool("fix", false, "Repair the found problems")
+	cc.Flags().String("backup", "", "Back up the sqlite3 database to this file before repairing")
+
+	checkCommand.command = newCommand(cc)
+
+	return checkCommand
+}
+
+func (command *checkCommand) check(cmd *cobra.Command, args []string) error {
+	report, err := command.checksManager.Check(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	if report.ProblemsCount() == 0 {
+		cli.Print("No problems found")
+		return nil
+	}
+
+	printCheckReport(report)
+
+	fix, _ := cmd.Flags().GetBool("fix")
+	if !fix {
+		cli.Print(fmt.Sprintf("%d problems found, run with --fix to repair them", report.ProblemsCount()))
+		return nil
+	}
+
+	options, err := command.getFixOptions(cmd, report)
+	if err != nil {
+		return err
+	}
+
+	fixReport, err := command.checksManager.Fix(cmd.Context(), options)
+	if err != nil {
+		return err
+	}
+
+	cli.PrintTable(
+		[]string{},
+		[][]string{
+			{"Deleted attendances", fmt.Sprint(fixReport.DeletedAttendances)},
+			{"Deleted games", fmt.Sprint(fixReport.DeletedGames)},
+			{"Deactivated seasons", fmt.Sprint(fixReport.DeactivatedSeasons)},
+		},
+	)
+
+	report, err = command.checksManager.Check(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	cli.Print(fmt.Sprintf("%d problems left", report.ProblemsCount()))
+
+	return nil
+}
+
+// getFixOptions backs up the database if requested. Games are only deleted with a backup or
+// after a confirmation, as deleting them can not be undone.
+func (command *checkCommand) getFixOptions(cmd *cobra.Command, report checks.Report) (checks.FixOptions, error) {
+	backup, _ := cmd.Flags().GetString("backup")
+	if backup != "" {
+		err := db.Backup(cmd.Context(), command.conn, command.driver, backup)
+		if err != nil {
+			return checks.FixOptions{}, err
+		}
+
+		cli.Print(fmt.Sprintf("Database backed up to %s", backup))
+
+		return checks.FixOptions{DeleteGames: true}, nil
+	}
+
+	gamesCount := report.EmptyTeamGamesCount()
+	if gamesCount == 0 {
+		return checks.FixOptions{}, nil
+	}
+
+	question := fmt.Sprintf("%d games with an empty team will be deleted, this can not be undone. Delete them?", gamesCount)
+	if confirm(cmd, question) {
+		return checks.FixOptions{DeleteGames: true}, nil
+	}
+
+	cli.Print("Games with an empty team are kept, confirm or run with --backup to delete them")
+
+	return checks.FixOptions{}, nil
+}
+
+func printCheckReport(report checks.Report) {
+	entries := [][]string{}
+	for _, attendance := range report.OrphanedAttendances {
+		missing := fmt.Sprintf("player %d", attendance.PlayerID)
+		if attendance.MissingGame {
+			missing = fmt.Sprintf("game %d", attendance.GameID)
+		}
+
+		entries = append(entries, []string{
+			"Orphaned attendance",
+			fmt.Sprintf("Attendance %d (%s) of missing %s", attendance.ID, attendance.UUID, missing),
+		})
+	}
+
+	for _, game := range report.InvalidTeams {
+		problem := "Unbalanced teams"
+		if game.HasEmptyTeam() {
+			problem = "Empty team"
+		}
+
+		entries = append(entries, []string{
+			problem,
+			fmt.Sprintf(
+				"Game %s at %s: %d vs. %d players",
+				game.GameUUID,
+				game.PlayedAt.Local().Format("2006-01-02 15:04"),
+				game.Team1,
+				game.Team2,
+			),
+		})
+	}
+
+	for _, attendance := range report.RepeatedAttendances {
+		problem := "Player repeated in team"
+		if attendance.BothTeams {
+			problem = "Player in both teams"
+		}
+
+		entries = append(entries, []string{
+			problem,
+			fmt.Sprintf("%s attends game %s %d times", attendance.PlayerName, attendance.GameUUID, attendance.Count),
+		})
+	}
+
+	if report.HasMultipleActiveSeasons() {
+		entries = append(entries, []string{
+			"Multiple active seasons",
+			fmt.Sprintf("%v", report.ActiveSeasons),
+		})
+	}
+
+	cli.PrintTable([]string{"Problem", "Details"}, entries)
+}
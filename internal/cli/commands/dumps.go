@@ -0,0 +1,129 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/spie/fskick/internal/cli"
+	"github.com/spie/fskick/internal/dumps"
+)
+
+type exportCommand struct {
+	command
+	dumpsManager dumps.Manager
+}
+
+func NewExportCommand(dumpsManager dumps.Manager) *exportCommand {
+	exportCommand := exportCommand{dumpsManager: dumpsManager}
+
+	cc := &cobra.Command{
+		Use:   "export [file]",
+		Short: "Export all data as JSON or NDJSON archive",
+		Long:  "Export seasons, players, users, games and attendances with their UUIDs. Without a file the archive is written to stdout.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  exportCommand.Export,
+	}
+
+	cc.Flags().StringP("format", "f", "", "json or ndjson, by default from the file extension or ndjson")
+	cc.Flags().Bool("without-passwords", false, "Leave out the password hashes of users")
+
+	exportCommand.command = newCommand(cc)
+
+	return &exportCommand
+}
+
+func (command *exportCommand) Export(cmd *cobra.Command, args []string) error {
+	var w io.Writer = os.Stdout
+	fileName := ""
+	if len(args) == 1 {
+		fileName = args[0]
+		file, err := os.Create(fileName)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		w = file
+	}
+
+	format, _ := cmd.Flags().GetString("format")
+	withoutPasswords, _ := cmd.Flags().GetBool("without-passwords")
+
+	return command.dumpsManager.Export(cmd.Context(), w, dumps.ExportOptions{
+		Format:        getArchiveFormat(format, fileName),
+		WithPasswords: !withoutPasswords,
+	})
+}
+
+type importDumpCommand struct {
+	command
+	dumpsManager dumps.Manager
+}
+
+func NewImportDumpCommand(dumpsManager dumps.Manager) *importDumpCommand {
+	importDumpCommand := importDumpCommand{dumpsManager: dumpsManager}
+
+	cc := &cobra.Command{
+		Use:   "import-dump <file>",
+		Short: "Restore an archive created by export",
+		Long:  "Restore an archive created by export. Records with UUIDs already in the database are skipped.",
+		Args:  cobra.ExactArgs(1),
+		RunE:  importDumpCommand.ImportDump,
+	}
+
+	cc.Flags().StringP("format", "f", "", "json or ndjson, by default from the file extension or ndjson")
+
+	importDumpCommand.command = newCommand(cc)
+
+	return &importDumpCommand
+}
+
+func (command *importDumpCommand) ImportDump(cmd *cobra.Command, args []string) error {
+	file, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	format, _ := cmd.Flags().GetString("format")
+
+	archive, err := dumps.ReadArchive(file, getArchiveFormat(format, args[0]))
+	if err != nil {
+		return err
+	}
+
+	report, err := command.dumpsManager.Restore(cmd.Context(), archive)
+	if err != nil {
+		return err
+	}
+
+	cli.PrintTable(
+		[]string{},
+		[][]string{
+			{"Seasons", fmt.Sprintf("%d", report.Seasons)},
+			{"Players", fmt.Sprintf("%d", report.Players)},
+			{"Games", fmt.Sprintf("%d", report.Games)},
+			{"Attendances", fmt.Sprintf("%d", report.Attendances)},
+			{"Skipped (existing)", fmt.Sprintf("%d", report.Skipped)},
+		},
+	)
+
+	return nil
+}
+
+func getArchiveFormat(format string, fileName string) string {
+	if format != "" {
+		return format
+	}
+
+	if strings.ToLower(filepath.Ext(fileName)) == ".json" {
+		return dumps.FormatJSON
+	}
+
+	return dumps.FormatNDJSON
+}
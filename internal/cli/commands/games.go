@@ -1,9 +1,16 @@
 package commands
 
 import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
 
 	"github.com/spie/fskick/internal/cli"
@@ -26,11 +33,11 @@ func NewGamesCommand() *gamesCommand {
 
 type createGameCommand struct {
 	command
-	gamesManager   games.Manager
-	playersManager players.Manager
+	gamesManager   GamesManager
+	playersManager PlayersManager
 }
 
-func NewCreateGameCommand(gamesManager games.Manager, playersManager players.Manager) *createGameCommand {
+func NewCreateGameCommand(gamesManager GamesManager, playersManager PlayersManager) *createGameCommand {
 	createGameCommand := createGameCommand{gamesManager: gamesManager, playersManager: playersManager}
 
 	cc := &cobra.Command{
@@ -38,9 +45,12 @@ func NewCreateGameCommand(gamesManager games.Manager, playersManager players.Man
 		RunE: createGameCommand.CreateGame,
 	}
 
-	cc.Flags().StringP("winners", "w", "", "comma seperated names of winners")
-	cc.Flags().StringP("losers", "l", "", "comma seperated names of losers")
+	cc.Flags().StringP("winners", "w", "", "comma seperated names of winners, optionally with position (name:def or name:off)")
+	cc.Flags().StringP("losers", "l", "", "comma seperated names of losers, optionally with position (name:def or name:off)")
 	cc.Flags().StringP("playedAt", "p", "", "Date and time of the game")
+	cc.Flags().BoolP("draw", "d", false, "The game ended in a draw, winners and losers are just the two teams")
+	cc.Flags().BoolP("force", "f", false, "Create the game even if the same game was already recorded recently")
+	cc.Flags().String("idempotencyKey", "", "Key of the game, creating it again with the same key returns the stored game")
 
 	createGameCommand.command = newCommand(cc)
 
@@ -48,13 +58,20 @@ func NewCreateGameCommand(gamesManager games.Manager, playersManager players.Man
 }
 
 func (createGameCommand *createGameCommand) CreateGame(cmd *cobra.Command, args []string) error {
-	winnerNames, _ := cmd.Flags().GetString("winners")
-	loserNames, _ := cmd.Flags().GetString("losers")
+	winnersFlag, _ := cmd.Flags().GetString("winners")
+	losersFlag, _ := cmd.Flags().GetString("losers")
 
-	winners, losers, err := createGameCommand.playersManager.GetTeamsByNames(
-		getPlayerNamesFromFlag(winnerNames),
-		getPlayerNamesFromFlag(loserNames),
-	)
+	winnerNames, winnerPositions, err := getPlayerNamesAndPositionsFromFlag(winnersFlag)
+	if err != nil {
+		return err
+	}
+
+	loserNames, loserPositions, err := getPlayerNamesAndPositionsFromFlag(losersFlag)
+	if err != nil {
+		return err
+	}
+
+	winners, losers, err := createGameCommand.playersManager.GetTeamsByNames(cmd.Context(), winnerNames, loserNames)
 	if err != nil {
 		return err
 	}
@@ -64,22 +81,69 @@ func (createGameCommand *createGameCommand) CreateGame(cmd *cobra.Command, args
 		return err
 	}
 
-	_, err = createGameCommand.gamesManager.CreateGame(playedAt, winners, losers)
+	positions := getPositionsForTeam(winners, winnerPositions)
+	for playerUuid, position := range getPositionsForTeam(losers, loserPositions) {
+		positions[playerUuid] = position
+	}
+
+	draw, _ := cmd.Flags().GetBool("draw")
+	force, _ := cmd.Flags().GetBool("force")
+	idempotencyKey, _ := cmd.Flags().GetString("idempotencyKey")
+
+	entry := games.GameEntry{
+		PlayedAt:       playedAt,
+		Winners:        winners,
+		Losers:         losers,
+		Draw:           draw,
+		Positions:      positions,
+		IdempotencyKey: idempotencyKey,
+		AllowDuplicate: force,
+	}
+
+	_, err = createGameCommand.gamesManager.CreateGame(cmd.Context(), entry)
+	if errors.Is(err, games.ErrDuplicateGame) {
+		if !confirm(cmd, fmt.Sprintf("%s\nCreate the game anyway?", err)) {
+			return fmt.Errorf("%w, use --force to create it anyway", err)
+		}
+
+		entry.AllowDuplicate = true
+		_, err = createGameCommand.gamesManager.CreateGame(cmd.Context(), entry)
+	}
 	if err != nil {
 		return err
 	}
 
+	if draw {
+		cli.PrintTable([]string{}, [][]string{{"Draw", winnersFlag, losersFlag}})
+
+		return nil
+	}
+
 	cli.PrintTable(
 		[]string{},
 		[][]string{
-			{"Winners", winnerNames},
-			{"Losers", loserNames},
+			{"Winners", winnersFlag},
+			{"Losers", losersFlag},
 		},
 	)
 
 	return nil
 }
 
+// confirm asks the question on an interactive terminal, without a terminal the answer is no.
+func confirm(cmd *cobra.Command, question string) bool {
+	if !isatty.IsTerminal(os.Stdin.Fd()) {
+		return false
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "%s [y/N] ", question)
+
+	answer, _ := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+
+	return answer == "y" || answer == "yes"
+}
+
 func getPlayerNamesFromFlag(names string) []string {
 	if names == "" {
 		return []string{}
@@ -88,6 +152,36 @@ func getPlayerNamesFromFlag(names string) []string {
 	return strings.Split(names, ",")
 }
 
+// getPlayerNamesAndPositionsFromFlag splits entries like "name:def" into the player name
+// and the position played by the player.
+func getPlayerNamesAndPositionsFromFlag(flag string) ([]string, map[string]players.Position, error) {
+	entries := getPlayerNamesFromFlag(flag)
+	names := make([]string, len(entries))
+	positions := map[string]players.Position{}
+	for i, entry := range entries {
+		name, positionName, _ := strings.Cut(entry, ":")
+
+		position, err := players.ParsePosition(positionName)
+		if err != nil {
+			return nil, nil, fmt.Errorf("position of %s: %w", name, err)
+		}
+
+		names[i] = name
+		positions[name] = position
+	}
+
+	return names, positions, nil
+}
+
+func getPositionsForTeam(team players.Team, positions map[string]players.Position) games.Positions {
+	teamPositions := games.Positions{}
+	for _, player := range team {
+		teamPositions[player.UUID] = positions[player.Name]
+	}
+
+	return teamPositions
+}
+
 func (command *createGameCommand) getPlayedAt() (time.Time, error) {
 	playedAtFlag, _ := command.cc.Flags().GetString("playedAt")
 	if playedAtFlag == "" {
@@ -101,3 +195,327 @@ func (command *createGameCommand) getPlayedAt() (time.Time, error) {
 
 	return playedAt, nil
 }
+
+type matchPlayersCommand struct {
+	command
+	gamesManager   GamesManager
+	playersManager PlayersManager
+}
+
+func NewMatchPlayersCommand(gamesManager GamesManager, playersManager PlayersManager) *matchPlayersCommand {
+	matchPlayersCommand := matchPlayersCommand{gamesManager: gamesManager, playersManager: playersManager}
+
+	cc := &cobra.Command{
+		Use:   "match [players...]",
+		Short: "Suggests balanced teams for 2 or 4 players",
+		Long: "Splits the players into the two most balanced teams by their win ratios on the positions, " +
+			"keeping the preferred positions of the players where possible.",
+		Args: cobra.RangeArgs(2, 4),
+		RunE: matchPlayersCommand.matchPlayers,
+	}
+
+	matchPlayersCommand.command = newCommand(cc)
+
+	return &matchPlayersCommand
+}
+
+func (matchPlayersCommand matchPlayersCommand) matchPlayers(cmd *cobra.Command, args []string) error {
+	playersToMatch, _, err := matchPlayersCommand.playersManager.GetTeamsByNames(cmd.Context(), args, []string{})
+	if err != nil {
+		return err
+	}
+
+	matchup, err := matchPlayersCommand.gamesManager.MatchPlayers(cmd.Context(), playersToMatch)
+	if err != nil {
+		return err
+	}
+
+	if len(matchup.Team1) == 1 {
+		cli.PrintTable(
+			[]string{"Team", "Player"},
+			[][]string{
+				{"1", matchup.Team1[0].PublicName()},
+				{"2", matchup.Team2[0].PublicName()},
+			},
+		)
+	} else {
+		cli.PrintTable(
+			[]string{"Team", "Defense", "Offense"},
+			[][]string{
+				{"1", matchup.Team1[0].PublicName(), matchup.Team1[1].PublicName()},
+				{"2", matchup.Team2[0].PublicName(), matchup.Team2[1].PublicName()},
+			},
+		)
+	}
+	cli.Print(fmt.Sprintf("Difference of the win ratios: %.2f", matchup.Difference))
+
+	return nil
+}
+
+type listGamesCommand struct {
+	command
+	gamesManager   GamesManager
+	playersManager PlayersManager
+	seasonsManager SeasonsManager
+}
+
+func NewListGamesCommand(
+	gamesManager GamesManager,
+	playersManager PlayersManager,
+	seasonsManager SeasonsManager,
+) *listGamesCommand {
+	listGamesCommand := listGamesCommand{
+		gamesManager:   gamesManager,
+		playersManager: playersManager,
+		seasonsManager: seasonsManager,
+	}
+
+	cc := &cobra.Command{
+		Use:   "list",
+		Short: "List played games, newest first",
+		RunE:  listGamesCommand.ListGames,
+	}
+
+	cc.Flags().StringP("season", "s", "", "Name of the season")
+	cc.Flags().StringP("player", "p", "", "Name of a player in the games")
+	cc.Flags().StringP("teammate", "t", "", "Name of the player's teammate, needs --player")
+	cc.Flags().StringP("opponent", "o", "", "Name of the player's opponent, needs --player")
+	cc.Flags().String("from", "", "First date of the games (2006-01-02)")
+	cc.Flags().String("to", "", "Last date of the games (2006-01-02)")
+	cc.Flags().String("outcome", "", "win, draw or loss, win and loss need --player")
+	cc.Flags().IntP("limit", "l", games.DefaultGamesLimit, "Maximum number of games")
+	cc.Flags().StringP("cursor", "c", "", "Cursor of the next page from a previous list")
+
+	listGamesCommand.command = newCommand(cc)
+
+	return &listGamesCommand
+}
+
+func (command *listGamesCommand) ListGames(cmd *cobra.Command, args []string) error {
+	filter, err := command.getGamesFilter(cmd)
+	if err != nil {
+		return err
+	}
+
+	gamesPage, err := command.gamesManager.ListGames(cmd.Context(), filter)
+	if err != nil {
+		return err
+	}
+
+	entries := make([][]string, len(gamesPage.Games))
+	for i, game := range gamesPage.Games {
+		entries[i] = []string{
+			game.PlayedAt.Format("2006-01-02 15:04"),
+			game.Season.Name,
+			getTeamNames(game.Team(1)),
+			getTeamNames(game.Team(2)),
+			getGameResult(game),
+		}
+	}
+
+	cli.PrintTable([]string{"Played At", "Season", "Team 1", "Team 2", "Result"}, entries)
+
+	if gamesPage.NextCursor != "" {
+		cli.Print(fmt.Sprintf("Next page: --cursor %s", gamesPage.NextCursor))
+	}
+
+	return nil
+}
+
+func (command *listGamesCommand) getGamesFilter(cmd *cobra.Command) (games.GamesFilter, error) {
+	filter := games.GamesFilter{}
+	filter.Cursor, _ = cmd.Flags().GetString("cursor")
+	filter.Limit, _ = cmd.Flags().GetInt("limit")
+
+	seasonName, _ := cmd.Flags().GetString("season")
+	if seasonName != "" {
+		season, err := command.seasonsManager.GetSeasonByName(cmd.Context(), seasonName)
+		if err != nil {
+			return games.GamesFilter{}, fmt.Errorf("season %s: %w", seasonName, err)
+		}
+
+		filter.Season = &season
+	}
+
+	var err error
+	filter.Player, err = command.getOptionalPlayer(cmd, "player")
+	if err != nil {
+		return games.GamesFilter{}, err
+	}
+
+	filter.Teammate, err = command.getOptionalPlayer(cmd, "teammate")
+	if err != nil {
+		return games.GamesFilter{}, err
+	}
+
+	filter.Oponent, err = command.getOptionalPlayer(cmd, "opponent")
+	if err != nil {
+		return games.GamesFilter{}, err
+	}
+
+	outcome, _ := cmd.Flags().GetString("outcome")
+	filter.Outcome, err = games.ParseOutcome(outcome)
+	if err != nil {
+		return games.GamesFilter{}, err
+	}
+
+	from, _ := cmd.Flags().GetString("from")
+	if from != "" {
+		filter.From, err = time.Parse("2006-01-02", from)
+		if err != nil {
+			return games.GamesFilter{}, err
+		}
+	}
+
+	to, _ := cmd.Flags().GetString("to")
+	if to != "" {
+		filter.To, err = time.Parse("2006-01-02", to)
+		if err != nil {
+			return games.GamesFilter{}, err
+		}
+
+		filter.To = filter.To.AddDate(0, 0, 1)
+	}
+
+	return filter, nil
+}
+
+func (command *listGamesCommand) getOptionalPlayer(cmd *cobra.Command, flag string) (*players.Player, error) {
+	name, _ := cmd.Flags().GetString(flag)
+	if name == "" {
+		return nil, nil
+	}
+
+	player, err := command.playersManager.GetPlayerByName(cmd.Context(), name)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", flag, name, err)
+	}
+
+	return &player, nil
+}
+
+func getTeamNames(attendances []games.Attendance) string {
+	names := make([]string, len(attendances))
+	for i, attendance := range attendances {
+		names[i] = attendance.Player.Name
+	}
+
+	return strings.Join(names, ", ")
+}
+
+func getGameResult(game games.Game) string {
+	result := "Draw"
+	switch game.Outcome() {
+	case games.OutcomeWin:
+		result = "Team 1 won"
+	case games.OutcomeLoss:
+		result = "Team 2 won"
+	}
+
+	if game.Score != nil {
+		result += fmt.Sprintf(" %d:%d", game.Score.Team1, game.Score.Team2)
+	}
+
+	return result
+}
+
+type importGamesCommand struct {
+	command
+	importer games.Importer
+}
+
+func NewImportGamesCommand(importer games.Importer) *importGamesCommand {
+	importGamesCommand := importGamesCommand{importer: importer}
+
+	cc := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Import games from a CSV or JSON lines file",
+		Long: `Import games from a CSV file with the header date,winners,losers[,score,draw,season]
+or a JSON lines file with objects like {"date": "2021-03-04", "winners": ["a", "b"], "losers": ["c", "d"], "score": "10:8"}.
+Games without a season are assigned to the season running at their date.`,
+		Args: cobra.ExactArgs(1),
+		RunE: importGamesCommand.ImportGames,
+	}
+
+	cc.Flags().Bool("dry-run", false, "Report conflicts and duplicates without storing the games")
+	cc.Flags().Bool("create-players", false, "Create players missing in the database")
+	cc.Flags().StringP("format", "f", "", "csv or jsonl, by default from the file extension")
+
+	importGamesCommand.command = newCommand(cc)
+
+	return &importGamesCommand
+}
+
+func (command *importGamesCommand) ImportGames(cmd *cobra.Command, args []string) error {
+	file, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	format, _ := cmd.Flags().GetString("format")
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(args[0]), ".")
+	}
+
+	var records []games.ImportRecord
+	switch format {
+	case "csv":
+		records, err = games.ParseImportCSV(file)
+	case "jsonl", "ndjson", "json":
+		records, err = games.ParseImportJSONLines(file)
+	default:
+		return fmt.Errorf("unknown import format %q, use csv or jsonl", format)
+	}
+	if err != nil {
+		return err
+	}
+
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	createPlayers, _ := cmd.Flags().GetBool("create-players")
+
+	report, err := command.importer.ImportGames(
+		cmd.Context(),
+		records,
+		games.ImportOptions{DryRun: dryRun, CreatePlayers: createPlayers},
+	)
+
+	printImportProblems("Conflicts", report.Conflicts)
+	printImportProblems("Duplicates (skipped)", report.Duplicates)
+
+	if errors.Is(err, games.ErrImportConflicts) {
+		return fmt.Errorf("%w, nothing was imported", err)
+	}
+	if err != nil {
+		return err
+	}
+
+	result := "Imported"
+	if dryRun {
+		result = "Would import"
+	}
+
+	cli.PrintTable(
+		[]string{},
+		[][]string{
+			{result, fmt.Sprintf("%d games", report.Imported)},
+			{"Created players", strings.Join(report.CreatedPlayers, ", ")},
+		},
+	)
+
+	return nil
+}
+
+func printImportProblems(title string, problems []games.ImportProblem) {
+	if len(problems) == 0 {
+		return
+	}
+
+	entries := make([][]string, len(problems))
+	for i, problem := range problems {
+		entries[i] = []string{strconv.Itoa(problem.Line), problem.Message}
+	}
+
+	cli.Print(title)
+	cli.PrintTable([]string{"Line", "Problem"}, entries)
+}
@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/spie/fskick/internal/games"
+	"github.com/spie/fskick/internal/players"
+	"github.com/spie/fskick/internal/seasons"
+	"github.com/spie/fskick/internal/streaks"
+	"github.com/spie/fskick/internal/tournaments"
+)
+
+// SeasonsManager handles the seasons, in the local database with seasons.Manager or on a
+// running server with client.SeasonsManager.
+type SeasonsManager interface {
+	CreateSeason(ctx context.Context, name string) (seasons.Season, error)
+	GetSeasons(ctx context.Context) ([]seasons.Season, error)
+	ActivateSeason(ctx context.Context, name string) (seasons.Season, error)
+	SetTeamSizes(ctx context.Context, name string, teamSizes seasons.TeamSizes) (seasons.Season, error)
+	ActiveSeason(ctx context.Context) (seasons.Season, error)
+	GetSeasonByName(ctx context.Context, name string) (seasons.Season, error)
+}
+
+// PlayersManager handles the players, in the local database with players.Manager or on a
+// running server with client.PlayersManager.
+type PlayersManager interface {
+	CreatePlayer(ctx context.Context, name string) (players.Player, error)
+	UpdateProfile(ctx context.Context, player players.Player, profile players.Profile) (players.Player, error)
+	GetPlayerByName(ctx context.Context, name string) (players.Player, error)
+	GetPlayers(ctx context.Context) ([]players.Player, error)
+	GetTeamsByNames(ctx context.Context, winnerNames []string, loserNames []string) (players.Team, players.Team, error)
+}
+
+// GamesManager handles the games and stats, in the local database with games.Manager or on a
+// running server with client.GamesManager.
+type GamesManager interface {
+	CreateGame(ctx context.Context, entry games.GameEntry) (*games.Game, error)
+	ListGames(ctx context.Context, filter games.GamesFilter) (games.GamesPage, error)
+	GetGamesCount(ctx context.Context, mode games.Mode) (int, error)
+	GetGamesCountForSeason(ctx context.Context, season seasons.Season, mode games.Mode) (int, error)
+	GetPlayerStatsForSeason(
+		ctx context.Context,
+		season seasons.Season,
+		mode games.Mode,
+		sort string,
+	) ([]games.PlayerStats, error)
+	GetAllPlayerStats(ctx context.Context, mode games.Mode, sort string) ([]games.PlayerStats, error)
+	MatchPlayers(ctx context.Context, playersToMatch []players.Player) (games.Matchup, error)
+}
+
+// StreaksManager reads the streaks, from the local database with streaks.Manager or from a
+// running server with client.StreaksManager.
+type StreaksManager interface {
+	GetCurrentStreaks(ctx context.Context, outcome games.Outcome) ([]streaks.Streak, error)
+}
+
+// TournamentsManager handles the tournaments, in the local database with tournaments.Manager
+// or on a running server with client.TournamentsManager.
+type TournamentsManager interface {
+	CreateTournament(
+		ctx context.Context,
+		name string,
+		format tournaments.Format,
+		teamSize int,
+	) (tournaments.Tournament, error)
+	GetTournaments(ctx context.Context) ([]tournaments.Tournament, error)
+	GetTournament(ctx context.Context, name string) (tournaments.Tournament, error)
+	RegisterTeam(ctx context.Context, tournamentName string, teamName string, playerNames []string) (tournaments.Team, error)
+	Schedule(ctx context.Context, name string, options tournaments.ScheduleOptions) (tournaments.Tournament, error)
+	RecordResult(
+		ctx context.Context,
+		name string,
+		number int,
+		result tournaments.Result,
+	) (tournaments.Tournament, error)
+}
@@ -0,0 +1,56 @@
+package commands
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+
+	"github.com/spie/fskick/internal/cli/play"
+)
+
+type playCommand struct {
+	command
+	gamesManager   GamesManager
+	playersManager PlayersManager
+	seasonsManager SeasonsManager
+	streaksManager StreaksManager
+}
+
+func NewPlayCommand(
+	gamesManager GamesManager,
+	playersManager PlayersManager,
+	seasonsManager SeasonsManager,
+	streaksManager StreaksManager,
+) *playCommand {
+	playCommand := &playCommand{
+		gamesManager:   gamesManager,
+		playersManager: playersManager,
+		seasonsManager: seasonsManager,
+		streaksManager: streaksManager,
+	}
+
+	cc := &cobra.Command{
+		Use:   "play",
+		Short: "Records games at the table",
+		Long:  "Opens a terminal ui to pick the players, enter the score and save the game in the active season",
+		Args:  cobra.NoArgs,
+		RunE:  playCommand.play,
+	}
+
+	playCommand.command = newCommand(cc)
+
+	return playCommand
+}
+
+func (playCommand playCommand) play(cmd *cobra.Command, args []string) error {
+	model := play.New(
+		cmd.Context(),
+		playCommand.playersManager,
+		playCommand.gamesManager,
+		playCommand.seasonsManager,
+		playCommand.streaksManager,
+	)
+
+	_, err := tea.NewProgram(model, tea.WithContext(cmd.Context())).Run()
+
+	return err
+}
@@ -3,12 +3,14 @@ package commands
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/spie/fskick/internal/cli"
 	"github.com/spie/fskick/internal/games"
 	"github.com/spie/fskick/internal/players"
+	"github.com/spie/fskick/internal/tables"
 )
 
 type playersCommand struct {
@@ -27,10 +29,10 @@ func NewPlayersCommand() *playersCommand {
 
 type createPlayerCommand struct {
 	command
-	playersManager players.Manager
+	playersManager PlayersManager
 }
 
-func NewCreatePlayerCommand(playersManager players.Manager) *createPlayerCommand {
+func NewCreatePlayerCommand(playersManager PlayersManager) *createPlayerCommand {
 	createPlayerCommand := &createPlayerCommand{playersManager: playersManager}
 
 	cc := &cobra.Command{
@@ -47,7 +49,7 @@ func NewCreatePlayerCommand(playersManager players.Manager) *createPlayerCommand
 }
 
 func (createPlayerCommand *createPlayerCommand) createPlayer(cmd *cobra.Command, args []string) error {
-	player, err := createPlayerCommand.playersManager.CreatePlayer(args[0])
+	player, err := createPlayerCommand.playersManager.CreatePlayer(cmd.Context(), args[0])
 	if err != nil {
 		return err
 	}
@@ -57,12 +59,103 @@ func (createPlayerCommand *createPlayerCommand) createPlayer(cmd *cobra.Command,
 	return nil
 }
 
+type updatePlayerCommand struct {
+	command
+	playersManager PlayersManager
+}
+
+func NewUpdatePlayerCommand(playersManager PlayersManager) *updatePlayerCommand {
+	updatePlayerCommand := &updatePlayerCommand{playersManager: playersManager}
+
+	cc := &cobra.Command{
+		Use:   "update [name]",
+		Short: "Updates the profile of a player",
+		Long:  "Updates the profile of the player with the given name. Profile fields without a flag are kept.",
+		Args:  cobra.ExactArgs(1),
+		RunE:  updatePlayerCommand.updatePlayer,
+	}
+
+	cc.Flags().StringP("display-name", "d", "", "Name shown instead of the player name")
+	cc.Flags().String("position", "", "Preferred position, defense or offense")
+	cc.Flags().String("side", "", "Favorite table side, left or right")
+	cc.Flags().StringP("joined", "j", "", "Date the player joined")
+
+	updatePlayerCommand.command = newCommand(cc)
+
+	return updatePlayerCommand
+}
+
+func (updatePlayerCommand *updatePlayerCommand) updatePlayer(cmd *cobra.Command, args []string) error {
+	player, err := updatePlayerCommand.playersManager.GetPlayerByName(cmd.Context(), args[0])
+	if err != nil {
+		return err
+	}
+
+	profile := players.Profile{
+		DisplayName:       player.DisplayName,
+		PreferredPosition: player.PreferredPosition,
+		FavoriteSide:      player.FavoriteSide,
+		JoinedAt:          player.JoinedAt,
+	}
+
+	if cmd.Flags().Changed("display-name") {
+		profile.DisplayName, _ = cmd.Flags().GetString("display-name")
+	}
+
+	if cmd.Flags().Changed("position") {
+		position, _ := cmd.Flags().GetString("position")
+		profile.PreferredPosition, err = players.ParsePosition(position)
+		if err != nil {
+			return err
+		}
+	}
+
+	if cmd.Flags().Changed("side") {
+		side, _ := cmd.Flags().GetString("side")
+		profile.FavoriteSide, err = players.ParseSide(side)
+		if err != nil {
+			return err
+		}
+	}
+
+	if cmd.Flags().Changed("joined") {
+		joined, _ := cmd.Flags().GetString("joined")
+		profile.JoinedAt, err = time.Parse("2006-01-02", joined)
+		if err != nil {
+			return err
+		}
+	}
+
+	player, err = updatePlayerCommand.playersManager.UpdateProfile(cmd.Context(), player, profile)
+	if err != nil {
+		return err
+	}
+
+	joined := ""
+	if !player.JoinedAt.IsZero() {
+		joined = player.JoinedAt.Format("2006-01-02")
+	}
+
+	cli.PrintTable(
+		[]string{},
+		[][]string{
+			{"Name", player.Name},
+			{"Display Name", player.DisplayName},
+			{"Position", string(player.PreferredPosition)},
+			{"Side", string(player.FavoriteSide)},
+			{"Joined", joined},
+		},
+	)
+
+	return nil
+}
+
 type getPlayersCommand struct {
 	command
-	gamesManager games.Manager
+	gamesManager GamesManager
 }
 
-func NewGetPlayersCommand(gamesManager games.Manager) *getPlayersCommand {
+func NewGetPlayersCommand(gamesManager GamesManager) *getPlayersCommand {
 	getPlayersCommand := getPlayersCommand{
 		gamesManager: gamesManager,
 	}
@@ -74,6 +167,8 @@ func NewGetPlayersCommand(gamesManager games.Manager) *getPlayersCommand {
 	}
 
 	cc.Flags().StringP("sort", "s", "", "Table sort by")
+	addModeFlag(cc)
+	addOutputFlag(cc)
 
 	getPlayersCommand.command = newCommand(cc)
 
@@ -81,7 +176,17 @@ func NewGetPlayersCommand(gamesManager games.Manager) *getPlayersCommand {
 }
 
 func (getPlayersCommand *getPlayersCommand) getPlayers(cmd *cobra.Command, args []string) error {
-	gamesCount, err := getPlayersCommand.gamesManager.GetGamesCount()
+	output, err := getOutputFormat(cmd)
+	if err != nil {
+		return err
+	}
+
+	mode, err := getMode(cmd)
+	if err != nil {
+		return err
+	}
+
+	gamesCount, err := getPlayersCommand.gamesManager.GetGamesCount(cmd.Context(), mode)
 	if err != nil {
 		return err
 	}
@@ -91,7 +196,7 @@ func (getPlayersCommand *getPlayersCommand) getPlayers(cmd *cobra.Command, args
 		return err
 	}
 
-	playersStats, err := getPlayersCommand.gamesManager.GetAllPlayerStats(sortName)
+	playersStats, err := getPlayersCommand.gamesManager.GetAllPlayerStats(cmd.Context(), mode, sortName)
 	if err != nil {
 		return err
 	}
@@ -100,12 +205,7 @@ func (getPlayersCommand *getPlayersCommand) getPlayers(cmd *cobra.Command, args
 		playersStats = filterPlayerStatsByName(args[0], playersStats)
 	}
 
-	head := cli.CreateTableHead(gamesCount, len(playersStats))
-	tableEntries := cli.CreateTableEntries(gamesCount, playersStats)
-
-	cli.PrintTable(head, tableEntries)
-
-	return nil
+	return cli.PrintFormatted(tables.NewPlayerStatsTable(gamesCount, playersStats), output)
 }
 
 func filterPlayerStatsByName(name string, playersStats []games.PlayerStats) []games.PlayerStats {
@@ -128,8 +228,9 @@ func getSortName(cmd *cobra.Command) (string, error) {
 		return "pointsRatio", nil
 	}
 
-	if sortName != "pointsRatio" && sortName != "wint" && sortName != "games" && sortName != "winRatio" {
-		return "", errors.New("Sort flag has to be pointsRatio, games, wins or winRatio")
+	if sortName != "pointsRatio" && sortName != "wint" && sortName != "games" && sortName != "winRatio" &&
+		sortName != "defenseWinRatio" && sortName != "offenseWinRatio" {
+		return "", errors.New("Sort flag has to be pointsRatio, games, wins, winRatio, defenseWinRatio or offenseWinRatio")
 	}
 
 	return sortName, nil
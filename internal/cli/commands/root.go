@@ -1,10 +1,14 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spie/fskick/internal/cli"
+	"github.com/spie/fskick/internal/games"
+	"github.com/spie/fskick/internal/tables"
 )
 
 type Command interface {
@@ -33,16 +37,58 @@ func (cmd *command) getCommand() *cobra.Command {
 	return cmd.cc
 }
 
+func addOutputFlag(cc *cobra.Command) {
+	cc.Flags().StringP("output", "o", tables.FormatTable, "Output format, table, json, csv, markdown or html")
+}
+
+func getOutputFormat(cmd *cobra.Command) (string, error) {
+	output, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return "", err
+	}
+
+	return tables.ParseFormat(output)
+}
+
+func addModeFlag(cc *cobra.Command) {
+	cc.Flags().StringP("mode", "m", string(games.ModeAll), "Count games of mode all, singles (1v1) or doubles (2v2)")
+}
+
+func getMode(cmd *cobra.Command) (games.Mode, error) {
+	mode, err := cmd.Flags().GetString("mode")
+	if err != nil {
+		return "", err
+	}
+
+	return games.ParseMode(mode)
+}
+
+var ErrRemoteMode = errors.New("not available with --remote, the command needs the local database")
+
+// DisableRemote makes the command and its sub commands fail, for commands working directly with
+// the local database when the cli works with a remote server.
+func DisableRemote(c Command) {
+	c.getCommand().PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return fmt.Errorf("%s: %w", cmd.CommandPath(), ErrRemoteMode)
+	}
+}
+
 type rootCommand struct {
 	command
 }
 
-func NewRootCommand() *rootCommand {
+// NewRootCommand creates the root command, which runs migrate before any sub command. The
+// config flags are parsed before, they are added to show them in the help.
+func NewRootCommand(migrate func() error, configFlags *pflag.FlagSet) *rootCommand {
 	rootCommand := rootCommand{command: newCommand(&cobra.Command{
 		Use:   "fskick",
 		Short: "FSKick CLI app",
 		Long:  "CLI app for FSKick to create new players, seasons, games and show results and statistics",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return migrate()
+		},
 	})}
+	rootCommand.cc.PersistentFlags().AddFlagSet(configFlags)
 
 	return &rootCommand
 }
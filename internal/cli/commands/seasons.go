@@ -1,14 +1,15 @@
 package commands
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
 	"github.com/spf13/cobra"
 
 	"github.com/spie/fskick/internal/cli"
-	"github.com/spie/fskick/internal/games"
 	"github.com/spie/fskick/internal/seasons"
+	"github.com/spie/fskick/internal/tables"
 )
 
 type seasonsCommand struct {
@@ -27,10 +28,10 @@ func NewSeasonsCommand() *seasonsCommand {
 
 type createSeasonCommand struct {
 	command
-	seasonsManager seasons.Manager
+	seasonsManager SeasonsManager
 }
 
-func NewCreateSeasonCommand(seasonsManager seasons.Manager) *createSeasonCommand {
+func NewCreateSeasonCommand(seasonsManager SeasonsManager) *createSeasonCommand {
 	createSeasonCommand := &createSeasonCommand{seasonsManager: seasonsManager}
 
 	cc := &cobra.Command{
@@ -47,7 +48,7 @@ func NewCreateSeasonCommand(seasonsManager seasons.Manager) *createSeasonCommand
 }
 
 func (createScreateSeasonCommand *createSeasonCommand) createSeason(cmd *cobra.Command, args []string) error {
-	season, err := createScreateSeasonCommand.seasonsManager.CreateSeason(args[0])
+	season, err := createScreateSeasonCommand.seasonsManager.CreateSeason(cmd.Context(), args[0])
 	if err != nil {
 		return err
 	}
@@ -59,10 +60,10 @@ func (createScreateSeasonCommand *createSeasonCommand) createSeason(cmd *cobra.C
 
 type getSeasonsCommand struct {
 	command
-	seasonsManager seasons.Manager
+	seasonsManager SeasonsManager
 }
 
-func NewGetSeasonsCommand(seasonsManager seasons.Manager) *getSeasonsCommand {
+func NewGetSeasonsCommand(seasonsManager SeasonsManager) *getSeasonsCommand {
 	getSeasonsCommand := &getSeasonsCommand{seasonsManager: seasonsManager}
 
 	cc := &cobra.Command{
@@ -72,37 +73,59 @@ func NewGetSeasonsCommand(seasonsManager seasons.Manager) *getSeasonsCommand {
 		RunE:  getSeasonsCommand.getSeasons,
 	}
 
+	addOutputFlag(cc)
+
 	getSeasonsCommand.command = newCommand(cc)
 
 	return getSeasonsCommand
 }
 
 func (getSeasonsCommand *getSeasonsCommand) getSeasons(cmd *cobra.Command, args []string) error {
-	seasons, err := getSeasonsCommand.seasonsManager.GetSeasons()
+	output, err := getOutputFormat(cmd)
+	if err != nil {
+		return err
+	}
+
+	seasons, err := getSeasonsCommand.seasonsManager.GetSeasons(cmd.Context())
 	if err != nil {
 		return err
 	}
 
+	if output != tables.FormatTable {
+		seasonsTable := tables.Table{
+			Columns: []tables.Column{
+				{Key: "name", Title: "Name"},
+				{Key: "active", Title: "Active"},
+				{Key: "teamSizes", Title: "Team sizes"},
+			},
+		}
+		for _, season := range seasons {
+			seasonsTable.Rows = append(seasonsTable.Rows, []any{season.Name, season.Active, string(season.TeamSizes)})
+		}
+
+		return cli.PrintFormatted(seasonsTable, output)
+	}
+
 	seasonsTable := [][]string{}
 	for _, season := range seasons {
 		active := ""
 		if season.Active {
 			active = "Active"
 		}
-		seasonsTable = append(seasonsTable, []string{season.Name, active})
+		seasonsTable = append(seasonsTable, []string{season.Name, active, string(season.TeamSizes)})
 	}
 
-	cli.PrintTable([]string{"Name", "Active"}, seasonsTable)
+	cli.PrintTable([]string{"Name", "Active", "Team sizes"}, seasonsTable)
 
 	return nil
 }
 
 type activateSeasonCommand struct {
 	command
-	seasonsManager seasons.Manager
+	seasonsManager SeasonsManager
 }
 
-func NewActivateSeasonCommand(seasonsManager seasons.Manager) *activateSeasonCommand {
+func NewActivateSeasonCommand(seasonsManager SeasonsManager) *activateSeasonCommand {
 	activateSeasonCommand := activateSeasonCommand{seasonsManager: seasonsManager}
 
 	cc := &cobra.Command{
@@ -119,7 +142,7 @@ func NewActivateSeasonCommand(seasonsManager seasons.Manager) *activateSeasonCom
 }
 
 func (activateSeasonCommand *activateSeasonCommand) activateSeason(cmd *cobra.Command, args []string) error {
-	season, err := activateSeasonCommand.seasonsManager.ActivateSeason(args[0])
+	season, err := activateSeasonCommand.seasonsManager.ActivateSeason(cmd.Context(), args[0])
 	if err != nil {
 		return err
 	}
@@ -129,15 +152,55 @@ func (activateSeasonCommand *activateSeasonCommand) activateSeason(cmd *cobra.Co
 	return nil
 }
 
+type seasonRulesCommand struct {
+	command
+	seasonsManager SeasonsManager
+}
+
+func NewSeasonRulesCommand(seasonsManager SeasonsManager) *seasonRulesCommand {
+	seasonRulesCommand := seasonRulesCommand{seasonsManager: seasonsManager}
+
+	cc := &cobra.Command{
+		Use:   "rules [name]",
+		Short: "Set the rules of a season",
+		Long:  "Set the rules for the games of the given season, the allowed team sizes.",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  seasonRulesCommand.setRules,
+	}
+
+	cc.Flags().String("team-sizes", string(seasons.TeamSizesBoth), "Allowed team sizes, 1v1, 2v2 or both")
+
+	seasonRulesCommand.command = newCommand(cc)
+
+	return &seasonRulesCommand
+}
+
+func (seasonRulesCommand *seasonRulesCommand) setRules(cmd *cobra.Command, args []string) error {
+	teamSizesFlag, _ := cmd.Flags().GetString("team-sizes")
+	teamSizes, err := seasons.ParseTeamSizes(teamSizesFlag)
+	if err != nil {
+		return err
+	}
+
+	season, err := seasonRulesCommand.seasonsManager.SetTeamSizes(cmd.Context(), args[0], teamSizes)
+	if err != nil {
+		return err
+	}
+
+	cli.Print(fmt.Sprintf("Team sizes of season %s set to %s", season.Name, season.TeamSizes))
+
+	return nil
+}
+
 type getTableCommand struct {
 	command
-	gamesManager   games.Manager
-	seasonsManager seasons.Manager
+	gamesManager   GamesManager
+	seasonsManager SeasonsManager
 }
 
 func NewGetTableCommand(
-	gamesManager games.Manager,
-	seasonsManager seasons.Manager,
+	gamesManager GamesManager,
+	seasonsManager SeasonsManager,
 ) *getTableCommand {
 	getTableCommand := &getTableCommand{
 		gamesManager:   gamesManager,
@@ -152,6 +215,8 @@ func NewGetTableCommand(
 	}
 
 	cc.Flags().StringP("sort", "s", "", "Table sort by")
+	addModeFlag(cc)
+	addOutputFlag(cc)
 
 	getTableCommand.command = newCommand(cc)
 
@@ -159,7 +224,12 @@ func NewGetTableCommand(
 }
 
 func (tableCommand *getTableCommand) getTable(cmd *cobra.Command, args []string) error {
-	season, err := tableCommand.getSeason(args)
+	output, err := getOutputFormat(cmd)
+	if err != nil {
+		return err
+	}
+
+	season, err := tableCommand.getSeason(cmd.Context(), args)
 	if err != nil {
 		return err
 	}
@@ -169,33 +239,36 @@ func (tableCommand *getTableCommand) getTable(cmd *cobra.Command, args []string)
 		return err
 	}
 
-	playerStats, err := tableCommand.gamesManager.GetPlayerStatsForSeason(season, sortName)
+	mode, err := getMode(cmd)
 	if err != nil {
 		return err
 	}
 
-	gamesCount, err := tableCommand.gamesManager.GetGamesCountForSeason(season)
+	playerStats, err := tableCommand.gamesManager.GetPlayerStatsForSeason(cmd.Context(), season, mode, sortName)
 	if err != nil {
 		return err
 	}
 
-	head := cli.CreateTableHead(gamesCount, len(playerStats))
-	tableEntries := cli.CreateTableEntries(gamesCount, playerStats)
+	gamesCount, err := tableCommand.gamesManager.GetGamesCountForSeason(cmd.Context(), season, mode)
+	if err != nil {
+		return err
+	}
 
-	cli.Print(fmt.Sprintf("Season: %s", season.Name))
-	cli.PrintTable(head, tableEntries)
+	if output == tables.FormatTable {
+		cli.Print(fmt.Sprintf("Season: %s", season.Name))
+	}
 
-	return nil
+	return cli.PrintFormatted(tables.NewPlayerStatsTable(gamesCount, playerStats), output)
 }
 
-func (tableCommand *getTableCommand) getSeason(args []string) (seasons.Season, error) {
+func (tableCommand *getTableCommand) getSeason(ctx context.Context, args []string) (seasons.Season, error) {
 	if len(args) > 0 {
-		season, err := tableCommand.seasonsManager.GetSeasonByName(args[0])
+		season, err := tableCommand.seasonsManager.GetSeasonByName(ctx, args[0])
 
 		return season, err
 	}
 
-	season, err := tableCommand.seasonsManager.ActiveSeason()
+	season, err := tableCommand.seasonsManager.ActiveSeason(ctx)
 
 	return season, err
 }
@@ -210,8 +283,9 @@ func (command *getTableCommand) getSortName() (string, error) {
 		return "pointsRatio", nil
 	}
 
-	if sortName != "pointsRatio" && sortName != "wins" && sortName != "games" && sortName != "winRatio" {
-		return "", errors.New("Sort flag has to be pointsRatio, games, wins or winRatio")
+	if sortName != "pointsRatio" && sortName != "wins" && sortName != "games" && sortName != "winRatio" &&
+		sortName != "defenseWinRatio" && sortName != "offenseWinRatio" {
+		return "", errors.New("Sort flag has to be pointsRatio, games, wins, winRatio, defenseWinRatio or offenseWinRatio")
 	}
 
 	return sortName, nil
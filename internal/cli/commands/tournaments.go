@@ -0,0 +1,419 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/spie/fskick/internal/cli"
+	"github.com/spie/fskick/internal/games"
+	"github.com/spie/fskick/internal/tables"
+	"github.com/spie/fskick/internal/tournaments"
+)
+
+type tournamentsCommand struct {
+	command
+}
+
+func NewTournamentsCommand() *tournamentsCommand {
+	tournamentsCommand := tournamentsCommand{command: newCommand(&cobra.Command{
+		Use:     "tournament",
+		Aliases: []string{"tournaments"},
+		Short:   "Commands to handle tournaments",
+		Long:    "All commands to run a tournament: create it, register the teams, generate the schedule and record the results.",
+	})}
+
+	return &tournamentsCommand
+}
+
+type createTournamentCommand struct {
+	command
+	tournamentsManager TournamentsManager
+}
+
+func NewCreateTournamentCommand(tournamentsManager TournamentsManager) *createTournamentCommand {
+	createTournamentCommand := &createTournamentCommand{tournamentsManager: tournamentsManager}
+
+	cc := &cobra.Command{
+		Use:   "new [name]",
+		Short: "Create a new tournament",
+		Long:  "Create a new tournament with the given name, teams can register until the schedule is generated.",
+		Args:  cobra.ExactArgs(1),
+		RunE:  createTournamentCommand.createTournament,
+	}
+
+	cc.Flags().StringP(
+		"format",
+		"f",
+		string(tournaments.FormatSingleElimination),
+		"Format of the tournament, single, double (elimination) or groups followed by a knockout",
+	)
+	cc.Flags().IntP("team-size", "t", 2, "Players of each team, 1 or 2")
+
+	createTournamentCommand.command = newCommand(cc)
+
+	return createTournamentCommand
+}
+
+func (command *createTournamentCommand) createTournament(cmd *cobra.Command, args []string) error {
+	formatFlag, _ := cmd.Flags().GetString("format")
+	format, err := tournaments.ParseFormat(formatFlag)
+	if err != nil {
+		return err
+	}
+
+	teamSize, _ := cmd.Flags().GetInt("team-size")
+
+	tournament, err := command.tournamentsManager.CreateTournament(cmd.Context(), args[0], format, teamSize)
+	if err != nil {
+		return err
+	}
+
+	cli.Print(fmt.Sprintf("Tournament %s created", tournament.Name))
+
+	return nil
+}
+
+type getTournamentsCommand struct {
+	command
+	tournamentsManager TournamentsManager
+}
+
+func NewGetTournamentsCommand(tournamentsManager TournamentsManager) *getTournamentsCommand {
+	getTournamentsCommand := &getTournamentsCommand{tournamentsManager: tournamentsManager}
+
+	cc := &cobra.Command{
+		Use:   "list",
+		Short: "List all tournaments",
+		Long:  "List all tournaments with format, team size and status",
+		RunE:  getTournamentsCommand.getTournaments,
+	}
+
+	addOutputFlag(cc)
+
+	getTournamentsCommand.command = newCommand(cc)
+
+	return getTournamentsCommand
+}
+
+func (command *getTournamentsCommand) getTournaments(cmd *cobra.Command, args []string) error {
+	output, err := getOutputFormat(cmd)
+	if err != nil {
+		return err
+	}
+
+	tournamentsList, err := command.tournamentsManager.GetTournaments(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	tournamentsTable := tables.Table{
+		Columns: []tables.Column{
+			{Key: "name", Title: "Name"},
+			{Key: "format", Title: "Format"},
+			{Key: "teamSize", Title: "Team size"},
+			{Key: "status", Title: "Status"},
+		},
+	}
+	for _, tournament := range tournamentsList {
+		tournamentsTable.Rows = append(tournamentsTable.Rows, []any{
+			tournament.Name,
+			string(tournament.Format),
+			tournament.TeamSize,
+			string(tournament.Status),
+		})
+	}
+
+	return cli.PrintFormatted(tournamentsTable, output)
+}
+
+type registerTeamCommand struct {
+	command
+	tournamentsManager TournamentsManager
+}
+
+func NewRegisterTeamCommand(tournamentsManager TournamentsManager) *registerTeamCommand {
+	registerTeamCommand := &registerTeamCommand{tournamentsManager: tournamentsManager}
+
+	cc := &cobra.Command{
+		Use:   "register [tournament] [players...]",
+		Short: "Register a team for a tournament",
+		Long:  "Register the players as a team, teams are seeded in the order of their registration.",
+		Args:  cobra.MinimumNArgs(2),
+		RunE:  registerTeamCommand.registerTeam,
+	}
+
+	cc.Flags().StringP("name", "n", "", "Name of the team, the names of the players if empty")
+
+	registerTeamCommand.command = newCommand(cc)
+
+	return registerTeamCommand
+}
+
+func (command *registerTeamCommand) registerTeam(cmd *cobra.Command, args []string) error {
+	name, _ := cmd.Flags().GetString("name")
+
+	team, err := command.tournamentsManager.RegisterTeam(cmd.Context(), args[0], name, args[1:])
+	if err != nil {
+		return err
+	}
+
+	cli.Print(fmt.Sprintf("Team %s registered with seed %d", team.Name, team.Seed))
+
+	return nil
+}
+
+type scheduleTournamentCommand struct {
+	command
+	tournamentsManager TournamentsManager
+}
+
+func NewScheduleTournamentCommand(tournamentsManager TournamentsManager) *scheduleTournamentCommand {
+	scheduleTournamentCommand := &scheduleTournamentCommand{tournamentsManager: tournamentsManager}
+
+	cc := &cobra.Command{
+		Use:   "schedule [tournament]",
+		Short: "Generate the schedule of a tournament",
+		Long:  "Close the registration and generate the matches of the tournament.",
+		Args:  cobra.ExactArgs(1),
+		RunE:  scheduleTournamentCommand.schedule,
+	}
+
+	cc.Flags().IntP("groups", "g", 0, "Number of groups of the groups format, groups of about 4 teams if 0")
+	cc.Flags().IntP("advancing", "a", 0, "Teams of each group playing the knockout, 2 if 0")
+
+	scheduleTournamentCommand.command = newCommand(cc)
+
+	return scheduleTournamentCommand
+}
+
+func (command *scheduleTournamentCommand) schedule(cmd *cobra.Command, args []string) error {
+	groups, _ := cmd.Flags().GetInt("groups")
+	advancing, _ := cmd.Flags().GetInt("advancing")
+
+	tournament, err := command.tournamentsManager.Schedule(cmd.Context(), args[0], tournaments.ScheduleOptions{
+		Groups:    groups,
+		Advancing: advancing,
+	})
+	if err != nil {
+		return err
+	}
+
+	printTournament(tournament)
+
+	return nil
+}
+
+type showTournamentCommand struct {
+	command
+	tournamentsManager TournamentsManager
+}
+
+func NewShowTournamentCommand(tournamentsManager TournamentsManager) *showTournamentCommand {
+	showTournamentCommand := &showTournamentCommand{tournamentsManager: tournamentsManager}
+
+	cc := &cobra.Command{
+		Use:   "show [tournament]",
+		Short: "Show a tournament",
+		Long:  "Show the teams, the group standings and the matches of a tournament.",
+		Args:  cobra.ExactArgs(1),
+		RunE:  showTournamentCommand.show,
+	}
+
+	showTournamentCommand.command = newCommand(cc)
+
+	return showTournamentCommand
+}
+
+func (command *showTournamentCommand) show(cmd *cobra.Command, args []string) error {
+	tournament, err := command.tournamentsManager.GetTournament(cmd.Context(), args[0])
+	if err != nil {
+		return err
+	}
+
+	printTournament(tournament)
+
+	return nil
+}
+
+type recordResultCommand struct {
+	command
+	tournamentsManager TournamentsManager
+}
+
+func NewRecordResultCommand(tournamentsManager TournamentsManager) *recordResultCommand {
+	recordResultCommand := &recordResultCommand{tournamentsManager: tournamentsManager}
+
+	cc := &cobra.Command{
+		Use:   "result [tournament] [match]",
+		Short: "Record the result of a tournament match",
+		Long: "Record the result of the match with the given number as a game of the active season. " +
+			"The winners advance in the bracket.",
+		Args: cobra.ExactArgs(2),
+		RunE: recordResultCommand.recordResult,
+	}
+
+	cc.Flags().IntP("winner", "w", 0, "Winner of the match, team 1 or 2")
+	cc.Flags().BoolP("draw", "d", false, "The match ended in a draw, only in groups")
+	cc.Flags().StringP("score", "s", "", "Goals of team 1 and team 2, like 10:8")
+	cc.Flags().StringP("playedAt", "p", "", "Date of the match")
+
+	recordResultCommand.command = newCommand(cc)
+
+	return recordResultCommand
+}
+
+func (command *recordResultCommand) recordResult(cmd *cobra.Command, args []string) error {
+	number, err := strconv.Atoi(args[1])
+	if err != nil {
+		return fmt.Errorf("match has to be the number of the match: %s", args[1])
+	}
+
+	result, err := getResultFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	tournament, err := command.tournamentsManager.RecordResult(cmd.Context(), args[0], number, result)
+	if err != nil {
+		return err
+	}
+
+	match, _ := tournament.Match(number)
+	cli.Print(fmt.Sprintf("Match %d: %s", match.Number, getMatchResult(tournament, match)))
+
+	if champion, ok := tournament.Champion(); ok {
+		cli.Print(fmt.Sprintf("%s won the tournament", champion.Name))
+
+		return nil
+	}
+
+	printMatches(tournament, func(match tournaments.Match) bool { return match.Ready() })
+
+	return nil
+}
+
+func getResultFromFlags(cmd *cobra.Command) (tournaments.Result, error) {
+	winner, _ := cmd.Flags().GetInt("winner")
+	draw, _ := cmd.Flags().GetBool("draw")
+	scoreFlag, _ := cmd.Flags().GetString("score")
+	playedAtFlag, _ := cmd.Flags().GetString("playedAt")
+
+	if draw == (winner != 0) {
+		return tournaments.Result{}, errors.New("set the winner of the match or draw")
+	}
+
+	result := tournaments.Result{Winner: winner, Draw: draw}
+	if scoreFlag != "" {
+		team1, team2, _ := strings.Cut(scoreFlag, ":")
+		team1Goals, err1 := strconv.Atoi(team1)
+		team2Goals, err2 := strconv.Atoi(team2)
+		if err1 != nil || err2 != nil {
+			return tournaments.Result{}, fmt.Errorf("score has to be the goals of both teams like 10:8: %s", scoreFlag)
+		}
+
+		result.Score = &games.Score{Team1: team1Goals, Team2: team2Goals}
+	}
+
+	if playedAtFlag != "" {
+		playedAt, err := time.Parse("2006-01-02", playedAtFlag)
+		if err != nil {
+			return tournaments.Result{}, err
+		}
+
+		result.PlayedAt = playedAt
+	}
+
+	return result, nil
+}
+
+func printTournament(tournament tournaments.Tournament) {
+	cli.Print(fmt.Sprintf(
+		"Tournament: %s (%s, %dv%d, %s)",
+		tournament.Name,
+		tournament.Format,
+		tournament.TeamSize,
+		tournament.TeamSize,
+		tournament.Status,
+	))
+
+	teamsTable := [][]string{}
+	for _, team := range tournament.Teams {
+		names := make([]string, len(team.Players))
+		for i, player := range team.Players {
+			names[i] = player.Name
+		}
+		teamsTable = append(teamsTable, []string{strconv.Itoa(team.Seed), team.Name, strings.Join(names, ", ")})
+	}
+	cli.PrintTable([]string{"Seed", "Team", "Players"}, teamsTable)
+
+	for _, group := range tournament.GroupNumbers() {
+		cli.Print(fmt.Sprintf("Group %d", group))
+
+		standingsTable := [][]string{}
+		for _, standing := range tournament.Standings(group) {
+			standingsTable = append(standingsTable, []string{
+				strconv.Itoa(standing.Rank),
+				standing.Team.Name,
+				strconv.Itoa(standing.Played),
+				fmt.Sprintf("%d-%d-%d", standing.Wins, standing.Draws, standing.Losses),
+				fmt.Sprintf("%d:%d", standing.GoalsFor, standing.GoalsAgainst),
+				strconv.Itoa(standing.Points),
+			})
+		}
+		cli.PrintTable([]string{"#", "Team", "Played", "W-D-L", "Goals", "Points"}, standingsTable)
+	}
+
+	if len(tournament.Matches) > 0 {
+		printMatches(tournament, func(match tournaments.Match) bool { return !match.Bye() })
+	}
+
+	if champion, ok := tournament.Champion(); ok {
+		cli.Print(fmt.Sprintf("Champion: %s", champion.Name))
+	}
+}
+
+func printMatches(tournament tournaments.Tournament, filter func(match tournaments.Match) bool) {
+	matchesTable := [][]string{}
+	for _, match := range tournament.Matches {
+		if !filter(match) {
+			continue
+		}
+
+		stage := string(match.Stage)
+		if match.Stage == tournaments.StageGroup {
+			stage = fmt.Sprintf("group %d", match.Group)
+		}
+
+		matchesTable = append(matchesTable, []string{
+			strconv.Itoa(match.Number),
+			stage,
+			strconv.Itoa(match.Round),
+			tournament.TeamLabel(match, 1),
+			tournament.TeamLabel(match, 2),
+			getMatchResult(tournament, match),
+		})
+	}
+
+	cli.PrintTable([]string{"Match", "Stage", "Round", "Team 1", "Team 2", "Result"}, matchesTable)
+}
+
+func getMatchResult(tournament tournaments.Tournament, match tournaments.Match) string {
+	if !match.Done {
+		return ""
+	}
+
+	result := "Draw"
+	if !match.Draw {
+		result = fmt.Sprintf("%s won", tournament.TeamName(match.WinnerID))
+	}
+	if match.Score != nil {
+		result += fmt.Sprintf(" %d:%d", match.Score.Team1, match.Score.Team2)
+	}
+
+	return result
+}
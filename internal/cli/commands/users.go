@@ -46,6 +46,7 @@ func (createUserFromPlayerCommand createUserFromPlayerCommand) createUser(
 	args []string,
 ) error {
 	user, err := createUserFromPlayerCommand.usersManager.CreateUserFromPlayer(
+		cmd.Context(),
 		args[0],
 		args[1],
 		args[2],
@@ -58,3 +59,36 @@ func (createUserFromPlayerCommand createUserFromPlayerCommand) createUser(
 
 	return nil
 }
+
+type createApiTokenCommand struct {
+	command
+	usersManager users.Manager
+}
+
+func NewCreateApiTokenCommand(usersManager users.Manager) *createApiTokenCommand {
+	createApiTokenCommand := &createApiTokenCommand{usersManager: usersManager}
+
+	cc := &cobra.Command{
+		Use:   "token [email]",
+		Short: "Creates a new api token for a user",
+		Long: "Creates a new api token for the user with the given email, for the cli with --remote. " +
+			"The token is only shown once, a previous token of the user stops working.",
+		Args: cobra.ExactArgs(1),
+		RunE: createApiTokenCommand.createApiToken,
+	}
+
+	createApiTokenCommand.command = newCommand(cc)
+
+	return createApiTokenCommand
+}
+
+func (createApiTokenCommand createApiTokenCommand) createApiToken(cmd *cobra.Command, args []string) error {
+	token, err := createApiTokenCommand.usersManager.CreateApiToken(cmd.Context(), args[0])
+	if err != nil {
+		return err
+	}
+
+	cli.Print(token)
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package play
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/spie/fskick/internal/players"
+)
+
+// fuzzyScore tells how well the query matches the name. All letters of the query have to
+// appear in the name in order, letters at the start and right after the previous letter score
+// higher.
+func fuzzyScore(query string, name string) (int, bool) {
+	queryRunes := []rune(strings.ToLower(query))
+	if len(queryRunes) == 0 {
+		return 0, true
+	}
+
+	score := 0
+	last := -2
+	matched := 0
+	for i, r := range []rune(strings.ToLower(name)) {
+		if r != queryRunes[matched] {
+			continue
+		}
+
+		score++
+		if i == 0 {
+			score += 3
+		}
+		if last == i-1 {
+			score += 2
+		}
+
+		last = i
+		matched++
+		if matched == len(queryRunes) {
+			return score, true
+		}
+	}
+
+	return 0, false
+}
+
+// filterPlayers returns the players matching the query by name or display name, best matches
+// first. Excluded players, like the ones already picked, are left out.
+func filterPlayers(query string, allPlayers []players.Player, exclude func(player players.Player) bool) []players.Player {
+	type match struct {
+		player players.Player
+		score  int
+	}
+
+	matches := []match{}
+	for _, player := range allPlayers {
+		if exclude(player) {
+			continue
+		}
+
+		score, ok := fuzzyScore(query, player.Name)
+		if displayScore, displayOk := fuzzyScore(query, player.DisplayName); displayOk && player.DisplayName != "" {
+			score, ok = max(score, displayScore), true
+		}
+		if ok {
+			matches = append(matches, match{player: player, score: score})
+		}
+	}
+
+	slices.SortStableFunc(matches, func(a, b match) int {
+		if a.score != b.score {
+			return b.score - a.score
+		}
+
+		return strings.Compare(strings.ToLower(a.player.Name), strings.ToLower(b.player.Name))
+	})
+
+	filtered := make([]players.Player, len(matches))
+	for i, match := range matches {
+		filtered[i] = match.player
+	}
+
+	return filtered
+}
@@ -0,0 +1,101 @@
+package play
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/spie/fskick/internal/db"
+	"github.com/spie/fskick/internal/players"
+)
+
+func TestFilterPlayers(t *testing.T) {
+	allPlayers := []players.Player{
+		{Model: db.Model{ID: 1}, Name: "bob"},
+		{Model: db.Model{ID: 2}, Name: "alice"},
+		{Model: db.Model{ID: 3}, Name: "malte"},
+		{Model: db.Model{ID: 4}, Name: "charlie", DisplayName: "Chuck"},
+	}
+
+	tests := map[string]struct {
+		query      string
+		exclude    func(player players.Player) bool
+		assertions []func(t *testing.T, filtered []players.Player)
+	}{
+		"empty query sorts by name": {
+			query: "",
+			assertions: []func(t *testing.T, filtered []players.Player){
+				func(t *testing.T, filtered []players.Player) {
+					assert.Equal(t, []string{"alice", "bob", "charlie", "malte"}, names(filtered))
+				},
+			},
+		},
+		"letters in order": {
+			query: "ae",
+			assertions: []func(t *testing.T, filtered []players.Player){
+				func(t *testing.T, filtered []players.Player) {
+					assert.Equal(t, []string{"alice", "charlie", "malte"}, names(filtered))
+				},
+			},
+		},
+		"prefix and consecutive letters rank first": {
+			query: "al",
+			assertions: []func(t *testing.T, filtered []players.Player){
+				func(t *testing.T, filtered []players.Player) {
+					assert.Equal(t, []string{"alice", "malte", "charlie"}, names(filtered))
+				},
+			},
+		},
+		"display name and case": {
+			query: "CHU",
+			assertions: []func(t *testing.T, filtered []players.Player){
+				func(t *testing.T, filtered []players.Player) {
+					assert.Equal(t, []string{"charlie"}, names(filtered))
+				},
+			},
+		},
+		"excluded players": {
+			query: "",
+			exclude: func(player players.Player) bool {
+				return player.ID == 1
+			},
+			assertions: []func(t *testing.T, filtered []players.Player){
+				func(t *testing.T, filtered []players.Player) {
+					assert.NotContains(t, names(filtered), "bob")
+				},
+			},
+		},
+		"no match": {
+			query: "xyz",
+			assertions: []func(t *testing.T, filtered []players.Player){
+				func(t *testing.T, filtered []players.Player) {
+					assert.Empty(t, filtered)
+				},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			exclude := tt.exclude
+			if exclude == nil {
+				exclude = func(player players.Player) bool { return false }
+			}
+
+			filtered := filterPlayers(tt.query, allPlayers, exclude)
+
+			for _, assertion := range tt.assertions {
+				assertion(t, filtered)
+			}
+		})
+	}
+}
+
+func names(team []players.Player) []string {
+	playerNames := make([]string, len(team))
+	for i, player := range team {
+		playerNames[i] = player.Name
+	}
+
+	return playerNames
+}
@@ -0,0 +1,679 @@
+// Package play contains the terminal ui to record games at the table.
+package play
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/spie/fskick/internal/games"
+	"github.com/spie/fskick/internal/players"
+	"github.com/spie/fskick/internal/seasons"
+	"github.com/spie/fskick/internal/streaks"
+	"github.com/spie/fskick/internal/tables"
+)
+
+const (
+	teamSize       = 2
+	visibleMatches = 8
+	maxGoalDigits  = 2
+)
+
+type playersManager interface {
+	GetPlayers(ctx context.Context) ([]players.Player, error)
+}
+
+type gamesManager interface {
+	CreateGame(ctx context.Context, entry games.GameEntry) (*games.Game, error)
+	GetGamesCountForSeason(ctx context.Context, season seasons.Season, mode games.Mode) (int, error)
+	GetPlayerStatsForSeason(
+		ctx context.Context,
+		season seasons.Season,
+		mode games.Mode,
+		sort string,
+	) ([]games.PlayerStats, error)
+}
+
+type seasonsManager interface {
+	ActiveSeason(ctx context.Context) (seasons.Season, error)
+}
+
+type streaksManager interface {
+	GetCurrentStreaks(ctx context.Context, outcome games.Outcome) ([]streaks.Streak, error)
+}
+
+type step int
+
+const (
+	stepLoading step = iota
+	stepTeam1
+	stepTeam2
+	stepScore
+	stepOutcome
+	stepConfirm
+	stepSaving
+	stepResult
+)
+
+var (
+	titleStyle    = lipgloss.NewStyle().Bold(true)
+	selectedStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+	helpStyle     = lipgloss.NewStyle().Faint(true)
+	errorStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+)
+
+type loadedMsg struct {
+	season  seasons.Season
+	players []players.Player
+	err     error
+}
+
+type savedMsg struct {
+	table         string
+	streakChanges []string
+	err           error
+}
+
+// Model walks through picking both teams, entering the score and confirming the game. After
+// the game is saved it shows the season table and how the streaks of the players changed.
+type Model struct {
+	ctx            context.Context
+	playersManager playersManager
+	gamesManager   gamesManager
+	seasonsManager seasonsManager
+	streaksManager streaksManager
+
+	step       step
+	season     seasons.Season
+	allPlayers []players.Player
+	teams      [2]players.Team
+	query      string
+	cursor     int
+	matches    []players.Player
+	goals      [2]string
+	goalsField int
+	outcome    games.Outcome
+	duplicate  bool
+	result     savedMsg
+	err        error
+}
+
+func New(
+	ctx context.Context,
+	playersManager playersManager,
+	gamesManager gamesManager,
+	seasonsManager seasonsManager,
+	streaksManager streaksManager,
+) Model {
+	return Model{
+		ctx:            ctx,
+		playersManager: playersManager,
+		gamesManager:   gamesManager,
+		seasonsManager: seasonsManager,
+		streaksManager: streaksManager,
+	}
+}
+
+func (m Model) Init() tea.Cmd {
+	return m.load
+}
+
+func (m Model) load() tea.Msg {
+	season, err := m.seasonsManager.ActiveSeason(m.ctx)
+	if err != nil {
+		return loadedMsg{err: fmt.Errorf("active season: %w", err)}
+	}
+
+	allPlayers, err := m.playersManager.GetPlayers(m.ctx)
+	if err != nil {
+		return loadedMsg{err: err}
+	}
+
+	return loadedMsg{season: season, players: allPlayers}
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case loadedMsg:
+		if msg.err != nil {
+			m.err = msg.err
+
+			return m, nil
+		}
+
+		m.season = msg.season
+		m.allPlayers = msg.players
+
+		return m.startGame(), nil
+	case savedMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			m.duplicate = errors.Is(msg.err, games.ErrDuplicateGame)
+			m.step = stepConfirm
+
+			return m, nil
+		}
+
+		m.result = msg
+		m.step = stepResult
+
+		return m, nil
+	case tea.KeyMsg:
+		if msg.Type == tea.KeyCtrlC {
+			return m, tea.Quit
+		}
+
+		return m.updateKey(msg)
+	}
+
+	return m, nil
+}
+
+func (m Model) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch m.step {
+	case stepLoading:
+		if m.err != nil && (msg.Type == tea.KeyEsc || msg.String() == "q") {
+			return m, tea.Quit
+		}
+	case stepTeam1, stepTeam2:
+		return m.updatePicker(msg)
+	case stepScore:
+		return m.updateScore(msg), nil
+	case stepOutcome:
+		return m.updateOutcome(msg), nil
+	case stepConfirm:
+		return m.updateConfirm(msg)
+	case stepResult:
+		switch msg.String() {
+		case "n", "enter":
+			return m.startGame(), nil
+		case "q", "esc":
+			return m, tea.Quit
+		}
+	}
+
+	return m, nil
+}
+
+func (m Model) startGame() Model {
+	m.step = stepTeam1
+	m.teams = [2]players.Team{}
+	m.goals = [2]string{}
+	m.goalsField = 0
+	m.outcome = ""
+	m.duplicate = false
+	m.err = nil
+
+	return m.filter("")
+}
+
+// team returns the index of the team picked in the current step.
+func (m Model) team() int {
+	if m.step == stepTeam2 {
+		return 1
+	}
+
+	return 0
+}
+
+func (m Model) filter(query string) Model {
+	m.query = query
+	m.cursor = 0
+	m.matches = filterPlayers(query, m.allPlayers, m.picked)
+
+	return m
+}
+
+func (m Model) picked(player players.Player) bool {
+	for _, team := range m.teams {
+		for _, teamPlayer := range team {
+			if teamPlayer.UUID == player.UUID {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (m Model) updatePicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	team := m.team()
+	m.err = nil
+
+	switch msg.Type {
+	case tea.KeyEsc:
+		if m.step == stepTeam1 {
+			return m, tea.Quit
+		}
+
+		m.step = stepTeam1
+		m.teams[1] = nil
+
+		return m.filter(""), nil
+	case tea.KeyUp:
+		m.cursor = max(m.cursor-1, 0)
+	case tea.KeyDown:
+		m.cursor = min(m.cursor+1, max(len(m.matches)-1, 0))
+	case tea.KeyEnter:
+		if len(m.teams[team]) == teamSize {
+			return m.nextTeam(), nil
+		}
+		if len(m.matches) == 0 {
+			return m, nil
+		}
+
+		m.teams[team] = append(m.teams[team], m.matches[m.cursor])
+
+		return m.filter(""), nil
+	case tea.KeyTab:
+		if len(m.teams[team]) == 0 {
+			m.err = fmt.Errorf("pick at least one player")
+
+			return m, nil
+		}
+
+		return m.nextTeam(), nil
+	case tea.KeyCtrlX:
+		if len(m.teams[team]) == teamSize {
+			m.teams[team][0], m.teams[team][1] = m.teams[team][1], m.teams[team][0]
+		}
+	case tea.KeyBackspace:
+		if m.query != "" {
+			query := []rune(m.query)
+
+			return m.filter(string(query[:len(query)-1])), nil
+		}
+		if len(m.teams[team]) > 0 {
+			m.teams[team] = m.teams[team][:len(m.teams[team])-1]
+
+			return m.filter(""), nil
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		if len(m.teams[team]) == teamSize {
+			return m, nil
+		}
+
+		return m.filter(m.query + string(msg.Runes)), nil
+	}
+
+	return m, nil
+}
+
+func (m Model) nextTeam() Model {
+	if m.step == stepTeam1 {
+		m.step = stepTeam2
+	} else {
+		m.step = stepScore
+	}
+
+	return m.filter("")
+}
+
+func (m Model) updateScore(msg tea.KeyMsg) Model {
+	m.err = nil
+
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.step = stepTeam2
+
+		return m.filter("")
+	case tea.KeyTab, tea.KeyLeft, tea.KeyRight:
+		m.goalsField = 1 - m.goalsField
+	case tea.KeyBackspace:
+		goals := m.goals[m.goalsField]
+		if goals != "" {
+			m.goals[m.goalsField] = goals[:len(goals)-1]
+		} else if m.goalsField == 1 {
+			m.goalsField = 0
+		}
+	case tea.KeyRunes:
+		for _, r := range msg.Runes {
+			if r < '0' || r > '9' || len(m.goals[m.goalsField]) >= maxGoalDigits {
+				continue
+			}
+
+			m.goals[m.goalsField] += string(r)
+		}
+	case tea.KeyEnter:
+		score, err := m.score()
+		if err != nil {
+			m.err = err
+
+			return m
+		}
+		if score == nil {
+			m.step = stepOutcome
+
+			return m
+		}
+
+		switch {
+		case score.Team1 > score.Team2:
+			m.outcome = games.OutcomeWin
+		case score.Team1 < score.Team2:
+			m.outcome = games.OutcomeLoss
+		default:
+			m.outcome = games.OutcomeDraw
+		}
+		m.step = stepConfirm
+	}
+
+	return m
+}
+
+// score returns the entered goals of both teams, nil if no goals were entered.
+func (m Model) score() (*games.Score, error) {
+	if m.goals[0] == "" && m.goals[1] == "" {
+		return nil, nil
+	}
+	if m.goals[0] == "" || m.goals[1] == "" {
+		return nil, fmt.Errorf("enter the goals of both teams or none")
+	}
+
+	team1, err := strconv.Atoi(m.goals[0])
+	if err != nil {
+		return nil, fmt.Errorf("goals of team 1: %w", err)
+	}
+	team2, err := strconv.Atoi(m.goals[1])
+	if err != nil {
+		return nil, fmt.Errorf("goals of team 2: %w", err)
+	}
+
+	return &games.Score{Team1: team1, Team2: team2}, nil
+}
+
+func (m Model) updateOutcome(msg tea.KeyMsg) Model {
+	switch msg.String() {
+	case "esc":
+		m.step = stepScore
+	case "1":
+		m.outcome = games.OutcomeWin
+		m.step = stepConfirm
+	case "2":
+		m.outcome = games.OutcomeLoss
+		m.step = stepConfirm
+	case "d":
+		m.outcome = games.OutcomeDraw
+		m.step = stepConfirm
+	}
+
+	return m
+}
+
+func (m Model) updateConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter", "y":
+		m.step = stepSaving
+		m.err = nil
+
+		return m, m.save
+	case "esc", "n":
+		m.step = stepScore
+		m.duplicate = false
+		m.err = nil
+	}
+
+	return m, nil
+}
+
+// save creates the game with the winners first and loads the season table and the streaks of
+// the players afterwards. The current streaks are read before and after to show the changes.
+func (m Model) save() tea.Msg {
+	gamePlayers := append(append(players.Team{}, m.teams[0]...), m.teams[1]...)
+	streaksBefore, err := m.getStreaks(gamePlayers)
+	if err != nil {
+		return savedMsg{err: err}
+	}
+
+	winners, losers := m.teams[0], m.teams[1]
+	score, err := m.score()
+	if err != nil {
+		return savedMsg{err: err}
+	}
+	if m.outcome == games.OutcomeLoss {
+		winners, losers = losers, winners
+		if score != nil {
+			score = &games.Score{Team1: score.Team2, Team2: score.Team1}
+		}
+	}
+
+	_, err = m.gamesManager.CreateGame(m.ctx, games.GameEntry{
+		PlayedAt:       time.Now(),
+		Winners:        winners,
+		Losers:         losers,
+		Draw:           m.outcome == games.OutcomeDraw,
+		Positions:      m.positions(),
+		Score:          score,
+		AllowDuplicate: m.duplicate,
+	})
+	if err != nil {
+		return savedMsg{err: err}
+	}
+
+	table, err := m.renderTable()
+	if err != nil {
+		return savedMsg{err: err}
+	}
+
+	streaksAfter, err := m.getStreaks(gamePlayers)
+	if err != nil {
+		return savedMsg{err: err}
+	}
+
+	streakChanges := make([]string, len(gamePlayers))
+	for i, player := range gamePlayers {
+		streakChanges[i] = fmt.Sprintf("%s: %s → %s", player.PublicName(), streaksBefore[i], streaksAfter[i])
+	}
+
+	return savedMsg{table: table, streakChanges: streakChanges}
+}
+
+// positions returns the positions of teams with two players, the first picked player plays
+// in defense.
+func (m Model) positions() games.Positions {
+	positions := games.Positions{}
+	for _, team := range m.teams {
+		if len(team) != teamSize {
+			continue
+		}
+
+		positions[team[0].UUID] = players.PositionDefense
+		positions[team[1].UUID] = players.PositionOffense
+	}
+
+	return positions
+}
+
+func (m Model) renderTable() (string, error) {
+	playerStats, err := m.gamesManager.GetPlayerStatsForSeason(m.ctx, m.season, games.ModeAll, "")
+	if err != nil {
+		return "", err
+	}
+
+	gamesCount, err := m.gamesManager.GetGamesCountForSeason(m.ctx, m.season, games.ModeAll)
+	if err != nil {
+		return "", err
+	}
+
+	var table bytes.Buffer
+	err = tables.Render(&table, tables.NewPlayerStatsTable(gamesCount, playerStats), tables.FormatTable)
+	if err != nil {
+		return "", err
+	}
+
+	return table.String(), nil
+}
+
+// getStreaks describes the current streak of each player, like "3 wins".
+func (m Model) getStreaks(gamePlayers players.Team) ([]string, error) {
+	numbers := map[games.Outcome]map[string]int{}
+	for _, outcome := range []games.Outcome{games.OutcomeWin, games.OutcomeLoss} {
+		currentStreaks, err := m.streaksManager.GetCurrentStreaks(m.ctx, outcome)
+		if err != nil {
+			return nil, err
+		}
+
+		numbers[outcome] = map[string]int{}
+		for _, streak := range currentStreaks {
+			numbers[outcome][streak.Player.UUID] = streak.Number
+		}
+	}
+
+	descriptions := make([]string, len(gamePlayers))
+	for i, player := range gamePlayers {
+		switch {
+		case numbers[games.OutcomeWin][player.UUID] > 0:
+			descriptions[i] = pluralize(numbers[games.OutcomeWin][player.UUID], "win", "wins")
+		case numbers[games.OutcomeLoss][player.UUID] > 0:
+			descriptions[i] = pluralize(numbers[games.OutcomeLoss][player.UUID], "loss", "losses")
+		default:
+			descriptions[i] = "no streak"
+		}
+	}
+
+	return descriptions, nil
+}
+
+func pluralize(number int, singular string, plural string) string {
+	if number == 1 {
+		return fmt.Sprintf("%d %s", number, singular)
+	}
+
+	return fmt.Sprintf("%d %s", number, plural)
+}
+
+func (m Model) View() string {
+	var view strings.Builder
+
+	view.WriteString(titleStyle.Render(fmt.Sprintf("fskick play · %s", m.season.Name)))
+	view.WriteString("\n\n")
+
+	switch m.step {
+	case stepLoading:
+		if m.err == nil {
+			view.WriteString("Loading players…\n")
+		}
+	case stepTeam1, stepTeam2:
+		m.viewPicker(&view)
+	case stepScore:
+		m.viewTeams(&view)
+		view.WriteString("\nScore\n")
+		for i, goals := range m.goals {
+			field := fmt.Sprintf("Team %d: %-2s", i+1, goals)
+			if i == m.goalsField {
+				field = selectedStyle.Render(field + "_")
+			}
+			view.WriteString("  " + field + "\n")
+		}
+		view.WriteString(helpStyle.Render("\ntab: switch team · enter: continue, without goals to pick the winner · esc: back"))
+	case stepOutcome:
+		m.viewTeams(&view)
+		view.WriteString("\nWho won?\n")
+		view.WriteString(helpStyle.Render("\n1: team 1 · 2: team 2 · d: draw · esc: back"))
+	case stepConfirm, stepSaving:
+		m.viewTeams(&view)
+		view.WriteString("\n" + m.describeResult() + "\n")
+		if m.step == stepSaving {
+			view.WriteString("\nSaving…")
+		} else if m.duplicate {
+			view.WriteString(helpStyle.Render("\nenter: save anyway · esc: back"))
+		} else {
+			view.WriteString(helpStyle.Render("\nenter: save game · esc: back"))
+		}
+	case stepResult:
+		view.WriteString("Game saved.\n\n")
+		view.WriteString(m.result.table)
+		view.WriteString("\nStreaks\n")
+		for _, change := range m.result.streakChanges {
+			view.WriteString("  " + change + "\n")
+		}
+		view.WriteString(helpStyle.Render("\nn: next game · q: quit"))
+	}
+
+	if m.err != nil {
+		view.WriteString("\n" + errorStyle.Render(m.err.Error()))
+		if m.step == stepLoading {
+			view.WriteString(helpStyle.Render("\nq: quit"))
+		}
+	}
+
+	view.WriteString("\n")
+
+	return view.String()
+}
+
+func (m Model) viewPicker(view *strings.Builder) {
+	team := m.team()
+	if team == 1 {
+		view.WriteString(fmt.Sprintf("Team 1: %s\n", describeTeam(m.teams[0])))
+	}
+	view.WriteString(fmt.Sprintf("Team %d: %s\n\n", team+1, describeTeam(m.teams[team])))
+	if len(m.teams[team]) == teamSize {
+		view.WriteString(helpStyle.Render("enter: team done · ctrl+x: swap positions · backspace: remove player · esc: back"))
+
+		return
+	}
+
+	view.WriteString(fmt.Sprintf("Player %d > %s_\n", len(m.teams[team])+1, m.query))
+
+	start := max(m.cursor-visibleMatches+1, 0)
+	for i, player := range m.matches[start:min(start+visibleMatches, len(m.matches))] {
+		if start+i == m.cursor {
+			view.WriteString(selectedStyle.Render("› "+player.PublicName()) + "\n")
+			continue
+		}
+		view.WriteString("  " + player.PublicName() + "\n")
+	}
+	if len(m.matches) == 0 {
+		view.WriteString(helpStyle.Render("  no players found") + "\n")
+	}
+
+	view.WriteString(helpStyle.Render("\ntype to search · ↑/↓: select · enter: add player · tab: team done · esc: back"))
+}
+
+func (m Model) viewTeams(view *strings.Builder) {
+	for i, team := range m.teams {
+		view.WriteString(fmt.Sprintf("Team %d: %s\n", i+1, describeTeam(team)))
+	}
+}
+
+func (m Model) describeResult() string {
+	score, err := m.score()
+	switch {
+	case err != nil:
+		return errorStyle.Render(err.Error())
+	case score != nil:
+		return fmt.Sprintf("Score %d:%d", score.Team1, score.Team2)
+	case m.outcome == games.OutcomeDraw:
+		return "Draw"
+	case m.outcome == games.OutcomeWin:
+		return "Team 1 won"
+	default:
+		return "Team 2 won"
+	}
+}
+
+// describeTeam lists the players of the team with their positions.
+func describeTeam(team players.Team) string {
+	if len(team) == 0 {
+		return "–"
+	}
+	if len(team) == 1 {
+		return team[0].PublicName()
+	}
+
+	return fmt.Sprintf(
+		"%s (%s), %s (%s)",
+		team[0].PublicName(),
+		players.PositionDefense,
+		team[1].PublicName(),
+		players.PositionOffense,
+	)
+}
@@ -0,0 +1,236 @@
+package play
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/spie/fskick/internal/db"
+	"github.com/spie/fskick/internal/games"
+	"github.com/spie/fskick/internal/players"
+	"github.com/spie/fskick/internal/seasons"
+	"github.com/spie/fskick/internal/streaks"
+)
+
+type mockGamesManager struct {
+	created *games.GameEntry
+	err     error
+}
+
+// CreateGame fails with err, a duplicate game is created when it is allowed.
+func (mockGamesManager mockGamesManager) CreateGame(_ context.Context, entry games.GameEntry) (*games.Game, error) {
+	if mockGamesManager.err != nil && !(errors.Is(mockGamesManager.err, games.ErrDuplicateGame) && entry.AllowDuplicate) {
+		return nil, mockGamesManager.err
+	}
+
+	*mockGamesManager.created = entry
+
+	return &games.Game{}, nil
+}
+
+func (mockGamesManager mockGamesManager) GetGamesCountForSeason(_ context.Context, _ seasons.Season, _ games.Mode) (int, error) {
+	return 1, nil
+}
+
+func (mockGamesManager mockGamesManager) GetPlayerStatsForSeason(
+	_ context.Context,
+	_ seasons.Season,
+	_ games.Mode,
+	_ string,
+) ([]games.PlayerStats, error) {
+	return []games.PlayerStats{
+		{PlayerAttendance: games.PlayerAttendance{Player: newPlayer(1, "alice"), Wins: 1, Games: 1}, Points: 3},
+	}, nil
+}
+
+type mockStreaksManager struct {
+	calls *int
+}
+
+// GetCurrentStreaks gives alice a winning streak, one game longer after the game is saved.
+func (mockStreaksManager mockStreaksManager) GetCurrentStreaks(
+	_ context.Context,
+	outcome games.Outcome,
+) ([]streaks.Streak, error) {
+	*mockStreaksManager.calls++
+	if outcome != games.OutcomeWin {
+		return []streaks.Streak{}, nil
+	}
+
+	number := 1
+	if *mockStreaksManager.calls > 2 {
+		number = 2
+	}
+
+	return []streaks.Streak{{Number: number, Player: newPlayer(1, "alice")}}, nil
+}
+
+func newPlayer(id uint, name string) players.Player {
+	return players.Player{Model: db.Model{ID: id, UUID: name}, Name: name}
+}
+
+func TestModel(t *testing.T) {
+	alice, bob, carol, dave := newPlayer(1, "alice"), newPlayer(2, "bob"), newPlayer(3, "carol"), newPlayer(4, "dave")
+
+	tests := map[string]struct {
+		keys       []tea.KeyMsg
+		createErr  error
+		assertions []func(t *testing.T, m Model, created games.GameEntry, msg tea.Msg)
+	}{
+		"doubles with score": {
+			keys: append(
+				append(typeText("al"), enter(), enter(), enter()),
+				append(typeText("car"), enter(), enter(), tea.KeyMsg{Type: tea.KeyCtrlX}, enter())...,
+			),
+			assertions: []func(t *testing.T, m Model, created games.GameEntry, msg tea.Msg){
+				func(t *testing.T, m Model, created games.GameEntry, msg tea.Msg) {
+					assert.Equal(t, stepScore, m.step)
+					assert.Equal(t, [2]players.Team{{alice, bob}, {dave, carol}}, m.teams)
+					assert.Equal(t, games.Positions{
+						"alice": players.PositionDefense,
+						"bob":   players.PositionOffense,
+						"dave":  players.PositionDefense,
+						"carol": players.PositionOffense,
+					}, m.positions())
+				},
+			},
+		},
+		"team 2 wins by score": {
+			keys: append(
+				append(typeText("alice"), enter(), tea.KeyMsg{Type: tea.KeyTab}),
+				append(typeText("bob"), enter(), tea.KeyMsg{Type: tea.KeyTab}, typeText("5")[0], tea.KeyMsg{Type: tea.KeyTab},
+					typeText("10")[0], enter(), enter())...,
+			),
+			assertions: []func(t *testing.T, m Model, created games.GameEntry, msg tea.Msg){
+				func(t *testing.T, m Model, created games.GameEntry, msg tea.Msg) {
+					assert.Equal(t, stepResult, m.step)
+					assert.NoError(t, m.err)
+					assert.Equal(t, players.Team{bob}, created.Winners)
+					assert.Equal(t, players.Team{alice}, created.Losers)
+					assert.False(t, created.Draw)
+					assert.Equal(t, &games.Score{Team1: 10, Team2: 5}, created.Score)
+					assert.Empty(t, created.Positions)
+				},
+				func(t *testing.T, m Model, created games.GameEntry, msg tea.Msg) {
+					assert.Contains(t, m.result.table, "alice")
+					assert.Equal(t, []string{"alice: 1 win → 2 wins", "bob: no streak → no streak"}, m.result.streakChanges)
+				},
+			},
+		},
+		"draw without score": {
+			keys: append(
+				append(typeText("alice"), enter(), tea.KeyMsg{Type: tea.KeyTab}),
+				append(typeText("bob"), enter(), tea.KeyMsg{Type: tea.KeyTab}, enter(), typeText("d")[0], enter())...,
+			),
+			assertions: []func(t *testing.T, m Model, created games.GameEntry, msg tea.Msg){
+				func(t *testing.T, m Model, created games.GameEntry, msg tea.Msg) {
+					assert.Equal(t, stepResult, m.step)
+					assert.True(t, created.Draw)
+					assert.Nil(t, created.Score)
+				},
+			},
+		},
+		"incomplete score": {
+			keys: append(
+				append(typeText("alice"), enter(), tea.KeyMsg{Type: tea.KeyTab}),
+				append(typeText("bob"), enter(), tea.KeyMsg{Type: tea.KeyTab}, typeText("3")[0], enter())...,
+			),
+			assertions: []func(t *testing.T, m Model, created games.GameEntry, msg tea.Msg){
+				func(t *testing.T, m Model, created games.GameEntry, msg tea.Msg) {
+					assert.Equal(t, stepScore, m.step)
+					assert.ErrorContains(t, m.err, "enter the goals of both teams or none")
+				},
+			},
+		},
+		"save error goes back to confirmation": {
+			keys: append(
+				append(typeText("alice"), enter(), tea.KeyMsg{Type: tea.KeyTab}),
+				append(typeText("bob"), enter(), tea.KeyMsg{Type: tea.KeyTab}, enter(), typeText("1")[0], enter())...,
+			),
+			createErr: errors.New("database is locked"),
+			assertions: []func(t *testing.T, m Model, created games.GameEntry, msg tea.Msg){
+				func(t *testing.T, m Model, created games.GameEntry, msg tea.Msg) {
+					assert.Equal(t, stepConfirm, m.step)
+					assert.ErrorContains(t, m.err, "database is locked")
+				},
+			},
+		},
+		"duplicate is saved after confirmation": {
+			keys: append(
+				append(typeText("alice"), enter(), tea.KeyMsg{Type: tea.KeyTab}),
+				append(typeText("bob"), enter(), tea.KeyMsg{Type: tea.KeyTab}, enter(), typeText("1")[0], enter(), enter())...,
+			),
+			createErr: games.ErrDuplicateGame,
+			assertions: []func(t *testing.T, m Model, created games.GameEntry, msg tea.Msg){
+				func(t *testing.T, m Model, created games.GameEntry, msg tea.Msg) {
+					assert.Equal(t, stepResult, m.step)
+					assert.NoError(t, m.err)
+					assert.True(t, created.AllowDuplicate)
+					assert.Equal(t, players.Team{alice}, created.Winners)
+				},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			created := games.GameEntry{}
+			calls := 0
+			m := New(
+				t.Context(),
+				nil,
+				mockGamesManager{created: &created, err: tt.createErr},
+				nil,
+				mockStreaksManager{calls: &calls},
+			)
+
+			var model tea.Model = m
+			model, _ = model.Update(loadedMsg{
+				season:  seasons.Season{Name: "2026"},
+				players: []players.Player{alice, bob, carol, dave},
+			})
+
+			var msg tea.Msg
+			for _, key := range tt.keys {
+				var cmd tea.Cmd
+				model, cmd = model.Update(key)
+				if cmd != nil {
+					msg = cmd()
+					model, _ = model.Update(msg)
+				}
+			}
+
+			for _, assertion := range tt.assertions {
+				assertion(t, model.(Model), created, msg)
+			}
+		})
+	}
+}
+
+func typeText(text string) []tea.KeyMsg {
+	return []tea.KeyMsg{{Type: tea.KeyRunes, Runes: []rune(text)}}
+}
+
+func enter() tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyEnter}
+}
+
+func TestModelWithInvalidScore(t *testing.T) {
+	created := games.GameEntry{}
+	calls := 0
+	m := New(t.Context(), nil, mockGamesManager{created: &created}, nil, mockStreaksManager{calls: &calls})
+	m.teams = [2]players.Team{{newPlayer(1, "alice")}, {newPlayer(2, "bob")}}
+	m.goals = [2]string{"3", ""}
+	m.step = stepConfirm
+
+	assert.Contains(t, m.View(), "enter the goals of both teams or none")
+
+	var model tea.Model
+	model, _ = m.Update(m.save())
+	assert.Equal(t, stepConfirm, model.(Model).step)
+	assert.ErrorContains(t, model.(Model).err, "enter the goals of both teams or none")
+	assert.Empty(t, created.Winners)
+}
@@ -0,0 +1,166 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/spie/fskick/internal/db"
+)
+
+const DefaultTimeout = 30 * time.Second
+
+const maxErrorMessageSize = 1 << 10
+
+var (
+	ErrInvalidURL   = errors.New("remote url has to be an http or https url")
+	ErrUnauthorized = errors.New("not authorized, check the api token")
+	ErrForbidden    = errors.New("not allowed for the user of the api token")
+)
+
+// ResponseError is returned for responses with an error status. Responses with 404 match
+// db.ErrNotFound, responses with 401 match ErrUnauthorized and responses with 403 match
+// ErrForbidden in errors.Is.
+type ResponseError struct {
+	Status  int
+	Message string
+}
+
+func (err *ResponseError) Error() string {
+	return fmt.Sprintf("remote responded with %d %s: %s", err.Status, http.StatusText(err.Status), err.Message)
+}
+
+func (err *ResponseError) Is(target error) bool {
+	switch err.Status {
+	case http.StatusNotFound:
+		return target == db.ErrNotFound
+	case http.StatusUnauthorized:
+		return target == ErrUnauthorized
+	case http.StatusForbidden:
+		return target == ErrForbidden
+	}
+
+	return false
+}
+
+// Client sends requests to the api of a running server, authenticated with the api token of
+// a user. Reading does not need a token.
+type Client struct {
+	baseURL    *url.URL
+	token      string
+	httpClient *http.Client
+}
+
+func New(baseURL string, token string) (Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return Client{}, fmt.Errorf("%w: %w", ErrInvalidURL, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Client{}, fmt.Errorf("%w: %s", ErrInvalidURL, baseURL)
+	}
+
+	return Client{
+		baseURL:    u,
+		token:      token,
+		httpClient: &http.Client{Timeout: DefaultTimeout},
+	}, nil
+}
+
+func (client Client) get(ctx context.Context, path string, query url.Values, response any) error {
+	return client.do(ctx, http.MethodGet, path, query, nil, nil, response)
+}
+
+func (client Client) send(ctx context.Context, method string, path string, request any, response any) error {
+	return client.do(ctx, method, path, nil, nil, request, response)
+}
+
+// sendWithHeaders sends the request with the additional headers, headers without a value
+// are left out.
+func (client Client) sendWithHeaders(
+	ctx context.Context,
+	method string,
+	path string,
+	headers http.Header,
+	request any,
+	response any,
+) error {
+	return client.do(ctx, method, path, nil, headers, request, response)
+}
+
+func (client Client) do(
+	ctx context.Context,
+	method string,
+	path string,
+	query url.Values,
+	headers http.Header,
+	request any,
+	response any,
+) error {
+	u := client.baseURL.JoinPath(path)
+	u.RawQuery = query.Encode()
+
+	var body io.Reader
+	if request != nil {
+		jsonReq, err := json.Marshal(request)
+		if err != nil {
+			return fmt.Errorf("encode request of %s %s: %w", method, path, err)
+		}
+		body = bytes.NewReader(jsonReq)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return fmt.Errorf("create request %s %s: %w", method, path, err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if request != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if client.token != "" {
+		req.Header.Set("Authorization", "Bearer "+client.token)
+	}
+	for name, values := range headers {
+		for _, value := range values {
+			if value != "" {
+				req.Header.Add(name, value)
+			}
+		}
+	}
+
+	res, err := client.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusBadRequest {
+		message, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorMessageSize))
+
+		return fmt.Errorf("%s %s: %w", method, path, &ResponseError{
+			Status:  res.StatusCode,
+			Message: strings.TrimSpace(string(message)),
+		})
+	}
+
+	err = json.NewDecoder(res.Body).Decode(response)
+	if err != nil {
+		return fmt.Errorf("decode response of %s %s: %w", method, path, err)
+	}
+
+	return nil
+}
+
+func hasStatus(err error, status int) bool {
+	var responseErr *ResponseError
+
+	return errors.As(err, &responseErr) && responseErr.Status == status
+}
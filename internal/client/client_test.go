@@ -0,0 +1,211 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/spie/fskick/internal/db"
+	"github.com/spie/fskick/internal/games"
+	"github.com/spie/fskick/internal/players"
+)
+
+func TestNew(t *testing.T) {
+	tests := map[string]struct {
+		baseURL    string
+		assertions []func(t *testing.T, err error)
+	}{
+		"https url": {
+			baseURL: "https://kicker.example/fskick",
+			assertions: []func(t *testing.T, err error){
+				func(t *testing.T, err error) {
+					assert.NoError(t, err)
+				},
+			},
+		},
+		"without scheme": {
+			baseURL: "kicker.example",
+			assertions: []func(t *testing.T, err error){
+				func(t *testing.T, err error) {
+					assert.ErrorIs(t, err, ErrInvalidURL)
+				},
+			},
+		},
+		"other scheme": {
+			baseURL: "ftp://kicker.example",
+			assertions: []func(t *testing.T, err error){
+				func(t *testing.T, err error) {
+					assert.ErrorIs(t, err, ErrInvalidURL)
+				},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := New(tt.baseURL, "")
+
+			for _, assertion := range tt.assertions {
+				assertion(t, err)
+			}
+		})
+	}
+}
+
+func TestGamesManager_ListGames(t *testing.T) {
+	tests := map[string]struct {
+		status     int
+		body       string
+		assertions []func(t *testing.T, req *http.Request, gamesPage games.GamesPage, err error)
+	}{
+		"sends filter and token": {
+			status: http.StatusOK,
+			body: `{"games":[{"uuid":"game1","winners":[{"name":"alice","position":"defense"}],` +
+				`"losers":[{"name":"bob"}],"draw":false,"score":{"winners":10,"losers":4}}],"nextCursor":"next"}`,
+			assertions: []func(t *testing.T, req *http.Request, gamesPage games.GamesPage, err error){
+				func(t *testing.T, req *http.Request, gamesPage games.GamesPage, err error) {
+					assert.NoError(t, err)
+					assert.Equal(t, "/fskick/api/games", req.URL.Path)
+					assert.Equal(t, "Bearer token123", req.Header.Get("Authorization"))
+					assert.Equal(t, "player1", req.URL.Query().Get("player"))
+					assert.Equal(t, "2024-06-30", req.URL.Query().Get("to"))
+					assert.Equal(t, "5", req.URL.Query().Get("limit"))
+				},
+				func(t *testing.T, req *http.Request, gamesPage games.GamesPage, err error) {
+					assert.Equal(t, "next", gamesPage.NextCursor)
+					assert.Len(t, gamesPage.Games, 1)
+
+					game := gamesPage.Games[0]
+					assert.Equal(t, games.OutcomeWin, game.Outcome())
+					assert.Equal(t, "alice", game.Team(1)[0].Player.Name)
+					assert.Equal(t, players.PositionDefense, game.Team(1)[0].Position)
+					assert.Equal(t, "bob", game.Team(2)[0].Player.Name)
+					assert.Equal(t, &games.Score{Team1: 10, Team2: 4}, game.Score)
+				},
+			},
+		},
+		"not found": {
+			status: http.StatusNotFound,
+			body:   "Player player1 not found\n",
+			assertions: []func(t *testing.T, req *http.Request, gamesPage games.GamesPage, err error){
+				func(t *testing.T, req *http.Request, gamesPage games.GamesPage, err error) {
+					assert.ErrorIs(t, err, db.ErrNotFound)
+					assert.ErrorContains(t, err, "404 Not Found: Player player1 not found")
+				},
+			},
+		},
+		"unauthorized": {
+			status: http.StatusUnauthorized,
+			body:   "Unauthorized.",
+			assertions: []func(t *testing.T, req *http.Request, gamesPage games.GamesPage, err error){
+				func(t *testing.T, req *http.Request, gamesPage games.GamesPage, err error) {
+					assert.ErrorIs(t, err, ErrUnauthorized)
+					assert.NotErrorIs(t, err, db.ErrNotFound)
+				},
+			},
+		},
+		"forbidden": {
+			status: http.StatusForbidden,
+			body:   "Only admins are allowed.",
+			assertions: []func(t *testing.T, req *http.Request, gamesPage games.GamesPage, err error){
+				func(t *testing.T, req *http.Request, gamesPage games.GamesPage, err error) {
+					assert.ErrorIs(t, err, ErrForbidden)
+					assert.NotErrorIs(t, err, ErrUnauthorized)
+				},
+			},
+		},
+		"invalid response": {
+			status: http.StatusOK,
+			body:   "<html>",
+			assertions: []func(t *testing.T, req *http.Request, gamesPage games.GamesPage, err error){
+				func(t *testing.T, req *http.Request, gamesPage games.GamesPage, err error) {
+					assert.ErrorContains(t, err, "decode response of GET /api/games")
+				},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var req *http.Request
+			api := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, r *http.Request) {
+				req = r
+				res.WriteHeader(tt.status)
+				res.Write([]byte(tt.body))
+			}))
+			defer api.Close()
+
+			client, err := New(api.URL+"/fskick", "token123")
+			assert.NoError(t, err)
+
+			gamesPage, err := NewGamesManager(client).ListGames(t.Context(), games.GamesFilter{
+				Player: &players.Player{Model: db.Model{UUID: "player1"}},
+				To:     time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+				Limit:  5,
+			})
+
+			for _, assertion := range tt.assertions {
+				assertion(t, req, gamesPage, err)
+			}
+		})
+	}
+}
+
+func TestGamesManager_MatchPlayers(t *testing.T) {
+	alice := players.Player{Model: db.Model{ID: 1}, Name: "alice"}
+	bob := players.Player{Model: db.Model{ID: 2}, Name: "bob"}
+
+	tests := map[string]struct {
+		status     int
+		body       string
+		assertions []func(t *testing.T, req *http.Request, matchup games.Matchup, err error)
+	}{
+		"maps the teams to the given players": {
+			status: http.StatusOK,
+			body:   `{"matchup":{"team1":[{"name":"bob"}],"team2":[{"name":"alice"}],"difference":0.25}}`,
+			assertions: []func(t *testing.T, req *http.Request, matchup games.Matchup, err error){
+				func(t *testing.T, req *http.Request, matchup games.Matchup, err error) {
+					assert.NoError(t, err)
+					assert.Equal(t, "/api/matchmaking", req.URL.Path)
+					assert.Equal(t, []string{"alice", "bob"}, req.URL.Query()["player"])
+					assert.Equal(t, players.Team{bob}, matchup.Team1)
+					assert.Equal(t, players.Team{alice}, matchup.Team2)
+					assert.Equal(t, 0.25, matchup.Difference)
+				},
+			},
+		},
+		"with wrong number of players": {
+			status: http.StatusBadRequest,
+			body:   "matchmaking needs 2 or 4 different players\n",
+			assertions: []func(t *testing.T, req *http.Request, matchup games.Matchup, err error){
+				func(t *testing.T, req *http.Request, matchup games.Matchup, err error) {
+					assert.ErrorIs(t, err, games.ErrMatchPlayers)
+				},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var req *http.Request
+			api := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, r *http.Request) {
+				req = r
+				res.WriteHeader(tt.status)
+				res.Write([]byte(tt.body))
+			}))
+			defer api.Close()
+
+			client, err := New(api.URL, "")
+			assert.NoError(t, err)
+
+			matchup, err := NewGamesManager(client).MatchPlayers(t.Context(), []players.Player{alice, bob})
+
+			for _, assertion := range tt.assertions {
+				assertion(t, req, matchup, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,282 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spie/fskick/internal/games"
+	"github.com/spie/fskick/internal/players"
+	"github.com/spie/fskick/internal/seasons"
+)
+
+// GamesManager handles the games and stats of a running server like games.Manager.
+type GamesManager struct {
+	client Client
+}
+
+func NewGamesManager(client Client) GamesManager {
+	return GamesManager{client: client}
+}
+
+type teamMemberRequest struct {
+	Name     string `json:"name"`
+	Position string `json:"position"`
+}
+
+// CreateGame records the game on the server. The server records the user of the api token
+// as recorder, so the recorder of the entry is ignored. The idempotency key is sent as
+// Idempotency-Key header.
+func (manager GamesManager) CreateGame(ctx context.Context, entry games.GameEntry) (*games.Game, error) {
+	request := struct {
+		PlayedAt       time.Time           `json:"playedAt"`
+		Winners        []teamMemberRequest `json:"winners"`
+		Losers         []teamMemberRequest `json:"losers"`
+		Draw           bool                `json:"draw"`
+		Score          *scoreResponse      `json:"score,omitempty"`
+		AllowDuplicate bool                `json:"allowDuplicate"`
+	}{
+		PlayedAt:       entry.PlayedAt,
+		Winners:        newTeamMemberRequests(entry.Winners, entry.Positions),
+		Losers:         newTeamMemberRequests(entry.Losers, entry.Positions),
+		Draw:           entry.Draw,
+		AllowDuplicate: entry.AllowDuplicate,
+	}
+	if entry.Score != nil {
+		request.Score = &scoreResponse{Winners: entry.Score.Team1, Losers: entry.Score.Team2}
+	}
+
+	var response struct {
+		Game gameResponse `json:"game"`
+	}
+	err := manager.client.sendWithHeaders(
+		ctx,
+		http.MethodPost,
+		"/api/games",
+		http.Header{"Idempotency-Key": {entry.IdempotencyKey}},
+		request,
+		&response,
+	)
+	var responseErr *ResponseError
+	if errors.As(err, &responseErr) && responseErr.Status == http.StatusConflict {
+		details := strings.TrimPrefix(responseErr.Message, games.ErrDuplicateGame.Error()+": ")
+
+		return nil, fmt.Errorf("%w: %s", games.ErrDuplicateGame, details)
+	}
+	if errors.As(err, &responseErr) && responseErr.Status == http.StatusUnprocessableEntity {
+		validationErr, ok := newValidationError(responseErr.Message)
+		if ok {
+			return nil, validationErr
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create game: %w", err)
+	}
+
+	game := response.Game.game()
+
+	return &game, nil
+}
+
+// newValidationError reads the field errors of an invalid game from the response. The errors of
+// the fields only keep their message.
+func newValidationError(message string) (*games.ValidationError, bool) {
+	var response struct {
+		Errors []struct {
+			Field   string `json:"field"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	err := json.Unmarshal([]byte(message), &response)
+	if err != nil || len(response.Errors) == 0 {
+		return nil, false
+	}
+
+	validationErr := &games.ValidationError{Fields: make([]games.FieldError, len(response.Errors))}
+	for i, fieldErr := range response.Errors {
+		validationErr.Fields[i] = games.FieldError{Field: fieldErr.Field, Err: errors.New(fieldErr.Message)}
+	}
+
+	return validationErr, true
+}
+
+func newTeamMemberRequests(team players.Team, positions games.Positions) []teamMemberRequest {
+	members := make([]teamMemberRequest, len(team))
+	for i, player := range team {
+		members[i] = teamMemberRequest{Name: player.Name, Position: string(positions[player.UUID])}
+	}
+
+	return members
+}
+
+func (manager GamesManager) ListGames(ctx context.Context, filter games.GamesFilter) (games.GamesPage, error) {
+	query := url.Values{}
+	if filter.Season != nil {
+		query.Set("season", filter.Season.UUID)
+	}
+	setPlayerQuery(query, "player", filter.Player)
+	setPlayerQuery(query, "teammate", filter.Teammate)
+	setPlayerQuery(query, "opponent", filter.Oponent)
+	if filter.Outcome != "" {
+		query.Set("outcome", string(filter.Outcome))
+	}
+	if !filter.From.IsZero() {
+		query.Set("from", filter.From.Format("2006-01-02"))
+	}
+	// the api takes the last date of the games, the filter the day after it
+	if !filter.To.IsZero() {
+		query.Set("to", filter.To.AddDate(0, 0, -1).Format("2006-01-02"))
+	}
+	if filter.Limit > 0 {
+		query.Set("limit", strconv.Itoa(filter.Limit))
+	}
+	if filter.Cursor != "" {
+		query.Set("cursor", filter.Cursor)
+	}
+
+	var response gamesPageResponse
+	err := manager.client.get(ctx, "/api/games", query, &response)
+	if err != nil {
+		return games.GamesPage{}, fmt.Errorf("list games: %w", err)
+	}
+
+	gamesPage := games.GamesPage{Games: make([]games.Game, len(response.Games)), NextCursor: response.NextCursor}
+	for i, game := range response.Games {
+		gamesPage.Games[i] = game.game()
+	}
+
+	return gamesPage, nil
+}
+
+func setPlayerQuery(query url.Values, key string, player *players.Player) {
+	if player != nil {
+		query.Set(key, player.UUID)
+	}
+}
+
+func (manager GamesManager) GetGamesCount(ctx context.Context, mode games.Mode) (int, error) {
+	var response struct {
+		GamesCount int `json:"gamesCount"`
+	}
+	err := manager.client.get(ctx, "/api/games/count", tableQuery(mode, ""), &response)
+	if err != nil {
+		return 0, fmt.Errorf("get games count: %w", err)
+	}
+
+	return response.GamesCount, nil
+}
+
+func (manager GamesManager) GetGamesCountForSeason(ctx context.Context, season seasons.Season, mode games.Mode) (int, error) {
+	table, err := manager.getTable(ctx, season, mode, "")
+	if err != nil {
+		return 0, err
+	}
+
+	return table.Season.GamesCount, nil
+}
+
+func (manager GamesManager) GetPlayerStatsForSeason(
+	ctx context.Context,
+	season seasons.Season,
+	mode games.Mode,
+	sort string,
+) ([]games.PlayerStats, error) {
+	table, err := manager.getTable(ctx, season, mode, sort)
+	if err != nil {
+		return nil, err
+	}
+
+	return table.playerStats(), nil
+}
+
+func (manager GamesManager) GetAllPlayerStats(ctx context.Context, mode games.Mode, sort string) ([]games.PlayerStats, error) {
+	var response playerStatsListResponse
+	err := manager.client.get(ctx, "/api/players", tableQuery(mode, sort), &response)
+	if err != nil {
+		return nil, fmt.Errorf("get all player stats: %w", err)
+	}
+
+	return response.playerStats(), nil
+}
+
+// MatchPlayers lets the server split the players into balanced teams. The teams are made of
+// the given players, so the positions are keyed like the players.
+func (manager GamesManager) MatchPlayers(ctx context.Context, playersToMatch []players.Player) (games.Matchup, error) {
+	query := url.Values{}
+	playersByName := make(map[string]players.Player, len(playersToMatch))
+	for _, player := range playersToMatch {
+		query.Add("player", player.Name)
+		playersByName[player.Name] = player
+	}
+
+	var response struct {
+		Matchup matchupResponse `json:"matchup"`
+	}
+	err := manager.client.get(ctx, "/api/matchmaking", query, &response)
+	if hasStatus(err, http.StatusBadRequest) {
+		return games.Matchup{}, fmt.Errorf("%w: %d given", games.ErrMatchPlayers, len(playersToMatch))
+	}
+	if err != nil {
+		return games.Matchup{}, fmt.Errorf("match players: %w", err)
+	}
+
+	matchup := games.Matchup{Positions: games.Positions{}, Difference: response.Matchup.Difference}
+	matchup.Team1 = getMatchupTeam(response.Matchup.Team1, playersByName, matchup.Positions)
+	matchup.Team2 = getMatchupTeam(response.Matchup.Team2, playersByName, matchup.Positions)
+
+	return matchup, nil
+}
+
+func getMatchupTeam(
+	members []teamMemberResponse,
+	playersByName map[string]players.Player,
+	positions games.Positions,
+) players.Team {
+	team := make(players.Team, len(members))
+	for i, member := range members {
+		team[i] = playersByName[member.Name]
+		if member.Position != "" {
+			positions[team[i].UUID] = players.Position(member.Position)
+		}
+	}
+
+	return team
+}
+
+func (manager GamesManager) getTable(
+	ctx context.Context,
+	season seasons.Season,
+	mode games.Mode,
+	sort string,
+) (tableResponse, error) {
+	var response tableResponse
+	err := manager.client.get(
+		ctx,
+		fmt.Sprintf("/api/seasons/table/%s", url.PathEscape(season.UUID)),
+		tableQuery(mode, sort),
+		&response,
+	)
+	if err != nil {
+		return tableResponse{}, fmt.Errorf("get seasons table: %w", err)
+	}
+
+	return response, nil
+}
+
+func tableQuery(mode games.Mode, sort string) url.Values {
+	query := url.Values{}
+	if mode != "" && mode != games.ModeAll {
+		query.Set("mode", string(mode))
+	}
+	if sort != "" {
+		query.Set("sort", sort)
+	}
+
+	return query
+}
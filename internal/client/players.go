@@ -0,0 +1,124 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/spie/fskick/internal/players"
+)
+
+// PlayersManager handles the players of a running server like players.Manager.
+type PlayersManager struct {
+	client Client
+}
+
+func NewPlayersManager(client Client) PlayersManager {
+	return PlayersManager{client: client}
+}
+
+func (manager PlayersManager) CreatePlayer(ctx context.Context, name string) (players.Player, error) {
+	var response struct {
+		Player playerResponse `json:"player"`
+	}
+	err := manager.client.send(ctx, http.MethodPost, "/api/players", map[string]string{"name": name}, &response)
+	if hasStatus(err, http.StatusConflict) {
+		return players.Player{}, fmt.Errorf("%w: %s", players.ErrPlayerExists, name)
+	}
+	if err != nil {
+		return players.Player{}, fmt.Errorf("create player: %w", err)
+	}
+
+	return response.Player.player(), nil
+}
+
+func (manager PlayersManager) UpdateProfile(
+	ctx context.Context,
+	player players.Player,
+	profile players.Profile,
+) (players.Player, error) {
+	request := map[string]any{
+		"displayName":       profile.DisplayName,
+		"preferredPosition": profile.PreferredPosition,
+		"favoriteSide":      profile.FavoriteSide,
+		"joinedAt":          profile.JoinedAt,
+	}
+
+	var response struct {
+		Player playerResponse `json:"player"`
+	}
+	err := manager.client.send(
+		ctx,
+		http.MethodPut,
+		fmt.Sprintf("/api/players/%s/profile", url.PathEscape(player.UUID)),
+		request,
+		&response,
+	)
+	if err != nil {
+		return players.Player{}, fmt.Errorf("update profile: %w", err)
+	}
+
+	return response.Player.player(), nil
+}
+
+func (manager PlayersManager) GetPlayerByName(ctx context.Context, name string) (players.Player, error) {
+	var response struct {
+		Player playerResponse `json:"player"`
+	}
+	err := manager.client.get(ctx, "/api/players/lookup", url.Values{"name": {name}}, &response)
+	if err != nil {
+		return players.Player{}, fmt.Errorf("get player by name: %w", err)
+	}
+
+	return response.Player.player(), nil
+}
+
+// GetPlayers returns all players. The api does not return the ids of the players, they are
+// identified by their uuids.
+func (manager PlayersManager) GetPlayers(ctx context.Context) ([]players.Player, error) {
+	var response struct {
+		Players []playerResponse `json:"players"`
+	}
+	err := manager.client.get(ctx, "/api/players/profiles", nil, &response)
+	if err != nil {
+		return nil, fmt.Errorf("get players: %w", err)
+	}
+
+	allPlayers := make([]players.Player, len(response.Players))
+	for i, player := range response.Players {
+		allPlayers[i] = player.player()
+	}
+
+	return allPlayers, nil
+}
+
+// GetTeamsByNames looks up the players of both teams.
+func (manager PlayersManager) GetTeamsByNames(
+	ctx context.Context,
+	winnerNames []string,
+	loserNames []string,
+) (players.Team, players.Team, error) {
+	team := players.Team{}
+	notFound := []string{}
+	for _, name := range append(append([]string{}, winnerNames...), loserNames...) {
+		player, err := manager.GetPlayerByName(ctx, name)
+		if errors.Is(err, players.ErrPlayerNotFound) {
+			notFound = append(notFound, name)
+			continue
+		}
+		if err != nil {
+			return players.Team{}, players.Team{}, err
+		}
+
+		team = append(team, player)
+	}
+
+	if len(notFound) > 0 {
+		return players.Team{}, players.Team{}, fmt.Errorf("Players not found: %s", strings.Join(notFound, ","))
+	}
+
+	return team[:len(winnerNames)], team[len(winnerNames):], nil
+}
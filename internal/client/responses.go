@@ -0,0 +1,320 @@
+package client
+
+import (
+	"time"
+
+	"github.com/spie/fskick/internal/db"
+	"github.com/spie/fskick/internal/games"
+	"github.com/spie/fskick/internal/players"
+	"github.com/spie/fskick/internal/seasons"
+	"github.com/spie/fskick/internal/tournaments"
+)
+
+type seasonResponse struct {
+	UUID      string    `json:"uuid"`
+	Name      string    `json:"name"`
+	Active    bool      `json:"active"`
+	TeamSizes string    `json:"teamSizes"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (response seasonResponse) season() seasons.Season {
+	return seasons.Season{
+		Model: db.Model{
+			UUID:      response.UUID,
+			CreatedAt: response.CreatedAt,
+			UpdatedAt: response.UpdatedAt,
+		},
+		Name:      response.Name,
+		Active:    response.Active,
+		TeamSizes: seasons.TeamSizes(response.TeamSizes),
+	}
+}
+
+type playerResponse struct {
+	UUID              string    `json:"uuid"`
+	Name              string    `json:"name"`
+	DisplayName       string    `json:"displayName"`
+	PreferredPosition string    `json:"preferredPosition"`
+	FavoriteSide      string    `json:"favoriteSide"`
+	JoinedAt          time.Time `json:"joinedAt"`
+	CreatedAt         time.Time `json:"createdAt"`
+	UpdatedAt         time.Time `json:"updatedAt"`
+}
+
+func (response playerResponse) player() players.Player {
+	return players.Player{
+		Model: db.Model{
+			UUID:      response.UUID,
+			CreatedAt: response.CreatedAt,
+			UpdatedAt: response.UpdatedAt,
+		},
+		Name:              response.Name,
+		DisplayName:       response.DisplayName,
+		PreferredPosition: players.Position(response.PreferredPosition),
+		FavoriteSide:      players.Side(response.FavoriteSide),
+		JoinedAt:          response.JoinedAt,
+	}
+}
+
+type playerStatsResponse struct {
+	playerResponse
+	Wins            int     `json:"wins"`
+	Draws           int     `json:"draws"`
+	Games           int     `json:"games"`
+	GamesRatio      float64 `json:"gamesRatio"`
+	PointsRatio     float64 `json:"pointsRatio"`
+	Points          int     `json:"points"`
+	WinRatio        float64 `json:"winRatio"`
+	Position        int     `json:"position"`
+	DefenseWins     int     `json:"defenseWins"`
+	DefenseGames    int     `json:"defenseGames"`
+	DefenseWinRatio float64 `json:"defenseWinRatio"`
+	OffenseWins     int     `json:"offenseWins"`
+	OffenseGames    int     `json:"offenseGames"`
+	OffenseWinRatio float64 `json:"offenseWinRatio"`
+}
+
+func (response playerStatsResponse) playerStats() games.PlayerStats {
+	return games.PlayerStats{
+		PlayerAttendance: games.PlayerAttendance{
+			Player:       response.player(),
+			Wins:         response.Wins,
+			Draws:        response.Draws,
+			Games:        response.Games,
+			DefenseWins:  response.DefenseWins,
+			DefenseGames: response.DefenseGames,
+			OffenseWins:  response.OffenseWins,
+			OffenseGames: response.OffenseGames,
+		},
+		PointsRatio:     response.PointsRatio,
+		Points:          response.Points,
+		WinRatio:        response.WinRatio,
+		GamesRatio:      response.GamesRatio,
+		Position:        response.Position,
+		DefenseWinRatio: response.DefenseWinRatio,
+		OffenseWinRatio: response.OffenseWinRatio,
+	}
+}
+
+type playerStatsListResponse struct {
+	PlayerStats []playerStatsResponse `json:"playerStats"`
+}
+
+func (response playerStatsListResponse) playerStats() []games.PlayerStats {
+	playerStats := make([]games.PlayerStats, len(response.PlayerStats))
+	for i, stats := range response.PlayerStats {
+		playerStats[i] = stats.playerStats()
+	}
+
+	return playerStats
+}
+
+type tableResponse struct {
+	Season struct {
+		seasonResponse
+		GamesCount int `json:"gamesCount"`
+	} `json:"season"`
+	playerStatsListResponse
+}
+
+type teamMemberResponse struct {
+	playerResponse
+	Position string `json:"position"`
+}
+
+type matchupResponse struct {
+	Team1      []teamMemberResponse `json:"team1"`
+	Team2      []teamMemberResponse `json:"team2"`
+	Difference float64              `json:"difference"`
+}
+
+type scoreResponse struct {
+	Winners int `json:"winners"`
+	Losers  int `json:"losers"`
+}
+
+type gameResponse struct {
+	UUID     string               `json:"uuid"`
+	PlayedAt time.Time            `json:"playedAt"`
+	Season   seasonResponse       `json:"season"`
+	Winners  []teamMemberResponse `json:"winners"`
+	Losers   []teamMemberResponse `json:"losers"`
+	Draw     bool                 `json:"draw"`
+	Score    *scoreResponse       `json:"score"`
+}
+
+// game maps the response to a game with attendances. The api only tells winners and losers,
+// so the winners are team 1.
+func (response gameResponse) game() games.Game {
+	season := response.Season.season()
+	game := games.Game{
+		Model:    db.Model{UUID: response.UUID},
+		PlayedAt: response.PlayedAt,
+		Season:   &season,
+	}
+
+	winnersOutcome, losersOutcome := games.OutcomeWin, games.OutcomeLoss
+	if response.Draw {
+		winnersOutcome, losersOutcome = games.OutcomeDraw, games.OutcomeDraw
+	}
+	game.Attendances = append(
+		newAttendances(response.Winners, 1, winnersOutcome, response.UUID),
+		newAttendances(response.Losers, 2, losersOutcome, response.UUID)...,
+	)
+
+	if response.Score != nil {
+		game.Score = &games.Score{Team1: response.Score.Winners, Team2: response.Score.Losers}
+	}
+
+	return game
+}
+
+func newAttendances(members []teamMemberResponse, team int, outcome games.Outcome, gameUuid string) []games.Attendance {
+	attendances := make([]games.Attendance, len(members))
+	for i, member := range members {
+		attendances[i] = games.Attendance{
+			Team:     team,
+			Outcome:  outcome,
+			Position: players.Position(member.Position),
+			GameUUID: gameUuid,
+			Player:   member.player(),
+		}
+	}
+
+	return attendances
+}
+
+type gamesPageResponse struct {
+	Games      []gameResponse `json:"games"`
+	NextCursor string         `json:"nextCursor"`
+}
+
+type tournamentResponse struct {
+	UUID      string    `json:"uuid"`
+	Name      string    `json:"name"`
+	Format    string    `json:"format"`
+	TeamSize  int       `json:"teamSize"`
+	Groups    int       `json:"groups"`
+	Advancing int       `json:"advancing"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (response tournamentResponse) tournament() tournaments.Tournament {
+	return tournaments.Tournament{
+		Model: db.Model{
+			UUID:      response.UUID,
+			CreatedAt: response.CreatedAt,
+			UpdatedAt: response.UpdatedAt,
+		},
+		Name:      response.Name,
+		Format:    tournaments.Format(response.Format),
+		TeamSize:  response.TeamSize,
+		Groups:    response.Groups,
+		Advancing: response.Advancing,
+		Status:    tournaments.Status(response.Status),
+	}
+}
+
+type tournamentTeamResponse struct {
+	UUID    string           `json:"uuid"`
+	Name    string           `json:"name"`
+	Seed    int              `json:"seed"`
+	Players []playerResponse `json:"players"`
+}
+
+// team uses the seed as id, the api refers to the teams by uuid.
+func (response tournamentTeamResponse) team() tournaments.Team {
+	team := tournaments.Team{
+		Model: db.Model{ID: uint(response.Seed), UUID: response.UUID},
+		Name:  response.Name,
+		Seed:  response.Seed,
+	}
+	for _, player := range response.Players {
+		team.Players = append(team.Players, player.player())
+	}
+
+	return team
+}
+
+// matchScoreResponse is the score of a tournament match seen from team 1.
+type matchScoreResponse struct {
+	Team1 int `json:"team1"`
+	Team2 int `json:"team2"`
+}
+
+type tournamentMatchResponse struct {
+	UUID        string              `json:"uuid"`
+	Number      int                 `json:"number"`
+	Stage       string              `json:"stage"`
+	Round       int                 `json:"round"`
+	Group       int                 `json:"group"`
+	Team1Source string              `json:"team1Source"`
+	Team2Source string              `json:"team2Source"`
+	Team1       string              `json:"team1"`
+	Team2       string              `json:"team2"`
+	Winner      string              `json:"winner"`
+	Draw        bool                `json:"draw"`
+	Score       *matchScoreResponse `json:"score"`
+	Game        string              `json:"game"`
+	Done        bool                `json:"done"`
+}
+
+type tournamentDetailResponse struct {
+	tournamentResponse
+	Teams   []tournamentTeamResponse  `json:"teams"`
+	Matches []tournamentMatchResponse `json:"matches"`
+}
+
+// tournament maps the teams of the matches by uuid to the teams, the standings are computed
+// from the matches like on the server.
+func (response tournamentDetailResponse) tournament() (tournaments.Tournament, error) {
+	tournament := response.tournamentResponse.tournament()
+
+	teamIDs := map[string]uint{}
+	for _, teamResponse := range response.Teams {
+		team := teamResponse.team()
+		teamIDs[team.UUID] = team.ID
+		tournament.Teams = append(tournament.Teams, team)
+	}
+
+	for _, matchResponse := range response.Matches {
+		source1, err := tournaments.ParseSource(matchResponse.Team1Source)
+		if err != nil {
+			return tournaments.Tournament{}, err
+		}
+		source2, err := tournaments.ParseSource(matchResponse.Team2Source)
+		if err != nil {
+			return tournaments.Tournament{}, err
+		}
+
+		match := tournaments.Match{
+			Model:    db.Model{UUID: matchResponse.UUID},
+			Number:   matchResponse.Number,
+			Stage:    tournaments.Stage(matchResponse.Stage),
+			Round:    matchResponse.Round,
+			Group:    matchResponse.Group,
+			Source1:  source1,
+			Source2:  source2,
+			Team1ID:  teamIDs[matchResponse.Team1],
+			Team2ID:  teamIDs[matchResponse.Team2],
+			WinnerID: teamIDs[matchResponse.Winner],
+			Draw:     matchResponse.Draw,
+			GameUUID: matchResponse.Game,
+			Done:     matchResponse.Done,
+		}
+		if match.Done && !match.Draw && match.WinnerID != 0 {
+			match.LoserID = match.Team1ID + match.Team2ID - match.WinnerID
+		}
+		if matchResponse.Score != nil {
+			match.Score = &games.Score{Team1: matchResponse.Score.Team1, Team2: matchResponse.Score.Team2}
+		}
+
+		tournament.Matches = append(tournament.Matches, match)
+	}
+
+	return tournament, nil
+}
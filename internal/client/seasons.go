@@ -0,0 +1,134 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/spie/fskick/internal/seasons"
+)
+
+// SeasonsManager handles the seasons of a running server like seasons.Manager.
+type SeasonsManager struct {
+	client Client
+}
+
+func NewSeasonsManager(client Client) SeasonsManager {
+	return SeasonsManager{client: client}
+}
+
+func (manager SeasonsManager) CreateSeason(ctx context.Context, name string) (seasons.Season, error) {
+	var response struct {
+		Season seasonResponse `json:"season"`
+	}
+	err := manager.client.send(ctx, http.MethodPost, "/api/seasons", map[string]string{"name": name}, &response)
+	if hasStatus(err, http.StatusConflict) {
+		return seasons.Season{}, fmt.Errorf("%w: %s", seasons.ErrSeasonExists, name)
+	}
+	if err != nil {
+		return seasons.Season{}, fmt.Errorf("create season: %w", err)
+	}
+
+	return response.Season.season(), nil
+}
+
+func (manager SeasonsManager) GetSeasons(ctx context.Context) ([]seasons.Season, error) {
+	var response struct {
+		Seasons []seasonResponse `json:"seasons"`
+	}
+	err := manager.client.get(ctx, "/api/seasons", nil, &response)
+	if err != nil {
+		return nil, fmt.Errorf("get seasons: %w", err)
+	}
+
+	allSeasons := make([]seasons.Season, len(response.Seasons))
+	for i, season := range response.Seasons {
+		allSeasons[i] = season.season()
+	}
+
+	return allSeasons, nil
+}
+
+func (manager SeasonsManager) ActivateSeason(ctx context.Context, name string) (seasons.Season, error) {
+	season, err := manager.GetSeasonByName(ctx, name)
+	if err != nil {
+		return seasons.Season{}, err
+	}
+
+	var response struct {
+		Season seasonResponse `json:"season"`
+	}
+	err = manager.client.send(
+		ctx,
+		http.MethodPost,
+		fmt.Sprintf("/api/seasons/%s/activate", url.PathEscape(season.UUID)),
+		nil,
+		&response,
+	)
+	if hasStatus(err, http.StatusConflict) {
+		return seasons.Season{}, fmt.Errorf("activate season: %w", seasons.ErrActiveSeasonExists)
+	}
+	if err != nil {
+		return seasons.Season{}, fmt.Errorf("activate season: %w", err)
+	}
+
+	return response.Season.season(), nil
+}
+
+func (manager SeasonsManager) SetTeamSizes(
+	ctx context.Context,
+	name string,
+	teamSizes seasons.TeamSizes,
+) (seasons.Season, error) {
+	season, err := manager.GetSeasonByName(ctx, name)
+	if err != nil {
+		return seasons.Season{}, err
+	}
+
+	var response struct {
+		Season seasonResponse `json:"season"`
+	}
+	err = manager.client.send(
+		ctx,
+		http.MethodPut,
+		fmt.Sprintf("/api/seasons/%s/rules", url.PathEscape(season.UUID)),
+		map[string]string{"teamSizes": string(teamSizes)},
+		&response,
+	)
+	if hasStatus(err, http.StatusUnprocessableEntity) {
+		return seasons.Season{}, fmt.Errorf("%w: %s", seasons.ErrUnknownTeamSizes, teamSizes)
+	}
+	if err != nil {
+		return seasons.Season{}, fmt.Errorf("set team sizes of season: %w", err)
+	}
+
+	return response.Season.season(), nil
+}
+
+func (manager SeasonsManager) ActiveSeason(ctx context.Context) (seasons.Season, error) {
+	return manager.findSeason(ctx, func(season seasons.Season) bool { return season.Active })
+}
+
+func (manager SeasonsManager) GetSeasonByName(ctx context.Context, name string) (seasons.Season, error) {
+	return manager.findSeason(ctx, func(season seasons.Season) bool { return season.Name == name })
+}
+
+// findSeason returns the first season matching, the api has no endpoint for single seasons.
+func (manager SeasonsManager) findSeason(
+	ctx context.Context,
+	matches func(season seasons.Season) bool,
+) (seasons.Season, error) {
+	allSeasons, err := manager.GetSeasons(ctx)
+	if err != nil {
+		return seasons.Season{}, err
+	}
+
+	for _, season := range allSeasons {
+		if matches(season) {
+			return season, nil
+		}
+	}
+
+	return seasons.Season{}, seasons.ErrSeasonNotFound
+}
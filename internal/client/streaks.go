@@ -0,0 +1,39 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/spie/fskick/internal/games"
+	"github.com/spie/fskick/internal/streaks"
+)
+
+// StreaksManager reads the streaks of a running server like streaks.Manager.
+type StreaksManager struct {
+	client Client
+}
+
+func NewStreaksManager(client Client) StreaksManager {
+	return StreaksManager{client: client}
+}
+
+func (manager StreaksManager) GetCurrentStreaks(ctx context.Context, outcome games.Outcome) ([]streaks.Streak, error) {
+	var response struct {
+		Streaks []struct {
+			Number int            `json:"number"`
+			Player playerResponse `json:"player"`
+		} `json:"streaks"`
+	}
+	err := manager.client.get(ctx, "/api/streaks/current", url.Values{"outcome": {string(outcome)}}, &response)
+	if err != nil {
+		return nil, fmt.Errorf("get current streaks: %w", err)
+	}
+
+	currentStreaks := make([]streaks.Streak, len(response.Streaks))
+	for i, streak := range response.Streaks {
+		currentStreaks[i] = streaks.Streak{Number: streak.Number, Player: streak.Player.player()}
+	}
+
+	return currentStreaks, nil
+}
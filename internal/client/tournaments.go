@@ -0,0 +1,203 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/spie/fskick/internal/tournaments"
+)
+
+// TournamentsManager handles the tournaments of a running server like tournaments.Manager.
+type TournamentsManager struct {
+	client Client
+}
+
+func NewTournamentsManager(client Client) TournamentsManager {
+	return TournamentsManager{client: client}
+}
+
+func (manager TournamentsManager) CreateTournament(
+	ctx context.Context,
+	name string,
+	format tournaments.Format,
+	teamSize int,
+) (tournaments.Tournament, error) {
+	request := map[string]any{"name": name, "format": format, "teamSize": teamSize}
+
+	tournament, err := manager.sendTournament(ctx, "/api/tournaments", request)
+	if hasStatus(err, http.StatusConflict) {
+		return tournaments.Tournament{}, fmt.Errorf("%w: %s", tournaments.ErrTournamentExists, name)
+	}
+	if err != nil {
+		return tournaments.Tournament{}, fmt.Errorf("create tournament: %w", err)
+	}
+
+	return tournament, nil
+}
+
+func (manager TournamentsManager) GetTournaments(ctx context.Context) ([]tournaments.Tournament, error) {
+	var response struct {
+		Tournaments []tournamentResponse `json:"tournaments"`
+	}
+	err := manager.client.get(ctx, "/api/tournaments", nil, &response)
+	if err != nil {
+		return nil, fmt.Errorf("get tournaments: %w", err)
+	}
+
+	allTournaments := make([]tournaments.Tournament, len(response.Tournaments))
+	for i, tournament := range response.Tournaments {
+		allTournaments[i] = tournament.tournament()
+	}
+
+	return allTournaments, nil
+}
+
+// GetTournament returns the tournament with its teams and matches. The teams have their seeds
+// as ids.
+func (manager TournamentsManager) GetTournament(ctx context.Context, name string) (tournaments.Tournament, error) {
+	uuid, err := manager.getTournamentUuid(ctx, name)
+	if err != nil {
+		return tournaments.Tournament{}, err
+	}
+
+	var response struct {
+		Tournament tournamentDetailResponse `json:"tournament"`
+	}
+	err = manager.client.get(ctx, fmt.Sprintf("/api/tournaments/%s", url.PathEscape(uuid)), nil, &response)
+	if err != nil {
+		return tournaments.Tournament{}, fmt.Errorf("get tournament: %w", err)
+	}
+
+	return response.Tournament.tournament()
+}
+
+func (manager TournamentsManager) RegisterTeam(
+	ctx context.Context,
+	tournamentName string,
+	teamName string,
+	playerNames []string,
+) (tournaments.Team, error) {
+	uuid, err := manager.getTournamentUuid(ctx, tournamentName)
+	if err != nil {
+		return tournaments.Team{}, err
+	}
+
+	var response struct {
+		Team tournamentTeamResponse `json:"team"`
+	}
+	err = manager.client.send(
+		ctx,
+		http.MethodPost,
+		fmt.Sprintf("/api/tournaments/%s/teams", url.PathEscape(uuid)),
+		map[string]any{"name": teamName, "players": playerNames},
+		&response,
+	)
+	if err != nil {
+		return tournaments.Team{}, fmt.Errorf("register team: %w", err)
+	}
+
+	return response.Team.team(), nil
+}
+
+func (manager TournamentsManager) Schedule(
+	ctx context.Context,
+	name string,
+	options tournaments.ScheduleOptions,
+) (tournaments.Tournament, error) {
+	uuid, err := manager.getTournamentUuid(ctx, name)
+	if err != nil {
+		return tournaments.Tournament{}, err
+	}
+
+	tournament, err := manager.sendTournament(
+		ctx,
+		fmt.Sprintf("/api/tournaments/%s/schedule", url.PathEscape(uuid)),
+		map[string]int{"groups": options.Groups, "advancing": options.Advancing},
+	)
+	if err != nil {
+		return tournaments.Tournament{}, fmt.Errorf("schedule tournament: %w", err)
+	}
+
+	return tournament, nil
+}
+
+// RecordResult records the result of the match on the server. The server records the user of
+// the api token as recorder, so the recorder of the result is ignored.
+func (manager TournamentsManager) RecordResult(
+	ctx context.Context,
+	name string,
+	number int,
+	result tournaments.Result,
+) (tournaments.Tournament, error) {
+	uuid, err := manager.getTournamentUuid(ctx, name)
+	if err != nil {
+		return tournaments.Tournament{}, err
+	}
+
+	request := struct {
+		PlayedAt time.Time           `json:"playedAt"`
+		Winner   int                 `json:"winner"`
+		Draw     bool                `json:"draw"`
+		Score    *matchScoreResponse `json:"score,omitempty"`
+	}{
+		PlayedAt: result.PlayedAt,
+		Winner:   result.Winner,
+		Draw:     result.Draw,
+	}
+	if result.Score != nil {
+		request.Score = &matchScoreResponse{Team1: result.Score.Team1, Team2: result.Score.Team2}
+	}
+
+	tournament, err := manager.sendTournament(
+		ctx,
+		fmt.Sprintf("/api/tournaments/%s/matches/%d/result", url.PathEscape(uuid), number),
+		request,
+	)
+	var responseErr *ResponseError
+	if errors.As(err, &responseErr) && responseErr.Status == http.StatusUnprocessableEntity {
+		validationErr, ok := newValidationError(responseErr.Message)
+		if ok {
+			return tournaments.Tournament{}, validationErr
+		}
+	}
+	if hasStatus(err, http.StatusNotFound) {
+		return tournaments.Tournament{}, fmt.Errorf("%w: %d", tournaments.ErrMatchNotFound, number)
+	}
+	if err != nil {
+		return tournaments.Tournament{}, fmt.Errorf("record result: %w", err)
+	}
+
+	return tournament, nil
+}
+
+func (manager TournamentsManager) sendTournament(ctx context.Context, path string, request any) (tournaments.Tournament, error) {
+	var response struct {
+		Tournament tournamentDetailResponse `json:"tournament"`
+	}
+	err := manager.client.send(ctx, http.MethodPost, path, request, &response)
+	if err != nil {
+		return tournaments.Tournament{}, err
+	}
+
+	return response.Tournament.tournament()
+}
+
+// getTournamentUuid looks the tournament up in the list, the api addresses tournaments by uuid.
+func (manager TournamentsManager) getTournamentUuid(ctx context.Context, name string) (string, error) {
+	allTournaments, err := manager.GetTournaments(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	for _, tournament := range allTournaments {
+		if tournament.Name == name {
+			return tournament.UUID, nil
+		}
+	}
+
+	return "", tournaments.ErrTournamentNotFound
+}
@@ -1,66 +1,226 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"maps"
 	"os"
+	"slices"
+	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/spf13/pflag"
 	"github.com/spie/fskick/internal/db"
+	"github.com/spie/fskick/internal/games"
 )
 
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+)
+
+// DefaultFile is read if it exists and no other config file is given with --config or
+// FSKICK_CONFIG.
+const DefaultFile = "fskick.yaml"
+
+var (
+	ErrInvalidValue   = errors.New("invalid value")
+	ErrMissingValue   = errors.New("missing value")
+	ErrUnknownSetting = errors.New("unknown setting")
+)
+
+// ValidationError is returned for every setting with an invalid value, Source tells where
+// the value came from.
+type ValidationError struct {
+	Key    string
+	Source string
+	Err    error
+}
+
+func (err *ValidationError) Error() string {
+	return fmt.Sprintf("config %s (%s): %v", err.Key, err.Source, err.Err)
+}
+
+func (err *ValidationError) Unwrap() error {
+	return err.Err
+}
+
 type AppConfig struct {
-	ApiHost     string
-	DbConfig    db.DbConfig
-	ServerHost  string
-	ImprintText string
+	ApiHost         string
+	Admins          []string
+	DbConfig        db.DbConfig
+	AutoMigrate     bool
+	ImprintText     string
+	AvatarStorage   string
+	AvatarDirectory string
+	Points          games.Points
+	DuplicateWindow time.Duration
+	StatsCacheTTL   time.Duration
+	QueryTimeout    time.Duration
+	LogLevel        slog.Level
+	LogFormat       string
+	HttpTimeouts    HttpTimeouts
+	Remote          Remote
+	// File is the config file read, empty if there is none.
+	File string
+	// Settings are the effective values of all settings, for showing the config.
+	Settings []Setting
 }
 
-func LoadCliConfig() (AppConfig, error) {
-	cfg, err := loadEnvConfig()
-	if err != nil {
-		return AppConfig{}, err
-	}
+// HttpTimeouts limit the reading and writing of requests, keeping idle connections and
+// waiting for running requests on shutdown.
+type HttpTimeouts struct {
+	Read     time.Duration
+	Write    time.Duration
+	Idle     time.Duration
+	Shutdown time.Duration
+}
 
-	setDbConfig(&cfg)
+// Remote is the server the cli works with instead of the database, if URL is set.
+type Remote struct {
+	URL   string
+	Token string
+}
 
-	return cfg, nil
+// Setting is the effective value of a setting and where it came from.
+type Setting struct {
+	Key    string
+	Env    string
+	Flag   string
+	Value  string
+	Source string
+	redact func(value string) string
 }
 
-func LoadServerConfig() (AppConfig, error) {
-	cfg, err := loadEnvConfig()
-	if err != nil {
-		return AppConfig{}, err
+// Redacted returns the value with secrets, like the password of a database, replaced.
+func (setting Setting) Redacted() string {
+	if setting.redact == nil || setting.Value == "" {
+		return setting.Value
 	}
 
-	setDbConfig(&cfg)
-	setApiConfig(&cfg)
+	return setting.redact(setting.Value)
+}
 
-	cfg.ImprintText = os.Getenv("IMPRINT_TEXT")
+// NewFlagSet creates the flags of all settings and --config for the config file.
+func NewFlagSet(name string) *pflag.FlagSet {
+	flags := pflag.NewFlagSet(name, pflag.ContinueOnError)
+	flags.String("config", "", fmt.Sprintf("Config file, yaml (default %s if it exists)", DefaultFile))
 
-	return cfg, nil
+	for _, setting := range settings {
+		if setting.kind == kindBool {
+			flags.Bool(setting.flag(), setting.fallback == "true", setting.usage)
+			continue
+		}
+
+		flags.String(setting.flag(), setting.fallback, setting.usage)
+	}
+
+	return flags
 }
 
-func loadEnvConfig() (AppConfig, error) {
-	var cfg AppConfig
+// Load reads the config in layers: the defaults, then the config file, then the environment,
+// including a .env file, then the flags set in flags. Empty environment variables are ignored,
+// flags can be nil. All invalid settings are returned as joined ValidationErrors.
+func Load(flags *pflag.FlagSet) (AppConfig, error) {
 	err := godotenv.Load()
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return AppConfig{}, fmt.Errorf("load .env: %w", err)
+	}
+
+	values := map[string]Setting{}
+	for _, setting := range settings {
+		values[setting.key] = Setting{
+			Key:    setting.key,
+			Env:    setting.env,
+			Flag:   setting.flag(),
+			Value:  setting.fallback,
+			Source: SourceDefault,
+			redact: setting.redact,
+		}
+	}
+
+	file, err := configFile(flags)
 	if err != nil {
 		return AppConfig{}, err
 	}
 
+	errs := []error{}
+	if file != "" {
+		fileValues, err := readFile(file)
+		if err != nil {
+			return AppConfig{}, err
+		}
+
+		errs = append(errs, setValues(values, fileValues, SourceFile)...)
+	}
+
+	envValues := map[string]string{}
+	flagValues := map[string]string{}
+	for _, setting := range settings {
+		if value := os.Getenv(setting.env); value != "" {
+			envValues[setting.key] = value
+		}
+
+		if flags != nil && flags.Changed(setting.flag()) {
+			flagValues[setting.key] = flags.Lookup(setting.flag()).Value.String()
+		}
+	}
+	errs = append(errs, setValues(values, envValues, SourceEnv)...)
+	errs = append(errs, setValues(values, flagValues, SourceFlag)...)
+
+	cfg, parseErrs := parse(values)
+	errs = append(errs, parseErrs...)
+	if len(errs) > 0 {
+		return AppConfig{}, errors.Join(errs...)
+	}
+
+	cfg.File = file
+	for _, setting := range settings {
+		cfg.Settings = append(cfg.Settings, values[setting.key])
+	}
+
 	return cfg, nil
 }
 
-func setDbConfig(cfg *AppConfig) {
-	cfg.DbConfig = db.CreateDbConfig(
-		os.Getenv("DB_DATABASE"),
-		os.Getenv("DB_LOG") == "true",
-		os.Getenv("DB_DEBUG") == "true",
-	)
-}
+// configFile returns the file of --config or FSKICK_CONFIG, or DefaultFile if it exists.
+func configFile(flags *pflag.FlagSet) (string, error) {
+	if flags != nil && flags.Changed("config") {
+		return flags.Lookup("config").Value.String(), nil
+	}
+
+	if file, ok := os.LookupEnv("FSKICK_CONFIG"); ok {
+		return file, nil
+	}
+
+	_, err := os.Stat(DefaultFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("check config file: %w", err)
+	}
 
-func setApiConfig(cfg *AppConfig) {
-	cfg.ApiHost = os.Getenv("API_HOST")
+	return DefaultFile, nil
 }
 
-func setServerConfig(cfg *AppConfig) {
-	cfg.ServerHost = os.Getenv("HTTP_HOST")
+func setValues(values map[string]Setting, newValues map[string]string, source string) []error {
+	errs := []error{}
+	for _, key := range slices.Sorted(maps.Keys(newValues)) {
+		value := newValues[key]
+		setting, ok := values[key]
+		if !ok {
+			errs = append(errs, &ValidationError{Key: key, Source: source, Err: ErrUnknownSetting})
+			continue
+		}
+
+		setting.Value = value
+		setting.Source = source
+		values[key] = setting
+	}
+
+	return errs
 }
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// readFile reads the yaml config file. Nested keys are joined with dots, so
+//
+//	db:
+//	  driver: postgres
+//
+// sets db.driver.
+func readFile(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config file: %w", err)
+	}
+
+	var file map[string]any
+	err = yaml.Unmarshal(content, &file)
+	if err != nil {
+		return nil, fmt.Errorf("parse config file %s: %w", path, err)
+	}
+
+	values := map[string]string{}
+	flatten(values, "", file)
+
+	return values, nil
+}
+
+func flatten(values map[string]string, prefix string, file map[string]any) {
+	for key, value := range file {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		switch value := value.(type) {
+		case map[string]any:
+			flatten(values, key, value)
+		case nil:
+			values[key] = ""
+		default:
+			values[key] = fmt.Sprint(value)
+		}
+	}
+}
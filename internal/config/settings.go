@@ -0,0 +1,469 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spie/fskick/internal/db"
+	"github.com/spie/fskick/internal/logging"
+)
+
+type kind int
+
+const (
+	kindString kind = iota
+	kindBool
+	kindInt
+	kindDuration
+)
+
+// setting is configured with key in the config file, with env in the environment and with
+// --key, dots and underscores replaced by dashes, as flag, unless flagName is set.
+type setting struct {
+	key      string
+	env      string
+	flagName string
+	fallback string
+	usage    string
+	kind     kind
+	apply    func(builder *builder, value string) error
+	redact   func(value string) string
+}
+
+func (setting setting) flag() string {
+	if setting.flagName != "" {
+		return setting.flagName
+	}
+
+	return strings.NewReplacer(".", "-", "_", "-").Replace(setting.key)
+}
+
+// builder collects the parsed values, the db config and the log level depend on several
+// settings and are created in build.
+type builder struct {
+	cfg         AppConfig
+	dbDriver    string
+	dbDatabase  string
+	dbLog       bool
+	dbDebug     bool
+	dbSlowQuery time.Duration
+	logLevel    string
+}
+
+var settings = []setting{
+	{
+		key:      "api.host",
+		env:      "API_HOST",
+		fallback: ":8000",
+		usage:    "Address the server listens on, host:port",
+		apply: func(builder *builder, value string) error {
+			_, _, err := net.SplitHostPort(value)
+			if err != nil {
+				return invalidValue(value, err)
+			}
+
+			builder.cfg.ApiHost = value
+
+			return nil
+		},
+	},
+	{
+		key:      "api.admins",
+		env:      "API_ADMINS",
+		fallback: "",
+		usage:    "Comma separated emails of the users managing seasons and all player profiles",
+		apply: func(builder *builder, value string) error {
+			builder.cfg.Admins = nil
+			for _, email := range strings.Split(value, ",") {
+				email = strings.TrimSpace(email)
+				if email != "" {
+					builder.cfg.Admins = append(builder.cfg.Admins, email)
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		key:      "db.driver",
+		env:      "DB_DRIVER",
+		fallback: db.DriverSQLite,
+		usage:    "Database driver, sqlite3 or postgres",
+		apply: func(builder *builder, value string) error {
+			if value != db.DriverSQLite && value != db.DriverPostgres {
+				return invalidValue(value, db.ErrUnknownDriver)
+			}
+
+			builder.dbDriver = value
+
+			return nil
+		},
+	},
+	{
+		key:      "db.database",
+		env:      "DB_DATABASE",
+		fallback: "fskick.db",
+		usage:    "File of the sqlite3 database or connection string of the postgres database",
+		apply: func(builder *builder, value string) error {
+			if value == "" {
+				return ErrMissingValue
+			}
+
+			builder.dbDatabase = value
+
+			return nil
+		},
+		redact: redactDatabase,
+	},
+	{
+		key:      "db.log",
+		env:      "DB_LOG",
+		fallback: "false",
+		usage:    "Log slow queries",
+		kind:     kindBool,
+		apply: func(builder *builder, value string) error {
+			return parseBool(value, &builder.dbLog)
+		},
+	},
+	{
+		key:      "db.debug",
+		env:      "DB_DEBUG",
+		fallback: "false",
+		usage:    "Log all queries",
+		kind:     kindBool,
+		apply: func(builder *builder, value string) error {
+			return parseBool(value, &builder.dbDebug)
+		},
+	},
+	{
+		key:      "db.auto_migrate",
+		env:      "DB_AUTO_MIGRATE",
+		fallback: "true",
+		usage:    "Apply pending migrations when the server or a command starts, disable it to keep migrations rolled back",
+		kind:     kindBool,
+		apply: func(builder *builder, value string) error {
+			return parseBool(value, &builder.cfg.AutoMigrate)
+		},
+	},
+	{
+		key:      "db.slow_query",
+		env:      "DB_SLOW_QUERY",
+		fallback: db.DefaultSlowQuery.String(),
+		usage:    "Duration after which a query is logged as slow",
+		kind:     kindDuration,
+		apply: func(builder *builder, value string) error {
+			return parseDuration(value, &builder.dbSlowQuery)
+		},
+	},
+	{
+		key:      "imprint.text",
+		env:      "IMPRINT_TEXT",
+		fallback: "",
+		usage:    "Text of the imprint page",
+		apply: func(builder *builder, value string) error {
+			builder.cfg.ImprintText = value
+
+			return nil
+		},
+	},
+	{
+		key:      "avatars.storage",
+		env:      "AVATAR_STORAGE",
+		fallback: "database",
+		usage:    "Storage of the avatars, database or directory",
+		apply: func(builder *builder, value string) error {
+			if value != "database" && value != "directory" {
+				return invalidValue(value, fmt.Errorf("has to be database or directory"))
+			}
+
+			builder.cfg.AvatarStorage = value
+
+			return nil
+		},
+	},
+	{
+		key:      "avatars.directory",
+		env:      "AVATAR_DIRECTORY",
+		fallback: "",
+		usage:    "Directory of the avatars with the directory storage",
+		apply: func(builder *builder, value string) error {
+			if value == "" && builder.cfg.AvatarStorage == "directory" {
+				return ErrMissingValue
+			}
+
+			builder.cfg.AvatarDirectory = value
+
+			return nil
+		},
+	},
+	{
+		key:      "points.win",
+		env:      "WIN_POINTS",
+		fallback: "3",
+		usage:    "Points for a won game",
+		kind:     kindInt,
+		apply: func(builder *builder, value string) error {
+			return parsePoints(value, &builder.cfg.Points.Win)
+		},
+	},
+	{
+		key:      "points.draw",
+		env:      "DRAW_POINTS",
+		fallback: "1",
+		usage:    "Points for a drawn game",
+		kind:     kindInt,
+		apply: func(builder *builder, value string) error {
+			return parsePoints(value, &builder.cfg.Points.Draw)
+		},
+	},
+	{
+		key:      "games.duplicate_window",
+		env:      "DUPLICATE_WINDOW",
+		fallback: "15m",
+		usage:    "Games of the same teams with the same outcome within this time are duplicates, 0 disables the check",
+		kind:     kindDuration,
+		apply: func(builder *builder, value string) error {
+			return parseDuration(value, &builder.cfg.DuplicateWindow)
+		},
+	},
+	{
+		key:      "stats.cache_ttl",
+		env:      "STATS_CACHE_TTL",
+		fallback: "1m",
+		usage:    "How long the server caches stats, 0 disables the cache",
+		kind:     kindDuration,
+		apply: func(builder *builder, value string) error {
+			return parseDuration(value, &builder.cfg.StatsCacheTTL)
+		},
+	},
+	{
+		key:      "http.query_timeout",
+		env:      "QUERY_TIMEOUT",
+		fallback: "5s",
+		usage:    "How long the queries of a request may run, 0 disables the timeout",
+		kind:     kindDuration,
+		apply: func(builder *builder, value string) error {
+			return parseDuration(value, &builder.cfg.QueryTimeout)
+		},
+	},
+	{
+		key:      "http.read_timeout",
+		env:      "HTTP_READ_TIMEOUT",
+		fallback: "10s",
+		usage:    "How long reading a request may take",
+		kind:     kindDuration,
+		apply: func(builder *builder, value string) error {
+			return parseDuration(value, &builder.cfg.HttpTimeouts.Read)
+		},
+	},
+	{
+		key:      "http.write_timeout",
+		env:      "HTTP_WRITE_TIMEOUT",
+		fallback: "30s",
+		usage:    "How long writing a response may take",
+		kind:     kindDuration,
+		apply: func(builder *builder, value string) error {
+			return parseDuration(value, &builder.cfg.HttpTimeouts.Write)
+		},
+	},
+	{
+		key:      "http.idle_timeout",
+		env:      "HTTP_IDLE_TIMEOUT",
+		fallback: "2m",
+		usage:    "How long idle connections are kept",
+		kind:     kindDuration,
+		apply: func(builder *builder, value string) error {
+			return parseDuration(value, &builder.cfg.HttpTimeouts.Idle)
+		},
+	},
+	{
+		key:      "http.shutdown_timeout",
+		env:      "HTTP_SHUTDOWN_TIMEOUT",
+		fallback: "15s",
+		usage:    "How long the server waits for running requests on shutdown",
+		kind:     kindDuration,
+		apply: func(builder *builder, value string) error {
+			err := parseDuration(value, &builder.cfg.HttpTimeouts.Shutdown)
+			if err == nil && builder.cfg.HttpTimeouts.Shutdown == 0 {
+				return invalidValue(value, fmt.Errorf("has to be greater than 0"))
+			}
+
+			return err
+		},
+	},
+	{
+		key:      "remote.url",
+		env:      "REMOTE_URL",
+		flagName: "remote",
+		fallback: "",
+		usage:    "Url of a running server, the cli then works with its api instead of the database",
+		apply: func(builder *builder, value string) error {
+			if value == "" {
+				return nil
+			}
+
+			u, err := url.Parse(value)
+			if err != nil {
+				return invalidValue(value, err)
+			}
+			if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return invalidValue(value, fmt.Errorf("has to be an http or https url"))
+			}
+
+			builder.cfg.Remote.URL = value
+
+			return nil
+		},
+	},
+	{
+		key:      "remote.token",
+		env:      "REMOTE_TOKEN",
+		flagName: "token",
+		fallback: "",
+		usage:    "Api token of a user for the remote server, created with fskick users token",
+		apply: func(builder *builder, value string) error {
+			builder.cfg.Remote.Token = value
+
+			return nil
+		},
+		redact: func(value string) string {
+			return redacted
+		},
+	},
+	{
+		key:      "log.level",
+		env:      "LOG_LEVEL",
+		fallback: "",
+		usage:    "Log level, debug, info, warn or error (default info, debug with db.debug)",
+		apply: func(builder *builder, value string) error {
+			_, err := logging.ParseLevel(value)
+			if err != nil {
+				return invalidValue(value, err)
+			}
+
+			builder.logLevel = value
+
+			return nil
+		},
+	},
+	{
+		key:      "log.format",
+		env:      "LOG_FORMAT",
+		fallback: logging.FormatText,
+		usage:    "Log format, text or json",
+		apply: func(builder *builder, value string) error {
+			if value != logging.FormatText && value != logging.FormatJSON {
+				return invalidValue(value, logging.ErrUnknownFormat)
+			}
+
+			builder.cfg.LogFormat = value
+
+			return nil
+		},
+	},
+}
+
+// parse applies the values in the order of the settings, so a setting can depend on one
+// before it.
+func parse(values map[string]Setting) (AppConfig, []error) {
+	builder := &builder{}
+	errs := []error{}
+
+	for _, setting := range settings {
+		value := values[setting.key]
+		err := setting.apply(builder, strings.TrimSpace(value.Value))
+		if err != nil {
+			errs = append(errs, &ValidationError{Key: setting.key, Source: value.Source, Err: err})
+		}
+	}
+
+	return builder.build(), errs
+}
+
+func (builder *builder) build() AppConfig {
+	cfg := builder.cfg
+	cfg.DbConfig = db.CreateDbConfig(builder.dbDriver, builder.dbDatabase, builder.dbDebug, builder.dbLog).
+		WithSlowQuery(builder.dbSlowQuery)
+
+	level := builder.logLevel
+	if level == "" && builder.dbDebug {
+		level = "debug"
+	}
+	cfg.LogLevel, _ = logging.ParseLevel(level)
+
+	return cfg
+}
+
+func invalidValue(value string, err error) error {
+	return fmt.Errorf("%w %q: %w", ErrInvalidValue, value, err)
+}
+
+func parseBool(value string, target *bool) error {
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return invalidValue(value, err)
+	}
+
+	*target = parsed
+
+	return nil
+}
+
+func parseDuration(value string, target *time.Duration) error {
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return invalidValue(value, err)
+	}
+	if parsed < 0 {
+		return invalidValue(value, fmt.Errorf("has to be positive"))
+	}
+
+	*target = parsed
+
+	return nil
+}
+
+func parsePoints(value string, target *int) error {
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return invalidValue(value, err)
+	}
+	if parsed < 0 {
+		return invalidValue(value, fmt.Errorf("has to be positive"))
+	}
+
+	*target = parsed
+
+	return nil
+}
+
+// redacted replaces secret values, like url.URL.Redacted does for the password of a url.
+const redacted = "xxxxx"
+
+var passwordPattern = regexp.MustCompile(`(?i)(password\s*=\s*)('[^']*'|\S+)`)
+
+// redactDatabase replaces the password in the connection string of a postgres database, as url
+// or as key=value pairs.
+func redactDatabase(value string) string {
+	if strings.Contains(value, "://") {
+		u, err := url.Parse(value)
+		if err != nil {
+			return redacted
+		}
+
+		query := u.Query()
+		if query.Has("password") {
+			query.Set("password", redacted)
+			u.RawQuery = query.Encode()
+		}
+
+		return u.Redacted()
+	}
+
+	return passwordPattern.ReplaceAllString(value, "${1}"+redacted)
+}
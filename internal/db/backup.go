@@ -0,0 +1,92 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+
+	sqlite3 "github.com/mattn/go-sqlite3"
+)
+
+var (
+	ErrBackupNotSupported = errors.New("backup is only supported for sqlite3, use pg_dump for postgres")
+	ErrBackupExists       = errors.New("backup file already exists")
+)
+
+// Backup copies the sqlite3 database to the file at path with the online backup API of
+// sqlite, so the database can be used while it is copied.
+func Backup(ctx context.Context, conn *sql.DB, driver string, path string) error {
+	if driver != DriverSQLite {
+		return ErrBackupNotSupported
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%w: %s", ErrBackupExists, path)
+	}
+
+	backupConn, err := sql.Open(sqlDrivers[DriverSQLite], path)
+	if err != nil {
+		return fmt.Errorf("open backup database: %w", err)
+	}
+	defer backupConn.Close()
+
+	destination, err := backupConn.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("connect backup database: %w", err)
+	}
+	defer destination.Close()
+
+	source, err := conn.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("connect database for backup: %w", err)
+	}
+	defer source.Close()
+
+	return destination.Raw(func(destinationConn any) error {
+		return source.Raw(func(sourceConn any) error {
+			return backupSQLite(destinationConn, sourceConn)
+		})
+	})
+}
+
+func backupSQLite(destinationConn any, sourceConn any) error {
+	destination, ok := destinationConn.(*sqlite3.SQLiteConn)
+	if !ok {
+		return ErrBackupNotSupported
+	}
+
+	source, ok := sourceConn.(*sqlite3.SQLiteConn)
+	if !ok {
+		return ErrBackupNotSupported
+	}
+
+	backup, err := destination.Backup("main", source, "main")
+	if err != nil {
+		return fmt.Errorf("start backup: %w", err)
+	}
+
+	_, err = backup.Step(-1)
+	if err != nil {
+		backup.Finish()
+		return fmt.Errorf("backup: %w", err)
+	}
+
+	err = backup.Finish()
+	if err != nil {
+		return fmt.Errorf("finish backup: %w", err)
+	}
+
+	return nil
+}
+
+// Vacuum rebuilds the database to reclaim unused space.
+func Vacuum(ctx context.Context, conn *sql.DB) error {
+	_, err := conn.ExecContext(ctx, "VACUUM")
+	if err != nil {
+		return fmt.Errorf("vacuum: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package db
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	sqlite3 "github.com/mattn/go-sqlite3"
+)
+
+// Codes of postgres constraint violations, see https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	postgresForeignKeyViolation = "23503"
+	postgresUniqueViolation     = "23505"
+)
+
+// IsUniqueViolation reports if err is caused by a duplicate value of a unique column or index.
+func IsUniqueViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique ||
+			sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey
+	}
+
+	var postgresErr *pgconn.PgError
+	if errors.As(err, &postgresErr) {
+		return postgresErr.Code == postgresUniqueViolation
+	}
+
+	return false
+}
+
+// IsForeignKeyViolation reports if err is caused by a reference to a row that does not exist.
+func IsForeignKeyViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.ExtendedCode == sqlite3.ErrConstraintForeignKey
+	}
+
+	var postgresErr *pgconn.PgError
+	if errors.As(err, &postgresErr) {
+		return postgresErr.Code == postgresForeignKeyViolation
+	}
+
+	return false
+}
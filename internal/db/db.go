@@ -1,42 +1,101 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"io/fs"
+	"strings"
+	"time"
 
+	_ "github.com/jackc/pgx/v5/stdlib"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/pressly/goose/v3"
 )
 
+const (
+	DriverSQLite   = "sqlite3"
+	DriverPostgres = "postgres"
+)
+
 var (
-	ErrNotFound = sql.ErrNoRows
+	ErrNotFound      = sql.ErrNoRows
+	ErrUnknownDriver = errors.New("db driver has to be sqlite3 or postgres")
 )
 
+// sqlDrivers maps the drivers to the names of their database/sql drivers.
+var sqlDrivers = map[string]string{
+	DriverSQLite:   "sqlite3",
+	DriverPostgres: "pgx",
+}
+
+// gooseDialects maps the drivers to the dialects of their migrations.
+var gooseDialects = map[string]goose.Dialect{
+	DriverSQLite:   goose.DialectSQLite3,
+	DriverPostgres: goose.DialectPostgres,
+}
+
+// DefaultSlowQuery is the duration after which a query is logged as slow.
+const DefaultSlowQuery = 200 * time.Millisecond
+
 type DbConfig struct {
+	driver    string
 	database  string
 	withDebug bool
 	withLog   bool
+	slowQuery time.Duration
 }
 
-func CreateDbConfig(database string, withDebug bool, withLog bool) DbConfig {
+// CreateDbConfig creates the config for the driver, sqlite3 or postgres. The database is the
+// file of a sqlite3 database or the connection string of a postgres database.
+func CreateDbConfig(driver string, database string, withDebug bool, withLog bool) DbConfig {
+	if driver == "" {
+		driver = DriverSQLite
+	}
+
 	return DbConfig{
+		driver:    driver,
 		database:  database,
 		withDebug: withDebug,
 		withLog:   withLog,
+		slowQuery: DefaultSlowQuery,
 	}
 }
 
+func (cfg DbConfig) Driver() string {
+	return cfg.driver
+}
+
+// WithSlowQuery returns the config logging queries running longer than threshold as slow.
+func (cfg DbConfig) WithSlowQuery(threshold time.Duration) DbConfig {
+	cfg.slowQuery = threshold
+
+	return cfg
+}
+
+// Connection runs the queries of the repositories, it is implemented by *sql.DB and Tx. The
+// queries are cancelled with their context.
 type Connection interface {
-	Query(query string, args ...any) (*sql.Rows, error)
-	QueryRow(query string, args ...any) *sql.Row
-	Exec(query string, args ...any) (sql.Result, error)
-	Begin() (*sql.Tx, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
 	Close() error
 }
 
 func OpenDbConnection(cfg DbConfig) (*sql.DB, error) {
-	conn, err := sql.Open("sqlite3", cfg.database)
+	sqlDriver, ok := sqlDrivers[cfg.driver]
+	if !ok {
+		return nil, fmt.Errorf("open db connection: %w", ErrUnknownDriver)
+	}
+
+	database := cfg.database
+	if cfg.driver == DriverSQLite {
+		database = withForeignKeys(database)
+	}
+
+	conn, err := sql.Open(sqlDriver, database)
 	if err != nil {
 		return nil, fmt.Errorf("open db connection: %w", err)
 	}
@@ -44,15 +103,32 @@ func OpenDbConnection(cfg DbConfig) (*sql.DB, error) {
 	return conn, nil
 }
 
-func MigrateFS(conn *sql.DB, migrationsFS fs.FS, dir string) error {
+// withForeignKeys adds the option to the sqlite3 connection string to run PRAGMA foreign_keys = ON
+// for every connection, sqlite3 ignores foreign keys otherwise.
+func withForeignKeys(database string) string {
+	separator := "?"
+	if strings.Contains(database, "?") {
+		separator = "&"
+	}
+
+	return database + separator + "_foreign_keys=1"
+}
+
+// MigrateFS runs the migrations in dir with the dialect of the driver.
+func MigrateFS(conn *sql.DB, driver string, migrationsFS fs.FS, dir string) error {
+	dialect, ok := gooseDialects[driver]
+	if !ok {
+		return fmt.Errorf("migrate: %w", ErrUnknownDriver)
+	}
+
 	goose.SetBaseFS(migrationsFS)
 	defer func() {
 		goose.SetBaseFS(nil)
 	}()
 
-	err := goose.SetDialect("sqlite")
+	err := goose.SetDialect(string(dialect))
 	if err != nil {
-		return fmt.Errorf("migrate, set dialect = sqlite: %w", err)
+		return fmt.Errorf("migrate, set dialect = %s: %w", dialect, err)
 	}
 
 	err = goose.Up(conn, dir)
@@ -0,0 +1,84 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+)
+
+// queryLogger logs the queries run on a connection. With DB_LOG slow queries are logged as
+// warnings, with DB_DEBUG every query is logged with the number of its arguments. The
+// arguments themselves are never logged, they contain password and token hashes.
+type queryLogger struct {
+	Connection
+	logger    *slog.Logger
+	debug     bool
+	slowQuery time.Duration
+}
+
+// WithQueryLogger returns conn logging its queries to logger if DB_LOG or DB_DEBUG is set
+// in cfg, and conn itself otherwise. Transactions started with Transaction are logged too.
+func WithQueryLogger(conn Connection, cfg DbConfig, logger *slog.Logger) Connection {
+	if !cfg.withLog && !cfg.withDebug {
+		return conn
+	}
+
+	return queryLogger{
+		Connection: conn,
+		logger:     logger,
+		debug:      cfg.withDebug,
+		slowQuery:  cfg.slowQuery,
+	}
+}
+
+// QueryContext logs the time until the first rows are ready, reading the rows is not included.
+func (conn queryLogger) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := conn.Connection.QueryContext(ctx, query, args...)
+	conn.log(ctx, query, args, time.Since(start), err)
+
+	return rows, err
+}
+
+func (conn queryLogger) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	start := time.Now()
+	row := conn.Connection.QueryRowContext(ctx, query, args...)
+	conn.log(ctx, query, args, time.Since(start), row.Err())
+
+	return row
+}
+
+func (conn queryLogger) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	start := time.Now()
+	result, err := conn.Connection.ExecContext(ctx, query, args...)
+	conn.log(ctx, query, args, time.Since(start), err)
+
+	return result, err
+}
+
+// wrap returns tx logging its queries like conn.
+func (conn queryLogger) wrap(tx Connection) Connection {
+	conn.Connection = wrapTx(conn.Connection, tx)
+
+	return conn
+}
+
+func (conn queryLogger) log(ctx context.Context, query string, args []any, duration time.Duration, err error) {
+	attrs := []any{slog.String("query", query), slog.Duration("duration", duration)}
+	if conn.debug {
+		attrs = append(attrs, slog.Int("args", len(args)))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+
+	if conn.slowQuery > 0 && duration >= conn.slowQuery {
+		conn.logger.WarnContext(ctx, "slow query", attrs...)
+		return
+	}
+
+	if conn.debug {
+		conn.logger.DebugContext(ctx, "query", attrs...)
+	}
+}
@@ -0,0 +1,128 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"time"
+
+	"github.com/pressly/goose/v3"
+)
+
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+type MigrationResult struct {
+	Version   int64
+	Name      string
+	Direction string
+	Duration  time.Duration
+}
+
+// Migrator runs single migration steps on demand, in contrast to MigrateFS which applies all
+// pending migrations.
+type Migrator struct {
+	provider *goose.Provider
+}
+
+func NewMigrator(conn *sql.DB, driver string, migrationsFS fs.FS, dir string) (Migrator, error) {
+	dialect, ok := gooseDialects[driver]
+	if !ok {
+		return Migrator{}, fmt.Errorf("create migrator: %w", ErrUnknownDriver)
+	}
+
+	dirFS, err := fs.Sub(migrationsFS, dir)
+	if err != nil {
+		return Migrator{}, fmt.Errorf("create migrator: %w", err)
+	}
+
+	provider, err := goose.NewProvider(dialect, conn, dirFS)
+	if err != nil {
+		return Migrator{}, fmt.Errorf("create migrator: %w", err)
+	}
+
+	return Migrator{provider: provider}, nil
+}
+
+func (migrator Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	statuses, err := migrator.provider.Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("migration status: %w", err)
+	}
+
+	migrationStatuses := make([]MigrationStatus, len(statuses))
+	for i, status := range statuses {
+		migrationStatuses[i] = MigrationStatus{
+			Version:   status.Source.Version,
+			Name:      status.Source.Path,
+			Applied:   status.State == goose.StateApplied,
+			AppliedAt: status.AppliedAt,
+		}
+	}
+
+	return migrationStatuses, nil
+}
+
+// HasPending reports whether migrations are not applied yet.
+func (migrator Migrator) HasPending(ctx context.Context) (bool, error) {
+	pending, err := migrator.provider.HasPending(ctx)
+	if err != nil {
+		return false, fmt.Errorf("check pending migrations: %w", err)
+	}
+
+	return pending, nil
+}
+
+// Up applies all pending migrations.
+func (migrator Migrator) Up(ctx context.Context) ([]MigrationResult, error) {
+	results, err := migrator.provider.Up(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("migrate up: %w", err)
+	}
+
+	return newMigrationResults(results...), nil
+}
+
+// Down rolls back the latest applied migration.
+func (migrator Migrator) Down(ctx context.Context) (MigrationResult, error) {
+	result, err := migrator.provider.Down(ctx)
+	if err != nil {
+		return MigrationResult{}, fmt.Errorf("migrate down: %w", err)
+	}
+
+	return newMigrationResults(result)[0], nil
+}
+
+// Redo rolls back the latest applied migration and applies it again.
+func (migrator Migrator) Redo(ctx context.Context) ([]MigrationResult, error) {
+	down, err := migrator.provider.Down(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("migrate redo: %w", err)
+	}
+
+	up, err := migrator.provider.ApplyVersion(ctx, down.Source.Version, true)
+	if err != nil {
+		return newMigrationResults(down), fmt.Errorf("migrate redo: %w", err)
+	}
+
+	return newMigrationResults(down, up), nil
+}
+
+func newMigrationResults(results ...*goose.MigrationResult) []MigrationResult {
+	migrationResults := make([]MigrationResult, len(results))
+	for i, result := range results {
+		migrationResults[i] = MigrationResult{
+			Version:   result.Source.Version,
+			Name:      result.Source.Path,
+			Direction: result.Direction,
+			Duration:  result.Duration,
+		}
+	}
+
+	return migrationResults
+}
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"database/sql"
 	"time"
 
 	"github.com/spie/fskick/internal/uuid"
@@ -24,3 +25,29 @@ func (model *Model) CreateUUID() error {
 
 	return nil
 }
+
+// NullTime scans a nullable time column into t, a NULL leaves t zero.
+func NullTime(t *time.Time) sql.Scanner {
+	return nullTimeScanner{time: t}
+}
+
+type nullTimeScanner struct {
+	time *time.Time
+}
+
+func (scanner nullTimeScanner) Scan(value any) error {
+	var nullTime sql.NullTime
+	err := nullTime.Scan(value)
+	if err != nil {
+		return err
+	}
+
+	*scanner.time = nullTime.Time
+
+	return nil
+}
+
+// NullTimeValue stores a zero time as NULL.
+func NullTimeValue(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
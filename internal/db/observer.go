@@ -0,0 +1,51 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// QueryObserver is called after every query with its duration and error.
+type QueryObserver func(ctx context.Context, query string, duration time.Duration, err error)
+
+type observedConnection struct {
+	Connection
+	observe QueryObserver
+}
+
+// WithQueryObserver returns conn calling observe after every query, also in transactions
+// started with Transaction.
+func WithQueryObserver(conn Connection, observe QueryObserver) Connection {
+	return observedConnection{Connection: conn, observe: observe}
+}
+
+func (conn observedConnection) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := conn.Connection.QueryContext(ctx, query, args...)
+	conn.observe(ctx, query, time.Since(start), err)
+
+	return rows, err
+}
+
+func (conn observedConnection) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	start := time.Now()
+	row := conn.Connection.QueryRowContext(ctx, query, args...)
+	conn.observe(ctx, query, time.Since(start), row.Err())
+
+	return row
+}
+
+func (conn observedConnection) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	start := time.Now()
+	result, err := conn.Connection.ExecContext(ctx, query, args...)
+	conn.observe(ctx, query, time.Since(start), err)
+
+	return result, err
+}
+
+func (conn observedConnection) wrap(tx Connection) Connection {
+	conn.Connection = wrapTx(conn.Connection, tx)
+
+	return conn
+}
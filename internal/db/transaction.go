@@ -0,0 +1,63 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+var ErrNestedTransaction = errors.New("transaction already started")
+
+// Tx is a transaction usable as Connection, so repositories can run their queries in it.
+type Tx struct {
+	*sql.Tx
+}
+
+func (tx Tx) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	return nil, ErrNestedTransaction
+}
+
+func (tx Tx) Close() error {
+	return nil
+}
+
+// txWrapper is implemented by connections wrapping another connection, like the query logger,
+// to wrap the transactions started on them too.
+type txWrapper interface {
+	wrap(tx Connection) Connection
+}
+
+func wrapTx(conn Connection, tx Connection) Connection {
+	if wrapper, ok := conn.(txWrapper); ok {
+		return wrapper.wrap(tx)
+	}
+
+	return tx
+}
+
+// Transaction runs fn in a transaction. It is committed if fn succeeds and rolled back otherwise.
+// On a connection of a running transaction fn joins it, the outer transaction commits or rolls
+// back all of its changes.
+func Transaction(ctx context.Context, conn Connection, fn func(tx Connection) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if errors.Is(err, ErrNestedTransaction) {
+		return fn(conn)
+	}
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+
+	err = fn(wrapTx(conn, Tx{Tx: tx}))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return nil
+}
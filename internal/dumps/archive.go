@@ -0,0 +1,242 @@
+package dumps
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ArchiveVersion is increased on incompatible changes of the archive format.
+const ArchiveVersion = 1
+
+const (
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+)
+
+var (
+	ErrUnknownFormat      = errors.New("format has to be json or ndjson")
+	ErrUnsupportedVersion = errors.New("unsupported archive version")
+	ErrInvalidArchive     = errors.New("invalid archive")
+)
+
+// Archive holds all data of an instance. Relations are kept by UUIDs, so the archive can be
+// restored into a database with other IDs.
+type Archive struct {
+	Version       int                `json:"version"`
+	ExportedAt    time.Time          `json:"exportedAt"`
+	WithPasswords bool               `json:"withPasswords"`
+	Seasons       []SeasonRecord     `json:"seasons"`
+	Players       []PlayerRecord     `json:"players"`
+	Games         []GameRecord       `json:"games"`
+	Attendances   []AttendanceRecord `json:"attendances"`
+}
+
+type SeasonRecord struct {
+	UUID      string    `json:"uuid"`
+	Name      string    `json:"name"`
+	Active    bool      `json:"active"`
+	TeamSizes string    `json:"teamSizes,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// PlayerRecord is a player, and a user if the email is set. The password hash is only set
+// for archives exported with passwords.
+type PlayerRecord struct {
+	UUID              string    `json:"uuid"`
+	Name              string    `json:"name"`
+	DisplayName       string    `json:"displayName,omitempty"`
+	PreferredPosition string    `json:"preferredPosition,omitempty"`
+	FavoriteSide      string    `json:"favoriteSide,omitempty"`
+	JoinedAt          time.Time `json:"joinedAt"`
+	Avatar            string    `json:"avatar,omitempty"`
+	Email             string    `json:"email,omitempty"`
+	PasswordHash      string    `json:"passwordHash,omitempty"`
+	CreatedAt         time.Time `json:"createdAt"`
+	UpdatedAt         time.Time `json:"updatedAt"`
+}
+
+type GameRecord struct {
+	UUID           string    `json:"uuid"`
+	PlayedAt       time.Time `json:"playedAt"`
+	SeasonUUID     string    `json:"season"`
+	RecordedByUUID string    `json:"recordedBy,omitempty"`
+	Team1Score     *int      `json:"team1Score,omitempty"`
+	Team2Score     *int      `json:"team2Score,omitempty"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+type AttendanceRecord struct {
+	UUID       string    `json:"uuid"`
+	GameUUID   string    `json:"game"`
+	PlayerUUID string    `json:"player"`
+	Team       int       `json:"team"`
+	Outcome    string    `json:"outcome"`
+	Position   string    `json:"position,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// ndjsonLine is one line of an NDJSON archive. The first line is the header with the
+// archive's version, every other line holds one record.
+type ndjsonLine struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+type ndjsonHeader struct {
+	Version       int       `json:"version"`
+	ExportedAt    time.Time `json:"exportedAt"`
+	WithPasswords bool      `json:"withPasswords"`
+}
+
+func WriteArchive(w io.Writer, archive Archive, format string) error {
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(archive)
+	case FormatNDJSON:
+		return writeNDJSON(w, archive)
+	}
+
+	return ErrUnknownFormat
+}
+
+func writeNDJSON(w io.Writer, archive Archive) error {
+	encoder := json.NewEncoder(w)
+	writeLine := func(lineType string, data any) error {
+		rawData, err := json.Marshal(data)
+		if err != nil {
+			return fmt.Errorf("write %s: %w", lineType, err)
+		}
+
+		return encoder.Encode(ndjsonLine{Type: lineType, Data: rawData})
+	}
+
+	err := writeLine("header", ndjsonHeader{
+		Version:       archive.Version,
+		ExportedAt:    archive.ExportedAt,
+		WithPasswords: archive.WithPasswords,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, season := range archive.Seasons {
+		if err = writeLine("season", season); err != nil {
+			return err
+		}
+	}
+	for _, player := range archive.Players {
+		if err = writeLine("player", player); err != nil {
+			return err
+		}
+	}
+	for _, game := range archive.Games {
+		if err = writeLine("game", game); err != nil {
+			return err
+		}
+	}
+	for _, attendance := range archive.Attendances {
+		if err = writeLine("attendance", attendance); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func ReadArchive(r io.Reader, format string) (Archive, error) {
+	var archive Archive
+	var err error
+	switch format {
+	case FormatJSON:
+		err = json.NewDecoder(r).Decode(&archive)
+		if err != nil {
+			err = fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+	case FormatNDJSON:
+		archive, err = readNDJSON(r)
+	default:
+		return Archive{}, ErrUnknownFormat
+	}
+	if err != nil {
+		return Archive{}, err
+	}
+
+	if archive.Version != ArchiveVersion {
+		return Archive{}, fmt.Errorf("%w: %d", ErrUnsupportedVersion, archive.Version)
+	}
+
+	return archive, nil
+}
+
+func readNDJSON(r io.Reader) (Archive, error) {
+	archive := Archive{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var line ndjsonLine
+		err := json.Unmarshal(scanner.Bytes(), &line)
+		if err != nil {
+			return Archive{}, fmt.Errorf("%w: line %d: %v", ErrInvalidArchive, lineNumber, err)
+		}
+
+		if lineNumber == 1 && line.Type != "header" {
+			return Archive{}, fmt.Errorf("%w: missing header", ErrInvalidArchive)
+		}
+
+		switch line.Type {
+		case "header":
+			var header ndjsonHeader
+			err = json.Unmarshal(line.Data, &header)
+			archive.Version = header.Version
+			archive.ExportedAt = header.ExportedAt
+			archive.WithPasswords = header.WithPasswords
+		case "season":
+			err = appendRecord(line.Data, &archive.Seasons)
+		case "player":
+			err = appendRecord(line.Data, &archive.Players)
+		case "game":
+			err = appendRecord(line.Data, &archive.Games)
+		case "attendance":
+			err = appendRecord(line.Data, &archive.Attendances)
+		default:
+			err = fmt.Errorf("unknown type %q", line.Type)
+		}
+		if err != nil {
+			return Archive{}, fmt.Errorf("%w: line %d: %v", ErrInvalidArchive, lineNumber, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Archive{}, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+
+	return archive, nil
+}
+
+func appendRecord[T any](data json.RawMessage, records *[]T) error {
+	var record T
+	err := json.Unmarshal(data, &record)
+	if err != nil {
+		return err
+	}
+
+	*records = append(*records, record)
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package dumps
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArchiveRoundTrip(t *testing.T) {
+	score := 10
+	archive := Archive{
+		Version:     ArchiveVersion,
+		ExportedAt:  time.Date(2024, 5, 1, 18, 30, 0, 0, time.UTC),
+		Seasons:     []SeasonRecord{{UUID: "season-uuid", Name: "2024", Active: true}},
+		Players:     []PlayerRecord{{UUID: "player-uuid", Name: "alice", Email: "alice@example.com"}},
+		Games:       []GameRecord{{UUID: "game-uuid", SeasonUUID: "season-uuid", Team1Score: &score, Team2Score: &score}},
+		Attendances: []AttendanceRecord{{UUID: "attendance-uuid", GameUUID: "game-uuid", PlayerUUID: "player-uuid", Team: 1, Outcome: "draw"}},
+	}
+
+	for _, format := range []string{FormatJSON, FormatNDJSON} {
+		t.Run(format, func(t *testing.T) {
+			var buffer bytes.Buffer
+			err := WriteArchive(&buffer, archive, format)
+			assert.NoError(t, err)
+
+			readArchive, err := ReadArchive(&buffer, format)
+			assert.NoError(t, err)
+			assert.Equal(t, archive, readArchive)
+		})
+	}
+}
+
+func TestReadArchive(t *testing.T) {
+	tests := map[string]struct {
+		archive    string
+		format     string
+		assertions []func(t *testing.T, archive Archive, err error)
+	}{
+		"with unsupported version": {
+			archive: `{"type": "header", "data": {"version": 99}}`,
+			format:  FormatNDJSON,
+			assertions: []func(t *testing.T, archive Archive, err error){
+				func(t *testing.T, archive Archive, err error) {
+					assert.ErrorIs(t, err, ErrUnsupportedVersion)
+				},
+			},
+		},
+		"without header": {
+			archive: `{"type": "season", "data": {"uuid": "season-uuid"}}`,
+			format:  FormatNDJSON,
+			assertions: []func(t *testing.T, archive Archive, err error){
+				func(t *testing.T, archive Archive, err error) {
+					assert.ErrorIs(t, err, ErrInvalidArchive)
+				},
+			},
+		},
+		"with unknown format": {
+			archive: `{}`,
+			format:  "xml",
+			assertions: []func(t *testing.T, archive Archive, err error){
+				func(t *testing.T, archive Archive, err error) {
+					assert.ErrorIs(t, err, ErrUnknownFormat)
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			archive, err := ReadArchive(strings.NewReader(test.archive), test.format)
+
+			for _, assertion := range test.assertions {
+				assertion(t, archive, err)
+			}
+		})
+	}
+}
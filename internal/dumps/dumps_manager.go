@@ -0,0 +1,200 @@
+package dumps
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spie/fskick/internal/db"
+)
+
+type ExportOptions struct {
+	Format        string
+	WithPasswords bool
+}
+
+// RestoreReport counts the restored records and the records skipped because their UUID
+// already exists.
+type RestoreReport struct {
+	Seasons     int
+	Players     int
+	Games       int
+	Attendances int
+	Skipped     int
+}
+
+type Manager struct {
+	conn db.Connection
+}
+
+func NewManager(conn db.Connection) Manager {
+	return Manager{conn: conn}
+}
+
+// Export writes all data as archive. It reads in one transaction to get a consistent state.
+func (manager Manager) Export(ctx context.Context, w io.Writer, options ExportOptions) error {
+	archive := Archive{
+		Version:       ArchiveVersion,
+		ExportedAt:    time.Now(),
+		WithPasswords: options.WithPasswords,
+	}
+
+	err := db.Transaction(ctx, manager.conn, func(tx db.Connection) error {
+		repository := NewDumpsRepository(tx)
+
+		var err error
+		archive.Seasons, err = repository.GetSeasons(ctx)
+		if err != nil {
+			return err
+		}
+
+		archive.Players, err = repository.GetPlayers(ctx, options.WithPasswords)
+		if err != nil {
+			return err
+		}
+
+		archive.Games, err = repository.GetGames(ctx)
+		if err != nil {
+			return err
+		}
+
+		archive.Attendances, err = repository.GetAttendances(ctx)
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+
+	return WriteArchive(w, archive, options.Format)
+}
+
+// Restore inserts the archive's records in one transaction. Records with a UUID that already
+// exists are skipped, so restoring an archive twice does not duplicate data. Seasons are only
+// restored as active if the database has no active season.
+func (manager Manager) Restore(ctx context.Context, archive Archive) (RestoreReport, error) {
+	report := RestoreReport{}
+
+	err := db.Transaction(ctx, manager.conn, func(tx db.Connection) error {
+		var err error
+		report, err = restore(ctx, NewDumpsRepository(tx), archive)
+
+		return err
+	})
+	if err != nil {
+		return RestoreReport{}, fmt.Errorf("restore: %w", err)
+	}
+
+	return report, nil
+}
+
+func restore(ctx context.Context, repository DumpsRepository, archive Archive) (RestoreReport, error) {
+	report := RestoreReport{}
+
+	hasActiveSeason, err := repository.HasActiveSeason(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	seasonIDs := map[string]uint{}
+	for _, season := range archive.Seasons {
+		id, exists, err := repository.FindID(ctx, "seasons", season.UUID)
+		if err != nil {
+			return report, err
+		}
+		if exists {
+			seasonIDs[season.UUID] = id
+			report.Skipped++
+			continue
+		}
+
+		season.Active = season.Active && !hasActiveSeason
+		seasonIDs[season.UUID], err = repository.InsertSeason(ctx, season)
+		if err != nil {
+			return report, err
+		}
+		report.Seasons++
+	}
+
+	playerIDs := map[string]uint{}
+	for _, player := range archive.Players {
+		id, exists, err := repository.FindID(ctx, "players", player.UUID)
+		if err != nil {
+			return report, err
+		}
+		if exists {
+			playerIDs[player.UUID] = id
+			report.Skipped++
+			continue
+		}
+
+		playerIDs[player.UUID], err = repository.InsertPlayer(ctx, player)
+		if err != nil {
+			return report, err
+		}
+		report.Players++
+	}
+
+	gameIDs := map[string]uint{}
+	for _, game := range archive.Games {
+		id, exists, err := repository.FindID(ctx, "games", game.UUID)
+		if err != nil {
+			return report, err
+		}
+		if exists {
+			gameIDs[game.UUID] = id
+			report.Skipped++
+			continue
+		}
+
+		seasonID, ok := seasonIDs[game.SeasonUUID]
+		if !ok {
+			return report, fmt.Errorf("%w: season %s of game %s is missing", ErrInvalidArchive, game.SeasonUUID, game.UUID)
+		}
+
+		var recordedByID *uint
+		if game.RecordedByUUID != "" {
+			playerID, ok := playerIDs[game.RecordedByUUID]
+			if !ok {
+				return report, fmt.Errorf("%w: recorder %s of game %s is missing", ErrInvalidArchive, game.RecordedByUUID, game.UUID)
+			}
+			recordedByID = &playerID
+		}
+
+		gameIDs[game.UUID], err = repository.InsertGame(ctx, game, seasonID, recordedByID)
+		if err != nil {
+			return report, err
+		}
+		report.Games++
+	}
+
+	for _, attendance := range archive.Attendances {
+		_, exists, err := repository.FindID(ctx, "attendances", attendance.UUID)
+		if err != nil {
+			return report, err
+		}
+		if exists {
+			report.Skipped++
+			continue
+		}
+
+		gameID, ok := gameIDs[attendance.GameUUID]
+		if !ok {
+			return report, fmt.Errorf("%w: game %s of attendance %s is missing", ErrInvalidArchive, attendance.GameUUID, attendance.UUID)
+		}
+
+		playerID, ok := playerIDs[attendance.PlayerUUID]
+		if !ok {
+			return report, fmt.Errorf("%w: player %s of attendance %s is missing", ErrInvalidArchive, attendance.PlayerUUID, attendance.UUID)
+		}
+
+		err = repository.InsertAttendance(ctx, attendance, gameID, playerID)
+		if err != nil {
+			return report, err
+		}
+		report.Attendances++
+	}
+
+	return report, nil
+}
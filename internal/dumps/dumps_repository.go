@@ -0,0 +1,359 @@
+package dumps
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/spie/fskick/internal/db"
+	"github.com/spie/fskick/internal/seasons"
+)
+
+type DumpsRepository struct {
+	conn db.Connection
+}
+
+func NewDumpsRepository(conn db.Connection) DumpsRepository {
+	return DumpsRepository{conn: conn}
+}
+
+func (repository DumpsRepository) GetSeasons(ctx context.Context) ([]SeasonRecord, error) {
+	rows, err := repository.conn.QueryContext(
+		ctx,
+		`SELECT uuid, name, active, team_sizes, created_at, updated_at FROM seasons ORDER BY id ASC`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query seasons for dump: %w", err)
+	}
+	defer rows.Close()
+
+	seasons := []SeasonRecord{}
+	for rows.Next() {
+		var season SeasonRecord
+		err = rows.Scan(&season.UUID, &season.Name, &season.Active, &season.TeamSizes, &season.CreatedAt, &season.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("scan seasons for dump: %w", err)
+		}
+
+		seasons = append(seasons, season)
+	}
+
+	return seasons, nil
+}
+
+func (repository DumpsRepository) GetPlayers(ctx context.Context, withPasswords bool) ([]PlayerRecord, error) {
+	rows, err := repository.conn.QueryContext(
+		ctx,
+		`SELECT
+			uuid,
+			name,
+			COALESCE(display_name, ''),
+			COALESCE(preferred_position, ''),
+			COALESCE(favorite_side, ''),
+			joined_at,
+			COALESCE(avatar, ''),
+			COALESCE(email, ''),
+			COALESCE(password, ''),
+			created_at,
+			updated_at
+		FROM players
+		ORDER BY id ASC`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query players for dump: %w", err)
+	}
+	defer rows.Close()
+
+	players := []PlayerRecord{}
+	for rows.Next() {
+		var player PlayerRecord
+		err = rows.Scan(
+			&player.UUID,
+			&player.Name,
+			&player.DisplayName,
+			&player.PreferredPosition,
+			&player.FavoriteSide,
+			db.NullTime(&player.JoinedAt),
+			&player.Avatar,
+			&player.Email,
+			&player.PasswordHash,
+			&player.CreatedAt,
+			&player.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan players for dump: %w", err)
+		}
+
+		if !withPasswords {
+			player.PasswordHash = ""
+		}
+
+		players = append(players, player)
+	}
+
+	return players, nil
+}
+
+func (repository DumpsRepository) GetGames(ctx context.Context) ([]GameRecord, error) {
+	rows, err := repository.conn.QueryContext(
+		ctx,
+		`SELECT
+			g.uuid,
+			g.played_at,
+			s.uuid,
+			COALESCE(r.uuid, ''),
+			g.team1_score,
+			g.team2_score,
+			g.created_at,
+			g.updated_at
+		FROM games g
+		JOIN seasons s ON s.id = g.season_id
+		LEFT JOIN players r ON r.id = g.recorded_by_id
+		ORDER BY g.played_at ASC, g.id ASC`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query games for dump: %w", err)
+	}
+	defer rows.Close()
+
+	games := []GameRecord{}
+	for rows.Next() {
+		var game GameRecord
+		var team1Score, team2Score sql.NullInt64
+		err = rows.Scan(
+			&game.UUID,
+			&game.PlayedAt,
+			&game.SeasonUUID,
+			&game.RecordedByUUID,
+			&team1Score,
+			&team2Score,
+			&game.CreatedAt,
+			&game.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan games for dump: %w", err)
+		}
+
+		if team1Score.Valid && team2Score.Valid {
+			team1, team2 := int(team1Score.Int64), int(team2Score.Int64)
+			game.Team1Score, game.Team2Score = &team1, &team2
+		}
+
+		games = append(games, game)
+	}
+
+	return games, nil
+}
+
+func (repository DumpsRepository) GetAttendances(ctx context.Context) ([]AttendanceRecord, error) {
+	rows, err := repository.conn.QueryContext(
+		ctx,
+		`SELECT
+			a.uuid,
+			g.uuid,
+			p.uuid,
+			a.team,
+			a.outcome,
+			COALESCE(a.position, ''),
+			a.created_at,
+			a.updated_at
+		FROM attendances a
+		JOIN games g ON g.id = a.game_id
+		JOIN players p ON p.id = a.player_id
+		ORDER BY a.id ASC`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query attendances for dump: %w", err)
+	}
+	defer rows.Close()
+
+	attendances := []AttendanceRecord{}
+	for rows.Next() {
+		var attendance AttendanceRecord
+		err = rows.Scan(
+			&attendance.UUID,
+			&attendance.GameUUID,
+			&attendance.PlayerUUID,
+			&attendance.Team,
+			&attendance.Outcome,
+			&attendance.Position,
+			&attendance.CreatedAt,
+			&attendance.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan attendances for dump: %w", err)
+		}
+
+		attendances = append(attendances, attendance)
+	}
+
+	return attendances, nil
+}
+
+// FindID returns the ID of the row with the UUID in the table, or false if there is none.
+func (repository DumpsRepository) FindID(ctx context.Context, table string, uuid string) (uint, bool, error) {
+	var id uint
+	err := repository.conn.
+		QueryRowContext(ctx, fmt.Sprintf("SELECT id FROM %s WHERE uuid = $1", table), uuid).
+		Scan(&id)
+	if errors.Is(err, db.ErrNotFound) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("query %s id: %w", table, err)
+	}
+
+	return id, true, nil
+}
+
+func (repository DumpsRepository) HasActiveSeason(ctx context.Context) (bool, error) {
+	var count int
+	err := repository.conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM seasons WHERE active = true").Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("count active seasons: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// InsertSeason inserts the season, seasons of archives without team sizes allow both.
+func (repository DumpsRepository) InsertSeason(ctx context.Context, season SeasonRecord) (uint, error) {
+	if season.TeamSizes == "" {
+		season.TeamSizes = string(seasons.TeamSizesBoth)
+	}
+
+	var id uint
+	err := repository.conn.QueryRowContext(
+		ctx,
+		`INSERT INTO seasons (uuid, name, active, team_sizes, created_at, updated_at, deleted_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id`,
+		season.UUID,
+		season.Name,
+		season.Active,
+		season.TeamSizes,
+		season.CreatedAt,
+		season.UpdatedAt,
+		nil,
+	).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("insert season %s: %w", season.UUID, err)
+	}
+
+	return id, nil
+}
+
+func (repository DumpsRepository) InsertPlayer(ctx context.Context, player PlayerRecord) (uint, error) {
+	var id uint
+	err := repository.conn.QueryRowContext(
+		ctx,
+		`INSERT INTO players (
+			uuid,
+			name,
+			display_name,
+			preferred_position,
+			favorite_side,
+			joined_at,
+			avatar,
+			email,
+			password,
+			created_at,
+			updated_at,
+			deleted_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id`,
+		player.UUID,
+		player.Name,
+		nullString(player.DisplayName),
+		nullString(player.PreferredPosition),
+		nullString(player.FavoriteSide),
+		db.NullTimeValue(player.JoinedAt),
+		nullString(player.Avatar),
+		nullString(player.Email),
+		nullString(player.PasswordHash),
+		player.CreatedAt,
+		player.UpdatedAt,
+		nil,
+	).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("insert player %s: %w", player.UUID, err)
+	}
+
+	return id, nil
+}
+
+func (repository DumpsRepository) InsertGame(ctx context.Context, game GameRecord, seasonID uint, recordedByID *uint) (uint, error) {
+	var id uint
+	err := repository.conn.QueryRowContext(
+		ctx,
+		`INSERT INTO games (
+			uuid,
+			played_at,
+			season_id,
+			recorded_by_id,
+			team1_score,
+			team2_score,
+			created_at,
+			updated_at,
+			deleted_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id`,
+		game.UUID,
+		game.PlayedAt,
+		seasonID,
+		recordedByID,
+		game.Team1Score,
+		game.Team2Score,
+		game.CreatedAt,
+		game.UpdatedAt,
+		nil,
+	).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("insert game %s: %w", game.UUID, err)
+	}
+
+	return id, nil
+}
+
+func (repository DumpsRepository) InsertAttendance(ctx context.Context, attendance AttendanceRecord, gameID uint, playerID uint) error {
+	_, err := repository.conn.ExecContext(
+		ctx,
+		`INSERT INTO attendances (
+			uuid,
+			team,
+			outcome,
+			position,
+			player_id,
+			game_id,
+			created_at,
+			updated_at,
+			deleted_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		attendance.UUID,
+		attendance.Team,
+		attendance.Outcome,
+		nullString(attendance.Position),
+		playerID,
+		gameID,
+		attendance.CreatedAt,
+		attendance.UpdatedAt,
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("insert attendance %s: %w", attendance.UUID, err)
+	}
+
+	return nil
+}
+
+func nullString(value string) *string {
+	if value == "" {
+		return nil
+	}
+
+	return &value
+}
@@ -1,25 +1,44 @@
 package games
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 
 	"github.com/spie/fskick/internal/db"
 	"github.com/spie/fskick/internal/players"
 	"github.com/spie/fskick/internal/seasons"
 )
 
+type Outcome string
+
+const (
+	OutcomeWin  Outcome = "win"
+	OutcomeDraw Outcome = "draw"
+	OutcomeLoss Outcome = "loss"
+)
+
 type Attendance struct {
 	db.Model
-	Win      bool
+	Team     int
+	Outcome  Outcome
+	Position players.Position
 	PlayerID uint
 	GameID   uint
+	GameUUID string
+	Player   players.Player
 }
 
 type PlayerAttendance struct {
 	players.Player
-	Wins  int
-	Games int
+	Wins         int
+	Draws        int
+	Games        int
+	DefenseWins  int
+	DefenseGames int
+	OffenseWins  int
+	OffenseGames int
 }
 
 type PlayerWithAttendances struct {
@@ -36,19 +55,23 @@ func NewAttendanceRepository(conn db.Connection) AttendanceRepository {
 }
 
 func (repository AttendanceRepository) CollectPlayerAttendancesForSeason(
+	ctx context.Context,
 	season seasons.Season,
+	mode Mode,
 ) ([]PlayerAttendance, error) {
-	rows, err := repository.conn.Query(
+	rows, err := repository.conn.QueryContext(
+		ctx,
 		fmt.Sprintf(
 			`SELECT
 			%s
 			FROM players p
 			JOIN attendances a ON p.id = a.player_id
 			JOIN games g ON g.id = a.game_id
-			WHERE g.season_id = $1
+			WHERE g.season_id = $1 AND %s
 			GROUP BY p.id
 			`,
 			getPlayerAttendanceColumns(),
+			getModeCondition(mode),
 		),
 		season.ID,
 	)
@@ -65,17 +88,59 @@ func (repository AttendanceRepository) CollectPlayerAttendancesForSeason(
 	return playerAttendances, nil
 }
 
-func (repository AttendanceRepository) CollectAllPlayerAttendances() ([]PlayerAttendance, error) {
-	rows, err := repository.conn.Query(
+// CollectPlayerAttendancesForSeasonUntil collects the attendances of the season's games
+// played before the game, including the game itself if inclusive.
+func (repository AttendanceRepository) CollectPlayerAttendancesForSeasonUntil(
+	ctx context.Context,
+	season seasons.Season,
+	game Game,
+	inclusive bool,
+) ([]PlayerAttendance, error) {
+	rows, err := repository.conn.QueryContext(
+		ctx,
+		fmt.Sprintf(
+			`SELECT
+			%s
+			FROM players p
+			JOIN attendances a ON p.id = a.player_id
+			JOIN games g ON g.id = a.game_id
+			WHERE g.season_id = $1 AND %s
+			GROUP BY p.id
+			`,
+			getPlayerAttendanceColumns(),
+			getPlayedUntilCondition(inclusive),
+		),
+		season.ID,
+		game.cursor.playedAt,
+		game.ID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("collect player attendances for season until game: %w", err)
+	}
+	defer rows.Close()
+
+	playerAttendances, err := scanPlayerAttendances(rows)
+	if err != nil {
+		return nil, fmt.Errorf("scan row for collect player attendances for season until game: %w", err)
+	}
+
+	return playerAttendances, nil
+}
+
+func (repository AttendanceRepository) CollectAllPlayerAttendances(ctx context.Context, mode Mode) ([]PlayerAttendance, error) {
+	rows, err := repository.conn.QueryContext(
+		ctx,
 		fmt.Sprintf(
 			`SELECT
 			%s
 			FROM players p
 			JOIN attendances a ON p.id = a.player_id
 			JOIN games g ON g.id = a.game_id
+			WHERE %s
 			GROUP BY p.id
 			`,
 			getPlayerAttendanceColumns(),
+			getModeCondition(mode),
 		),
 	)
 	if err != nil {
@@ -92,12 +157,14 @@ func (repository AttendanceRepository) CollectAllPlayerAttendances() ([]PlayerAt
 }
 
 func (repository AttendanceRepository) CollectFellowPlayerAttendances(
+	ctx context.Context,
 	player players.Player,
 ) ([]PlayerAttendance, error) {
-	rows, err := repository.conn.Query(
+	rows, err := repository.conn.QueryContext(
+		ctx,
 		fmt.Sprintf(
 			`WITH player_games AS (
-				SELECT g.id AS game_id, a.win
+				SELECT g.id AS game_id, a.team
 				FROM attendances a
 				JOIN games g ON g.id = a.game_id
 				WHERE a.player_id = $1
@@ -107,7 +174,7 @@ func (repository AttendanceRepository) CollectFellowPlayerAttendances(
 			FROM players p
 			JOIN attendances a ON p.id = a.player_id
 			JOIN games g ON g.id = a.game_id
-			JOIN player_games pg ON g.id = pg.game_id AND a.win = pg.win
+			JOIN player_games pg ON g.id = pg.game_id AND a.team = pg.team
 			WHERE p.id != $1
 			GROUP BY p.id
 			`,
@@ -129,30 +196,28 @@ func (repository AttendanceRepository) CollectFellowPlayerAttendances(
 }
 
 func (repository AttendanceRepository) CollectOponentPlayerAttendances(
+	ctx context.Context,
 	player players.Player,
 ) ([]PlayerAttendance, error) {
-	rows, err := repository.conn.Query(
+	rows, err := repository.conn.QueryContext(ctx, fmt.Sprintf(
 		`WITH player_games AS (
-			SELECT g.id AS game_id, a.win
+			SELECT g.id AS game_id, a.team
 			FROM attendances a
 			JOIN games g ON g.id = a.game_id
 			WHERE a.player_id = $1
 		)
 		SELECT
-			p.id,
-			p.uuid,
-			p.name,
-			p.created_at,
-			p.updated_at,
-			COUNT(a.id) AS games_played,
-			SUM(CASE WHEN a.win THEN 0 ELSE 1 END) as wins
+			%s,
+			%s
 		FROM players p
 		JOIN attendances a ON p.id = a.player_id
 		JOIN games g ON g.id = a.game_id
-		JOIN player_games pg ON g.id = pg.game_id AND a.win != pg.win
+		JOIN player_games pg ON g.id = pg.game_id AND a.team != pg.team
 		WHERE p.id != $1
 		GROUP BY p.id
 		`,
+		players.GetPlayerColumns("p"),
+		getAttendanceCountColumns(OutcomeLoss)),
 		player.ID,
 	)
 	if err != nil {
@@ -168,9 +233,10 @@ func (repository AttendanceRepository) CollectOponentPlayerAttendances(
 	return playerAttendances, nil
 }
 
-func (repository AttendanceRepository) GetAttendancesForPlayer(player players.Player) ([]Attendance, error) {
-	rows, err := repository.conn.Query(
-		`SELECT a.id, a.uuid, a.win, a.created_at
+func (repository AttendanceRepository) GetAttendancesForPlayer(ctx context.Context, player players.Player) ([]Attendance, error) {
+	rows, err := repository.conn.QueryContext(
+		ctx,
+		`SELECT a.id, a.uuid, a.team, a.outcome, COALESCE(a.position, ''), a.player_id, a.game_id, g.uuid, a.created_at
 		FROM attendances a
 		JOIN games g ON a.game_id = g.id
 		WHERE a.player_id = $1
@@ -188,7 +254,12 @@ func (repository AttendanceRepository) GetAttendancesForPlayer(player players.Pl
 		err = rows.Scan(
 			&attendance.ID,
 			&attendance.UUID,
-			&attendance.Win,
+			&attendance.Team,
+			&attendance.Outcome,
+			&attendance.Position,
+			&attendance.PlayerID,
+			&attendance.GameID,
+			&attendance.GameUUID,
 			&attendance.CreatedAt,
 		)
 		if err != nil {
@@ -201,15 +272,16 @@ func (repository AttendanceRepository) GetAttendancesForPlayer(player players.Pl
 	return attendances, nil
 }
 
-func (repository AttendanceRepository) GetAttendancesForAllPlayers() ([]PlayerWithAttendances, error) {
-	rows, err := repository.conn.Query(
-		`SELECT p.id, p.uuid, p.name, p.created_at, a.id, a.uuid, a.win, a.created_at
+func (repository AttendanceRepository) GetAttendancesForAllPlayers(ctx context.Context) ([]PlayerWithAttendances, error) {
+	rows, err := repository.conn.QueryContext(ctx, fmt.Sprintf(
+		`SELECT %s, a.id, a.uuid, a.team, a.outcome, a.created_at
 		FROM players p
 		JOIN attendances a ON p.id = a.player_id
 		JOIN games g ON g.id = a.game_id
 		ORDER BY g.played_at ASC
 		`,
-	)
+		players.GetPlayerColumns("p"),
+	))
 	if err != nil {
 		return nil, fmt.Errorf("get all attendances for all players: %w", err)
 	}
@@ -219,16 +291,14 @@ func (repository AttendanceRepository) GetAttendancesForAllPlayers() ([]PlayerWi
 	for rows.Next() {
 		var player players.Player
 		var attendance Attendance
-		err = rows.Scan(
-			&player.ID,
-			&player.UUID,
-			&player.Name,
-			&player.CreatedAt,
+		err = rows.Scan(append(
+			players.GetPlayerScanFields(&player),
 			&attendance.ID,
 			&attendance.UUID,
-			&attendance.Win,
+			&attendance.Team,
+			&attendance.Outcome,
 			&attendance.CreatedAt,
-		)
+		)...)
 		if err != nil {
 			return nil, fmt.Errorf("scan player with attendances rows: %w", err)
 		}
@@ -246,30 +316,107 @@ func (repository AttendanceRepository) GetAttendancesForAllPlayers() ([]PlayerWi
 	return getPlayersWithAttendancesFromMap(playersWithAttendances), nil
 }
 
+// GetAttendancesForGames returns the attendances with their players, grouped by game ID.
+func (repository AttendanceRepository) GetAttendancesForGames(ctx context.Context, gameIDs []uint) (map[uint][]Attendance, error) {
+	attendances := map[uint][]Attendance{}
+	if len(gameIDs) == 0 {
+		return attendances, nil
+	}
+
+	placeholders := make([]string, len(gameIDs))
+	args := make([]any, len(gameIDs))
+	for i, gameID := range gameIDs {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = gameID
+	}
+
+	rows, err := repository.conn.QueryContext(
+		ctx,
+		fmt.Sprintf(
+			`SELECT a.id, a.uuid, a.team, a.outcome, COALESCE(a.position, ''), a.player_id, a.game_id, g.uuid, a.created_at, %s
+			FROM attendances a
+			JOIN games g ON g.id = a.game_id
+			JOIN players p ON p.id = a.player_id
+			WHERE a.game_id IN (%s)
+			ORDER BY a.team ASC, a.id ASC`,
+			players.GetPlayerColumns("p"),
+			strings.Join(placeholders, ","),
+		),
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("get attendances for games: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var attendance Attendance
+		err = rows.Scan(append(
+			[]any{
+				&attendance.ID,
+				&attendance.UUID,
+				&attendance.Team,
+				&attendance.Outcome,
+				&attendance.Position,
+				&attendance.PlayerID,
+				&attendance.GameID,
+				&attendance.GameUUID,
+				&attendance.CreatedAt,
+			},
+			players.GetPlayerScanFields(&attendance.Player)...,
+		)...)
+		if err != nil {
+			return nil, fmt.Errorf("scan attendances for games rows: %w", err)
+		}
+
+		attendances[attendance.GameID] = append(attendances[attendance.GameID], attendance)
+	}
+
+	return attendances, nil
+}
+
 func getPlayerAttendanceColumns() string {
-	return `
-		p.id,
-		p.uuid,
-		p.name,
-		p.created_at,
-		p.updated_at,
-		COUNT(a.id) AS games_played,
-		SUM(CASE WHEN a.win THEN 1 ELSE 0 END) as wins`
+	return fmt.Sprintf(
+		`%s,
+		%s`,
+		players.GetPlayerColumns("p"),
+		getAttendanceCountColumns(OutcomeWin),
+	)
+}
+
+// getAttendanceCountColumns counts games, wins, draws and the games and wins per position.
+// Oponent stats count the games won against the oponent, so their wins are the games lost
+// by the oponent.
+func getAttendanceCountColumns(winOutcome Outcome) string {
+	return fmt.Sprintf(
+		`COUNT(a.id) AS games_played,
+		SUM(CASE WHEN a.outcome = '%[1]s' THEN 1 ELSE 0 END) AS wins,
+		SUM(CASE WHEN a.outcome = '%[2]s' THEN 1 ELSE 0 END) AS draws,
+		SUM(CASE WHEN a.position = '%[3]s' AND a.outcome = '%[1]s' THEN 1 ELSE 0 END) AS defense_wins,
+		SUM(CASE WHEN a.position = '%[3]s' THEN 1 ELSE 0 END) AS defense_games,
+		SUM(CASE WHEN a.position = '%[4]s' AND a.outcome = '%[1]s' THEN 1 ELSE 0 END) AS offense_wins,
+		SUM(CASE WHEN a.position = '%[4]s' THEN 1 ELSE 0 END) AS offense_games`,
+		winOutcome,
+		OutcomeDraw,
+		players.PositionDefense,
+		players.PositionOffense,
+	)
 }
 
 func scanPlayerAttendances(rows *sql.Rows) ([]PlayerAttendance, error) {
 	var playerAttendances []PlayerAttendance
 	for rows.Next() {
 		var playerAttendance PlayerAttendance
-		err := rows.Scan(
-			&playerAttendance.ID,
-			&playerAttendance.UUID,
-			&playerAttendance.Name,
-			&playerAttendance.CreatedAt,
-			&playerAttendance.UpdatedAt,
+		err := rows.Scan(append(
+			players.GetPlayerScanFields(&playerAttendance.Player),
 			&playerAttendance.Games,
 			&playerAttendance.Wins,
-		)
+			&playerAttendance.Draws,
+			&playerAttendance.DefenseWins,
+			&playerAttendance.DefenseGames,
+			&playerAttendance.OffenseWins,
+			&playerAttendance.OffenseGames,
+		)...)
 		if err != nil {
 			return nil, fmt.Errorf("scan player attendances rows: %w", err)
 		}
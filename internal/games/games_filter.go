@@ -0,0 +1,111 @@
+package games
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spie/fskick/internal/players"
+	"github.com/spie/fskick/internal/seasons"
+)
+
+const (
+	DefaultGamesLimit = 20
+	MaxGamesLimit     = 100
+)
+
+var (
+	ErrInvalidFilter = errors.New("invalid games filter")
+	ErrInvalidCursor = errors.New("invalid games cursor")
+)
+
+// GamesFilter narrows down the listed games. Teammate, oponent and a won or lost outcome
+// are seen from the player's side, so they need a player.
+type GamesFilter struct {
+	Season   *seasons.Season
+	Player   *players.Player
+	Teammate *players.Player
+	Oponent  *players.Player
+	From     time.Time
+	To       time.Time
+	Outcome  Outcome
+	Cursor   string
+	Limit    int
+}
+
+func (filter GamesFilter) validate() error {
+	if filter.Player == nil && (filter.Teammate != nil || filter.Oponent != nil) {
+		return fmt.Errorf("%w: teammate and oponent need a player", ErrInvalidFilter)
+	}
+
+	switch filter.Outcome {
+	case "", OutcomeDraw:
+	case OutcomeWin, OutcomeLoss:
+		if filter.Player == nil {
+			return fmt.Errorf("%w: outcome %s needs a player", ErrInvalidFilter, filter.Outcome)
+		}
+	default:
+		return fmt.Errorf("%w: outcome has to be win, draw or loss", ErrInvalidFilter)
+	}
+
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		return fmt.Errorf("%w: to has to be after from", ErrInvalidFilter)
+	}
+
+	return nil
+}
+
+func (filter GamesFilter) getLimit() int {
+	if filter.Limit <= 0 {
+		return DefaultGamesLimit
+	}
+
+	return min(filter.Limit, MaxGamesLimit)
+}
+
+type GamesPage struct {
+	Games      []Game
+	NextCursor string
+}
+
+// gamesCursor points to the last game of a page. Games are ordered by the stored played_at
+// value, so the cursor keeps it as stored instead of a parsed time.
+type gamesCursor struct {
+	playedAt string
+	id       uint
+}
+
+func (cursor gamesCursor) encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d|%s", cursor.id, cursor.playedAt)))
+}
+
+func decodeGamesCursor(encoded string) (gamesCursor, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return gamesCursor{}, ErrInvalidCursor
+	}
+
+	id, playedAt, ok := strings.Cut(string(decoded), "|")
+	if !ok {
+		return gamesCursor{}, ErrInvalidCursor
+	}
+
+	gameID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return gamesCursor{}, ErrInvalidCursor
+	}
+
+	return gamesCursor{playedAt: playedAt, id: uint(gameID)}, nil
+}
+
+func ParseOutcome(outcome string) (Outcome, error) {
+	switch Outcome(outcome) {
+	case "", OutcomeWin, OutcomeDraw, OutcomeLoss:
+		return Outcome(outcome), nil
+	}
+
+	return "", fmt.Errorf("%w: outcome has to be win, draw or loss", ErrInvalidFilter)
+}
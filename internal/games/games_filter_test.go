@@ -0,0 +1,81 @@
+package games
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spie/fskick/internal/players"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGamesFilter_validate(t *testing.T) {
+	player := &players.Player{Name: "test_player"}
+	now := time.Now()
+
+	tests := map[string]struct {
+		filter     GamesFilter
+		assertions []func(t *testing.T, err error)
+	}{
+		"with empty filter": {
+			filter: GamesFilter{},
+			assertions: []func(t *testing.T, err error){
+				func(t *testing.T, err error) {
+					assert.NoError(t, err)
+				},
+			},
+		},
+		"with teammate without player": {
+			filter: GamesFilter{Teammate: player},
+			assertions: []func(t *testing.T, err error){
+				func(t *testing.T, err error) {
+					assert.ErrorIs(t, err, ErrInvalidFilter)
+				},
+			},
+		},
+		"with win without player": {
+			filter: GamesFilter{Outcome: OutcomeWin},
+			assertions: []func(t *testing.T, err error){
+				func(t *testing.T, err error) {
+					assert.ErrorIs(t, err, ErrInvalidFilter)
+				},
+			},
+		},
+		"with draw without player": {
+			filter: GamesFilter{Outcome: OutcomeDraw},
+			assertions: []func(t *testing.T, err error){
+				func(t *testing.T, err error) {
+					assert.NoError(t, err)
+				},
+			},
+		},
+		"with to before from": {
+			filter: GamesFilter{From: now, To: now.AddDate(0, 0, -1)},
+			assertions: []func(t *testing.T, err error){
+				func(t *testing.T, err error) {
+					assert.ErrorIs(t, err, ErrInvalidFilter)
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := test.filter.validate()
+
+			for _, assertion := range test.assertions {
+				assertion(t, err)
+			}
+		})
+	}
+}
+
+func TestGamesCursor(t *testing.T) {
+	cursor := gamesCursor{playedAt: "2024-05-01 18:30:00+00:00", id: 42}
+
+	decoded, err := decodeGamesCursor(cursor.encode())
+	assert.NoError(t, err)
+	assert.Equal(t, cursor, decoded)
+
+	_, err = decodeGamesCursor("not a cursor")
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
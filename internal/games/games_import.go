@@ -0,0 +1,556 @@
+package games
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spie/fskick/internal/db"
+	"github.com/spie/fskick/internal/players"
+	"github.com/spie/fskick/internal/seasons"
+)
+
+var (
+	ErrImportConflicts = errors.New("import has conflicts")
+	ErrImportFormat    = errors.New("invalid import file")
+	errImportDryRun    = errors.New("import dry run")
+)
+
+// ImportRecord is one game of an import file. Season is optional, without it the game is
+// assigned to the season running at the date the game was played.
+type ImportRecord struct {
+	Line     int
+	PlayedAt time.Time
+	Winners  []string
+	Losers   []string
+	Score    *Score
+	Draw     bool
+	Season   string
+}
+
+type ImportOptions struct {
+	DryRun        bool
+	CreatePlayers bool
+}
+
+type ImportProblem struct {
+	Line    int
+	Message string
+}
+
+type ImportReport struct {
+	Imported       int
+	CreatedPlayers []string
+	Conflicts      []ImportProblem
+	Duplicates     []ImportProblem
+}
+
+type importRecordJson struct {
+	Date    string   `json:"date"`
+	Winners []string `json:"winners"`
+	Losers  []string `json:"losers"`
+	Score   string   `json:"score"`
+	Draw    bool     `json:"draw"`
+	Season  string   `json:"season"`
+}
+
+// ParseImportCSV reads records from CSV with the header date,winners,losers and the
+// optional columns score, draw and season. Team members are separated by ";" or ",".
+func ParseImportCSV(reader io.Reader) ([]ImportRecord, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: read csv header: %v", ErrImportFormat, err)
+	}
+
+	columns := map[string]int{}
+	for i, column := range header {
+		columns[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	for _, column := range []string{"date", "winners", "losers"} {
+		if _, ok := columns[column]; !ok {
+			return nil, fmt.Errorf("%w: missing csv column %s", ErrImportFormat, column)
+		}
+	}
+
+	field := func(row []string, column string) string {
+		i, ok := columns[column]
+		if !ok || i >= len(row) {
+			return ""
+		}
+
+		return strings.TrimSpace(row[i])
+	}
+
+	entries := []importRecordJson{}
+	for {
+		row, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrImportFormat, err)
+		}
+
+		draw, _ := strconv.ParseBool(field(row, "draw"))
+		entries = append(entries, importRecordJson{
+			Date:    field(row, "date"),
+			Winners: splitImportTeam(field(row, "winners")),
+			Losers:  splitImportTeam(field(row, "losers")),
+			Score:   field(row, "score"),
+			Draw:    draw,
+			Season:  field(row, "season"),
+		})
+	}
+
+	// the header is line 1
+	return newImportRecords(entries, 2)
+}
+
+// ParseImportJSONLines reads one JSON object per line with the same fields as the CSV import,
+// but with winners and losers as arrays.
+func ParseImportJSONLines(reader io.Reader) ([]ImportRecord, error) {
+	scanner := bufio.NewScanner(reader)
+	entries := []importRecordJson{}
+	lines := []int{}
+	line := 0
+	for scanner.Scan() {
+		line++
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		var entry importRecordJson
+		err := json.Unmarshal(scanner.Bytes(), &entry)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrImportFormat, line, err)
+		}
+
+		entries = append(entries, entry)
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrImportFormat, err)
+	}
+
+	records, err := newImportRecords(entries, 1)
+	if err != nil {
+		return nil, err
+	}
+	for i := range records {
+		records[i].Line = lines[i]
+	}
+
+	return records, nil
+}
+
+func splitImportTeam(team string) []string {
+	names := strings.FieldsFunc(team, func(r rune) bool { return r == ';' || r == ',' })
+	for i, name := range names {
+		names[i] = strings.TrimSpace(name)
+	}
+
+	return names
+}
+
+// newImportRecords parses dates and scores. Games with only a date are spread over the day
+// by their order in the file, so they keep their order and a second import finds them as
+// duplicates.
+func newImportRecords(entries []importRecordJson, firstLine int) ([]ImportRecord, error) {
+	records := make([]ImportRecord, len(entries))
+	gamesPerDay := map[string]int{}
+	for i, entry := range entries {
+		line := firstLine + i
+		playedAt, dateOnly, err := parseImportDate(entry.Date)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrImportFormat, line, err)
+		}
+		if dateOnly {
+			playedAt = playedAt.Add(time.Duration(gamesPerDay[entry.Date]) * time.Minute)
+			gamesPerDay[entry.Date]++
+		}
+
+		score, err := ParseScore(entry.Score)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrImportFormat, line, err)
+		}
+
+		records[i] = ImportRecord{
+			Line:     line,
+			PlayedAt: playedAt,
+			Winners:  entry.Winners,
+			Losers:   entry.Losers,
+			Score:    score,
+			Draw:     entry.Draw || (score != nil && score.Team1 == score.Team2),
+			Season:   entry.Season,
+		}
+	}
+
+	return records, nil
+}
+
+func parseImportDate(date string) (time.Time, bool, error) {
+	playedAt, err := time.Parse(time.DateOnly, date)
+	if err == nil {
+		return playedAt, true, nil
+	}
+
+	for _, layout := range []string{time.RFC3339, time.DateTime, "2006-01-02 15:04"} {
+		playedAt, err = time.Parse(layout, date)
+		if err == nil {
+			return playedAt, false, nil
+		}
+	}
+
+	return time.Time{}, false, fmt.Errorf("date %q has to be like 2006-01-02 or 2006-01-02 15:04", date)
+}
+
+// ParseScore reads scores like "10:8" or "10-8", winners first. An empty score is nil.
+func ParseScore(score string) (*Score, error) {
+	if score == "" {
+		return nil, nil
+	}
+
+	team1, team2, ok := strings.Cut(strings.ReplaceAll(score, "-", ":"), ":")
+	if !ok {
+		return nil, fmt.Errorf("score %q has to be like 10:8", score)
+	}
+
+	team1Score, err1 := strconv.Atoi(strings.TrimSpace(team1))
+	team2Score, err2 := strconv.Atoi(strings.TrimSpace(team2))
+	if err1 != nil || err2 != nil || team1Score < 0 || team2Score < 0 {
+		return nil, fmt.Errorf("score %q has to be like 10:8", score)
+	}
+
+	return &Score{Team1: team1Score, Team2: team2Score}, nil
+}
+
+type Importer struct {
+	conn db.Connection
+}
+
+func NewImporter(conn db.Connection) Importer {
+	return Importer{conn: conn}
+}
+
+// ImportGames stores the records in one transaction. Records conflicting with the data
+// (unknown players, invalid teams, missing seasons) abort the import, duplicates of stored or
+// earlier records are skipped. A dry run reports the same without writing.
+func (importer Importer) ImportGames(ctx context.Context, records []ImportRecord, options ImportOptions) (ImportReport, error) {
+	var report ImportReport
+	err := db.Transaction(ctx, importer.conn, func(tx db.Connection) error {
+		var err error
+		report, err = importGames(
+			ctx,
+			NewGamesRepository(tx),
+			NewAttendanceRepository(tx),
+			players.NewPlayerRepository(tx),
+			seasons.NewSeasonsRepository(tx),
+			records,
+			options,
+		)
+		if err != nil {
+			return err
+		}
+
+		if options.DryRun {
+			return errImportDryRun
+		}
+
+		return nil
+	})
+	if errors.Is(err, errImportDryRun) {
+		return report, nil
+	}
+	if err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
+
+func importGames(
+	ctx context.Context,
+	gamesRepository GamesRepository,
+	attendanceRepository AttendanceRepository,
+	playerRepository players.PlayerRepository,
+	seasonsRepository seasons.SeasonsRepository,
+	records []ImportRecord,
+	options ImportOptions,
+) (ImportReport, error) {
+	report := ImportReport{CreatedPlayers: []string{}, Conflicts: []ImportProblem{}, Duplicates: []ImportProblem{}}
+
+	allSeasons, err := seasonsRepository.GetAll(ctx)
+	if err != nil {
+		return report, fmt.Errorf("import games: %w", err)
+	}
+	sort.Slice(allSeasons, func(p, q int) bool { return allSeasons[p].CreatedAt.Before(allSeasons[q].CreatedAt) })
+
+	playersByName, err := resolveImportPlayers(ctx, playerRepository, records, options, &report)
+	if err != nil {
+		return report, fmt.Errorf("import games: %w", err)
+	}
+
+	games := []*Game{}
+	gamesAttendances := [][]Attendance{}
+	imported := map[string]int{}
+	for _, record := range records {
+		conflict := validateImportRecord(record, playersByName)
+		if conflict == "" {
+			season, ok := getImportSeason(allSeasons, record)
+			if !ok {
+				conflict = "no season for the game"
+			} else if fields := validateTeamSizes(len(record.Winners), len(record.Losers), season); len(fields) > 0 {
+				conflict = describeImportFields(fields)
+			} else {
+				game, attendances := newImportGame(record, season, playersByName)
+
+				key := getImportGameKey(game.PlayedAt, attendances)
+				if line, ok := imported[key]; ok {
+					report.Duplicates = append(report.Duplicates, ImportProblem{
+						Line:    record.Line,
+						Message: fmt.Sprintf("same game as line %d", line),
+					})
+					continue
+				}
+
+				stored, err := isStoredGame(ctx, gamesRepository, attendanceRepository, game.PlayedAt, key)
+				if err != nil {
+					return report, fmt.Errorf("import games: %w", err)
+				}
+				if stored {
+					report.Duplicates = append(report.Duplicates, ImportProblem{
+						Line:    record.Line,
+						Message: "game is already stored",
+					})
+					continue
+				}
+
+				imported[key] = record.Line
+				games = append(games, game)
+				gamesAttendances = append(gamesAttendances, attendances)
+			}
+		}
+
+		if conflict != "" {
+			report.Conflicts = append(report.Conflicts, ImportProblem{Line: record.Line, Message: conflict})
+		}
+	}
+
+	if len(report.Conflicts) > 0 {
+		return report, ErrImportConflicts
+	}
+
+	err = gamesRepository.CreateGames(ctx, games, gamesAttendances)
+	if err != nil {
+		return report, fmt.Errorf("import games: %w", err)
+	}
+
+	report.Imported = len(games)
+
+	return report, nil
+}
+
+// resolveImportPlayers finds the players of all records, creating missing ones if enabled.
+// Created players joined with their first imported game.
+func resolveImportPlayers(
+	ctx context.Context,
+	playerRepository players.PlayerRepository,
+	records []ImportRecord,
+	options ImportOptions,
+	report *ImportReport,
+) (map[string]players.Player, error) {
+	names := []string{}
+	firstPlayedAt := map[string]time.Time{}
+	for _, record := range records {
+		for _, name := range append(slices.Clone(record.Winners), record.Losers...) {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+			if playedAt, ok := firstPlayedAt[name]; !ok || record.PlayedAt.Before(playedAt) {
+				firstPlayedAt[name] = record.PlayedAt
+			}
+		}
+	}
+
+	playersByName := map[string]players.Player{}
+	if len(names) == 0 {
+		return playersByName, nil
+	}
+
+	foundPlayers, err := playerRepository.FindPlayersByNames(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+	for _, player := range foundPlayers {
+		playersByName[player.Name] = player
+	}
+
+	if !options.CreatePlayers {
+		return playersByName, nil
+	}
+
+	for _, name := range names {
+		if _, ok := playersByName[name]; ok || name == "" {
+			continue
+		}
+
+		player := players.Player{Name: name, JoinedAt: firstPlayedAt[name]}
+		err = playerRepository.CreatePlayer(ctx, &player)
+		if err != nil {
+			return nil, err
+		}
+
+		playersByName[name] = player
+		report.CreatedPlayers = append(report.CreatedPlayers, name)
+	}
+
+	return playersByName, nil
+}
+
+func validateImportRecord(record ImportRecord, playersByName map[string]players.Player) string {
+	if len(record.Winners) == 0 || len(record.Losers) == 0 {
+		return "winners and losers are needed"
+	}
+
+	unknownNames := []string{}
+	for _, name := range append(slices.Clone(record.Winners), record.Losers...) {
+		if _, ok := playersByName[name]; !ok {
+			unknownNames = append(unknownNames, name)
+		}
+	}
+	if len(unknownNames) > 0 {
+		return fmt.Sprintf("unknown players %s", strings.Join(unknownNames, ", "))
+	}
+
+	for _, name := range record.Winners {
+		if slices.Contains(record.Losers, name) {
+			return fmt.Sprintf("%s is in both teams", name)
+		}
+	}
+
+	if record.Score != nil && !record.Draw && record.Score.Team1 < record.Score.Team2 {
+		return "winners have the lower score"
+	}
+
+	if record.Score != nil && record.Draw && record.Score.Team1 != record.Score.Team2 {
+		return "draw with different scores"
+	}
+
+	return ""
+}
+
+func describeImportFields(fields []FieldError) string {
+	messages := make([]string, len(fields))
+	for i, field := range fields {
+		messages[i] = field.Error()
+	}
+
+	return strings.Join(messages, ", ")
+}
+
+// getImportSeason returns the season named in the record, otherwise the last season started
+// before the game. Games before the first season belong to the first season.
+func getImportSeason(allSeasons []seasons.Season, record ImportRecord) (seasons.Season, bool) {
+	if len(allSeasons) == 0 {
+		return seasons.Season{}, false
+	}
+
+	if record.Season != "" {
+		for _, season := range allSeasons {
+			if season.Name == record.Season {
+				return season, true
+			}
+		}
+
+		return seasons.Season{}, false
+	}
+
+	season := allSeasons[0]
+	for _, nextSeason := range allSeasons[1:] {
+		if nextSeason.CreatedAt.After(record.PlayedAt) {
+			break
+		}
+
+		season = nextSeason
+	}
+
+	return season, true
+}
+
+func newImportGame(
+	record ImportRecord,
+	season seasons.Season,
+	playersByName map[string]players.Player,
+) (*Game, []Attendance) {
+	winnersOutcome, losersOutcome := OutcomeWin, OutcomeLoss
+	if record.Draw {
+		winnersOutcome, losersOutcome = OutcomeDraw, OutcomeDraw
+	}
+
+	game := &Game{PlayedAt: record.PlayedAt, Season: &season, SeasonID: season.ID, Score: record.Score}
+	attendances := append(
+		createAttendances(getImportTeam(record.Winners, playersByName), 1, winnersOutcome, Positions{}),
+		createAttendances(getImportTeam(record.Losers, playersByName), 2, losersOutcome, Positions{})...,
+	)
+
+	return game, attendances
+}
+
+func getImportTeam(names []string, playersByName map[string]players.Player) players.Team {
+	team := make(players.Team, len(names))
+	for i, name := range names {
+		team[i] = playersByName[name]
+	}
+
+	return team
+}
+
+// getImportGameKey identifies a game by its time, teams and outcome.
+func getImportGameKey(playedAt time.Time, attendances []Attendance) string {
+	members := make([]string, len(attendances))
+	for i, attendance := range attendances {
+		members[i] = fmt.Sprintf("%d:%d:%s", attendance.Team, attendance.PlayerID, attendance.Outcome)
+	}
+	sort.Strings(members)
+
+	return playedAt.UTC().Format(time.RFC3339Nano) + "|" + strings.Join(members, ",")
+}
+
+func isStoredGame(
+	ctx context.Context,
+	gamesRepository GamesRepository,
+	attendanceRepository AttendanceRepository,
+	playedAt time.Time,
+	key string,
+) (bool, error) {
+	gameIDs, err := gamesRepository.FindGameIDsPlayedAt(ctx, playedAt)
+	if err != nil {
+		return false, err
+	}
+
+	attendances, err := attendanceRepository.GetAttendancesForGames(ctx, gameIDs)
+	if err != nil {
+		return false, err
+	}
+
+	for _, gameAttendances := range attendances {
+		if getImportGameKey(playedAt, gameAttendances) == key {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,137 @@
+package games
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spie/fskick/internal/db"
+	"github.com/spie/fskick/internal/players"
+	"github.com/spie/fskick/internal/seasons"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseImportCSV(t *testing.T) {
+	tests := map[string]struct {
+		csv        string
+		assertions []func(t *testing.T, records []ImportRecord, err error)
+	}{
+		"with games on one day": {
+			csv: "date,winners,losers,score\n" +
+				"2020-05-01,\"alice,bob\",carol;dave,10:8\n" +
+				"2020-05-01,alice;bob,carol;dave,10-10\n",
+			assertions: []func(t *testing.T, records []ImportRecord, err error){
+				func(t *testing.T, records []ImportRecord, err error) {
+					assert.NoError(t, err)
+					assert.Len(t, records, 2)
+					assert.Equal(t, []string{"alice", "bob"}, records[0].Winners)
+					assert.Equal(t, []string{"carol", "dave"}, records[0].Losers)
+					assert.Equal(t, &Score{Team1: 10, Team2: 8}, records[0].Score)
+					assert.False(t, records[0].Draw)
+					assert.Equal(t, 2, records[0].Line)
+				},
+				func(t *testing.T, records []ImportRecord, err error) {
+					assert.True(t, records[1].Draw)
+					assert.Equal(t, time.Minute, records[1].PlayedAt.Sub(records[0].PlayedAt))
+				},
+			},
+		},
+		"with missing column": {
+			csv: "date,winners\n2020-05-01,alice\n",
+			assertions: []func(t *testing.T, records []ImportRecord, err error){
+				func(t *testing.T, records []ImportRecord, err error) {
+					assert.ErrorIs(t, err, ErrImportFormat)
+				},
+			},
+		},
+		"with invalid score": {
+			csv: "date,winners,losers,score\n2020-05-01,alice,bob,ten\n",
+			assertions: []func(t *testing.T, records []ImportRecord, err error){
+				func(t *testing.T, records []ImportRecord, err error) {
+					assert.ErrorIs(t, err, ErrImportFormat)
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			records, err := ParseImportCSV(strings.NewReader(test.csv))
+
+			for _, assertion := range test.assertions {
+				assertion(t, records, err)
+			}
+		})
+	}
+}
+
+func TestParseImportJSONLines(t *testing.T) {
+	records, err := ParseImportJSONLines(strings.NewReader(
+		`{"date": "2020-05-01 18:30", "winners": ["alice"], "losers": ["bob"], "season": "S1"}` + "\n\n" +
+			`{"date": "2020-05-02", "winners": ["bob"], "losers": ["alice"], "draw": true}` + "\n",
+	))
+
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+	assert.Equal(t, time.Date(2020, 5, 1, 18, 30, 0, 0, time.UTC), records[0].PlayedAt)
+	assert.Equal(t, "S1", records[0].Season)
+	assert.Equal(t, 3, records[1].Line)
+	assert.True(t, records[1].Draw)
+}
+
+func TestValidateImportRecord(t *testing.T) {
+	playersByName := map[string]players.Player{"alice": {}, "bob": {}}
+
+	tests := map[string]struct {
+		record   ImportRecord
+		conflict string
+	}{
+		"with valid record": {
+			record: ImportRecord{Winners: []string{"alice"}, Losers: []string{"bob"}},
+		},
+		"with unknown player": {
+			record:   ImportRecord{Winners: []string{"alice"}, Losers: []string{"carol"}},
+			conflict: "unknown players carol",
+		},
+		"with player in both teams": {
+			record:   ImportRecord{Winners: []string{"alice"}, Losers: []string{"alice", "bob"}},
+			conflict: "alice is in both teams",
+		},
+		"with winners' lower score": {
+			record: ImportRecord{
+				Winners: []string{"alice"},
+				Losers:  []string{"bob"},
+				Score:   &Score{Team1: 3, Team2: 10},
+			},
+			conflict: "winners have the lower score",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.conflict, validateImportRecord(test.record, playersByName))
+		})
+	}
+}
+
+func TestGetImportSeason(t *testing.T) {
+	allSeasons := []seasons.Season{
+		{Model: db.Model{ID: 1, CreatedAt: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)}, Name: "2021"},
+		{Model: db.Model{ID: 2, CreatedAt: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)}, Name: "2022"},
+	}
+
+	season, ok := getImportSeason(allSeasons, ImportRecord{PlayedAt: time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)})
+	assert.True(t, ok)
+	assert.Equal(t, "2021", season.Name)
+
+	season, ok = getImportSeason(allSeasons, ImportRecord{PlayedAt: time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC)})
+	assert.True(t, ok)
+	assert.Equal(t, "2022", season.Name)
+
+	season, ok = getImportSeason(allSeasons, ImportRecord{Season: "2021"})
+	assert.True(t, ok)
+	assert.Equal(t, uint(1), season.ID)
+
+	_, ok = getImportSeason(allSeasons, ImportRecord{Season: "unknown"})
+	assert.False(t, ok)
+}
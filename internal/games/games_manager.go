@@ -1,76 +1,334 @@
 package games
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"math"
+	"slices"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/spie/fskick/internal/cache"
 	"github.com/spie/fskick/internal/players"
 	"github.com/spie/fskick/internal/seasons"
 )
 
 type PlayerStats struct {
 	PlayerAttendance
-	PointsRatio float64
-	Points      int
-	WinRatio    float64
-	GamesRatio  float64
-	Position    int
+	PointsRatio     float64
+	Points          int
+	WinRatio        float64
+	GamesRatio      float64
+	Position        int
+	DefenseWinRatio float64
+	OffenseWinRatio float64
+}
+
+// BestPosition returns the position with the higher win ratio, or no position if the player
+// never played on a position.
+func (stats PlayerStats) BestPosition() players.Position {
+	if stats.DefenseGames == 0 && stats.OffenseGames == 0 {
+		return players.PositionNone
+	}
+
+	if stats.DefenseGames > 0 && (stats.OffenseGames == 0 || stats.DefenseWinRatio >= stats.OffenseWinRatio) {
+		return players.PositionDefense
+	}
+
+	return players.PositionOffense
+}
+
+const maxIdempotencyKeyLength = 64
+
+// Positions maps player UUIDs to the position played in a game.
+type Positions map[string]players.Position
+
+// Points awarded per won and drawn game.
+type Points struct {
+	Win  int
+	Draw int
+}
+
+func DefaultPoints() Points {
+	return Points{Win: 3, Draw: 1}
 }
 
 type Manager struct {
 	gameRepository       GamesRepository
 	attendanceRepository AttendanceRepository
 	seasonsManager       seasons.Manager
+	points               Points
+	duplicateWindow      time.Duration
+	cache                *cache.Cache
+	inTransaction        bool
 }
 
 func NewManager(
 	gameRepository GamesRepository,
 	attendanceRepository AttendanceRepository,
 	seasonsManager seasons.Manager,
+	points Points,
 ) Manager {
 	return Manager{
 		gameRepository:       gameRepository,
 		attendanceRepository: attendanceRepository,
 		seasonsManager:       seasonsManager,
+		points:               points,
 	}
 }
 
-func (manager Manager) CreateGame(
-	playedAt time.Time,
-	winners players.Team,
-	losers players.Team,
-) (*Game, error) {
-	activeSeason, err := manager.seasonsManager.ActiveSeason()
+// WithCache returns a manager caching the stats in c. The cache is invalidated when a game is
+// created.
+func (manager Manager) WithCache(c *cache.Cache) Manager {
+	manager.cache = c
+
+	return manager
+}
+
+// WithTransaction returns the manager working with the repositories of a running transaction.
+// It leaves invalidating the cache to the caller after the commit, see InvalidateCache.
+func (manager Manager) WithTransaction(gameRepository GamesRepository, attendanceRepository AttendanceRepository) Manager {
+	manager.gameRepository = gameRepository
+	manager.attendanceRepository = attendanceRepository
+	manager.inTransaction = true
+
+	return manager
+}
+
+// InvalidateCache drops the cached stats, after games were created in a committed transaction.
+func (manager Manager) InvalidateCache() {
+	manager.cache.Invalidate()
+}
+
+// WithDuplicateWindow returns a manager refusing to create a game if the same teams with the
+// same outcome played within the window before or after it. A window of 0 disables the check.
+func (manager Manager) WithDuplicateWindow(window time.Duration) Manager {
+	manager.duplicateWindow = window
+
+	return manager
+}
+
+// GameEntry is a game to create, the winners against the losers. For a draw both teams get
+// the draw outcome, winners and losers are just the two teams then. The score is optional,
+// winners first. RecordedBy is the player who entered the game, if known.
+type GameEntry struct {
+	PlayedAt   time.Time
+	Winners    players.Team
+	Losers     players.Team
+	Draw       bool
+	Positions  Positions
+	Score      *Score
+	RecordedBy *players.Player
+	// IdempotencyKey is sent by clients retrying the creation, the game already created with
+	// the key by the same recorder is returned then instead of creating it again. Reusing the
+	// key for another game fails with ErrIdempotencyKeyReused.
+	IdempotencyKey string
+	// AllowDuplicate creates the game even if the same teams with the same outcome played
+	// within the duplicate window.
+	AllowDuplicate bool
+}
+
+// fingerprint identifies the game of the entry. The players are kept in their order, retries
+// send the same entry.
+func (entry GameEntry) fingerprint() string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s|%t|", entry.PlayedAt.UTC().Format(time.RFC3339Nano), entry.Draw)
+	for _, team := range []players.Team{entry.Winners, entry.Losers} {
+		for _, player := range team {
+			fmt.Fprintf(hash, "%s:%s,", player.UUID, entry.Positions[player.UUID])
+		}
+		fmt.Fprint(hash, "|")
+	}
+	if entry.Score != nil {
+		fmt.Fprintf(hash, "%d:%d", entry.Score.Team1, entry.Score.Team2)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// CreateGame stores the game of the entry in the active season. Without a time the game is
+// played now. Entries breaking the rules of the season fail with a ValidationError.
+func (manager Manager) CreateGame(ctx context.Context, entry GameEntry) (*Game, error) {
+	if len(entry.IdempotencyKey) > maxIdempotencyKeyLength {
+		return &Game{}, fmt.Errorf("%w: longer than %d characters", ErrInvalidIdempotencyKey, maxIdempotencyKeyLength)
+	}
+
+	if entry.IdempotencyKey != "" {
+		game, err := manager.getGameByIdempotencyKey(ctx, entry)
+		if !errors.Is(err, ErrGameNotFound) {
+			return &game, err
+		}
+	}
+
+	activeSeason, err := manager.seasonsManager.ActiveSeason(ctx)
+	if err != nil {
+		return &Game{}, err
+	}
+
+	err = validateGameEntry(entry, activeSeason, time.Now())
 	if err != nil {
 		return &Game{}, err
 	}
 
+	playedAt := entry.PlayedAt
 	if playedAt.IsZero() {
 		playedAt = time.Now()
 	}
 
-	game := &Game{Season: &activeSeason, PlayedAt: playedAt}
+	winnersOutcome, losersOutcome := OutcomeWin, OutcomeLoss
+	if entry.Draw {
+		winnersOutcome, losersOutcome = OutcomeDraw, OutcomeDraw
+	}
+
+	game := &Game{
+		Season:         &activeSeason,
+		PlayedAt:       playedAt,
+		RecordedBy:     entry.RecordedBy,
+		Score:          entry.Score,
+		IdempotencyKey: entry.IdempotencyKey,
+
+		idempotencyFingerprint: entry.fingerprint(),
+	}
 	attendances := append(
-		createAttendances(winners, true),
-		createAttendances(losers, false)...,
+		createAttendances(entry.Winners, 1, winnersOutcome, entry.Positions),
+		createAttendances(entry.Losers, 2, losersOutcome, entry.Positions)...,
+	)
+
+	// the check for duplicates and the insert run in one transaction, with the season locked
+	// against games added in the meantime
+	err = manager.gameRepository.Transaction(
+		ctx,
+		func(gamesRepository GamesRepository, attendanceRepository AttendanceRepository) error {
+			if !entry.AllowDuplicate && manager.duplicateWindow > 0 {
+				err := gamesRepository.LockSeason(ctx, activeSeason.ID)
+				if err != nil {
+					return err
+				}
+
+				err = manager.checkDuplicate(ctx, gamesRepository, attendanceRepository, playedAt, attendances)
+				if err != nil {
+					return err
+				}
+			}
+
+			return gamesRepository.CreateGames(ctx, []*Game{game}, [][]Attendance{attendances})
+		},
 	)
+	// a retry with the same key created the game in the meantime. A running transaction is
+	// aborted by the failed insert, the caller has to retry it.
+	if errors.Is(err, ErrIdempotencyKeyUsed) && !manager.inTransaction {
+		existingGame, err := manager.getGameByIdempotencyKey(ctx, entry)
 
-	err = manager.gameRepository.CreateGame(game, attendances)
+		return &existingGame, err
+	}
 	if err != nil {
 		return &Game{}, err
 	}
 
+	if !manager.inTransaction {
+		manager.cache.Invalidate()
+	}
+
 	return game, nil
 }
 
-func createAttendances(team players.Team, win bool) []Attendance {
+// checkDuplicate fails with ErrDuplicateGame if the same teams with the same outcome played
+// within the duplicate window around playedAt.
+func (manager Manager) checkDuplicate(
+	ctx context.Context,
+	gamesRepository GamesRepository,
+	attendanceRepository AttendanceRepository,
+	playedAt time.Time,
+	attendances []Attendance,
+) error {
+	games, err := gamesRepository.FindGamesPlayedBetween(
+		ctx,
+		playedAt.Add(-manager.duplicateWindow),
+		playedAt.Add(manager.duplicateWindow),
+	)
+	if err != nil {
+		return err
+	}
+	if len(games) == 0 {
+		return nil
+	}
+
+	gameIDs := make([]uint, len(games))
+	for i, game := range games {
+		gameIDs[i] = game.ID
+	}
+
+	gamesAttendances, err := attendanceRepository.GetAttendancesForGames(ctx, gameIDs)
+	if err != nil {
+		return err
+	}
+
+	key := getTeamsKey(attendances)
+	for _, game := range games {
+		if getTeamsKey(gamesAttendances[game.ID]) == key {
+			return fmt.Errorf(
+				"%w: game %s played at %s",
+				ErrDuplicateGame,
+				game.UUID,
+				game.PlayedAt.Local().Format("2006-01-02 15:04"),
+			)
+		}
+	}
+
+	return nil
+}
+
+// getTeamsKey identifies the teams of a game with their outcome, no matter which team is
+// team 1.
+func getTeamsKey(attendances []Attendance) string {
+	members := map[int][]string{}
+	outcomes := map[int]Outcome{}
+	for _, attendance := range attendances {
+		members[attendance.Team] = append(members[attendance.Team], strconv.FormatUint(uint64(attendance.PlayerID), 10))
+		outcomes[attendance.Team] = attendance.Outcome
+	}
+
+	teams := make([]string, 0, len(members))
+	for team, playerIDs := range members {
+		sort.Strings(playerIDs)
+		teams = append(teams, fmt.Sprintf("%s:%s", outcomes[team], strings.Join(playerIDs, ",")))
+	}
+	sort.Strings(teams)
+
+	return strings.Join(teams, "|")
+}
+
+func validateScore(score *Score, draw bool) error {
+	if score == nil {
+		return nil
+	}
+
+	if score.Team1 < 0 || score.Team2 < 0 {
+		return fmt.Errorf("%w: goals can not be negative", ErrInvalidScore)
+	}
+	if draw && score.Team1 != score.Team2 {
+		return fmt.Errorf("%w: draw with different scores", ErrInvalidScore)
+	}
+	if !draw && score.Team1 <= score.Team2 {
+		return fmt.Errorf("%w: winners need the higher score", ErrInvalidScore)
+	}
+
+	return nil
+}
+
+func createAttendances(team players.Team, teamNumber int, outcome Outcome, positions Positions) []Attendance {
 	attendances := make([]Attendance, len(team))
 
 	for i, player := range team {
 		attendances[i] = Attendance{
-			Win:      win,
+			Team:     teamNumber,
+			Outcome:  outcome,
+			Position: positions[player.UUID],
 			PlayerID: player.ID,
 		}
 	}
@@ -78,127 +336,362 @@ func createAttendances(team players.Team, win bool) []Attendance {
 	return attendances
 }
 
-func (manager Manager) GetGamesCount() (int, error) {
-	return manager.gameRepository.Count()
+func (manager Manager) ListGames(ctx context.Context, filter GamesFilter) (GamesPage, error) {
+	err := filter.validate()
+	if err != nil {
+		return GamesPage{}, err
+	}
+
+	var cursor *gamesCursor
+	if filter.Cursor != "" {
+		decodedCursor, err := decodeGamesCursor(filter.Cursor)
+		if err != nil {
+			return GamesPage{}, err
+		}
+
+		cursor = &decodedCursor
+	}
+
+	limit := filter.getLimit()
+
+	games, err := manager.gameRepository.ListGames(ctx, filter, cursor, limit)
+	if err != nil {
+		return GamesPage{}, fmt.Errorf("list games: %w", err)
+	}
+
+	page := GamesPage{Games: games}
+	if len(games) > limit {
+		page.Games = games[:limit]
+		page.NextCursor = page.Games[limit-1].cursor.encode()
+	}
+
+	gameIDs := make([]uint, len(page.Games))
+	for i, game := range page.Games {
+		gameIDs[i] = game.ID
+	}
+
+	attendances, err := manager.attendanceRepository.GetAttendancesForGames(ctx, gameIDs)
+	if err != nil {
+		return GamesPage{}, fmt.Errorf("list games: %w", err)
+	}
+
+	for i, game := range page.Games {
+		page.Games[i].Attendances = attendances[game.ID]
+	}
+
+	return page, nil
+}
+
+func (manager Manager) GetGameByUUID(ctx context.Context, uuid string) (Game, error) {
+	game, err := manager.gameRepository.FindGameByUUID(ctx, uuid)
+	if err != nil {
+		return Game{}, err
+	}
+
+	return manager.withAttendances(ctx, game)
+}
+
+// getGameByIdempotencyKey returns the game created with the key of the entry by its recorder.
+// Games created from another entry with the key fail with ErrIdempotencyKeyReused, games
+// stored before fingerprints were kept are not checked.
+func (manager Manager) getGameByIdempotencyKey(ctx context.Context, entry GameEntry) (Game, error) {
+	game, err := manager.gameRepository.FindGameByIdempotencyKey(ctx, entry.RecordedBy, entry.IdempotencyKey)
+	if err != nil {
+		return Game{}, err
+	}
+	if game.idempotencyFingerprint != "" && game.idempotencyFingerprint != entry.fingerprint() {
+		return Game{}, fmt.Errorf("%w: %s", ErrIdempotencyKeyReused, entry.IdempotencyKey)
+	}
+
+	return manager.withAttendances(ctx, game)
+}
+
+func (manager Manager) withAttendances(ctx context.Context, game Game) (Game, error) {
+	attendances, err := manager.attendanceRepository.GetAttendancesForGames(ctx, []uint{game.ID})
+	if err != nil {
+		return Game{}, fmt.Errorf("get game: %w", err)
+	}
+
+	game.Attendances = attendances[game.ID]
+
+	return game, nil
+}
+
+// PlayerImpact compares the season stats of a participant right before and after a game.
+// Before is zero for the player's first game of the season.
+type PlayerImpact struct {
+	Attendance
+	Before PlayerStats
+	After  PlayerStats
+}
+
+func (impact PlayerImpact) PointsRatioChange() float64 {
+	return impact.After.PointsRatio - impact.Before.PointsRatio
+}
+
+// PositionChange returns the number of places the player moved up in the season table,
+// or 0 if the player had no position before.
+func (impact PlayerImpact) PositionChange() int {
+	if impact.Before.Position == 0 {
+		return 0
+	}
+
+	return impact.Before.Position - impact.After.Position
+}
+
+// GetGameImpacts returns the impact of the game on the season table for each participant.
+func (manager Manager) GetGameImpacts(ctx context.Context, game Game) ([]PlayerImpact, error) {
+	impacts, err := cache.Remember(manager.cache, fmt.Sprintf("impacts:%s", game.UUID), func() ([]PlayerImpact, error) {
+		return manager.loadGameImpacts(ctx, game)
+	})
+
+	return slices.Clone(impacts), err
+}
+
+func (manager Manager) loadGameImpacts(ctx context.Context, game Game) ([]PlayerImpact, error) {
+	before, err := manager.getPlayerStatsForSeasonUntil(ctx, game, false)
+	if err != nil {
+		return nil, fmt.Errorf("get game impacts: %w", err)
+	}
+
+	after, err := manager.getPlayerStatsForSeasonUntil(ctx, game, true)
+	if err != nil {
+		return nil, fmt.Errorf("get game impacts: %w", err)
+	}
+
+	impacts := make([]PlayerImpact, len(game.Attendances))
+	for i, attendance := range game.Attendances {
+		impacts[i] = PlayerImpact{
+			Attendance: attendance,
+			Before:     before[attendance.PlayerID],
+			After:      after[attendance.PlayerID],
+		}
+	}
+
+	return impacts, nil
+}
+
+func (manager Manager) getPlayerStatsForSeasonUntil(ctx context.Context, game Game, inclusive bool) (map[uint]PlayerStats, error) {
+	playerAttendances, err := manager.attendanceRepository.CollectPlayerAttendancesForSeasonUntil(
+		ctx,
+		*game.Season,
+		game,
+		inclusive,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	gamesCount, err := manager.gameRepository.CountForSeasonUntil(ctx, *game.Season, game, inclusive)
+	if err != nil {
+		return nil, err
+	}
+
+	maxGamesCount, err := manager.gameRepository.MaxGamesForSeasonUntil(ctx, *game.Season, game, inclusive)
+	if err != nil {
+		return nil, err
+	}
+
+	playerStats := createPlayerStats(playerAttendances, gamesCount, maxGamesCount, manager.points)
+	sortPlayerStats(playerStats, "pointsRatio")
+
+	playerStatsByID := map[uint]PlayerStats{}
+	for _, stats := range playerStats {
+		playerStatsByID[stats.ID] = stats
+	}
+
+	return playerStatsByID, nil
+}
+
+func (manager Manager) GetGamesCount(ctx context.Context, mode Mode) (int, error) {
+	return cache.Remember(manager.cache, fmt.Sprintf("count:%s", mode), func() (int, error) {
+		return manager.gameRepository.Count(ctx, mode)
+	})
+}
+
+// GetGamesCountRecordedSince counts the games recorded since the given time, it is not cached.
+func (manager Manager) GetGamesCountRecordedSince(ctx context.Context, since time.Time) (int, error) {
+	return manager.gameRepository.CountRecordedSince(ctx, since)
+}
+
+func (manager Manager) GetGamesCountForSeason(ctx context.Context, season seasons.Season, mode Mode) (int, error) {
+	return cache.Remember(manager.cache, fmt.Sprintf("count:season:%d:%s", season.ID, mode), func() (int, error) {
+		return manager.gameRepository.CountForSeason(ctx, season, mode)
+	})
+}
+
+func (manager Manager) GetGamesCountForPlayer(ctx context.Context, player players.Player) (int, error) {
+	return cache.Remember(manager.cache, fmt.Sprintf("count:player:%d", player.ID), func() (int, error) {
+		return manager.gameRepository.CountForPlayer(ctx, player)
+	})
+}
+
+func (manager Manager) GetPlayerStatsForSeason(
+	ctx context.Context,
+	season seasons.Season,
+	mode Mode,
+	sort string,
+) ([]PlayerStats, error) {
+	key := fmt.Sprintf("stats:season:%d:%s:%s", season.ID, mode, sort)
+	return manager.rememberPlayerStats(ctx, key, func() ([]PlayerStats, error) {
+		return manager.loadPlayerStatsForSeason(ctx, season, mode, sort)
+	})
+}
+
+func (manager Manager) GetAllPlayerStats(ctx context.Context, mode Mode, sort string) ([]PlayerStats, error) {
+	return manager.rememberPlayerStats(ctx, fmt.Sprintf("stats:all:%s:%s", mode, sort), func() ([]PlayerStats, error) {
+		return manager.loadAllPlayerStats(ctx, mode, sort)
+	})
+}
+
+func (manager Manager) GetFellowPlayerStats(ctx context.Context, player players.Player, sort string) ([]PlayerStats, error) {
+	return manager.rememberPlayerStats(ctx, fmt.Sprintf("stats:fellows:%d:%s", player.ID, sort), func() ([]PlayerStats, error) {
+		return manager.loadFellowPlayerStats(ctx, player, sort)
+	})
 }
 
-func (manager Manager) GetGamesCountForSeason(season seasons.Season) (int, error) {
-	return manager.gameRepository.CountForSeason(season)
+func (manager Manager) GetOponentPlayerStats(ctx context.Context, player players.Player, sort string) ([]PlayerStats, error) {
+	return manager.rememberPlayerStats(ctx, fmt.Sprintf("stats:oponents:%d:%s", player.ID, sort), func() ([]PlayerStats, error) {
+		return manager.loadOponentPlayerStats(ctx, player, sort)
+	})
 }
 
-func (manager Manager) GetGamesCountForPlayer(player players.Player) (int, error) {
-	return manager.gameRepository.CountForPlayer(player)
+// rememberPlayerStats returns a copy of the cached stats, callers may sort or change them.
+func (manager Manager) rememberPlayerStats(ctx context.Context, key string, load func() ([]PlayerStats, error)) ([]PlayerStats, error) {
+	playerStats, err := cache.Remember(manager.cache, key, load)
+
+	return slices.Clone(playerStats), err
 }
 
-func (manager Manager) GetPlayerStatsForSeason(season seasons.Season, sort string) ([]PlayerStats, error) {
-	playerAttendances, err := manager.attendanceRepository.CollectPlayerAttendancesForSeason(season)
+func (manager Manager) loadPlayerStatsForSeason(
+	ctx context.Context,
+	season seasons.Season,
+	mode Mode,
+	sort string,
+) ([]PlayerStats, error) {
+	playerAttendances, err := manager.attendanceRepository.CollectPlayerAttendancesForSeason(ctx, season, mode)
 	if err != nil {
 		return nil, fmt.Errorf("get player stats: %w", err)
 	}
 
-	gamesCount, err := manager.gameRepository.CountForSeason(season)
+	gamesCount, err := manager.gameRepository.CountForSeason(ctx, season, mode)
 	if err != nil {
 		return nil, fmt.Errorf("get player stats: %w", err)
 	}
 
-	maxGamesCount, err := manager.gameRepository.MaxGamesForSeason(season)
+	maxGamesCount, err := manager.gameRepository.MaxGamesForSeason(ctx, season, mode)
 	if err != nil {
 		return nil, fmt.Errorf("get player stats: %w", err)
 	}
 
-	playerStats := createPlayerStats(playerAttendances, gamesCount, maxGamesCount)
+	playerStats := createPlayerStats(playerAttendances, gamesCount, maxGamesCount, manager.points)
 
 	sortPlayerStats(playerStats, sort)
 
 	return playerStats, nil
 }
 
-func (manager Manager) GetAllPlayerStats(sort string) ([]PlayerStats, error) {
-	playerAttendances, err := manager.attendanceRepository.CollectAllPlayerAttendances()
+func (manager Manager) loadAllPlayerStats(ctx context.Context, mode Mode, sort string) ([]PlayerStats, error) {
+	playerAttendances, err := manager.attendanceRepository.CollectAllPlayerAttendances(ctx, mode)
 	if err != nil {
 		return nil, fmt.Errorf("get player stats: %w", err)
 	}
 
-	gamesCount, err := manager.gameRepository.Count()
+	gamesCount, err := manager.gameRepository.Count(ctx, mode)
 	if err != nil {
 		return nil, fmt.Errorf("get player stats: %w", err)
 	}
 
-	maxGamesCount, err := manager.gameRepository.MaxGames()
+	maxGamesCount, err := manager.gameRepository.MaxGames(ctx, mode)
 	if err != nil {
 		return nil, fmt.Errorf("get player stats: %w", err)
 	}
 
-	playerStats := createPlayerStats(playerAttendances, gamesCount, maxGamesCount)
+	playerStats := createPlayerStats(playerAttendances, gamesCount, maxGamesCount, manager.points)
 
 	sortPlayerStats(playerStats, sort)
 
 	return playerStats, nil
 }
 
-func (manager Manager) GetFellowPlayerStats(player players.Player, sort string) ([]PlayerStats, error) {
-	playerAttendances, err := manager.attendanceRepository.CollectFellowPlayerAttendances(player)
+func (manager Manager) loadFellowPlayerStats(ctx context.Context, player players.Player, sort string) ([]PlayerStats, error) {
+	playerAttendances, err := manager.attendanceRepository.CollectFellowPlayerAttendances(ctx, player)
 	if err != nil {
 		return nil, fmt.Errorf("get fellow player stats: %w", err)
 	}
 
-	gamesCount, err := manager.gameRepository.CountForPlayer(player)
+	gamesCount, err := manager.gameRepository.CountForPlayer(ctx, player)
 	if err != nil {
 		return nil, fmt.Errorf("get player's games count: %w", err)
 	}
 
-	maxGamesCount, err := manager.gameRepository.MaxGamesForPlayer(player)
+	maxGamesCount, err := manager.gameRepository.MaxGamesForPlayer(ctx, player)
 	if err != nil {
 		return nil, fmt.Errorf("get player stats: %w", err)
 	}
 
-	playerStats := createPlayerStats(playerAttendances, gamesCount, maxGamesCount)
+	playerStats := createPlayerStats(playerAttendances, gamesCount, maxGamesCount, manager.points)
 	sortPlayerStats(playerStats, sort)
 
 	return playerStats, nil
 }
 
-func (manager Manager) GetOponentPlayerStats(player players.Player, sort string) ([]PlayerStats, error) {
-	playerAttendances, err := manager.attendanceRepository.CollectOponentPlayerAttendances(player)
+func (manager Manager) loadOponentPlayerStats(ctx context.Context, player players.Player, sort string) ([]PlayerStats, error) {
+	playerAttendances, err := manager.attendanceRepository.CollectOponentPlayerAttendances(ctx, player)
 	if err != nil {
 		return nil, fmt.Errorf("get oponent player stats: %w", err)
 	}
 
-	gamesCount, err := manager.gameRepository.CountForPlayer(player)
+	gamesCount, err := manager.gameRepository.CountForPlayer(ctx, player)
 	if err != nil {
 		return nil, fmt.Errorf("get player's games count: %w", err)
 	}
 
-	maxGamesCount, err := manager.gameRepository.MaxGamesForPlayer(player)
+	maxGamesCount, err := manager.gameRepository.MaxGamesForPlayer(ctx, player)
 	if err != nil {
 		return nil, fmt.Errorf("get player stats: %w", err)
 	}
 
-	playerStats := createPlayerStats(playerAttendances, gamesCount, maxGamesCount)
+	playerStats := createPlayerStats(playerAttendances, gamesCount, maxGamesCount, manager.points)
 	sortPlayerStats(playerStats, sort)
 
 	return playerStats, nil
 }
 
-func (manager Manager) GetAttendancesForPlayer(player players.Player) ([]Attendance, error) {
-	return manager.attendanceRepository.GetAttendancesForPlayer(player)
+func (manager Manager) GetAttendancesForPlayer(ctx context.Context, player players.Player) ([]Attendance, error) {
+	return manager.attendanceRepository.GetAttendancesForPlayer(ctx, player)
 }
 
-func createPlayerStats(playerAttendances []PlayerAttendance, gamesCount int, maxGamesCount int) []PlayerStats {
+func createPlayerStats(
+	playerAttendances []PlayerAttendance,
+	gamesCount int,
+	maxGamesCount int,
+	points Points,
+) []PlayerStats {
 	playerStats := make([]PlayerStats, len(playerAttendances))
 	for i, playerAttendance := range playerAttendances {
 		stats := PlayerStats{PlayerAttendance: playerAttendance}
 		stats.WinRatio = float64(stats.Wins) / float64(stats.Games)
 		stats.GamesRatio = float64(stats.Games) / float64(gamesCount)
-		stats.Points = stats.Wins * 3
+		stats.Points = stats.Wins*points.Win + stats.Draws*points.Draw
 		stats.PointsRatio = float64(stats.Points) /
 			math.Max(float64(stats.Games), float64(maxGamesCount/2))
+		stats.DefenseWinRatio = getRatio(stats.DefenseWins, stats.DefenseGames)
+		stats.OffenseWinRatio = getRatio(stats.OffenseWins, stats.OffenseGames)
 		playerStats[i] = stats
 	}
 
 	return playerStats
 }
 
+func getRatio(count int, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return float64(count) / float64(total)
+}
+
 func sortPlayerStats(playerStats []PlayerStats, sortName string) {
 	if len(playerStats) <= 1 {
 		return
@@ -261,6 +754,28 @@ func getSortAndPositionFunc(
 			func(p PlayerStats) float64 {
 				return p.WinRatio
 			}
+	case "defenseWinRatio":
+		return func(p, q int) bool {
+				if (playerStats)[p].DefenseWinRatio == (playerStats)[q].DefenseWinRatio {
+					return (playerStats)[p].DefenseGames > (playerStats)[q].DefenseGames
+				}
+
+				return (playerStats)[p].DefenseWinRatio > (playerStats)[q].DefenseWinRatio
+			},
+			func(p PlayerStats) float64 {
+				return p.DefenseWinRatio
+			}
+	case "offenseWinRatio":
+		return func(p, q int) bool {
+				if (playerStats)[p].OffenseWinRatio == (playerStats)[q].OffenseWinRatio {
+					return (playerStats)[p].OffenseGames > (playerStats)[q].OffenseGames
+				}
+
+				return (playerStats)[p].OffenseWinRatio > (playerStats)[q].OffenseWinRatio
+			},
+			func(p PlayerStats) float64 {
+				return p.OffenseWinRatio
+			}
 	default:
 		return func(p, q int) bool {
 				if (playerStats)[p].PointsRatio == (playerStats)[q].PointsRatio {
@@ -0,0 +1,193 @@
+package games
+
+import (
+	"testing"
+
+	"github.com/spie/fskick/internal/players"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreatePlayerStats(t *testing.T) {
+	tests := map[string]struct {
+		playerAttendances []PlayerAttendance
+		gamesCount        int
+		maxGamesCount     int
+		assertions        []func(t *testing.T, playerStats []PlayerStats)
+	}{
+		"with points and ratios": {
+			playerAttendances: []PlayerAttendance{
+				{Player: players.Player{Name: "test_player"}, Wins: 3, Games: 4},
+			},
+			gamesCount:    8,
+			maxGamesCount: 4,
+			assertions: []func(t *testing.T, playerStats []PlayerStats){
+				func(t *testing.T, playerStats []PlayerStats) {
+					assert.Len(t, playerStats, 1)
+					assert.Equal(t, 9, playerStats[0].Points)
+					assert.Equal(t, 0.75, playerStats[0].WinRatio)
+					assert.Equal(t, 0.5, playerStats[0].GamesRatio)
+					assert.Equal(t, 2.25, playerStats[0].PointsRatio)
+				},
+			},
+		},
+		"with draw points": {
+			playerAttendances: []PlayerAttendance{
+				{Player: players.Player{Name: "test_player"}, Wins: 1, Draws: 2, Games: 4},
+			},
+			gamesCount:    4,
+			maxGamesCount: 4,
+			assertions: []func(t *testing.T, playerStats []PlayerStats){
+				func(t *testing.T, playerStats []PlayerStats) {
+					assert.Equal(t, 5, playerStats[0].Points)
+					assert.Equal(t, 0.25, playerStats[0].WinRatio)
+					assert.Equal(t, 1.25, playerStats[0].PointsRatio)
+				},
+			},
+		},
+		"with position win ratios": {
+			playerAttendances: []PlayerAttendance{
+				{
+					Player:       players.Player{Name: "test_player"},
+					Wins:         3,
+					Games:        5,
+					DefenseWins:  2,
+					DefenseGames: 2,
+					OffenseWins:  1,
+					OffenseGames: 2,
+				},
+			},
+			gamesCount:    5,
+			maxGamesCount: 5,
+			assertions: []func(t *testing.T, playerStats []PlayerStats){
+				func(t *testing.T, playerStats []PlayerStats) {
+					assert.Equal(t, 1.0, playerStats[0].DefenseWinRatio)
+					assert.Equal(t, 0.5, playerStats[0].OffenseWinRatio)
+					assert.Equal(t, players.PositionDefense, playerStats[0].BestPosition())
+				},
+			},
+		},
+		"without positions": {
+			playerAttendances: []PlayerAttendance{
+				{Player: players.Player{Name: "test_player"}, Wins: 1, Games: 1},
+			},
+			gamesCount:    1,
+			maxGamesCount: 1,
+			assertions: []func(t *testing.T, playerStats []PlayerStats){
+				func(t *testing.T, playerStats []PlayerStats) {
+					assert.Zero(t, playerStats[0].DefenseWinRatio)
+					assert.Zero(t, playerStats[0].OffenseWinRatio)
+					assert.Equal(t, players.PositionNone, playerStats[0].BestPosition())
+				},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			playerStats := createPlayerStats(tt.playerAttendances, tt.gamesCount, tt.maxGamesCount, DefaultPoints())
+
+			for _, assertion := range tt.assertions {
+				assertion(t, playerStats)
+			}
+		})
+	}
+}
+
+func TestPlayerImpact(t *testing.T) {
+	tests := map[string]struct {
+		impact     PlayerImpact
+		assertions []func(t *testing.T, impact PlayerImpact)
+	}{
+		"with moving up": {
+			impact: PlayerImpact{
+				Before: PlayerStats{PointsRatio: 1.5, Position: 4},
+				After:  PlayerStats{PointsRatio: 1.75, Position: 2},
+			},
+			assertions: []func(t *testing.T, impact PlayerImpact){
+				func(t *testing.T, impact PlayerImpact) {
+					assert.Equal(t, 0.25, impact.PointsRatioChange())
+					assert.Equal(t, 2, impact.PositionChange())
+				},
+			},
+		},
+		"with first game of the season": {
+			impact: PlayerImpact{
+				After: PlayerStats{PointsRatio: 3, Position: 1},
+			},
+			assertions: []func(t *testing.T, impact PlayerImpact){
+				func(t *testing.T, impact PlayerImpact) {
+					assert.Equal(t, 3.0, impact.PointsRatioChange())
+					assert.Equal(t, 0, impact.PositionChange())
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			for _, assertion := range test.assertions {
+				assertion(t, test.impact)
+			}
+		})
+	}
+}
+
+func TestValidateScore(t *testing.T) {
+	tests := map[string]struct {
+		score      *Score
+		draw       bool
+		assertions []func(t *testing.T, err error)
+	}{
+		"without score": {
+			assertions: []func(t *testing.T, err error){
+				func(t *testing.T, err error) {
+					assert.NoError(t, err)
+				},
+			},
+		},
+		"winners with higher score": {
+			score: &Score{Team1: 10, Team2: 8},
+			assertions: []func(t *testing.T, err error){
+				func(t *testing.T, err error) {
+					assert.NoError(t, err)
+				},
+			},
+		},
+		"winners with lower score": {
+			score: &Score{Team1: 8, Team2: 10},
+			assertions: []func(t *testing.T, err error){
+				func(t *testing.T, err error) {
+					assert.ErrorIs(t, err, ErrInvalidScore)
+				},
+			},
+		},
+		"draw with same score": {
+			score: &Score{Team1: 5, Team2: 5},
+			draw:  true,
+			assertions: []func(t *testing.T, err error){
+				func(t *testing.T, err error) {
+					assert.NoError(t, err)
+				},
+			},
+		},
+		"draw with different scores": {
+			score: &Score{Team1: 6, Team2: 5},
+			draw:  true,
+			assertions: []func(t *testing.T, err error){
+				func(t *testing.T, err error) {
+					assert.ErrorIs(t, err, ErrInvalidScore)
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validateScore(test.score, test.draw)
+
+			for _, assertion := range test.assertions {
+				assertion(t, err)
+			}
+		})
+	}
+}
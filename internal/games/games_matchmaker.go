@@ -0,0 +1,166 @@
+package games
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/spie/fskick/internal/players"
+)
+
+const (
+	// minPositionGames is the number of games on a position after which the win ratio on the
+	// position is used instead of the overall win ratio of a player.
+	minPositionGames = 3
+	// unknownStrength is the strength of players without games.
+	unknownStrength = 0.5
+	// positionMismatchPenalty is added to the difference of a matchup for every player not on
+	// the preferred position, so of matchups with about the same balance the one respecting
+	// the preferences wins.
+	positionMismatchPenalty = 0.05
+)
+
+var ErrMatchPlayers = errors.New("matchmaking needs 2 or 4 different players")
+
+// Matchup are two teams of about the same strength. In 2v2 the first player of a team plays
+// defense and the second offense, the positions are set as well.
+type Matchup struct {
+	Team1      players.Team
+	Team2      players.Team
+	Positions  Positions
+	Difference float64
+}
+
+// MatchPlayers splits the players into the two most balanced teams. The strength of a player
+// is the win ratio on the position played, from the singles or doubles stats, and preferred
+// positions are kept where the balance allows it.
+func (manager Manager) MatchPlayers(ctx context.Context, playersToMatch []players.Player) (Matchup, error) {
+	if !hasDifferentPlayers(playersToMatch) {
+		return Matchup{}, fmt.Errorf("%w: %d given", ErrMatchPlayers, len(playersToMatch))
+	}
+
+	mode := ModeSingles
+	if len(playersToMatch) == 4 {
+		mode = ModeDoubles
+	}
+
+	allStats, err := manager.GetAllPlayerStats(ctx, mode, "")
+	if err != nil {
+		return Matchup{}, fmt.Errorf("match players: %w", err)
+	}
+
+	playerStats := make(map[uint]PlayerStats, len(allStats))
+	for _, stats := range allStats {
+		playerStats[stats.ID] = stats
+	}
+
+	return matchPlayers(playersToMatch, playerStats), nil
+}
+
+func hasDifferentPlayers(playersToMatch []players.Player) bool {
+	if len(playersToMatch) != 2 && len(playersToMatch) != 4 {
+		return false
+	}
+
+	ids := make(map[uint]bool, len(playersToMatch))
+	for _, player := range playersToMatch {
+		ids[player.ID] = true
+	}
+
+	return len(ids) == len(playersToMatch)
+}
+
+func matchPlayers(playersToMatch []players.Player, playerStats map[uint]PlayerStats) Matchup {
+	if len(playersToMatch) == 2 {
+		team1, team2 := players.Team{playersToMatch[0]}, players.Team{playersToMatch[1]}
+
+		return Matchup{
+			Team1:     team1,
+			Team2:     team2,
+			Positions: Positions{},
+			Difference: math.Abs(
+				getStrength(playerStats[team1[0].ID], players.PositionNone) -
+					getStrength(playerStats[team2[0].ID], players.PositionNone),
+			),
+		}
+	}
+
+	var best Matchup
+	bestCost := math.Inf(1)
+	// the first player is always in team 1 and picks a partner, both teams are tried in both
+	// orders of defense and offense
+	for partner := 1; partner < 4; partner++ {
+		team1, team2 := players.Team{playersToMatch[0], playersToMatch[partner]}, players.Team{}
+		for i := 1; i < 4; i++ {
+			if i != partner {
+				team2 = append(team2, playersToMatch[i])
+			}
+		}
+
+		for _, team1 := range getPositionOrders(team1) {
+			for _, team2 := range getPositionOrders(team2) {
+				difference := math.Abs(getTeamStrength(team1, playerStats) - getTeamStrength(team2, playerStats))
+				cost := difference + positionMismatchPenalty*float64(countPositionMismatches(team1)+countPositionMismatches(team2))
+				if cost < bestCost {
+					bestCost = cost
+					best = Matchup{
+						Team1:      team1,
+						Team2:      team2,
+						Positions:  getTeamPositions(team1, team2),
+						Difference: difference,
+					}
+				}
+			}
+		}
+	}
+
+	return best
+}
+
+// getPositionOrders returns the team with the first player on defense and swapped.
+func getPositionOrders(team players.Team) []players.Team {
+	return []players.Team{{team[0], team[1]}, {team[1], team[0]}}
+}
+
+func getTeamStrength(team players.Team, playerStats map[uint]PlayerStats) float64 {
+	return (getStrength(playerStats[team[0].ID], players.PositionDefense) +
+		getStrength(playerStats[team[1].ID], players.PositionOffense)) / 2
+}
+
+// getStrength returns the win ratio of the player on the position, or the overall win ratio
+// while there are not enough games on the position.
+func getStrength(stats PlayerStats, position players.Position) float64 {
+	switch {
+	case position == players.PositionDefense && stats.DefenseGames >= minPositionGames:
+		return stats.DefenseWinRatio
+	case position == players.PositionOffense && stats.OffenseGames >= minPositionGames:
+		return stats.OffenseWinRatio
+	case stats.Games > 0:
+		return stats.WinRatio
+	}
+
+	return unknownStrength
+}
+
+func countPositionMismatches(team players.Team) int {
+	mismatches := 0
+	if team[0].PreferredPosition == players.PositionOffense {
+		mismatches++
+	}
+	if team[1].PreferredPosition == players.PositionDefense {
+		mismatches++
+	}
+
+	return mismatches
+}
+
+func getTeamPositions(teams ...players.Team) Positions {
+	positions := Positions{}
+	for _, team := range teams {
+		positions[team[0].UUID] = players.PositionDefense
+		positions[team[1].UUID] = players.PositionOffense
+	}
+
+	return positions
+}
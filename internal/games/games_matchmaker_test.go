@@ -0,0 +1,145 @@
+package games
+
+import (
+	"testing"
+
+	"github.com/spie/fskick/internal/db"
+	"github.com/spie/fskick/internal/players"
+	"github.com/stretchr/testify/assert"
+)
+
+func createMatchPlayer(id uint, name string, preferredPosition players.Position) players.Player {
+	return players.Player{Model: db.Model{ID: id, UUID: name}, Name: name, PreferredPosition: preferredPosition}
+}
+
+func getTeamNames(team players.Team) []string {
+	names := make([]string, len(team))
+	for i, player := range team {
+		names[i] = player.Name
+	}
+
+	return names
+}
+
+func TestMatchPlayers(t *testing.T) {
+	alice := createMatchPlayer(1, "alice", players.PositionNone)
+	bob := createMatchPlayer(2, "bob", players.PositionNone)
+	carol := createMatchPlayer(3, "carol", players.PositionNone)
+	dave := createMatchPlayer(4, "dave", players.PositionNone)
+
+	tests := map[string]struct {
+		players     []players.Player
+		playerStats map[uint]PlayerStats
+		assertions  []func(t *testing.T, matchup Matchup)
+	}{
+		"with strongest and weakest player together": {
+			players: []players.Player{alice, bob, carol, dave},
+			playerStats: map[uint]PlayerStats{
+				1: {PlayerAttendance: PlayerAttendance{Games: 10}, WinRatio: 0.9},
+				2: {PlayerAttendance: PlayerAttendance{Games: 10}, WinRatio: 0.6},
+				3: {PlayerAttendance: PlayerAttendance{Games: 10}, WinRatio: 0.5},
+				4: {PlayerAttendance: PlayerAttendance{Games: 10}, WinRatio: 0.2},
+			},
+			assertions: []func(t *testing.T, matchup Matchup){
+				func(t *testing.T, matchup Matchup) {
+					assert.ElementsMatch(t, []string{"alice", "dave"}, getTeamNames(matchup.Team1))
+					assert.ElementsMatch(t, []string{"bob", "carol"}, getTeamNames(matchup.Team2))
+					assert.InDelta(t, 0, matchup.Difference, 0.0001)
+					assert.Len(t, matchup.Positions, 4)
+				},
+			},
+		},
+		"with position win ratios": {
+			players: []players.Player{alice, bob, carol, dave},
+			playerStats: map[uint]PlayerStats{
+				1: {
+					PlayerAttendance: PlayerAttendance{Games: 10, DefenseGames: 5, OffenseGames: 5},
+					WinRatio:         0.5,
+					DefenseWinRatio:  0.8,
+					OffenseWinRatio:  0.2,
+				},
+				2: {PlayerAttendance: PlayerAttendance{Games: 10}, WinRatio: 0.5},
+				3: {PlayerAttendance: PlayerAttendance{Games: 10}, WinRatio: 0.5},
+				4: {
+					PlayerAttendance: PlayerAttendance{Games: 10, DefenseGames: 5, OffenseGames: 5},
+					WinRatio:         0.5,
+					DefenseWinRatio:  0.8,
+					OffenseWinRatio:  0.2,
+				},
+			},
+			assertions: []func(t *testing.T, matchup Matchup){
+				func(t *testing.T, matchup Matchup) {
+					// both defenders play defense in different teams
+					assert.Equal(t, players.PositionDefense, matchup.Positions["alice"])
+					assert.Equal(t, players.PositionDefense, matchup.Positions["dave"])
+					assert.InDelta(t, 0, matchup.Difference, 0.0001)
+				},
+			},
+		},
+		"with preferred positions": {
+			players: []players.Player{
+				createMatchPlayer(1, "alice", players.PositionOffense),
+				createMatchPlayer(2, "bob", players.PositionDefense),
+				createMatchPlayer(3, "carol", players.PositionOffense),
+				createMatchPlayer(4, "dave", players.PositionDefense),
+			},
+			playerStats: map[uint]PlayerStats{},
+			assertions: []func(t *testing.T, matchup Matchup){
+				func(t *testing.T, matchup Matchup) {
+					assert.Equal(t, "bob", matchup.Team1[0].Name)
+					assert.Equal(t, "alice", matchup.Team1[1].Name)
+					assert.Equal(t, players.PositionOffense, matchup.Positions["alice"])
+					assert.Equal(t, players.PositionDefense, matchup.Positions["bob"])
+					assert.Equal(t, players.PositionOffense, matchup.Positions["carol"])
+					assert.Equal(t, players.PositionDefense, matchup.Positions["dave"])
+				},
+			},
+		},
+		"with singles": {
+			players: []players.Player{alice, bob},
+			playerStats: map[uint]PlayerStats{
+				1: {PlayerAttendance: PlayerAttendance{Games: 4}, WinRatio: 0.75},
+			},
+			assertions: []func(t *testing.T, matchup Matchup){
+				func(t *testing.T, matchup Matchup) {
+					assert.Equal(t, []string{"alice"}, getTeamNames(matchup.Team1))
+					assert.Equal(t, []string{"bob"}, getTeamNames(matchup.Team2))
+					assert.Empty(t, matchup.Positions)
+					assert.InDelta(t, 0.25, matchup.Difference, 0.0001)
+				},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			matchup := matchPlayers(tt.players, tt.playerStats)
+
+			for _, assertion := range tt.assertions {
+				assertion(t, matchup)
+			}
+		})
+	}
+}
+
+func TestHasDifferentPlayers(t *testing.T) {
+	alice := createMatchPlayer(1, "alice", players.PositionNone)
+	bob := createMatchPlayer(2, "bob", players.PositionNone)
+	carol := createMatchPlayer(3, "carol", players.PositionNone)
+
+	tests := map[string]struct {
+		players  []players.Player
+		expected bool
+	}{
+		"with two players":    {players: []players.Player{alice, bob}, expected: true},
+		"with three players":  {players: []players.Player{alice, bob, carol}, expected: false},
+		"with a player twice": {players: []players.Player{alice, bob, carol, alice}, expected: false},
+		"without players":     {players: nil, expected: false},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, hasDifferentPlayers(tt.players))
+		})
+	}
+}
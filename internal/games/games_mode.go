@@ -0,0 +1,78 @@
+package games
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Mode separates the rankings by the team sizes of the games. Games with teams of different
+// sizes only count for all games.
+type Mode string
+
+const (
+	ModeAll     Mode = "all"
+	ModeSingles Mode = "singles"
+	ModeDoubles Mode = "doubles"
+)
+
+var ErrUnknownMode = errors.New("mode has to be all, singles or doubles")
+
+// ParseMode reads the mode, 1v1 and 2v2 are accepted as well. No value means all games.
+func ParseMode(value string) (Mode, error) {
+	switch value {
+	case "", string(ModeAll):
+		return ModeAll, nil
+	case string(ModeSingles), "1v1":
+		return ModeSingles, nil
+	case string(ModeDoubles), "2v2":
+		return ModeDoubles, nil
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrUnknownMode, value)
+}
+
+// getModeForTeamSizes derives the mode of a game from the number of players in both teams.
+func getModeForTeamSizes(team1Size int, team2Size int) Mode {
+	switch {
+	case team1Size == 1 && team2Size == 1:
+		return ModeSingles
+	case team1Size == 2 && team2Size == 2:
+		return ModeDoubles
+	}
+
+	return ModeAll
+}
+
+// Mode returns singles or doubles for games with equally sized teams, all otherwise.
+func (game Game) Mode() Mode {
+	return getModeForTeamSizes(len(game.Team(1)), len(game.Team(2)))
+}
+
+func (mode Mode) teamSize() int {
+	switch mode {
+	case ModeSingles:
+		return 1
+	case ModeDoubles:
+		return 2
+	}
+
+	return 0
+}
+
+// getModeCondition restricts the games g to the ones of the mode.
+func getModeCondition(mode Mode) string {
+	teamSize := mode.teamSize()
+	if teamSize == 0 {
+		return "1 = 1"
+	}
+
+	return fmt.Sprintf(
+		`g.id IN (
+			SELECT game_id FROM attendances
+			GROUP BY game_id
+			HAVING SUM(CASE WHEN team = 1 THEN 1 ELSE 0 END) = %[1]d
+				AND SUM(CASE WHEN team = 2 THEN 1 ELSE 0 END) = %[1]d
+		)`,
+		teamSize,
+	)
+}
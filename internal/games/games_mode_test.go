@@ -0,0 +1,55 @@
+package games
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMode(t *testing.T) {
+	tests := map[string]struct {
+		value string
+		mode  Mode
+		err   error
+	}{
+		"no value":  {value: "", mode: ModeAll},
+		"all":       {value: "all", mode: ModeAll},
+		"singles":   {value: "singles", mode: ModeSingles},
+		"1v1":       {value: "1v1", mode: ModeSingles},
+		"doubles":   {value: "doubles", mode: ModeDoubles},
+		"2v2":       {value: "2v2", mode: ModeDoubles},
+		"unknown":   {value: "3v3", err: ErrUnknownMode},
+		"uppercase": {value: "Singles", err: ErrUnknownMode},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mode, err := ParseMode(test.value)
+
+			assert.ErrorIs(t, err, test.err)
+			assert.Equal(t, test.mode, mode)
+		})
+	}
+}
+
+func TestGameMode(t *testing.T) {
+	tests := map[string]struct {
+		teams []int
+		mode  Mode
+	}{
+		"singles": {teams: []int{1, 2}, mode: ModeSingles},
+		"doubles": {teams: []int{1, 1, 2, 2}, mode: ModeDoubles},
+		"mixed":   {teams: []int{1, 1, 2}, mode: ModeAll},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			game := Game{}
+			for _, team := range test.teams {
+				game.Attendances = append(game.Attendances, Attendance{Team: team})
+			}
+
+			assert.Equal(t, test.mode, game.Mode())
+		})
+	}
+}
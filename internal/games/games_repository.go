@@ -1,7 +1,11 @@
 package games
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/spie/fskick/internal/db"
@@ -11,12 +15,58 @@ import (
 
 type Game struct {
 	db.Model
-	PlayedAt    time.Time
-	SeasonID    uint
-	Season      *seasons.Season
-	Attendances []Attendance
+	PlayedAt       time.Time
+	SeasonID       uint
+	Season         *seasons.Season
+	Attendances    []Attendance
+	RecordedBy     *players.Player
+	Score          *Score
+	IdempotencyKey string
+	// idempotencyFingerprint identifies the entry the game was created from with the key.
+	idempotencyFingerprint string
+	cursor                 gamesCursor
 }
 
+// Score holds the goals of team 1 and team 2, if they were recorded.
+type Score struct {
+	Team1 int
+	Team2 int
+}
+
+// Team returns the attendances of team 1 or team 2.
+func (game Game) Team(team int) []Attendance {
+	attendances := []Attendance{}
+	for _, attendance := range game.Attendances {
+		if attendance.Team == team {
+			attendances = append(attendances, attendance)
+		}
+	}
+
+	return attendances
+}
+
+// Outcome returns the outcome for team 1.
+func (game Game) Outcome() Outcome {
+	for _, attendance := range game.Attendances {
+		if attendance.Team == 1 {
+			return attendance.Outcome
+		}
+	}
+
+	return ""
+}
+
+var (
+	ErrGameNotFound          = db.ErrNotFound
+	ErrDuplicateAttendance   = errors.New("player attends the game more than once")
+	ErrUnknownReference      = errors.New("season or player of the game does not exist")
+	ErrInvalidScore          = errors.New("score does not match the outcome")
+	ErrIdempotencyKeyUsed    = errors.New("idempotency key was already used for a game")
+	ErrInvalidIdempotencyKey = errors.New("invalid idempotency key")
+	ErrIdempotencyKeyReused  = errors.New("idempotency key was used for another game")
+	ErrDuplicateGame         = errors.New("same game was already recorded")
+)
+
 type GamesRepository struct {
 	conn db.Connection
 }
@@ -25,7 +75,63 @@ func NewGamesRepository(conn db.Connection) GamesRepository {
 	return GamesRepository{conn: conn}
 }
 
-func (repository GamesRepository) CreateGame(game *Game, attendances []Attendance) error {
+// Transaction runs fn with the games and attendance repositories in one transaction.
+func (repository GamesRepository) Transaction(
+	ctx context.Context,
+	fn func(gamesRepository GamesRepository, attendanceRepository AttendanceRepository) error,
+) error {
+	return db.Transaction(ctx, repository.conn, func(tx db.Connection) error {
+		return fn(NewGamesRepository(tx), NewAttendanceRepository(tx))
+	})
+}
+
+// LockSeason makes other transactions adding games to the season wait until the transaction of
+// the repository ends. Writing the row of the season locks it in postgres and starts the write
+// transaction in sqlite3.
+func (repository GamesRepository) LockSeason(ctx context.Context, seasonID uint) error {
+	_, err := repository.conn.ExecContext(ctx, "UPDATE seasons SET updated_at = updated_at WHERE id = $1", seasonID)
+	if err != nil {
+		return fmt.Errorf("lock season: %w", err)
+	}
+
+	return nil
+}
+
+// CreateGames inserts all games with their attendances. The repository has to run in a
+// transaction (db.Tx) for the games to be inserted all or none. Games with teams not allowed in
+// their season fail with a ValidationError before anything is inserted.
+func (repository GamesRepository) CreateGames(ctx context.Context, games []*Game, attendances [][]Attendance) error {
+	for i, game := range games {
+		team1, team2 := 0, 0
+		for _, attendance := range attendances[i] {
+			if attendance.Team == 1 {
+				team1++
+			} else {
+				team2++
+			}
+		}
+
+		fields := validateTeamSizes(team1, team2, *game.Season)
+		if len(fields) > 0 {
+			return &ValidationError{Fields: fields}
+		}
+	}
+
+	for i, game := range games {
+		err := insertGame(ctx, repository.conn, game, attendances[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func insertGame(ctx context.Context, conn rowQuerier, game *Game, attendances []Attendance) error {
 	err := game.CreateUUID()
 	if err != nil {
 		return fmt.Errorf("create uuid for insert game: %w", err)
@@ -36,28 +142,57 @@ func (repository GamesRepository) CreateGame(game *Game, attendances []Attendanc
 	game.CreatedAt = now
 	game.UpdatedAt = now
 
-	tx, err := repository.conn.Begin()
-	if err != nil {
-		return fmt.Errorf("begin transaction for insert game: %w", err)
+	var recordedByID *uint
+	if game.RecordedBy != nil {
+		recordedByID = &game.RecordedBy.ID
+	}
+
+	var team1Score, team2Score *int
+	if game.Score != nil {
+		team1Score, team2Score = &game.Score.Team1, &game.Score.Team2
+	}
+
+	var idempotencyKey, idempotencyFingerprint *string
+	if game.IdempotencyKey != "" {
+		idempotencyKey, idempotencyFingerprint = &game.IdempotencyKey, &game.idempotencyFingerprint
 	}
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		}
-	}()
 
-	row := tx.QueryRow(
-		`INSERT INTO games (uuid, played_at, season_id, created_at, updated_at, deleted_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+	row := conn.QueryRowContext(
+		ctx,
+		`INSERT INTO games (
+			uuid,
+			played_at,
+			season_id,
+			recorded_by_id,
+			team1_score,
+			team2_score,
+			idempotency_key,
+			idempotency_fingerprint,
+			created_at,
+			updated_at,
+			deleted_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		RETURNING id`,
 		game.UUID,
 		game.PlayedAt,
 		game.Season.ID,
+		recordedByID,
+		team1Score,
+		team2Score,
+		idempotencyKey,
+		idempotencyFingerprint,
 		game.CreatedAt,
 		game.UpdatedAt,
 		nil,
 	)
 	err = row.Scan(&game.ID)
+	if db.IsUniqueViolation(err) {
+		return fmt.Errorf("insert game: %w", ErrIdempotencyKeyUsed)
+	}
+	if db.IsForeignKeyViolation(err) {
+		return fmt.Errorf("insert game: %w", ErrUnknownReference)
+	}
 	if err != nil {
 		return fmt.Errorf("insert game: %w", err)
 	}
@@ -73,12 +208,25 @@ func (repository GamesRepository) CreateGame(game *Game, attendances []Attendanc
 		attendance.UpdatedAt = now
 		attendance.GameID = game.ID
 
-		row = tx.QueryRow(
-			`INSERT INTO attendances (uuid, win, player_id, game_id, created_at, updated_at, deleted_at)
-			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		row = conn.QueryRowContext(
+			ctx,
+			`INSERT INTO attendances (
+				uuid,
+				team,
+				outcome,
+				position,
+				player_id,
+				game_id,
+				created_at,
+				updated_at,
+				deleted_at
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 			RETURNING id`,
 			attendance.UUID,
-			attendance.Win,
+			attendance.Team,
+			attendance.Outcome,
+			attendance.Position,
 			attendance.PlayerID,
 			attendance.GameID,
 			attendance.CreatedAt,
@@ -86,6 +234,12 @@ func (repository GamesRepository) CreateGame(game *Game, attendances []Attendanc
 			nil,
 		)
 		err = row.Scan(&attendance.ID)
+		if db.IsUniqueViolation(err) {
+			return fmt.Errorf("insert attendance: %w", ErrDuplicateAttendance)
+		}
+		if db.IsForeignKeyViolation(err) {
+			return fmt.Errorf("insert attendance: %w", ErrUnknownReference)
+		}
 		if err != nil {
 			return fmt.Errorf("insert attendance: %w", err)
 		}
@@ -93,20 +247,171 @@ func (repository GamesRepository) CreateGame(game *Game, attendances []Attendanc
 		createdAttendancees[i] = attendance
 	}
 
-	err = tx.Commit()
+	game.Attendances = createdAttendancees
+
+	return nil
+}
+
+// FindGameIDsPlayedAt returns the IDs of the games played at exactly the given time.
+func (repository GamesRepository) FindGameIDsPlayedAt(ctx context.Context, playedAt time.Time) ([]uint, error) {
+	rows, err := repository.conn.QueryContext(ctx, "SELECT id FROM games WHERE played_at = $1", playedAt)
 	if err != nil {
-		return fmt.Errorf("insert game: %w", err)
+		return nil, fmt.Errorf("query games played at: %w", err)
 	}
+	defer rows.Close()
 
-	game.Attendances = createdAttendancees
+	gameIDs := []uint{}
+	for rows.Next() {
+		var gameID uint
+		err = rows.Scan(&gameID)
+		if err != nil {
+			return nil, fmt.Errorf("scan games played at: %w", err)
+		}
 
-	return nil
+		gameIDs = append(gameIDs, gameID)
+	}
+
+	return gameIDs, nil
+}
+
+// FindGamesPlayedBetween returns the games played from from to to, both inclusive, without
+// their attendances.
+func (repository GamesRepository) FindGamesPlayedBetween(ctx context.Context, from time.Time, to time.Time) ([]Game, error) {
+	rows, err := repository.conn.QueryContext(
+		ctx,
+		"SELECT id, uuid, played_at FROM games WHERE played_at >= $1 AND played_at <= $2 ORDER BY played_at, id",
+		from,
+		to,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query games played between: %w", err)
+	}
+	defer rows.Close()
+
+	games := []Game{}
+	for rows.Next() {
+		var game Game
+		err = rows.Scan(&game.ID, &game.UUID, &game.PlayedAt)
+		if err != nil {
+			return nil, fmt.Errorf("scan games played between: %w", err)
+		}
+
+		games = append(games, game)
+	}
+
+	return games, nil
+}
+
+func (repository GamesRepository) FindGameByUUID(ctx context.Context, uuid string) (Game, error) {
+	return repository.findGame(ctx, "g.uuid = $1", uuid)
+}
+
+// FindGameByIdempotencyKey returns the game recorded by the player with the key, keys of
+// other players do not match. Without a player only games without a recorder match.
+func (repository GamesRepository) FindGameByIdempotencyKey(
+	ctx context.Context,
+	recordedBy *players.Player,
+	idempotencyKey string,
+) (Game, error) {
+	var recordedByID uint
+	if recordedBy != nil {
+		recordedByID = recordedBy.ID
+	}
+
+	return repository.findGame(
+		ctx,
+		"g.idempotency_key = $1 AND COALESCE(g.recorded_by_id, 0) = $2",
+		idempotencyKey,
+		recordedByID,
+	)
+}
+
+// findGame returns the game matching the condition on unique columns.
+func (repository GamesRepository) findGame(ctx context.Context, condition string, args ...any) (Game, error) {
+	game := Game{Season: &seasons.Season{}}
+	var recordedBy struct {
+		id          sql.NullInt64
+		uuid        sql.NullString
+		name        sql.NullString
+		displayName sql.NullString
+		avatar      sql.NullString
+	}
+	var team1Score, team2Score sql.NullInt64
+	var idempotencyKey, idempotencyFingerprint sql.NullString
+
+	err := repository.conn.QueryRowContext(
+		ctx,
+		`SELECT
+			g.id,
+			g.uuid,
+			g.played_at,
+			CAST(g.played_at AS TEXT),
+			g.season_id,
+			g.created_at,
+			g.updated_at,
+			s.uuid,
+			s.name,
+			s.active,
+			r.id,
+			r.uuid,
+			r.name,
+			r.display_name,
+			r.avatar,
+			g.team1_score,
+			g.team2_score,
+			g.idempotency_key,
+			g.idempotency_fingerprint
+		FROM games g
+		JOIN seasons s ON s.id = g.season_id
+		LEFT JOIN players r ON r.id = g.recorded_by_id
+		WHERE `+condition,
+		args...,
+	).Scan(
+		&game.ID,
+		&game.UUID,
+		&game.PlayedAt,
+		&game.cursor.playedAt,
+		&game.SeasonID,
+		&game.CreatedAt,
+		&game.UpdatedAt,
+		&game.Season.UUID,
+		&game.Season.Name,
+		&game.Season.Active,
+		&recordedBy.id,
+		&recordedBy.uuid,
+		&recordedBy.name,
+		&recordedBy.displayName,
+		&recordedBy.avatar,
+		&team1Score,
+		&team2Score,
+		&idempotencyKey,
+		&idempotencyFingerprint,
+	)
+	if err != nil {
+		return Game{}, fmt.Errorf("query game by %s: %w", condition, err)
+	}
+
+	game.Season.ID = game.SeasonID
+	game.cursor.id = game.ID
+	game.Score = newScore(team1Score, team2Score)
+	game.IdempotencyKey = idempotencyKey.String
+	game.idempotencyFingerprint = idempotencyFingerprint.String
+	if recordedBy.id.Valid {
+		game.RecordedBy = &players.Player{
+			Model:       db.Model{ID: uint(recordedBy.id.Int64), UUID: recordedBy.uuid.String},
+			Name:        recordedBy.name.String,
+			DisplayName: recordedBy.displayName.String,
+			Avatar:      recordedBy.avatar.String,
+		}
+	}
+
+	return game, nil
 }
 
-func (repository GamesRepository) Count() (int, error) {
+func (repository GamesRepository) Count(ctx context.Context, mode Mode) (int, error) {
 	var count int
 	err := repository.conn.
-		QueryRow("SELECT COUNT(*) FROM games").
+		QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM games g WHERE %s", getModeCondition(mode))).
 		Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("count games: %w", err)
@@ -115,11 +420,28 @@ func (repository GamesRepository) Count() (int, error) {
 	return count, nil
 }
 
-func (repository GamesRepository) CountForSeason(season seasons.Season) (int, error) {
+// CountRecordedSince counts the games recorded, not played, since the given time.
+func (repository GamesRepository) CountRecordedSince(ctx context.Context, since time.Time) (int, error) {
+	var count int
+	err := repository.conn.
+		QueryRowContext(ctx, "SELECT COUNT(*) FROM games WHERE created_at >= $1", since).
+		Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("count games recorded since: %w", err)
+	}
+
+	return count, nil
+}
+
+func (repository GamesRepository) CountForSeason(ctx context.Context, season seasons.Season, mode Mode) (int, error) {
 	var count int
 
 	err := repository.conn.
-		QueryRow("SELECT COUNT(*) FROM games WHERE season_id = $1", season.ID).
+		QueryRowContext(
+			ctx,
+			fmt.Sprintf("SELECT COUNT(*) FROM games g WHERE g.season_id = $1 AND %s", getModeCondition(mode)),
+			season.ID,
+		).
 		Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("count games: %w", err)
@@ -128,10 +450,33 @@ func (repository GamesRepository) CountForSeason(season seasons.Season) (int, er
 	return count, nil
 }
 
-func (repository GamesRepository) CountForPlayer(player players.Player) (int, error) {
+func (repository GamesRepository) CountForSeasonUntil(ctx context.Context, season seasons.Season, game Game, inclusive bool) (int, error) {
+	var count int
+
+	err := repository.conn.
+		QueryRowContext(
+			ctx,
+			fmt.Sprintf(
+				"SELECT COUNT(*) FROM games g WHERE g.season_id = $1 AND %s",
+				getPlayedUntilCondition(inclusive),
+			),
+			season.ID,
+			game.cursor.playedAt,
+			game.ID,
+		).
+		Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("count games until game: %w", err)
+	}
+
+	return count, nil
+}
+
+func (repository GamesRepository) CountForPlayer(ctx context.Context, player players.Player) (int, error) {
 	var count int
 	err := repository.conn.
-		QueryRow(
+		QueryRowContext(
+			ctx,
 			`SELECT COUNT(*)
 			FROM games g
 			JOIN attendances a ON a.game_id = g.id
@@ -146,18 +491,22 @@ func (repository GamesRepository) CountForPlayer(player players.Player) (int, er
 	return count, nil
 }
 
-func (repository GamesRepository) MaxGamesForSeason(season seasons.Season) (int, error) {
+func (repository GamesRepository) MaxGamesForSeason(ctx context.Context, season seasons.Season, mode Mode) (int, error) {
 	var maxGames int
-	row := repository.conn.QueryRow(
-		`SELECT COALESCE(MAX(games_played), 0) as max_games_played
-		FROM (
-			SELECT COUNT(a.id) as games_played
-			FROM players p
-			JOIN attendances a ON p.id = a.player_id
-			JOIN games g ON g.id = a.game_id
-			WHERE g.season_id = $1
-			GROUP BY p.id
-		)`,
+	row := repository.conn.QueryRowContext(
+		ctx,
+		fmt.Sprintf(
+			`SELECT COALESCE(MAX(games_played), 0) as max_games_played
+			FROM (
+				SELECT COUNT(a.id) as games_played
+				FROM players p
+				JOIN attendances a ON p.id = a.player_id
+				JOIN games g ON g.id = a.game_id
+				WHERE g.season_id = $1 AND %s
+				GROUP BY p.id
+			) subquery`,
+			getModeCondition(mode),
+		),
 		season.ID,
 	)
 
@@ -169,17 +518,56 @@ func (repository GamesRepository) MaxGamesForSeason(season seasons.Season) (int,
 	return maxGames, nil
 }
 
-func (repository GamesRepository) MaxGames() (int, error) {
+func (repository GamesRepository) MaxGamesForSeasonUntil(
+	ctx context.Context,
+	season seasons.Season,
+	game Game,
+	inclusive bool,
+) (int, error) {
 	var maxGames int
-	row := repository.conn.QueryRow(
-		`SELECT COALESCE(MAX(games_played), 0) as max_games_played
-		FROM (
-			SELECT COUNT(a.id) as games_played
-			FROM players p
-			JOIN attendances a ON p.id = a.player_id
-			JOIN games g ON g.id = a.game_id
-			GROUP BY p.id
-		)`,
+	row := repository.conn.QueryRowContext(
+		ctx,
+		fmt.Sprintf(
+			`SELECT COALESCE(MAX(games_played), 0) as max_games_played
+			FROM (
+				SELECT COUNT(a.id) as games_played
+				FROM players p
+				JOIN attendances a ON p.id = a.player_id
+				JOIN games g ON g.id = a.game_id
+				WHERE g.season_id = $1 AND %s
+				GROUP BY p.id
+			) subquery`,
+			getPlayedUntilCondition(inclusive),
+		),
+		season.ID,
+		game.cursor.playedAt,
+		game.ID,
+	)
+
+	err := row.Scan(&maxGames)
+	if err != nil {
+		return 0, fmt.Errorf("query max games for seasons until game: %w", err)
+	}
+
+	return maxGames, nil
+}
+
+func (repository GamesRepository) MaxGames(ctx context.Context, mode Mode) (int, error) {
+	var maxGames int
+	row := repository.conn.QueryRowContext(
+		ctx,
+		fmt.Sprintf(
+			`SELECT COALESCE(MAX(games_played), 0) as max_games_played
+			FROM (
+				SELECT COUNT(a.id) as games_played
+				FROM players p
+				JOIN attendances a ON p.id = a.player_id
+				JOIN games g ON g.id = a.game_id
+				WHERE %s
+				GROUP BY p.id
+			) subquery`,
+			getModeCondition(mode),
+		),
 	)
 
 	err := row.Scan(&maxGames)
@@ -190,11 +578,12 @@ func (repository GamesRepository) MaxGames() (int, error) {
 	return maxGames, nil
 }
 
-func (repository GamesRepository) MaxGamesForPlayer(player players.Player) (int, error) {
+func (repository GamesRepository) MaxGamesForPlayer(ctx context.Context, player players.Player) (int, error) {
 	var maxGames int
-	row := repository.conn.QueryRow(
+	row := repository.conn.QueryRowContext(
+		ctx,
 		`WITH player_games AS (
-			SELECT g.id AS game_id, a.win
+			SELECT g.id AS game_id, a.team
 			FROM attendances a
 			JOIn games g ON a.game_id = g.id
 			WHERE a.player_id = $1
@@ -205,7 +594,7 @@ func (repository GamesRepository) MaxGamesForPlayer(player players.Player) (int,
 			FROM players p
 			JOIN attendances a ON p.id = a.player_id
 			JOIN games g ON g.id = a.game_id
-			JOIN player_games pg ON g.id = pg.game_id AND a.win = pg.win
+			JOIN player_games pg ON g.id = pg.game_id AND a.team = pg.team
 			WHERE p.id != $1
 			GROUP BY p.id
 		) subquery`,
@@ -219,3 +608,153 @@ func (repository GamesRepository) MaxGamesForPlayer(player players.Player) (int,
 
 	return maxGames, nil
 }
+
+// ListGames returns the games matching the filter, newest first, starting after the cursor.
+// It queries one game more than the limit to know if there is a next page.
+func (repository GamesRepository) ListGames(ctx context.Context, filter GamesFilter, cursor *gamesCursor, limit int) ([]Game, error) {
+	conditions := []string{"1 = 1"}
+	// sqlite binds the arguments in the order the placeholders appear in the query,
+	// so arguments have to be added in the same order as their conditions.
+	args := []any{}
+	arg := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.Season != nil {
+		conditions = append(conditions, fmt.Sprintf("g.season_id = %s", arg(filter.Season.ID)))
+	}
+	if filter.Player != nil {
+		playerCondition := fmt.Sprintf("pa.player_id = %s", arg(filter.Player.ID))
+		if filter.Outcome != "" {
+			playerCondition += fmt.Sprintf(" AND pa.outcome = %s", arg(filter.Outcome))
+		}
+
+		conditions = append(conditions, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM attendances pa WHERE pa.game_id = g.id AND %s)",
+			playerCondition,
+		))
+	} else if filter.Outcome != "" {
+		conditions = append(conditions, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM attendances oa WHERE oa.game_id = g.id AND oa.outcome = %s)",
+			arg(filter.Outcome),
+		))
+	}
+	if filter.Teammate != nil {
+		conditions = append(conditions, getTeamCondition("=", arg(filter.Player.ID), arg(filter.Teammate.ID)))
+	}
+	if filter.Oponent != nil {
+		conditions = append(conditions, getTeamCondition("!=", arg(filter.Player.ID), arg(filter.Oponent.ID)))
+	}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("g.played_at >= %s", arg(filter.From)))
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("g.played_at < %s", arg(filter.To)))
+	}
+	if cursor != nil {
+		playedAt := arg(cursor.playedAt)
+		conditions = append(conditions, fmt.Sprintf(
+			"(g.played_at < %s OR (g.played_at = %s AND g.id < %s))",
+			playedAt,
+			playedAt,
+			arg(cursor.id),
+		))
+	}
+
+	rows, err := repository.conn.QueryContext(
+		ctx,
+		fmt.Sprintf(
+			`SELECT
+				g.id,
+				g.uuid,
+				g.played_at,
+				CAST(g.played_at AS TEXT),
+				g.season_id,
+				g.created_at,
+				g.updated_at,
+				s.uuid,
+				s.name,
+				s.active,
+				g.team1_score,
+				g.team2_score
+			FROM games g
+			JOIN seasons s ON s.id = g.season_id
+			WHERE %s
+			ORDER BY g.played_at DESC, g.id DESC
+			LIMIT %s`,
+			strings.Join(conditions, " AND "),
+			arg(limit+1),
+		),
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list games: %w", err)
+	}
+	defer rows.Close()
+
+	games := []Game{}
+	for rows.Next() {
+		game := Game{Season: &seasons.Season{}}
+		var team1Score, team2Score sql.NullInt64
+		err = rows.Scan(
+			&game.ID,
+			&game.UUID,
+			&game.PlayedAt,
+			&game.cursor.playedAt,
+			&game.SeasonID,
+			&game.CreatedAt,
+			&game.UpdatedAt,
+			&game.Season.UUID,
+			&game.Season.Name,
+			&game.Season.Active,
+			&team1Score,
+			&team2Score,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan game rows: %w", err)
+		}
+
+		game.Season.ID = game.SeasonID
+		game.cursor.id = game.ID
+		game.Score = newScore(team1Score, team2Score)
+		games = append(games, game)
+	}
+
+	return games, nil
+}
+
+// getTeamCondition matches games where the other player was in the same (=) or the
+// other team (!=) as the player.
+func getTeamCondition(operator string, playerID string, otherPlayerID string) string {
+	return fmt.Sprintf(
+		`EXISTS (
+			SELECT 1
+			FROM attendances pa
+			JOIN attendances oa ON oa.game_id = pa.game_id AND oa.team %s pa.team
+			WHERE pa.game_id = g.id AND pa.player_id = %s AND oa.player_id = %s
+		)`,
+		operator,
+		playerID,
+		otherPlayerID,
+	)
+}
+
+// getPlayedUntilCondition matches games played before the game given as $2 (played_at as
+// stored) and $3 (id), including the game itself if inclusive.
+func getPlayedUntilCondition(inclusive bool) string {
+	operator := "<"
+	if inclusive {
+		operator = "<="
+	}
+
+	return fmt.Sprintf("(g.played_at < $2 OR (g.played_at = $2 AND g.id %s $3))", operator)
+}
+
+func newScore(team1Score sql.NullInt64, team2Score sql.NullInt64) *Score {
+	if !team1Score.Valid || !team2Score.Valid {
+		return nil
+	}
+
+	return &Score{Team1: int(team1Score.Int64), Team2: int(team2Score.Int64)}
+}
@@ -0,0 +1,154 @@
+package games
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spie/fskick/internal/seasons"
+)
+
+// maxClockSkew is how far in the future a game may be played, for clients with a clock
+// slightly ahead.
+const maxClockSkew = time.Minute
+
+var (
+	ErrInvalidGame       = errors.New("invalid game")
+	ErrEmptyTeam         = errors.New("team has no players")
+	ErrTeamSize          = errors.New("team size is not allowed in the season")
+	ErrUnevenTeams       = errors.New("teams have different sizes")
+	ErrPlayerInBothTeams = errors.New("player plays in both teams")
+	ErrPlayedInFuture    = errors.New("game is played in the future")
+)
+
+// FieldError is the problem with one field of a game entry. The fields are named like in the
+// api, winners, losers, playedAt and score.
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (err FieldError) Error() string {
+	return fmt.Sprintf("%s: %v", err.Field, err.Err)
+}
+
+func (err FieldError) Unwrap() error {
+	return err.Err
+}
+
+// ValidationError lists the problems of a game entry field by field. It matches
+// ErrInvalidGame and the errors of the fields in errors.Is.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (err *ValidationError) Error() string {
+	lines := make([]string, len(err.Fields))
+	for i, field := range err.Fields {
+		lines[i] = "  " + field.Error()
+	}
+
+	return fmt.Sprintf("%v:\n%s", ErrInvalidGame, strings.Join(lines, "\n"))
+}
+
+func (err *ValidationError) Is(target error) bool {
+	return target == ErrInvalidGame
+}
+
+func (err *ValidationError) Unwrap() []error {
+	errs := make([]error, len(err.Fields))
+	for i, field := range err.Fields {
+		errs[i] = field
+	}
+
+	return errs
+}
+
+// validateGameEntry checks the entry against the rules of the season: both teams have the
+// same number of players, a team size allowed in the season, nobody plays in both teams, the
+// game is not played in the future and the score matches the outcome.
+func validateGameEntry(entry GameEntry, season seasons.Season, now time.Time) error {
+	fields := validateTeamSizes(len(entry.Winners), len(entry.Losers), season)
+
+	inBothTeams := []string{}
+	for _, winner := range entry.Winners {
+		for _, loser := range entry.Losers {
+			if winner.ID == loser.ID {
+				inBothTeams = append(inBothTeams, winner.Name)
+			}
+		}
+	}
+	if len(inBothTeams) > 0 {
+		fields = append(fields, FieldError{
+			Field: "losers",
+			Err:   fmt.Errorf("%w: %s", ErrPlayerInBothTeams, strings.Join(inBothTeams, ", ")),
+		})
+	}
+
+	if entry.PlayedAt.After(now.Add(maxClockSkew)) {
+		fields = append(fields, FieldError{
+			Field: "playedAt",
+			Err:   fmt.Errorf("%w: %s", ErrPlayedInFuture, entry.PlayedAt.Local().Format("2006-01-02 15:04")),
+		})
+	}
+
+	err := validateScore(entry.Score, entry.Draw)
+	if err != nil {
+		fields = append(fields, FieldError{Field: "score", Err: err})
+	}
+
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+
+	return nil
+}
+
+// validateTeamSizes checks that both teams have players, the same number of them and a team
+// size allowed in the season.
+func validateTeamSizes(winners int, losers int, season seasons.Season) []FieldError {
+	fields := []FieldError{}
+	if winners == 0 {
+		fields = append(fields, FieldError{Field: "winners", Err: ErrEmptyTeam})
+	}
+	if losers == 0 {
+		fields = append(fields, FieldError{Field: "losers", Err: ErrEmptyTeam})
+	}
+	if len(fields) > 0 {
+		return fields
+	}
+
+	if winners != losers {
+		return []FieldError{{
+			Field: "losers",
+			Err:   fmt.Errorf("%w: %d vs. %d players", ErrUnevenTeams, winners, losers),
+		}}
+	}
+
+	if !season.TeamSizes.Allows(winners) {
+		return []FieldError{{
+			Field: "losers",
+			Err: fmt.Errorf(
+				"%w: teams of %d, season %s allows %s",
+				ErrTeamSize,
+				winners,
+				season.Name,
+				describeTeamSizes(season.TeamSizes),
+			),
+		}}
+	}
+
+	return fields
+}
+
+func describeTeamSizes(teamSizes seasons.TeamSizes) string {
+	switch teamSizes {
+	case seasons.TeamSizesSingles:
+		return "1 player per team"
+	case seasons.TeamSizesDoubles:
+		return "2 players per team"
+	}
+
+	return "1 or 2 players per team"
+}
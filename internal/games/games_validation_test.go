@@ -0,0 +1,138 @@
+package games
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/spie/fskick/internal/db"
+	"github.com/spie/fskick/internal/players"
+	"github.com/spie/fskick/internal/seasons"
+)
+
+func TestValidateGameEntry(t *testing.T) {
+	alice := players.Player{Model: db.Model{ID: 1}, Name: "alice"}
+	bob := players.Player{Model: db.Model{ID: 2}, Name: "bob"}
+	carol := players.Player{Model: db.Model{ID: 3}, Name: "carol"}
+	dave := players.Player{Model: db.Model{ID: 4}, Name: "dave"}
+	now := time.Date(2024, 5, 1, 18, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		entry      GameEntry
+		teamSizes  seasons.TeamSizes
+		assertions []func(t *testing.T, err error)
+	}{
+		"valid game": {
+			entry:     GameEntry{Winners: players.Team{alice, bob}, Losers: players.Team{carol, dave}, PlayedAt: now},
+			teamSizes: seasons.TeamSizesBoth,
+			assertions: []func(t *testing.T, err error){
+				func(t *testing.T, err error) {
+					assert.NoError(t, err)
+				},
+			},
+		},
+		"empty teams": {
+			entry:     GameEntry{Winners: players.Team{}},
+			teamSizes: seasons.TeamSizesBoth,
+			assertions: []func(t *testing.T, err error){
+				func(t *testing.T, err error) {
+					assert.ErrorIs(t, err, ErrInvalidGame)
+					assert.ErrorIs(t, err, ErrEmptyTeam)
+					assert.ErrorContains(t, err, "winners: team has no players")
+					assert.ErrorContains(t, err, "losers: team has no players")
+				},
+			},
+		},
+		"uneven teams": {
+			entry:     GameEntry{Winners: players.Team{alice, bob}, Losers: players.Team{carol}},
+			teamSizes: seasons.TeamSizesBoth,
+			assertions: []func(t *testing.T, err error){
+				func(t *testing.T, err error) {
+					var validationErr *ValidationError
+					assert.ErrorAs(t, err, &validationErr)
+					assert.Len(t, validationErr.Fields, 1)
+					assert.Equal(t, "losers", validationErr.Fields[0].Field)
+					assert.ErrorIs(t, err, ErrUnevenTeams)
+					assert.ErrorContains(t, err, "losers: teams have different sizes: 2 vs. 1 players")
+				},
+			},
+		},
+		"team size of the season": {
+			entry:     GameEntry{Winners: players.Team{alice}, Losers: players.Team{carol}},
+			teamSizes: seasons.TeamSizesDoubles,
+			assertions: []func(t *testing.T, err error){
+				func(t *testing.T, err error) {
+					var validationErr *ValidationError
+					assert.ErrorAs(t, err, &validationErr)
+					assert.Len(t, validationErr.Fields, 1)
+					assert.Equal(t, "losers", validationErr.Fields[0].Field)
+					assert.ErrorIs(t, err, ErrTeamSize)
+					assert.NotErrorIs(t, err, ErrUnevenTeams)
+				},
+			},
+		},
+		"player in both teams": {
+			entry:     GameEntry{Winners: players.Team{alice}, Losers: players.Team{alice}},
+			teamSizes: seasons.TeamSizesSingles,
+			assertions: []func(t *testing.T, err error){
+				func(t *testing.T, err error) {
+					assert.ErrorIs(t, err, ErrPlayerInBothTeams)
+					assert.ErrorContains(t, err, "losers: player plays in both teams: alice")
+				},
+			},
+		},
+		"played in the future": {
+			entry:     GameEntry{Winners: players.Team{alice}, Losers: players.Team{bob}, PlayedAt: now.Add(time.Hour)},
+			teamSizes: seasons.TeamSizesBoth,
+			assertions: []func(t *testing.T, err error){
+				func(t *testing.T, err error) {
+					assert.ErrorIs(t, err, ErrPlayedInFuture)
+				},
+			},
+		},
+		"all problems of the entry": {
+			entry: GameEntry{
+				Winners:  players.Team{alice, bob},
+				PlayedAt: now.Add(time.Hour),
+				Score:    &Score{Team1: 1, Team2: 5},
+			},
+			teamSizes: seasons.TeamSizesDoubles,
+			assertions: []func(t *testing.T, err error){
+				func(t *testing.T, err error) {
+					var validationErr *ValidationError
+					assert.ErrorAs(t, err, &validationErr)
+					fields := []string{}
+					for _, field := range validationErr.Fields {
+						fields = append(fields, field.Field)
+					}
+					assert.Equal(t, []string{"losers", "playedAt", "score"}, fields)
+				},
+			},
+		},
+		"invalid score": {
+			entry: GameEntry{
+				Winners: players.Team{alice},
+				Losers:  players.Team{bob},
+				Score:   &Score{Team1: 3, Team2: 10},
+			},
+			teamSizes: seasons.TeamSizesBoth,
+			assertions: []func(t *testing.T, err error){
+				func(t *testing.T, err error) {
+					assert.ErrorIs(t, err, ErrInvalidScore)
+					assert.ErrorContains(t, err, "score: ")
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validateGameEntry(test.entry, seasons.Season{Name: "2024", TeamSizes: test.teamSizes}, now)
+
+			for _, assertion := range test.assertions {
+				assertion(t, err)
+			}
+		})
+	}
+}
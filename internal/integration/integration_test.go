@@ -0,0 +1,1704 @@
+package integration
+
+import (
+	"bytes"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/spie/fskick/internal/avatars"
+	"github.com/spie/fskick/internal/cache"
+	"github.com/spie/fskick/internal/checks"
+	"github.com/spie/fskick/internal/client"
+	"github.com/spie/fskick/internal/db"
+	"github.com/spie/fskick/internal/dumps"
+	"github.com/spie/fskick/internal/games"
+	"github.com/spie/fskick/internal/logging"
+	"github.com/spie/fskick/internal/passwords"
+	"github.com/spie/fskick/internal/players"
+	"github.com/spie/fskick/internal/seasons"
+	"github.com/spie/fskick/internal/server"
+	"github.com/spie/fskick/internal/streaks"
+	"github.com/spie/fskick/internal/tournaments"
+	"github.com/spie/fskick/internal/users"
+	"github.com/spie/fskick/migrations"
+)
+
+const postgresDsnEnv = "FSKICK_TEST_POSTGRES_DSN"
+
+// constraintsMigration added the constraints checked by fskick db check.
+const constraintsMigration = 20261019150000
+
+type managers struct {
+	seasons seasons.Manager
+	players players.Manager
+	games   games.Manager
+	users   users.Manager
+}
+
+func newManagers(conn db.Connection) managers {
+	seasonsManager := seasons.NewManager(seasons.NewSeasonsRepository(conn))
+	playersManager := players.NewManager(players.NewPlayerRepository(conn))
+
+	return managers{
+		seasons: seasonsManager,
+		players: playersManager,
+		games: games.NewManager(
+			games.NewGamesRepository(conn),
+			games.NewAttendanceRepository(conn),
+			seasonsManager,
+			games.DefaultPoints(),
+		),
+		users: users.NewManager(users.NewUsersRepository(conn), playersManager, passwords.NewPasswordService()),
+	}
+}
+
+func TestSeasons(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, conn *sql.DB) {
+		m := newManagers(conn)
+
+		_, err := m.seasons.CreateSeason(t.Context(), "2024")
+		assert.NoError(t, err)
+		_, err = m.seasons.CreateSeason(t.Context(), "2025")
+		assert.NoError(t, err)
+
+		_, err = m.seasons.ActivateSeason(t.Context(), "2024")
+		assert.NoError(t, err)
+		season, err := m.seasons.ActivateSeason(t.Context(), "2025")
+		assert.NoError(t, err)
+
+		activeSeason, err := m.seasons.ActiveSeason(t.Context())
+		assert.NoError(t, err)
+		assert.Equal(t, season.UUID, activeSeason.UUID)
+
+		allSeasons, err := m.seasons.GetSeasons(t.Context())
+		assert.NoError(t, err)
+		assert.Len(t, allSeasons, 2)
+
+		_, err = m.seasons.GetSeasonByName(t.Context(), "2023")
+		assert.ErrorIs(t, err, seasons.ErrSeasonNotFound)
+	})
+}
+
+func TestPlayersAndUsers(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, conn *sql.DB) {
+		m := newManagers(conn)
+		createPlayers(t, m, "alice", "bob", "carol")
+
+		winners, losers, err := m.players.GetTeamsByNames(t.Context(), []string{"alice", "bob"}, []string{"carol"})
+		assert.NoError(t, err)
+		assert.Len(t, winners, 2)
+		assert.Len(t, losers, 1)
+
+		joinedAt := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+		player, err := m.players.UpdateProfile(t.Context(), losers[0], players.Profile{
+			DisplayName:       "Caro",
+			PreferredPosition: players.PositionDefense,
+			FavoriteSide:      players.SideLeft,
+			JoinedAt:          joinedAt,
+		})
+		assert.NoError(t, err)
+
+		player, err = m.players.GetPlayerByUUID(t.Context(), player.UUID)
+		assert.NoError(t, err)
+		assert.Equal(t, "Caro", player.DisplayName)
+		assert.Equal(t, players.PositionDefense, player.PreferredPosition)
+		assert.True(t, joinedAt.Equal(player.JoinedAt))
+
+		// joined_at is nullable, players without it are still found
+		execAll(t, conn, "UPDATE players SET joined_at = NULL WHERE name = 'bob'")
+		player, err = m.players.GetPlayerByName(t.Context(), "bob")
+		assert.NoError(t, err)
+		assert.True(t, player.JoinedAt.IsZero())
+
+		_, err = m.users.CreateUserFromPlayer(t.Context(), "alice", "alice@example.com", "secret")
+		assert.NoError(t, err)
+
+		user, err := m.users.Authenticate(t.Context(), "alice@example.com", "secret")
+		assert.NoError(t, err)
+		assert.Equal(t, "alice", user.Name)
+	})
+}
+
+func TestGames(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, conn *sql.DB) {
+		m := newManagers(conn)
+		season := createActiveSeason(t, m, "2024")
+		createPlayers(t, m, "alice", "bob", "carol", "dave")
+
+		playedAt := time.Date(2024, 5, 1, 18, 0, 0, 0, time.UTC)
+		createGame(t, m, playedAt, []string{"alice", "bob"}, []string{"carol", "dave"}, false)
+		createGame(t, m, playedAt.Add(time.Hour), []string{"alice", "carol"}, []string{"bob", "dave"}, false)
+		lastGame := createGame(t, m, playedAt.Add(2*time.Hour), []string{"alice", "dave"}, []string{"bob", "carol"}, true)
+
+		gamesCount, err := m.games.GetGamesCountForSeason(t.Context(), season, games.ModeAll)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, gamesCount)
+
+		recordedCount, err := m.games.GetGamesCountRecordedSince(t.Context(), time.Now().Add(-time.Hour))
+		assert.NoError(t, err)
+		assert.Equal(t, 3, recordedCount)
+
+		recordedCount, err = m.games.GetGamesCountRecordedSince(t.Context(), time.Now().Add(time.Hour))
+		assert.NoError(t, err)
+		assert.Equal(t, 0, recordedCount)
+
+		playerStats, err := m.games.GetPlayerStatsForSeason(t.Context(), season, games.ModeAll, "pointsRatio")
+		assert.NoError(t, err)
+		assert.Len(t, playerStats, 4)
+		assert.Equal(t, "alice", playerStats[0].Name)
+		assert.Equal(t, 2, playerStats[0].Wins)
+		assert.Equal(t, 1, playerStats[0].Draws)
+		assert.Equal(t, 7, playerStats[0].Points)
+
+		allPlayerStats, err := m.games.GetAllPlayerStats(t.Context(), games.ModeAll, "pointsRatio")
+		assert.NoError(t, err)
+		assert.Len(t, allPlayerStats, 4)
+
+		alice, err := m.players.GetPlayerByName(t.Context(), "alice")
+		assert.NoError(t, err)
+
+		fellowStats, err := m.games.GetFellowPlayerStats(t.Context(), alice, "pointsRatio")
+		assert.NoError(t, err)
+		assert.Len(t, fellowStats, 3)
+
+		oponentStats, err := m.games.GetOponentPlayerStats(t.Context(), alice, "pointsRatio")
+		assert.NoError(t, err)
+		assert.Len(t, oponentStats, 3)
+
+		firstPage, err := m.games.ListGames(t.Context(), games.GamesFilter{Player: &alice, Limit: 2})
+		assert.NoError(t, err)
+		assert.Len(t, firstPage.Games, 2)
+		assert.Equal(t, lastGame.UUID, firstPage.Games[0].UUID)
+		assert.NotEmpty(t, firstPage.NextCursor)
+
+		secondPage, err := m.games.ListGames(t.Context(), games.GamesFilter{Player: &alice, Limit: 2, Cursor: firstPage.NextCursor})
+		assert.NoError(t, err)
+		assert.Len(t, secondPage.Games, 1)
+		assert.Empty(t, secondPage.NextCursor)
+
+		wins, err := m.games.ListGames(t.Context(), games.GamesFilter{Player: &alice, Outcome: games.OutcomeWin})
+		assert.NoError(t, err)
+		assert.Len(t, wins.Games, 2)
+
+		game, err := m.games.GetGameByUUID(t.Context(), lastGame.UUID)
+		assert.NoError(t, err)
+		assert.Len(t, game.Attendances, 4)
+		assert.Equal(t, games.OutcomeDraw, game.Outcome())
+
+		impacts, err := m.games.GetGameImpacts(t.Context(), game)
+		assert.NoError(t, err)
+		assert.Len(t, impacts, 4)
+
+		_, err = m.games.GetGameByUUID(t.Context(), "unknown")
+		assert.ErrorIs(t, err, games.ErrGameNotFound)
+	})
+}
+
+func TestImportAndDump(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, conn *sql.DB) {
+		m := newManagers(conn)
+		createActiveSeason(t, m, "2024")
+
+		records, err := games.ParseImportCSV(strings.NewReader(
+			"date,winners,losers,score\n" +
+				"2024-05-01,alice;bob,carol;dave,10:8\n" +
+				"2024-05-02,alice;carol,bob;dave,10:10\n",
+		))
+		assert.NoError(t, err)
+
+		importer := games.NewImporter(conn)
+		report, err := importer.ImportGames(t.Context(), records, games.ImportOptions{CreatePlayers: true})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, report.Imported)
+		assert.Len(t, report.CreatedPlayers, 4)
+
+		report, err = importer.ImportGames(t.Context(), records, games.ImportOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, 0, report.Imported)
+		assert.Len(t, report.Duplicates, 2)
+
+		uneven, err := games.ParseImportCSV(strings.NewReader("date,winners,losers\n2024-05-03,alice;bob,carol\n"))
+		assert.NoError(t, err)
+		report, err = importer.ImportGames(t.Context(), uneven, games.ImportOptions{})
+		assert.ErrorIs(t, err, games.ErrImportConflicts)
+		assert.Equal(t, []games.ImportProblem{{Line: 2, Message: "losers: teams have different sizes: 2 vs. 1 players"}}, report.Conflicts)
+
+		var buffer bytes.Buffer
+		err = dumps.NewManager(conn).Export(t.Context(), &buffer, dumps.ExportOptions{Format: dumps.FormatNDJSON})
+		assert.NoError(t, err)
+
+		archive, err := dumps.ReadArchive(&buffer, dumps.FormatNDJSON)
+		assert.NoError(t, err)
+		assert.Len(t, archive.Games, 2)
+		assert.Len(t, archive.Attendances, 8)
+
+		restoreReport, err := dumps.NewManager(conn).Restore(t.Context(), archive)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, restoreReport.Games)
+		assert.Equal(t, 15, restoreReport.Skipped)
+	})
+}
+
+func TestAvatars(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, conn *sql.DB) {
+		repository := avatars.NewAvatarsRepository(conn)
+
+		err := repository.StoreAvatar(t.Context(), "avatar.png", "image/png", []byte{0x89, 0x50, 0x4e, 0x47})
+		assert.NoError(t, err)
+
+		data, err := repository.LoadAvatar(t.Context(), "avatar.png")
+		assert.NoError(t, err)
+		assert.Equal(t, []byte{0x89, 0x50, 0x4e, 0x47}, data)
+
+		err = repository.DeleteAvatar(t.Context(), "avatar.png")
+		assert.NoError(t, err)
+
+		_, err = repository.LoadAvatar(t.Context(), "avatar.png")
+		assert.ErrorIs(t, err, avatars.ErrAvatarNotFound)
+	})
+}
+
+func TestStatsCache(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, conn *sql.DB) {
+		statsCache := cache.New(time.Minute)
+		m := newManagers(conn)
+		m.seasons = m.seasons.WithCache(statsCache)
+		m.players = m.players.WithCache(statsCache)
+		m.games = m.games.WithCache(statsCache)
+		streaksManager := streaks.NewManager(games.NewAttendanceRepository(conn)).WithCache(statsCache)
+
+		season := createActiveSeason(t, m, "2024")
+		createPlayers(t, m, "alice", "bob")
+
+		playedAt := time.Date(2024, 5, 1, 18, 0, 0, 0, time.UTC)
+		createGame(t, m, playedAt, []string{"alice"}, []string{"bob"}, false)
+
+		for range 2 {
+			playerStats, err := m.games.GetPlayerStatsForSeason(t.Context(), season, games.ModeAll, "pointsRatio")
+			assert.NoError(t, err)
+			assert.Equal(t, 1, playerStats[0].Games)
+
+			winningStreak, _, err := streaksManager.GetLongestWinningAndLosingStreaks(t.Context())
+			assert.NoError(t, err)
+			assert.Equal(t, 1, winningStreak.Number)
+		}
+		assert.Equal(t, cache.Stats{Hits: 2, Misses: 2, Entries: 2}, statsCache.Stats())
+
+		createGame(t, m, playedAt.Add(time.Hour), []string{"alice"}, []string{"bob"}, false)
+		assert.Equal(t, 0, statsCache.Stats().Entries)
+
+		playerStats, err := m.games.GetPlayerStatsForSeason(t.Context(), season, games.ModeAll, "pointsRatio")
+		assert.NoError(t, err)
+		assert.Equal(t, 2, playerStats[0].Games)
+
+		winningStreak, _, err := streaksManager.GetLongestWinningAndLosingStreaks(t.Context())
+		assert.NoError(t, err)
+		assert.Equal(t, 2, winningStreak.Number)
+
+		createPlayers(t, m, "carol")
+		assert.Equal(t, 0, statsCache.Stats().Entries)
+	})
+}
+
+func TestChecks(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, conn *sql.DB) {
+		m := newManagers(conn)
+		createActiveSeason(t, m, "2024")
+		createPlayers(t, m, "alice", "bob", "carol", "dave")
+
+		playedAt := time.Date(2024, 5, 1, 18, 0, 0, 0, time.UTC)
+		createGame(t, m, playedAt, []string{"alice", "bob"}, []string{"carol", "dave"}, false)
+		unbalancedGame := createGame(t, m, playedAt.Add(time.Hour), []string{"alice", "bob"}, []string{"carol", "dave"}, false)
+		repeatedGame := createGame(t, m, playedAt.Add(2*time.Hour), []string{"alice"}, []string{"bob"}, false)
+		emptyTeamGame := createGame(t, m, playedAt.Add(3*time.Hour), []string{"alice"}, []string{"bob"}, false)
+
+		alice, err := m.players.GetPlayerByName(t.Context(), "alice")
+		assert.NoError(t, err)
+		dave, err := m.players.GetPlayerByName(t.Context(), "dave")
+		assert.NoError(t, err)
+
+		// The inconsistencies can only be stored without the constraints.
+		migrator := migrateDownBeforeConstraints(t, conn)
+
+		execAll(t, conn,
+			fmt.Sprintf("DELETE FROM attendances WHERE game_id = %d AND player_id = %d", unbalancedGame.ID, dave.ID),
+			fmt.Sprintf("DELETE FROM attendances WHERE game_id = %d AND team = 2", emptyTeamGame.ID),
+			fmt.Sprintf(
+				`INSERT INTO attendances (uuid, team, outcome, player_id, game_id, created_at, updated_at)
+				VALUES ('repeated', 2, 'loss', %d, %d, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
+				alice.ID,
+				repeatedGame.ID,
+			),
+			`INSERT INTO attendances (uuid, team, outcome, player_id, game_id, created_at, updated_at)
+			VALUES ('orphaned', 1, 'win', 999, 999, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
+			`INSERT INTO seasons (uuid, name, active, created_at, updated_at) VALUES ('2025', '2025', true, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
+		)
+
+		checksManager := checks.NewManager(checks.NewChecksRepository(conn))
+		report, err := checksManager.Check(t.Context())
+		assert.NoError(t, err)
+		assert.Len(t, report.OrphanedAttendances, 1)
+		assert.Len(t, report.RepeatedAttendances, 1)
+		assert.True(t, report.RepeatedAttendances[0].BothTeams)
+		assert.Len(t, report.InvalidTeams, 3)
+		assert.Equal(t, unbalancedGame.UUID, report.InvalidTeams[0].GameUUID)
+		assert.Len(t, report.ActiveSeasons, 2)
+
+		// games are only deleted when asked for
+		fixReport, err := checksManager.Fix(t.Context(), checks.FixOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), fixReport.DeletedAttendances)
+		assert.Equal(t, int64(0), fixReport.DeletedGames)
+		assert.Equal(t, int64(1), fixReport.DeactivatedSeasons)
+
+		fixReport, err = checksManager.Fix(t.Context(), checks.FixOptions{DeleteGames: true})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), fixReport.DeletedAttendances)
+		assert.Equal(t, int64(1), fixReport.DeletedGames)
+
+		report, err = checksManager.Check(t.Context())
+		assert.NoError(t, err)
+		assert.Empty(t, report.OrphanedAttendances)
+		assert.Empty(t, report.RepeatedAttendances)
+		assert.Len(t, report.InvalidTeams, 1)
+		assert.Equal(t, unbalancedGame.UUID, report.InvalidTeams[0].GameUUID)
+		assert.Len(t, report.ActiveSeasons, 1)
+
+		_, err = migrator.Up(t.Context())
+		assert.NoError(t, err)
+	})
+}
+
+func TestConstraints(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, conn *sql.DB) {
+		m := newManagers(conn)
+		createActiveSeason(t, m, "2024")
+		createPlayers(t, m, "alice", "bob")
+
+		err := players.NewPlayerRepository(conn).CreatePlayer(t.Context(), &players.Player{Name: "alice"})
+		assert.ErrorIs(t, err, players.ErrPlayerExists)
+
+		err = seasons.NewSeasonsRepository(conn).CreateSeason(t.Context(), &seasons.Season{Name: "2024"})
+		assert.ErrorIs(t, err, seasons.ErrSeasonExists)
+
+		alice, err := m.players.GetPlayerByName(t.Context(), "alice")
+		assert.NoError(t, err)
+		bob, err := m.players.GetPlayerByName(t.Context(), "bob")
+		assert.NoError(t, err)
+
+		playedAt := time.Date(2024, 5, 1, 18, 0, 0, 0, time.UTC)
+		_, err = m.games.CreateGame(t.Context(), games.GameEntry{
+			PlayedAt: playedAt,
+			Winners:  players.Team{alice, alice},
+			Losers:   players.Team{bob, bob},
+		})
+		assert.ErrorIs(t, err, games.ErrDuplicateAttendance)
+
+		_, err = m.games.CreateGame(t.Context(), games.GameEntry{
+			PlayedAt: playedAt,
+			Winners:  players.Team{alice},
+			Losers:   players.Team{{Name: "unknown"}},
+		})
+		assert.ErrorIs(t, err, games.ErrUnknownReference)
+
+		gamesCount, err := m.games.GetGamesCount(t.Context(), games.ModeAll)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, gamesCount)
+
+		_, err = conn.Exec(
+			`INSERT INTO seasons (uuid, name, active, created_at, updated_at)
+			VALUES ('2025', '2025', true, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
+		)
+		assert.True(t, db.IsUniqueViolation(err))
+
+		_, err = m.users.CreateUserFromPlayer(t.Context(), "alice", "alice@example.com", "secret")
+		assert.NoError(t, err)
+		_, err = m.users.CreateUserFromPlayer(t.Context(), "bob", "alice@example.com", "secret")
+		assert.ErrorIs(t, err, users.ErrEmailExists)
+	})
+}
+
+func TestConstraintsMigration(t *testing.T) {
+	tests := map[string]struct {
+		sqliteOnly bool
+		queries    []string
+		assertions []func(t *testing.T, conn *sql.DB, m managers)
+	}{
+		"with game of missing season": {
+			queries: []string{"UPDATE games SET season_id = 999"},
+			assertions: []func(t *testing.T, conn *sql.DB, m managers){
+				func(t *testing.T, conn *sql.DB, m managers) {
+					season, err := m.seasons.GetSeasonByName(t.Context(), "Recovered games")
+					assert.NoError(t, err)
+					assert.False(t, season.Active)
+
+					gamesCount, err := m.games.GetGamesCountForSeason(t.Context(), season, games.ModeAll)
+					assert.NoError(t, err)
+					assert.Equal(t, 1, gamesCount)
+				},
+			},
+		},
+		// postgres checks the recorder since the column was added
+		"with game recorded by missing player": {
+			sqliteOnly: true,
+			queries:    []string{"UPDATE games SET recorded_by_id = 999"},
+			assertions: []func(t *testing.T, conn *sql.DB, m managers){
+				func(t *testing.T, conn *sql.DB, m managers) {
+					var recordedBy sql.NullInt64
+					err := conn.QueryRow("SELECT recorded_by_id FROM games").Scan(&recordedBy)
+					assert.NoError(t, err)
+					assert.False(t, recordedBy.Valid)
+				},
+			},
+		},
+		"with duplicate season name": {
+			queries: []string{
+				`INSERT INTO seasons (uuid, name, active, created_at, updated_at)
+				VALUES ('duplicate', '2024', false, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
+			},
+			assertions: []func(t *testing.T, conn *sql.DB, m managers){
+				func(t *testing.T, conn *sql.DB, m managers) {
+					season, err := m.seasons.GetSeasonByUuid(t.Context(), "duplicate")
+					assert.NoError(t, err)
+					assert.Equal(t, fmt.Sprintf("2024 (%d)", season.ID), season.Name)
+
+					season, err = m.seasons.GetSeasonByName(t.Context(), "2024")
+					assert.NoError(t, err)
+					assert.True(t, season.Active)
+				},
+			},
+		},
+		"with duplicate player name": {
+			queries: []string{
+				`INSERT INTO players (uuid, name, created_at, updated_at)
+				VALUES ('duplicate', 'alice', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
+			},
+			assertions: []func(t *testing.T, conn *sql.DB, m managers){
+				func(t *testing.T, conn *sql.DB, m managers) {
+					player, err := m.players.GetPlayerByUUID(t.Context(), "duplicate")
+					assert.NoError(t, err)
+					assert.Equal(t, fmt.Sprintf("alice (%d)", player.ID), player.Name)
+				},
+			},
+		},
+		"with duplicate email": {
+			queries: []string{"UPDATE players SET email = 'same@example.com', password = 'hash'"},
+			assertions: []func(t *testing.T, conn *sql.DB, m managers){
+				func(t *testing.T, conn *sql.DB, m managers) {
+					var emails []string
+					rows, err := conn.Query("SELECT COALESCE(email, '') FROM players ORDER BY id ASC")
+					assert.NoError(t, err)
+					defer rows.Close()
+					for rows.Next() {
+						var email string
+						assert.NoError(t, rows.Scan(&email))
+						emails = append(emails, email)
+					}
+					assert.Equal(t, []string{"same@example.com", ""}, emails)
+				},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			forEachDriver(t, func(t *testing.T, conn *sql.DB) {
+				if tt.sqliteOnly && isPostgres(conn) {
+					t.Skip("the row can not be stored in postgres")
+				}
+
+				m := newManagers(conn)
+				createActiveSeason(t, m, "2024")
+				createPlayers(t, m, "alice", "bob")
+				createGame(t, m, time.Date(2024, 5, 1, 18, 0, 0, 0, time.UTC), []string{"alice"}, []string{"bob"}, false)
+
+				migrator := migrateDownBeforeConstraints(t, conn)
+				execWithoutForeignKeys(t, conn, tt.queries...)
+
+				_, err := migrator.Up(t.Context())
+				assert.NoError(t, err)
+
+				for _, assertion := range tt.assertions {
+					assertion(t, conn, m)
+				}
+			})
+		})
+	}
+}
+
+func TestMigrator(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, conn *sql.DB) {
+		migrator := newTestMigrator(t, conn)
+
+		statuses, err := migrator.Status(t.Context())
+		assert.NoError(t, err)
+		latest := statuses[len(statuses)-1]
+		assert.True(t, latest.Applied)
+
+		result, err := migrator.Down(t.Context())
+		assert.NoError(t, err)
+		assert.Equal(t, latest.Version, result.Version)
+
+		results, err := migrator.Up(t.Context())
+		assert.NoError(t, err)
+		assert.Len(t, results, 1)
+
+		results, err = migrator.Redo(t.Context())
+		assert.NoError(t, err)
+		assert.Len(t, results, 2)
+	})
+}
+
+func TestHealth(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, conn *sql.DB) {
+		migrator := newTestMigrator(t, conn)
+		controller := server.NewHealthController(conn, migrator)
+
+		res := httptest.NewRecorder()
+		controller.Readyz(res, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, `{"status":"ok","checks":{"database":"ok","migrations":"ok"}}`, res.Body.String())
+
+		_, err := migrator.Down(t.Context())
+		assert.NoError(t, err)
+
+		res = httptest.NewRecorder()
+		controller.Readyz(res, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, res.Code)
+		assert.JSONEq(t, `{"status":"failed","checks":{"database":"ok","migrations":"failed"}}`, res.Body.String())
+
+		res = httptest.NewRecorder()
+		controller.Healthz(res, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		assert.Equal(t, http.StatusOK, res.Code)
+
+		_, err = migrator.Up(t.Context())
+		assert.NoError(t, err)
+
+		conn.Close()
+
+		res = httptest.NewRecorder()
+		controller.Healthz(res, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, res.Code)
+	})
+}
+
+func TestBackup(t *testing.T) {
+	conn := openTestDb(t, db.CreateDbConfig(db.DriverSQLite, filepath.Join(t.TempDir(), "fskick.db"), false, false))
+	m := newManagers(conn)
+	createPlayers(t, m, "alice")
+
+	path := filepath.Join(t.TempDir(), "backup.db")
+	err := db.Backup(t.Context(), conn, db.DriverSQLite, path)
+	assert.NoError(t, err)
+
+	backupConn, err := db.OpenDbConnection(db.CreateDbConfig(db.DriverSQLite, path, false, false))
+	assert.NoError(t, err)
+	defer backupConn.Close()
+
+	player, err := players.NewManager(players.NewPlayerRepository(backupConn)).GetPlayerByName(t.Context(), "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", player.Name)
+
+	err = db.Backup(t.Context(), conn, db.DriverSQLite, path)
+	assert.ErrorIs(t, err, db.ErrBackupExists)
+
+	err = db.Backup(t.Context(), conn, db.DriverPostgres, path)
+	assert.ErrorIs(t, err, db.ErrBackupNotSupported)
+}
+
+func TestQueryLogger(t *testing.T) {
+	conn := openTestDb(t, db.CreateDbConfig(db.DriverSQLite, filepath.Join(t.TempDir(), "fskick.db"), false, false))
+	output := &bytes.Buffer{}
+	logger, err := logging.New(output, logging.FormatText, slog.LevelDebug)
+	assert.NoError(t, err)
+	ctx := logging.WithRequestID(t.Context(), "some-id")
+
+	debug := db.CreateDbConfig(db.DriverSQLite, "", true, false)
+	m := newManagers(db.WithQueryLogger(conn, debug, logger))
+	createActiveSeason(t, m, "2024")
+
+	alice, err := m.players.CreatePlayer(ctx, "alice")
+	assert.NoError(t, err)
+	bob, err := m.players.CreatePlayer(ctx, "bob")
+	assert.NoError(t, err)
+	_, err = m.games.CreateGame(ctx, games.GameEntry{PlayedAt: time.Now(), Winners: players.Team{alice}, Losers: players.Team{bob}})
+	assert.NoError(t, err)
+
+	assert.Contains(t, output.String(), `msg=query query="INSERT INTO players`)
+	assert.Contains(t, output.String(), `msg=query query="INSERT INTO attendances`)
+	assert.Contains(t, output.String(), "args=10")
+	assert.NotContains(t, output.String(), "alice")
+	assert.Contains(t, output.String(), "request_id=some-id")
+
+	output.Reset()
+	slowQueries := db.CreateDbConfig(db.DriverSQLite, "", false, true).WithSlowQuery(time.Nanosecond)
+	m = newManagers(db.WithQueryLogger(conn, slowQueries, logger))
+
+	_, err = m.players.GetPlayers(ctx)
+	assert.NoError(t, err)
+
+	assert.Contains(t, output.String(), `level=WARN msg="slow query"`)
+	assert.NotContains(t, output.String(), "args=")
+
+	output.Reset()
+	m = newManagers(db.WithQueryLogger(conn, db.CreateDbConfig(db.DriverSQLite, "", false, false), logger))
+
+	_, err = m.players.GetPlayers(ctx)
+	assert.NoError(t, err)
+
+	assert.Empty(t, output.String())
+}
+
+// forEachDriver runs the test against a migrated sqlite3 database and, if configured, a
+// migrated postgres database.
+func forEachDriver(t *testing.T, test func(t *testing.T, conn *sql.DB)) {
+	t.Run(db.DriverSQLite, func(t *testing.T) {
+		database := filepath.Join(t.TempDir(), "fskick.db")
+		test(t, openTestDb(t, db.CreateDbConfig(db.DriverSQLite, database, false, false)))
+	})
+
+	t.Run(db.DriverPostgres, func(t *testing.T) {
+		dsn := os.Getenv(postgresDsnEnv)
+		if dsn == "" {
+			t.Skipf("%s is not set", postgresDsnEnv)
+		}
+
+		test(t, openTestDb(t, db.CreateDbConfig(db.DriverPostgres, createTestSchema(t, dsn), false, false)))
+	})
+}
+
+func openTestDb(t *testing.T, cfg db.DbConfig) *sql.DB {
+	conn, err := db.OpenDbConnection(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	err = db.MigrateFS(conn, cfg.Driver(), migrations.FS, migrations.Dir(cfg.Driver()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return conn
+}
+
+// newTestMigrator creates a migrator for the driver of conn, sqlite3 has no version() function.
+func newTestMigrator(t *testing.T, conn *sql.DB) db.Migrator {
+	driver := db.DriverSQLite
+	if isPostgres(conn) {
+		driver = db.DriverPostgres
+	}
+
+	migrator, err := db.NewMigrator(conn, driver, migrations.FS, migrations.Dir(driver))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return migrator
+}
+
+// createTestSchema creates a schema dropped after the test and returns the connection string
+// using it.
+func createTestSchema(t *testing.T, dsn string) string {
+	conn, err := db.OpenDbConnection(db.CreateDbConfig(db.DriverPostgres, dsn, false, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	suffix := make([]byte, 4)
+	_, err = rand.Read(suffix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := fmt.Sprintf("fskick_test_%s", hex.EncodeToString(suffix))
+
+	_, err = conn.Exec(fmt.Sprintf("CREATE SCHEMA %s", schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		conn, err := db.OpenDbConnection(db.CreateDbConfig(db.DriverPostgres, dsn, false, false))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+
+		_, err = conn.Exec(fmt.Sprintf("DROP SCHEMA %s CASCADE", schema))
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		dsnUrl, err := url.Parse(dsn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		query := dsnUrl.Query()
+		query.Set("search_path", schema)
+		dsnUrl.RawQuery = query.Encode()
+
+		return dsnUrl.String()
+	}
+
+	return fmt.Sprintf("%s search_path=%s", dsn, schema)
+}
+
+// migrateDownBeforeConstraints rolls the migrations back including the constraints migration.
+func migrateDownBeforeConstraints(t *testing.T, conn *sql.DB) db.Migrator {
+	migrator := newTestMigrator(t, conn)
+	for {
+		result, err := migrator.Down(t.Context())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Version <= constraintsMigration {
+			return migrator
+		}
+	}
+}
+
+func isPostgres(conn *sql.DB) bool {
+	_, err := conn.Exec("SELECT version()")
+
+	return err == nil
+}
+
+// execWithoutForeignKeys runs the queries on one connection with the foreign keys of sqlite3
+// switched off.
+func execWithoutForeignKeys(t *testing.T, conn *sql.DB, queries ...string) {
+	c, err := conn.Conn(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if !isPostgres(conn) {
+		_, err = c.ExecContext(t.Context(), "PRAGMA foreign_keys = OFF")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.ExecContext(t.Context(), "PRAGMA foreign_keys = ON")
+	}
+
+	for _, query := range queries {
+		_, err = c.ExecContext(t.Context(), query)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func execAll(t *testing.T, conn *sql.DB, queries ...string) {
+	for _, query := range queries {
+		_, err := conn.Exec(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func createActiveSeason(t *testing.T, m managers, name string) seasons.Season {
+	_, err := m.seasons.CreateSeason(t.Context(), name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	season, err := m.seasons.ActivateSeason(t.Context(), name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return season
+}
+
+func createPlayers(t *testing.T, m managers, names ...string) {
+	for _, name := range names {
+		_, err := m.players.CreatePlayer(t.Context(), name)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func createGame(t *testing.T, m managers, playedAt time.Time, winnerNames []string, loserNames []string, draw bool) *games.Game {
+	winners, losers, err := m.players.GetTeamsByNames(t.Context(), winnerNames, loserNames)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	game, err := m.games.CreateGame(t.Context(), games.GameEntry{PlayedAt: playedAt, Winners: winners, Losers: losers, Draw: draw})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return game
+}
+
+func TestRemote(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, conn *sql.DB) {
+		m := newManagers(conn)
+		createPlayers(t, m, "alice", "bob")
+		_, err := m.users.CreateUserFromPlayer(t.Context(), "alice", "alice@example.com", "secret")
+		assert.NoError(t, err)
+		token, err := m.users.CreateApiToken(t.Context(), "alice@example.com")
+		assert.NoError(t, err)
+		_, err = m.users.CreateUserFromPlayer(t.Context(), "bob", "bob@example.com", "secret")
+		assert.NoError(t, err)
+		bobToken, err := m.users.CreateApiToken(t.Context(), "bob@example.com")
+		assert.NoError(t, err)
+
+		gamesController := server.NewGamesController(
+			m.games,
+			m.seasons,
+			m.players,
+			streaks.NewManager(games.NewAttendanceRepository(conn)),
+			server.NewGamesViews(),
+		)
+		seasonsController := server.NewSeasonsController(m.seasons)
+		playersController := server.NewPlayersController(
+			m.players,
+			avatars.NewManager(avatars.NewAvatarsRepository(conn), m.players),
+		)
+		authenticator := server.NewAuthenticator(m.users).WithAdmins([]string{"alice@example.com"})
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /api/seasons", seasonsController.GetSeasons)
+		mux.HandleFunc("POST /api/seasons", authenticator.Admin(seasonsController.CreateSeason))
+		mux.HandleFunc("POST /api/seasons/{season}/activate", authenticator.Admin(seasonsController.ActivateSeason))
+		mux.HandleFunc("GET /api/seasons/table/{season}", gamesController.GetSeasonsTable)
+		mux.HandleFunc("GET /api/players", gamesController.GetPlayers)
+		mux.HandleFunc("POST /api/players", authenticator.Authenticated(playersController.CreatePlayer))
+		mux.HandleFunc("GET /api/players/lookup", playersController.LookupPlayer)
+		mux.HandleFunc("PUT /api/players/{player}/profile", authenticator.Authenticated(playersController.UpdateProfile))
+		mux.HandleFunc("POST /api/players/{player}/avatar", authenticator.Authenticated(playersController.UploadAvatar))
+		mux.HandleFunc("GET /api/games", gamesController.ListGames)
+		mux.HandleFunc("GET /api/games/count", gamesController.GetGamesCount)
+		mux.HandleFunc("POST /api/games", authenticator.Authenticated(gamesController.CreateGame))
+		api := httptest.NewServer(mux)
+		defer api.Close()
+
+		remote, err := client.New(api.URL, token)
+		assert.NoError(t, err)
+		seasonsManager := client.NewSeasonsManager(remote)
+		playersManager := client.NewPlayersManager(remote)
+		gamesManager := client.NewGamesManager(remote)
+
+		anonymous, err := client.New(api.URL, "")
+		assert.NoError(t, err)
+		_, err = client.NewSeasonsManager(anonymous).CreateSeason(t.Context(), "2024")
+		assert.ErrorIs(t, err, client.ErrUnauthorized)
+		bobRemote, err := client.New(api.URL, bobToken)
+		assert.NoError(t, err)
+		_, err = client.NewSeasonsManager(bobRemote).CreateSeason(t.Context(), "2024")
+		assert.ErrorIs(t, err, client.ErrForbidden)
+
+		_, err = seasonsManager.CreateSeason(t.Context(), "2024")
+		assert.NoError(t, err)
+		_, err = seasonsManager.CreateSeason(t.Context(), "2024")
+		assert.ErrorIs(t, err, seasons.ErrSeasonExists)
+		season, err := seasonsManager.ActivateSeason(t.Context(), "2024")
+		assert.NoError(t, err)
+		assert.True(t, season.Active)
+		_, err = seasonsManager.GetSeasonByName(t.Context(), "2025")
+		assert.ErrorIs(t, err, seasons.ErrSeasonNotFound)
+
+		_, err = playersManager.CreatePlayer(t.Context(), "carol")
+		assert.NoError(t, err)
+		_, _, err = playersManager.GetTeamsByNames(t.Context(), []string{"alice", "dave"}, []string{"carol"})
+		assert.ErrorContains(t, err, "Players not found: dave")
+		_, err = playersManager.CreatePlayer(t.Context(), "dave")
+		assert.NoError(t, err)
+
+		winners, losers, err := playersManager.GetTeamsByNames(t.Context(), []string{"alice", "bob"}, []string{"carol", "dave"})
+		assert.NoError(t, err)
+		game, err := gamesManager.CreateGame(t.Context(), games.GameEntry{
+			Winners:        winners,
+			Losers:         losers,
+			Positions:      games.Positions{winners[0].UUID: players.PositionDefense, winners[1].UUID: players.PositionOffense},
+			Score:          &games.Score{Team1: 10, Team2: 7},
+			IdempotencyKey: "game-1",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, games.OutcomeWin, game.Outcome())
+		assert.Equal(t, &games.Score{Team1: 10, Team2: 7}, game.Score)
+
+		retriedGame, err := gamesManager.CreateGame(t.Context(), games.GameEntry{
+			Winners:        winners,
+			Losers:         losers,
+			Positions:      games.Positions{winners[0].UUID: players.PositionDefense, winners[1].UUID: players.PositionOffense},
+			Score:          &games.Score{Team1: 10, Team2: 7},
+			IdempotencyKey: "game-1",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, game.UUID, retriedGame.UUID)
+
+		_, err = gamesManager.CreateGame(t.Context(), games.GameEntry{
+			Winners:        winners,
+			Losers:         losers,
+			IdempotencyKey: "game-1",
+		})
+		assert.ErrorContains(t, err, "422 Unprocessable Entity: "+games.ErrIdempotencyKeyReused.Error())
+
+		gamesPage, err := gamesManager.ListGames(t.Context(), games.GamesFilter{Player: &losers[0]})
+		assert.NoError(t, err)
+		assert.Len(t, gamesPage.Games, 1)
+		assert.Equal(t, players.PositionDefense, gamesPage.Games[0].Team(1)[0].Position)
+		assert.Equal(t, "bob", gamesPage.Games[0].Team(1)[1].Player.Name)
+
+		gamesCount, err := gamesManager.GetGamesCountForSeason(t.Context(), season, games.ModeAll)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, gamesCount)
+		playerStats, err := gamesManager.GetPlayerStatsForSeason(t.Context(), season, games.ModeAll, "pointsRatio")
+		assert.NoError(t, err)
+		assert.Len(t, playerStats, 4)
+		assert.Equal(t, 1, playerStats[0].Wins)
+
+		player, err := playersManager.UpdateProfile(t.Context(), losers[0], players.Profile{
+			DisplayName:  "Caro",
+			FavoriteSide: players.SideRight,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "Caro", player.DisplayName)
+
+		player, err = m.players.GetPlayerByName(t.Context(), "carol")
+		assert.NoError(t, err)
+		assert.Equal(t, players.SideRight, player.FavoriteSide)
+
+		_, err = client.NewPlayersManager(bobRemote).UpdateProfile(t.Context(), losers[0], players.Profile{})
+		assert.ErrorIs(t, err, client.ErrForbidden)
+		_, err = client.NewPlayersManager(bobRemote).UpdateProfile(t.Context(), winners[1], players.Profile{DisplayName: "Bobby"})
+		assert.NoError(t, err)
+
+		// admins change the avatars of all players like their profiles
+		uploadAvatar := func(playerUuid string, token string) int {
+			var body bytes.Buffer
+			writer := multipart.NewWriter(&body)
+			part, err := writer.CreateFormFile("avatar", "avatar.png")
+			assert.NoError(t, err)
+			assert.NoError(t, png.Encode(part, image.NewRGBA(image.Rect(0, 0, 8, 8))))
+			assert.NoError(t, writer.Close())
+
+			req, err := http.NewRequest(http.MethodPost, api.URL+"/api/players/"+playerUuid+"/avatar", &body)
+			assert.NoError(t, err)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			req.Header.Set("Authorization", "Bearer "+token)
+			res, err := http.DefaultClient.Do(req)
+			assert.NoError(t, err)
+			res.Body.Close()
+
+			return res.StatusCode
+		}
+		assert.Equal(t, http.StatusForbidden, uploadAvatar(losers[0].UUID, bobToken))
+		assert.Equal(t, http.StatusOK, uploadAvatar(winners[1].UUID, bobToken))
+		assert.Equal(t, http.StatusOK, uploadAvatar(losers[0].UUID, token))
+	})
+}
+
+func TestIdempotencyKeys(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, conn *sql.DB) {
+		m := newManagers(conn)
+		createActiveSeason(t, m, "2024")
+		createPlayers(t, m, "alice", "bob", "carol")
+
+		alice, err := m.players.GetPlayerByName(t.Context(), "alice")
+		assert.NoError(t, err)
+		bob, err := m.players.GetPlayerByName(t.Context(), "bob")
+		assert.NoError(t, err)
+		winners, losers, err := m.players.GetTeamsByNames(t.Context(), []string{"alice"}, []string{"bob"})
+		assert.NoError(t, err)
+		playedAt := time.Date(2024, 5, 1, 18, 0, 0, 0, time.UTC)
+		entry := games.GameEntry{
+			PlayedAt:       playedAt,
+			Winners:        winners,
+			Losers:         losers,
+			RecordedBy:     &alice,
+			IdempotencyKey: "key-1",
+		}
+
+		game, err := m.games.CreateGame(t.Context(), entry)
+		assert.NoError(t, err)
+
+		retriedGame, err := m.games.CreateGame(t.Context(), entry)
+		assert.NoError(t, err)
+		assert.Equal(t, game.UUID, retriedGame.UUID)
+
+		otherEntry := entry
+		otherEntry.Draw = true
+		_, err = m.games.CreateGame(t.Context(), otherEntry)
+		assert.ErrorIs(t, err, games.ErrIdempotencyKeyReused)
+
+		// the keys of other recorders do not match
+		otherEntry.RecordedBy = &bob
+		otherEntry.AllowDuplicate = true
+		bobsGame, err := m.games.CreateGame(t.Context(), otherEntry)
+		assert.NoError(t, err)
+		assert.NotEqual(t, game.UUID, bobsGame.UUID)
+
+		otherEntry.RecordedBy = nil
+		unrecordedGame, err := m.games.CreateGame(t.Context(), otherEntry)
+		assert.NoError(t, err)
+		retriedGame, err = m.games.CreateGame(t.Context(), otherEntry)
+		assert.NoError(t, err)
+		assert.Equal(t, unrecordedGame.UUID, retriedGame.UUID)
+
+		gamesCount, err := m.games.GetGamesCount(t.Context(), games.ModeAll)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, gamesCount)
+	})
+}
+
+func TestGameForm(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, conn *sql.DB) {
+		m := newManagers(conn)
+		createActiveSeason(t, m, "2024")
+		createPlayers(t, m, "alice", "bob", "carol", "dave")
+		_, err := m.users.CreateUserFromPlayer(t.Context(), "alice", "alice@example.com", "secret")
+		assert.NoError(t, err)
+
+		gamesViews := server.NewGamesViews()
+		gamesController := server.NewGamesController(
+			m.games,
+			m.seasons,
+			m.players,
+			streaks.NewManager(games.NewAttendanceRepository(conn)),
+			gamesViews,
+		)
+		authenticator := server.NewAuthenticator(m.users)
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /games/new", authenticator.Authenticated(gamesController.NewGame))
+		mux.HandleFunc("POST /games", server.SameOrigin(authenticator.Authenticated(gamesController.SubmitGame)))
+		web := httptest.NewServer(mux)
+		defer web.Close()
+
+		alice, err := m.players.GetPlayerByName(t.Context(), "alice")
+		assert.NoError(t, err)
+		bob, err := m.players.GetPlayerByName(t.Context(), "bob")
+		assert.NoError(t, err)
+		carol, err := m.players.GetPlayerByName(t.Context(), "carol")
+		assert.NoError(t, err)
+		dave, err := m.players.GetPlayerByName(t.Context(), "dave")
+		assert.NoError(t, err)
+
+		postFormWithHeaders := func(form url.Values, authenticated bool, headers map[string]string) (int, string) {
+			req, err := http.NewRequest(http.MethodPost, web.URL+"/games", strings.NewReader(form.Encode()))
+			assert.NoError(t, err)
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			for name, value := range headers {
+				req.Header.Set(name, value)
+			}
+			if authenticated {
+				req.SetBasicAuth("alice@example.com", "secret")
+			}
+
+			res, err := http.DefaultClient.Do(req)
+			assert.NoError(t, err)
+			defer res.Body.Close()
+
+			var body bytes.Buffer
+			_, err = body.ReadFrom(res.Body)
+			assert.NoError(t, err)
+
+			return res.StatusCode, body.String()
+		}
+		postForm := func(form url.Values, authenticated bool) (int, string) {
+			return postFormWithHeaders(form, authenticated, map[string]string{"Origin": web.URL, "Sec-Fetch-Site": "same-origin"})
+		}
+
+		res, err := http.Get(web.URL + "/games/new")
+		assert.NoError(t, err)
+		res.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+		status, _ := postForm(url.Values{"team1": {alice.UUID}, "team2": {bob.UUID}}, false)
+		assert.Equal(t, http.StatusUnauthorized, status)
+
+		crossSiteForm := url.Values{"team1": {alice.UUID}, "team2": {bob.UUID}, "result": {"team1"}}
+		status, _ = postFormWithHeaders(crossSiteForm, true, map[string]string{"Sec-Fetch-Site": "cross-site"})
+		assert.Equal(t, http.StatusForbidden, status)
+		status, _ = postFormWithHeaders(crossSiteForm, true, map[string]string{"Origin": "https://evil.example"})
+		assert.Equal(t, http.StatusForbidden, status)
+
+		status, body := postForm(url.Values{"team1": {alice.UUID, carol.UUID}, "team2": {carol.UUID, "unknown"}}, true)
+		assert.Equal(t, http.StatusUnprocessableEntity, status)
+		assert.Contains(t, body, "Unknown players: unknown.")
+		assert.Contains(t, body, "carol plays in both teams.")
+
+		status, body = postForm(url.Values{"team1": {alice.UUID}, "score1": {"3"}}, true)
+		assert.Equal(t, http.StatusUnprocessableEntity, status)
+		assert.Contains(t, body, "Team 2 has no players.")
+		assert.Contains(t, body, "Enter the goals of both teams or none.")
+
+		status, body = postForm(
+			url.Values{"team1": {alice.UUID}, "team2": {bob.UUID}, "result": {"team1"}, "score1": {"4"}, "score2": {"10"}},
+			true,
+		)
+		assert.Equal(t, http.StatusUnprocessableEntity, status)
+		assert.Contains(t, body, games.ErrInvalidScore.Error())
+
+		form := url.Values{
+			"team1":          {alice.UUID, carol.UUID},
+			"team2":          {bob.UUID, dave.UUID},
+			"result":         {"team2"},
+			"score1":         {"4"},
+			"score2":         {"10"},
+			"playedAt":       {"2024-06-01T18:30"},
+			"idempotencyKey": {"queued-1"},
+		}
+		status, body = postForm(form, true)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Contains(t, body, "Game saved.")
+		assert.Contains(t, body, "Season 2024")
+
+		// the offline queue sends the form again
+		status, _ = postForm(form, true)
+		assert.Equal(t, http.StatusOK, status)
+
+		gamesPage, err := m.games.ListGames(t.Context(), games.GamesFilter{})
+		assert.NoError(t, err)
+		assert.Len(t, gamesPage.Games, 1)
+
+		game := gamesPage.Games[0]
+		assert.Equal(t, "bob", game.Team(1)[0].Player.Name)
+		assert.Len(t, game.Team(1), 2)
+		assert.Len(t, game.Team(2), 2)
+		assert.Equal(t, &games.Score{Team1: 10, Team2: 4}, game.Score)
+		assert.Equal(t, time.Date(2024, 6, 1, 18, 30, 0, 0, time.Local), game.PlayedAt.In(time.Local))
+
+		game, err = m.games.GetGameByUUID(t.Context(), game.UUID)
+		assert.NoError(t, err)
+		assert.Equal(t, "alice", game.RecordedBy.Name)
+	})
+}
+
+func TestDuplicateGames(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, conn *sql.DB) {
+		m := newManagers(conn)
+		m.games = m.games.WithDuplicateWindow(time.Hour)
+		createActiveSeason(t, m, "2024")
+		createPlayers(t, m, "alice", "bob", "carol", "dave")
+		_, err := m.users.CreateUserFromPlayer(t.Context(), "alice", "alice@example.com", "secret")
+		assert.NoError(t, err)
+		token, err := m.users.CreateApiToken(t.Context(), "alice@example.com")
+		assert.NoError(t, err)
+
+		playedAt := time.Date(2024, 5, 1, 18, 0, 0, 0, time.UTC)
+		game := createGame(t, m, playedAt, []string{"alice", "bob"}, []string{"carol", "dave"}, false)
+		createGame(t, m, playedAt, []string{"carol"}, []string{"dave"}, true)
+
+		winners, losers, err := m.players.GetTeamsByNames(t.Context(), []string{"bob", "alice"}, []string{"carol", "dave"})
+		assert.NoError(t, err)
+		_, err = m.games.CreateGame(t.Context(), games.GameEntry{
+			PlayedAt: playedAt.Add(30 * time.Minute),
+			Winners:  winners,
+			Losers:   losers,
+		})
+		assert.ErrorIs(t, err, games.ErrDuplicateGame)
+		assert.ErrorContains(t, err, game.UUID)
+
+		// the teams of a draw are the same game in any order
+		dave, carol, err := m.players.GetTeamsByNames(t.Context(), []string{"dave"}, []string{"carol"})
+		assert.NoError(t, err)
+		_, err = m.games.CreateGame(t.Context(), games.GameEntry{PlayedAt: playedAt, Winners: dave, Losers: carol, Draw: true})
+		assert.ErrorIs(t, err, games.ErrDuplicateGame)
+
+		_, err = m.games.CreateGame(t.Context(), games.GameEntry{PlayedAt: playedAt, Winners: losers, Losers: winners})
+		assert.NoError(t, err)
+		_, err = m.games.CreateGame(t.Context(), games.GameEntry{PlayedAt: playedAt.Add(2 * time.Hour), Winners: winners, Losers: losers})
+		assert.NoError(t, err)
+		_, err = m.games.CreateGame(t.Context(), games.GameEntry{
+			PlayedAt:       playedAt,
+			Winners:        winners,
+			Losers:         losers,
+			AllowDuplicate: true,
+		})
+		assert.NoError(t, err)
+
+		gamesController := server.NewGamesController(
+			m.games,
+			m.seasons,
+			m.players,
+			streaks.NewManager(games.NewAttendanceRepository(conn)),
+			server.NewGamesViews(),
+		)
+		authenticator := server.NewAuthenticator(m.users)
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("POST /api/games", authenticator.Authenticated(gamesController.CreateGame))
+		mux.HandleFunc("POST /games", authenticator.Authenticated(gamesController.SubmitGame))
+		api := httptest.NewServer(mux)
+		defer api.Close()
+
+		form := url.Values{
+			"team1":    {winners[0].UUID, winners[1].UUID},
+			"team2":    {losers[0].UUID, losers[1].UUID},
+			"playedAt": {playedAt.In(time.Local).Format("2006-01-02T15:04")},
+		}
+		req, err := http.NewRequest(http.MethodPost, api.URL+"/games", strings.NewReader(form.Encode()))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetBasicAuth("alice@example.com", "secret")
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		var body bytes.Buffer
+		_, err = body.ReadFrom(res.Body)
+		assert.NoError(t, err)
+		res.Body.Close()
+		assert.Equal(t, http.StatusConflict, res.StatusCode)
+		assert.Contains(t, body.String(), "Save it anyway")
+
+		remote, err := client.New(api.URL, token)
+		assert.NoError(t, err)
+		gamesManager := client.NewGamesManager(remote)
+
+		_, err = gamesManager.CreateGame(t.Context(), games.GameEntry{PlayedAt: playedAt, Winners: winners, Losers: losers})
+		assert.ErrorIs(t, err, games.ErrDuplicateGame)
+		assert.ErrorContains(t, err, game.UUID)
+
+		_, err = gamesManager.CreateGame(t.Context(), games.GameEntry{
+			PlayedAt:       playedAt,
+			Winners:        winners,
+			Losers:         losers,
+			AllowDuplicate: true,
+		})
+		assert.NoError(t, err)
+
+		gamesCount, err := m.games.GetGamesCount(t.Context(), games.ModeAll)
+		assert.NoError(t, err)
+		assert.Equal(t, 6, gamesCount)
+	})
+}
+
+func TestGameRules(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, conn *sql.DB) {
+		m := newManagers(conn)
+		createActiveSeason(t, m, "2024")
+		createPlayers(t, m, "alice", "bob", "carol", "dave")
+		_, err := m.users.CreateUserFromPlayer(t.Context(), "alice", "alice@example.com", "secret")
+		assert.NoError(t, err)
+		token, err := m.users.CreateApiToken(t.Context(), "alice@example.com")
+		assert.NoError(t, err)
+
+		gamesController := server.NewGamesController(
+			m.games,
+			m.seasons,
+			m.players,
+			streaks.NewManager(games.NewAttendanceRepository(conn)),
+			server.NewGamesViews(),
+		)
+		seasonsController := server.NewSeasonsController(m.seasons)
+		authenticator := server.NewAuthenticator(m.users)
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /api/seasons", seasonsController.GetSeasons)
+		mux.HandleFunc("PUT /api/seasons/{season}/rules", authenticator.Authenticated(seasonsController.UpdateRules))
+		mux.HandleFunc("POST /api/games", authenticator.Authenticated(gamesController.CreateGame))
+		api := httptest.NewServer(mux)
+		defer api.Close()
+
+		remote, err := client.New(api.URL, token)
+		assert.NoError(t, err)
+		seasonsManager := client.NewSeasonsManager(remote)
+		gamesManager := client.NewGamesManager(remote)
+
+		_, err = seasonsManager.SetTeamSizes(t.Context(), "2024", "3v3")
+		assert.ErrorIs(t, err, seasons.ErrUnknownTeamSizes)
+		season, err := seasonsManager.SetTeamSizes(t.Context(), "2024", seasons.TeamSizesDoubles)
+		assert.NoError(t, err)
+		assert.Equal(t, seasons.TeamSizesDoubles, season.TeamSizes)
+
+		alice, bob, err := m.players.GetTeamsByNames(t.Context(), []string{"alice"}, []string{"bob"})
+		assert.NoError(t, err)
+		_, err = m.games.CreateGame(t.Context(), games.GameEntry{Winners: alice, Losers: bob})
+		assert.ErrorIs(t, err, games.ErrTeamSize)
+
+		winners, _, err := m.players.GetTeamsByNames(t.Context(), []string{"alice", "bob"}, []string{})
+		assert.NoError(t, err)
+		_, err = gamesManager.CreateGame(t.Context(), games.GameEntry{Winners: winners, Score: &games.Score{Team1: 1, Team2: 5}})
+		var validationErr *games.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+		assert.Len(t, validationErr.Fields, 2)
+		assert.ErrorContains(t, err, "losers: team has no players")
+
+		_, err = m.seasons.SetTeamSizes(t.Context(), "2024", seasons.TeamSizesSingles)
+		assert.NoError(t, err)
+		createGame(t, m, time.Now(), []string{"carol"}, []string{"dave"}, false)
+	})
+}
+
+func TestGameModes(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, conn *sql.DB) {
+		m := newManagers(conn)
+		season := createActiveSeason(t, m, "2024")
+		createPlayers(t, m, "alice", "bob", "carol", "dave")
+
+		now := time.Now()
+		createGame(t, m, now.Add(-3*time.Hour), []string{"alice"}, []string{"bob"}, false)
+		createGame(t, m, now.Add(-2*time.Hour), []string{"alice", "carol"}, []string{"bob", "dave"}, false)
+		mixed := createGame(t, m, now.Add(-time.Hour), []string{"bob", "dave"}, []string{"carol", "alice"}, false)
+
+		// games of uneven teams can only be left from before the validation
+		alice, err := m.players.GetPlayerByName(t.Context(), "alice")
+		assert.NoError(t, err)
+		execAll(t, conn, fmt.Sprintf("DELETE FROM attendances WHERE game_id = %d AND player_id = %d", mixed.ID, alice.ID))
+
+		for mode, expected := range map[games.Mode]struct {
+			gamesCount int
+			names      []string
+		}{
+			games.ModeAll:     {gamesCount: 3, names: []string{"bob", "dave", "alice", "carol"}},
+			games.ModeSingles: {gamesCount: 1, names: []string{"alice", "bob"}},
+			games.ModeDoubles: {gamesCount: 1, names: []string{"alice", "carol", "bob", "dave"}},
+		} {
+			gamesCount, err := m.games.GetGamesCountForSeason(t.Context(), season, mode)
+			assert.NoError(t, err, mode)
+			assert.Equal(t, expected.gamesCount, gamesCount, mode)
+			gamesCount, err = m.games.GetGamesCount(t.Context(), mode)
+			assert.NoError(t, err, mode)
+			assert.Equal(t, expected.gamesCount, gamesCount, mode)
+
+			for _, load := range []func() ([]games.PlayerStats, error){
+				func() ([]games.PlayerStats, error) {
+					return m.games.GetPlayerStatsForSeason(t.Context(), season, mode, "wins")
+				},
+				func() ([]games.PlayerStats, error) {
+					return m.games.GetAllPlayerStats(t.Context(), mode, "wins")
+				},
+			} {
+				playerStats, err := load()
+				assert.NoError(t, err, mode)
+				names := []string{}
+				for _, stats := range playerStats {
+					names = append(names, stats.Name)
+					assert.Equal(t, float64(stats.Games)/float64(expected.gamesCount), stats.GamesRatio, mode)
+				}
+				assert.ElementsMatch(t, expected.names, names, mode)
+			}
+		}
+
+		gamesController := server.NewGamesController(
+			m.games,
+			m.seasons,
+			m.players,
+			streaks.NewManager(games.NewAttendanceRepository(conn)),
+			server.NewGamesViews(),
+		)
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /api/games/count", gamesController.GetGamesCount)
+		mux.HandleFunc("GET /api/players", gamesController.GetPlayers)
+		mux.HandleFunc("GET /api/seasons/table/{season}", gamesController.GetSeasonsTable)
+		mux.HandleFunc("GET /players", gamesController.PlayersTable)
+		api := httptest.NewServer(mux)
+		defer api.Close()
+
+		remote, err := client.New(api.URL, "")
+		assert.NoError(t, err)
+		gamesManager := client.NewGamesManager(remote)
+
+		gamesCount, err := gamesManager.GetGamesCount(t.Context(), games.ModeDoubles)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, gamesCount)
+		gamesCount, err = gamesManager.GetGamesCountForSeason(t.Context(), season, games.ModeSingles)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, gamesCount)
+		playerStats, err := gamesManager.GetAllPlayerStats(t.Context(), games.ModeSingles, "")
+		assert.NoError(t, err)
+		assert.Len(t, playerStats, 2)
+		playerStats, err = gamesManager.GetPlayerStatsForSeason(t.Context(), season, games.ModeDoubles, "")
+		assert.NoError(t, err)
+		assert.Len(t, playerStats, 4)
+
+		res, err := http.Get(api.URL + "/api/players?mode=3v3")
+		assert.NoError(t, err)
+		res.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+		res, err = http.Get(api.URL + "/players?mode=singles")
+		assert.NoError(t, err)
+		defer res.Body.Close()
+		var body bytes.Buffer
+		_, err = body.ReadFrom(res.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Contains(t, body.String(), `value="singles" class="peer hidden" checked`)
+		assert.NotContains(t, body.String(), "carol")
+	})
+}
+
+func TestTournaments(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, conn *sql.DB) {
+		m := newManagers(conn)
+		tournamentsManager := tournaments.NewManager(tournaments.NewTournamentsRepository(conn), m.games, m.players)
+		createPlayers(t, m, "alice", "bob", "carol", "dave", "erin", "frank")
+
+		_, err := tournamentsManager.CreateTournament(t.Context(), "Cup", tournaments.FormatSingleElimination, 2)
+		assert.NoError(t, err)
+		_, err = tournamentsManager.CreateTournament(t.Context(), "Cup", tournaments.FormatGroups, 2)
+		assert.ErrorIs(t, err, tournaments.ErrTournamentExists)
+
+		for _, team := range [][]string{{"alice", "bob"}, {"carol", "dave"}, {"erin", "frank"}} {
+			_, err = tournamentsManager.RegisterTeam(t.Context(), "Cup", "", team)
+			assert.NoError(t, err)
+		}
+
+		tournament, err := tournamentsManager.Schedule(t.Context(), "Cup", tournaments.ScheduleOptions{})
+		assert.NoError(t, err)
+		assert.Len(t, tournament.Matches, 3)
+		assert.True(t, tournament.Matches[0].Bye())
+
+		_, err = tournamentsManager.RecordResult(t.Context(), "Cup", 2, tournaments.Result{Winner: 2})
+		assert.ErrorIs(t, err, tournaments.ErrNoActiveSeason)
+
+		createActiveSeason(t, m, "2024")
+		tournament, err = tournamentsManager.RecordResult(t.Context(), "Cup", 2, tournaments.Result{
+			Winner: 2,
+			Score:  &games.Score{Team1: 4, Team2: 10},
+		})
+		assert.NoError(t, err)
+		_, err = tournamentsManager.RecordResult(t.Context(), "Cup", 2, tournaments.Result{Winner: 1})
+		assert.ErrorIs(t, err, tournaments.ErrMatchPlayed)
+
+		game, err := m.games.GetGameByUUID(t.Context(), tournament.Matches[1].GameUUID)
+		assert.NoError(t, err)
+		assert.Equal(t, "erin", game.Team(1)[0].Player.Name)
+		assert.Equal(t, &games.Score{Team1: 10, Team2: 4}, game.Score)
+
+		tournament, err = tournamentsManager.GetTournament(t.Context(), "Cup")
+		assert.NoError(t, err)
+		assert.Equal(t, "alice & bob", tournament.TeamLabel(tournament.Matches[2], 1))
+		assert.Equal(t, "erin & frank", tournament.TeamLabel(tournament.Matches[2], 2))
+
+		// the game is not kept if the matches can not be updated
+		if !isPostgres(conn) {
+			execAll(t, conn, `CREATE TRIGGER fail_match_update BEFORE UPDATE ON tournament_matches
+				BEGIN SELECT RAISE(ABORT, 'match update failed'); END`)
+			_, err = tournamentsManager.RecordResult(t.Context(), "Cup", 3, tournaments.Result{Winner: 1})
+			assert.ErrorContains(t, err, "match update failed")
+			gamesCount, err := m.games.GetGamesCount(t.Context(), games.ModeAll)
+			assert.NoError(t, err)
+			assert.Equal(t, 1, gamesCount)
+			execAll(t, conn, "DROP TRIGGER fail_match_update")
+		}
+
+		_, err = tournamentsManager.RecordResult(t.Context(), "Cup", 3, tournaments.Result{Winner: 1})
+		assert.NoError(t, err)
+
+		tournament, err = tournamentsManager.GetTournament(t.Context(), "Cup")
+		assert.NoError(t, err)
+		assert.Equal(t, tournaments.StatusFinished, tournament.Status)
+		champion, ok := tournament.Champion()
+		assert.True(t, ok)
+		assert.Equal(t, "alice & bob", champion.Name)
+
+		gamesCount, err := m.games.GetGamesCount(t.Context(), games.ModeDoubles)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, gamesCount)
+
+		_, err = m.users.CreateUserFromPlayer(t.Context(), "alice", "alice@example.com", "secret")
+		assert.NoError(t, err)
+		token, err := m.users.CreateApiToken(t.Context(), "alice@example.com")
+		assert.NoError(t, err)
+
+		tournamentsController := server.NewTournamentsController(tournamentsManager, server.NewTournamentsViews())
+		authenticator := server.NewAuthenticator(m.users)
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /tournaments/{tournament}", tournamentsController.TournamentPage)
+		mux.HandleFunc("GET /tournaments/{tournament}/bracket", tournamentsController.TournamentBracket)
+		mux.HandleFunc("GET /api/tournaments", tournamentsController.GetTournaments)
+		mux.HandleFunc("POST /api/tournaments", authenticator.Authenticated(tournamentsController.CreateTournament))
+		mux.HandleFunc("GET /api/tournaments/{tournament}", tournamentsController.GetTournament)
+		mux.HandleFunc(
+			"POST /api/tournaments/{tournament}/teams",
+			authenticator.Authenticated(tournamentsController.RegisterTeam),
+		)
+		mux.HandleFunc(
+			"POST /api/tournaments/{tournament}/schedule",
+			authenticator.Authenticated(tournamentsController.ScheduleTournament),
+		)
+		mux.HandleFunc(
+			"POST /api/tournaments/{tournament}/matches/{match}/result",
+			authenticator.Authenticated(tournamentsController.RecordResult),
+		)
+		api := httptest.NewServer(mux)
+		defer api.Close()
+
+		remote, err := client.New(api.URL, token)
+		assert.NoError(t, err)
+		remoteManager := client.NewTournamentsManager(remote)
+
+		_, err = remoteManager.CreateTournament(t.Context(), "League", tournaments.FormatGroups, 1)
+		assert.NoError(t, err)
+		_, err = remoteManager.CreateTournament(t.Context(), "League", tournaments.FormatGroups, 1)
+		assert.ErrorIs(t, err, tournaments.ErrTournamentExists)
+		for _, name := range []string{"alice", "bob", "carol", "dave"} {
+			_, err = remoteManager.RegisterTeam(t.Context(), "League", "", []string{name})
+			assert.NoError(t, err)
+		}
+		_, err = remoteManager.RegisterTeam(t.Context(), "League", "", []string{"nobody"})
+		assert.Error(t, err)
+
+		tournament, err = remoteManager.Schedule(t.Context(), "League", tournaments.ScheduleOptions{Groups: 1})
+		assert.NoError(t, err)
+		assert.Len(t, tournament.Matches, 7)
+
+		_, err = remoteManager.RecordResult(t.Context(), "League", 8, tournaments.Result{Winner: 1})
+		assert.ErrorIs(t, err, tournaments.ErrMatchNotFound)
+
+		for tournament.Status == tournaments.StatusRunning {
+			recorded := false
+			for _, match := range tournament.Matches {
+				if !match.Ready() {
+					continue
+				}
+
+				result := tournaments.Result{Winner: 1}
+				if match.Stage == tournaments.StageGroup && match.Round == 1 {
+					result = tournaments.Result{Draw: true, Score: &games.Score{Team1: 5, Team2: 5}}
+				}
+
+				tournament, err = remoteManager.RecordResult(t.Context(), "League", match.Number, result)
+				assert.NoError(t, err)
+				recorded = true
+				break
+			}
+			if !recorded {
+				t.Fatal("no match of the running tournament is ready")
+			}
+		}
+
+		tournament, err = remoteManager.GetTournament(t.Context(), "League")
+		assert.NoError(t, err)
+		assert.Equal(t, tournaments.StatusFinished, tournament.Status)
+		assert.Equal(t, 3, tournament.Standings(1)[0].Played)
+		champion, ok = tournament.Champion()
+		assert.True(t, ok)
+
+		res, err := http.Get(fmt.Sprintf("%s/tournaments/%s/bracket", api.URL, tournament.UUID))
+		assert.NoError(t, err)
+		defer res.Body.Close()
+		var body bytes.Buffer
+		_, err = body.ReadFrom(res.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Contains(t, body.String(), "Champion: "+champion.Name)
+		assert.NotContains(t, body.String(), "hx-trigger")
+
+		res, err = http.Get(api.URL + "/tournaments/unknown")
+		assert.NoError(t, err)
+		res.Body.Close()
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+}
+
+func TestConcurrentTournamentChanges(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, conn *sql.DB) {
+		m := newManagers(conn)
+		tournamentsManager := tournaments.NewManager(tournaments.NewTournamentsRepository(conn), m.games, m.players)
+		createPlayers(t, m, "alice", "bob", "carol", "dave", "erin")
+		createActiveSeason(t, m, "2024")
+
+		_, err := tournamentsManager.CreateTournament(t.Context(), "Cup", tournaments.FormatSingleElimination, 1)
+		assert.NoError(t, err)
+
+		// alice can only play in one of the teams registered at the same time
+		names := []string{"alice", "bob", "carol", "dave"}
+		errs := make([]error, len(names))
+		var wg sync.WaitGroup
+		for i, name := range names {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, errs[i] = tournamentsManager.RegisterTeam(t.Context(), "Cup", name, []string{name})
+			}()
+		}
+		wg.Wait()
+		for _, err := range errs {
+			assert.NoError(t, err)
+		}
+		for i := range 2 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, errs[i] = tournamentsManager.RegisterTeam(t.Context(), "Cup", fmt.Sprintf("alice %d", i), []string{"alice"})
+			}()
+		}
+		wg.Wait()
+		assert.ErrorIs(t, errs[0], tournaments.ErrPlayerRegistered)
+		assert.ErrorIs(t, errs[1], tournaments.ErrPlayerRegistered)
+
+		tournament, err := tournamentsManager.Schedule(t.Context(), "Cup", tournaments.ScheduleOptions{})
+		assert.NoError(t, err)
+		seeds := []int{}
+		for _, team := range tournament.Teams {
+			seeds = append(seeds, team.Seed)
+		}
+		assert.Equal(t, []int{1, 2, 3, 4}, seeds)
+
+		// the results of both feeders of the final and a second result of match 1 by another
+		// player are recorded at the same time
+		alice, err := m.players.GetPlayerByName(t.Context(), "alice")
+		assert.NoError(t, err)
+		erin, err := m.players.GetPlayerByName(t.Context(), "erin")
+		assert.NoError(t, err)
+		results := []struct {
+			number int
+			result tournaments.Result
+		}{
+			{number: 1, result: tournaments.Result{Winner: 1, RecordedBy: &alice}},
+			{number: 1, result: tournaments.Result{Winner: 2, RecordedBy: &erin}},
+			{number: 2, result: tournaments.Result{Winner: 1, RecordedBy: &alice}},
+		}
+		errs = make([]error, len(results))
+		for i, result := range results {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, errs[i] = tournamentsManager.RecordResult(t.Context(), "Cup", result.number, result.result)
+			}()
+		}
+		wg.Wait()
+		assert.NoError(t, errs[2])
+		assert.Len(t, slices.DeleteFunc(slices.Clone(errs[:2]), func(err error) bool { return err == nil }), 1)
+		assert.True(t, errors.Is(errs[0], tournaments.ErrMatchPlayed) || errors.Is(errs[1], tournaments.ErrMatchPlayed))
+
+		gamesCount, err := m.games.GetGamesCount(t.Context(), games.ModeAll)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, gamesCount)
+
+		tournament, err = tournamentsManager.GetTournament(t.Context(), "Cup")
+		assert.NoError(t, err)
+		final, ok := tournament.Match(3)
+		assert.True(t, ok)
+		assert.True(t, final.Ready())
+		for _, number := range []int{1, 2} {
+			match, _ := tournament.Match(number)
+			assert.True(t, match.Done)
+			assert.Contains(t, []uint{final.Team1ID, final.Team2ID}, match.WinnerID)
+		}
+	})
+}
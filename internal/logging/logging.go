@@ -0,0 +1,82 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+var ErrUnknownFormat = errors.New("log format has to be text or json")
+
+type requestIDKey struct{}
+
+// New creates a logger writing records in the format, text or json, to w. Records logged with
+// a context carrying a request id get it as request_id attribute.
+func New(w io.Writer, format string, level slog.Level) (*slog.Logger, error) {
+	options := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	switch format {
+	case "", FormatText:
+		handler = slog.NewTextHandler(w, options)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(w, options)
+	default:
+		return nil, ErrUnknownFormat
+	}
+
+	return slog.New(contextHandler{Handler: handler}), nil
+}
+
+// ParseLevel parses debug, info, warn or error, an empty level is info.
+func ParseLevel(level string) (slog.Level, error) {
+	level = strings.TrimSpace(level)
+	if level == "" {
+		return slog.LevelInfo, nil
+	}
+
+	var parsed slog.Level
+	err := parsed.UnmarshalText([]byte(level))
+	if err != nil {
+		return slog.LevelInfo, err
+	}
+
+	return parsed, nil
+}
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+
+	return requestID
+}
+
+type contextHandler struct {
+	slog.Handler
+}
+
+func (handler contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID := RequestID(ctx); requestID != "" {
+		record.AddAttrs(slog.String("request_id", requestID))
+	}
+
+	return handler.Handler.Handle(ctx, record)
+}
+
+func (handler contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{Handler: handler.Handler.WithAttrs(attrs)}
+}
+
+func (handler contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{Handler: handler.Handler.WithGroup(name)}
+}
@@ -0,0 +1,120 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	tests := map[string]struct {
+		format     string
+		ctx        context.Context
+		assertions []func(t *testing.T, output string, err error)
+	}{
+		"text": {
+			format: FormatText,
+			ctx:    context.Background(),
+			assertions: []func(t *testing.T, output string, err error){
+				func(t *testing.T, output string, err error) {
+					assert.NoError(t, err)
+					assert.Contains(t, output, "level=INFO msg=message key=value")
+					assert.NotContains(t, output, "request_id")
+				},
+			},
+		},
+		"json": {
+			format: FormatJSON,
+			ctx:    context.Background(),
+			assertions: []func(t *testing.T, output string, err error){
+				func(t *testing.T, output string, err error) {
+					assert.NoError(t, err)
+					assert.Contains(t, output, `"msg":"message","key":"value"`)
+				},
+			},
+		},
+		"with request id": {
+			format: FormatText,
+			ctx:    WithRequestID(context.Background(), "some-id"),
+			assertions: []func(t *testing.T, output string, err error){
+				func(t *testing.T, output string, err error) {
+					assert.NoError(t, err)
+					assert.Contains(t, output, "key=value request_id=some-id")
+				},
+			},
+		},
+		"unknown format": {
+			format: "xml",
+			ctx:    context.Background(),
+			assertions: []func(t *testing.T, output string, err error){
+				func(t *testing.T, output string, err error) {
+					assert.ErrorIs(t, err, ErrUnknownFormat)
+					assert.Empty(t, output)
+				},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			output := &bytes.Buffer{}
+
+			logger, err := New(output, tt.format, slog.LevelInfo)
+			if err == nil {
+				logger.With("key", "value").InfoContext(tt.ctx, "message")
+				logger.DebugContext(tt.ctx, "hidden")
+			}
+
+			for _, assertion := range tt.assertions {
+				assertion(t, output.String(), err)
+			}
+		})
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := map[string]struct {
+		level      string
+		assertions []func(t *testing.T, level slog.Level, err error)
+	}{
+		"empty": {
+			level: "",
+			assertions: []func(t *testing.T, level slog.Level, err error){
+				func(t *testing.T, level slog.Level, err error) {
+					assert.NoError(t, err)
+					assert.Equal(t, slog.LevelInfo, level)
+				},
+			},
+		},
+		"debug": {
+			level: "debug",
+			assertions: []func(t *testing.T, level slog.Level, err error){
+				func(t *testing.T, level slog.Level, err error) {
+					assert.NoError(t, err)
+					assert.Equal(t, slog.LevelDebug, level)
+				},
+			},
+		},
+		"invalid": {
+			level: "loud",
+			assertions: []func(t *testing.T, level slog.Level, err error){
+				func(t *testing.T, level slog.Level, err error) {
+					assert.Error(t, err)
+				},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			level, err := ParseLevel(tt.level)
+
+			for _, assertion := range tt.assertions {
+				assertion(t, level, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "fskick"
+
+// Metrics collects the metrics of the server in its own registry, served by Handler.
+type Metrics struct {
+	registry        *prometheus.Registry
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	queryDuration   *prometheus.HistogramVec
+}
+
+func New() *Metrics {
+	metrics := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Number of handled HTTP requests by route.",
+		}, []string{"route", "method", "code"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Duration of handled HTTP requests by route.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method", "code"}),
+		queryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "Duration of database queries by statement, like select or insert.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"statement"}),
+	}
+
+	metrics.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		metrics.requests,
+		metrics.requestDuration,
+		metrics.queryDuration,
+	)
+
+	return metrics
+}
+
+// Register adds collectors, like the StatsCollector, to the served metrics.
+func (metrics *Metrics) Register(collectors ...prometheus.Collector) error {
+	for _, collector := range collectors {
+		err := metrics.registry.Register(collector)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Handler serves the metrics in the Prometheus text format. Metrics failing to collect are
+// logged and left out.
+func (metrics *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(metrics.registry, promhttp.HandlerOpts{
+		ErrorLog:      slog.NewLogLogger(slog.Default().Handler(), slog.LevelError),
+		ErrorHandling: promhttp.ContinueOnError,
+	})
+}
+
+// InstrumentRoute counts the requests to the route and measures their duration. The route is
+// the registered pattern, not the requested path, to keep the number of series small.
+func (metrics *Metrics) InstrumentRoute(route string, handler http.HandlerFunc) http.HandlerFunc {
+	labels := prometheus.Labels{"route": route}
+
+	return promhttp.InstrumentHandlerCounter(
+		metrics.requests.MustCurryWith(labels),
+		promhttp.InstrumentHandlerDuration(metrics.requestDuration.MustCurryWith(labels), handler),
+	)
+}
+
+// ObserveQuery measures the duration of a query, it is a db.QueryObserver.
+func (metrics *Metrics) ObserveQuery(_ context.Context, query string, duration time.Duration, _ error) {
+	metrics.queryDuration.WithLabelValues(statement(query)).Observe(duration.Seconds())
+}
+
+// statement returns the first keyword of the query in lower case, like select or insert.
+func statement(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return "unknown"
+	}
+
+	return strings.ToLower(fields[0])
+}
@@ -0,0 +1,145 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/spie/fskick/internal/db"
+	"github.com/spie/fskick/internal/games"
+	"github.com/spie/fskick/internal/players"
+	"github.com/spie/fskick/internal/seasons"
+	"github.com/spie/fskick/internal/streaks"
+	"github.com/stretchr/testify/assert"
+)
+
+type gamesManagerMock struct {
+	since time.Time
+}
+
+func (mock *gamesManagerMock) GetGamesCountForSeason(_ context.Context, season seasons.Season, _ games.Mode) (int, error) {
+	return int(season.ID) * 10, nil
+}
+
+func (mock *gamesManagerMock) GetGamesCountRecordedSince(_ context.Context, since time.Time) (int, error) {
+	mock.since = since
+
+	return 3, nil
+}
+
+type seasonsManagerMock struct {
+	err error
+}
+
+func (mock seasonsManagerMock) ActiveSeason(_ context.Context) (seasons.Season, error) {
+	return seasons.Season{Model: db.Model{ID: 2}}, mock.err
+}
+
+type playersManagerMock struct {
+	err error
+}
+
+func (mock playersManagerMock) GetPlayers(_ context.Context) ([]players.Player, error) {
+	return []players.Player{{Name: "alice"}, {Name: "bob"}}, mock.err
+}
+
+type streaksManagerMock struct{}
+
+func (mock streaksManagerMock) GetCurrentStreaks(_ context.Context, outcome games.Outcome) ([]streaks.Streak, error) {
+	if outcome == games.OutcomeWin {
+		return []streaks.Streak{{Number: 4}, {Number: 1}}, nil
+	}
+
+	return []streaks.Streak{}, nil
+}
+
+func TestStatsCollector(t *testing.T) {
+	tests := map[string]struct {
+		seasonsManager seasonsManagerMock
+		playersManager playersManagerMock
+		assertions     []func(t *testing.T, collector StatsCollector, gamesManager *gamesManagerMock)
+	}{
+		"collects stats": {
+			assertions: []func(t *testing.T, collector StatsCollector, gamesManager *gamesManagerMock){
+				func(t *testing.T, collector StatsCollector, gamesManager *gamesManagerMock) {
+					expected := `
+# HELP fskick_active_season_games Number of games in the active season.
+# TYPE fskick_active_season_games gauge
+fskick_active_season_games 20
+# HELP fskick_current_longest_streak Number of games of the longest current streak by outcome, win or loss.
+# TYPE fskick_current_longest_streak gauge
+fskick_current_longest_streak{outcome="loss"} 0
+fskick_current_longest_streak{outcome="win"} 4
+# HELP fskick_games_recorded_today Number of games recorded since midnight.
+# TYPE fskick_games_recorded_today gauge
+fskick_games_recorded_today 3
+# HELP fskick_players Number of players.
+# TYPE fskick_players gauge
+fskick_players 2
+`
+					assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected)))
+					assert.Equal(t, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), gamesManager.since)
+				},
+			},
+		},
+		"without active season": {
+			seasonsManager: seasonsManagerMock{err: seasons.ErrSeasonNotFound},
+			assertions: []func(t *testing.T, collector StatsCollector, gamesManager *gamesManagerMock){
+				func(t *testing.T, collector StatsCollector, gamesManager *gamesManagerMock) {
+					expected := `
+# HELP fskick_active_season_games Number of games in the active season.
+# TYPE fskick_active_season_games gauge
+fskick_active_season_games 0
+`
+					assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected), "fskick_active_season_games"))
+				},
+			},
+		},
+		"with error": {
+			playersManager: playersManagerMock{err: errors.New("some error")},
+			assertions: []func(t *testing.T, collector StatsCollector, gamesManager *gamesManagerMock){
+				func(t *testing.T, collector StatsCollector, gamesManager *gamesManagerMock) {
+					_, err := testutil.CollectAndLint(collector)
+					assert.ErrorContains(t, err, "some error")
+				},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			gamesManager := &gamesManagerMock{}
+			collector := NewStatsCollector(gamesManager, tt.seasonsManager, tt.playersManager, streaksManagerMock{})
+			collector.now = func() time.Time { return time.Date(2024, 6, 1, 14, 30, 0, 0, time.UTC) }
+
+			for _, assertion := range tt.assertions {
+				assertion(t, collector, gamesManager)
+			}
+		})
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	metrics := New()
+
+	handler := metrics.InstrumentRoute("/games/{game}", func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusNotFound)
+	})
+	handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/games/1", nil))
+	handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/games/2", nil))
+
+	metrics.ObserveQuery(context.Background(), "\n\tSELECT id FROM games", time.Millisecond, nil)
+
+	res := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Contains(t, res.Body.String(), `fskick_http_requests_total{code="404",method="get",route="/games/{game}"} 2`)
+	assert.Contains(t, res.Body.String(), `fskick_http_request_duration_seconds_count{code="404",method="get",route="/games/{game}"} 2`)
+	assert.Contains(t, res.Body.String(), `fskick_db_query_duration_seconds_count{statement="select"} 1`)
+}
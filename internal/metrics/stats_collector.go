@@ -0,0 +1,183 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/spie/fskick/internal/cache"
+	"github.com/spie/fskick/internal/games"
+	"github.com/spie/fskick/internal/players"
+	"github.com/spie/fskick/internal/seasons"
+	"github.com/spie/fskick/internal/streaks"
+)
+
+// collectTimeout limits the queries of a single collect.
+const collectTimeout = 5 * time.Second
+
+type gamesManager interface {
+	GetGamesCountForSeason(ctx context.Context, season seasons.Season, mode games.Mode) (int, error)
+	GetGamesCountRecordedSince(ctx context.Context, since time.Time) (int, error)
+}
+
+type seasonsManager interface {
+	ActiveSeason(ctx context.Context) (seasons.Season, error)
+}
+
+type playersManager interface {
+	GetPlayers(ctx context.Context) ([]players.Player, error)
+}
+
+type streaksManager interface {
+	GetCurrentStreaks(ctx context.Context, outcome games.Outcome) ([]streaks.Streak, error)
+}
+
+var (
+	activeSeasonGamesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "active_season_games"),
+		"Number of games in the active season.",
+		nil,
+		nil,
+	)
+	playersDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "players"),
+		"Number of players.",
+		nil,
+		nil,
+	)
+	gamesRecordedTodayDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "games_recorded_today"),
+		"Number of games recorded since midnight.",
+		nil,
+		nil,
+	)
+	currentLongestStreakDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "current_longest_streak"),
+		"Number of games of the longest current streak by outcome, win or loss.",
+		[]string{"outcome"},
+		nil,
+	)
+	cacheHitsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "stats_cache", "hits_total"),
+		"Number of stats answered from the cache.",
+		nil,
+		nil,
+	)
+	cacheMissesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "stats_cache", "misses_total"),
+		"Number of stats loaded from the database.",
+		nil,
+		nil,
+	)
+	cacheEntriesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "stats_cache", "entries"),
+		"Number of cached stats.",
+		nil,
+		nil,
+	)
+)
+
+// StatsCollector loads the gauges of the table from the managers on every scrape.
+type StatsCollector struct {
+	gamesManager   gamesManager
+	seasonsManager seasonsManager
+	playersManager playersManager
+	streaksManager streaksManager
+	now            func() time.Time
+}
+
+func NewStatsCollector(
+	gamesManager gamesManager,
+	seasonsManager seasonsManager,
+	playersManager playersManager,
+	streaksManager streaksManager,
+) StatsCollector {
+	return StatsCollector{
+		gamesManager:   gamesManager,
+		seasonsManager: seasonsManager,
+		playersManager: playersManager,
+		streaksManager: streaksManager,
+		now:            time.Now,
+	}
+}
+
+func (collector StatsCollector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- activeSeasonGamesDesc
+	descs <- playersDesc
+	descs <- gamesRecordedTodayDesc
+	descs <- currentLongestStreakDesc
+}
+
+func (collector StatsCollector) Collect(metrics chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	metrics <- gauge(activeSeasonGamesDesc, func() (int, error) {
+		season, err := collector.seasonsManager.ActiveSeason(ctx)
+		if errors.Is(err, seasons.ErrSeasonNotFound) {
+			return 0, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		return collector.gamesManager.GetGamesCountForSeason(ctx, season, games.ModeAll)
+	})
+
+	metrics <- gauge(playersDesc, func() (int, error) {
+		allPlayers, err := collector.playersManager.GetPlayers(ctx)
+
+		return len(allPlayers), err
+	})
+
+	metrics <- gauge(gamesRecordedTodayDesc, func() (int, error) {
+		now := collector.now()
+		midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+		return collector.gamesManager.GetGamesCountRecordedSince(ctx, midnight)
+	})
+
+	for _, outcome := range []games.Outcome{games.OutcomeWin, games.OutcomeLoss} {
+		metrics <- gauge(currentLongestStreakDesc, func() (int, error) {
+			currentStreaks, err := collector.streaksManager.GetCurrentStreaks(ctx, outcome)
+			if err != nil || len(currentStreaks) == 0 {
+				return 0, err
+			}
+
+			return currentStreaks[0].Number, nil
+		}, string(outcome))
+	}
+}
+
+func gauge(desc *prometheus.Desc, load func() (int, error), labelValues ...string) prometheus.Metric {
+	value, err := load()
+	if err != nil {
+		return prometheus.NewInvalidMetric(desc, err)
+	}
+
+	return prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(value), labelValues...)
+}
+
+// CacheCollector collects the hits, misses and entries of the stats cache.
+type CacheCollector struct {
+	cache *cache.Cache
+}
+
+func NewCacheCollector(c *cache.Cache) CacheCollector {
+	return CacheCollector{cache: c}
+}
+
+func (collector CacheCollector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- cacheHitsDesc
+	descs <- cacheMissesDesc
+	descs <- cacheEntriesDesc
+}
+
+func (collector CacheCollector) Collect(metrics chan<- prometheus.Metric) {
+	stats := collector.cache.Stats()
+
+	metrics <- prometheus.MustNewConstMetric(cacheHitsDesc, prometheus.CounterValue, float64(stats.Hits))
+	metrics <- prometheus.MustNewConstMetric(cacheMissesDesc, prometheus.CounterValue, float64(stats.Misses))
+	metrics <- prometheus.MustNewConstMetric(cacheEntriesDesc, prometheus.GaugeValue, float64(stats.Entries))
+}
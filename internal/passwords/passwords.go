@@ -11,3 +11,7 @@ func NewPasswordService() PasswordService {
 func (passwordService PasswordService) HashPassword(plaintextPassword []byte) ([]byte, error) {
 	return bcrypt.GenerateFromPassword(plaintextPassword, bcrypt.DefaultCost)
 }
+
+func (passwordService PasswordService) ComparePassword(hashedPassword []byte, plaintextPassword []byte) error {
+	return bcrypt.CompareHashAndPassword(hashedPassword, plaintextPassword)
+}
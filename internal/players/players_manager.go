@@ -1,32 +1,58 @@
 package players
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
+	"time"
+
+	"github.com/spie/fskick/internal/cache"
 )
 
 type Team []Player
 
 type playerRepository interface {
-	CreatePlayer(player *Player) error
-	FindPlayerByUUID(uuid string) (Player, error)
-	FindPlayerByName(name string) (Player, error)
-	FindPlayersByNames(names []string) ([]Player, error)
+	CreatePlayer(ctx context.Context, player *Player) error
+	FindPlayerByUUID(ctx context.Context, uuid string) (Player, error)
+	FindPlayerByName(ctx context.Context, name string) (Player, error)
+	FindPlayersByNames(ctx context.Context, names []string) ([]Player, error)
+	FindAllPlayers(ctx context.Context) ([]Player, error)
+	UpdatePlayer(ctx context.Context, player *Player) error
+}
+
+type Profile struct {
+	DisplayName       string
+	PreferredPosition Position
+	FavoriteSide      Side
+	JoinedAt          time.Time
 }
 
+var (
+	ErrInvalidPosition = errors.New("position has to be defense (def) or offense (off)")
+	ErrInvalidSide     = errors.New("side has to be left or right")
+)
+
 type Manager struct {
 	playerRepository playerRepository
+	cache            *cache.Cache
 }
 
 func NewManager(playerRepository playerRepository) Manager {
 	return Manager{playerRepository: playerRepository}
 }
 
-func (manager Manager) CreatePlayer(name string) (Player, error) {
-	_, err := manager.playerRepository.FindPlayerByName(name)
+// WithCache returns a manager invalidating the cached stats in c when a player changes.
+func (manager Manager) WithCache(c *cache.Cache) Manager {
+	manager.cache = c
+
+	return manager
+}
+
+func (manager Manager) CreatePlayer(ctx context.Context, name string) (Player, error) {
+	_, err := manager.playerRepository.FindPlayerByName(ctx, name)
 	if err == nil {
-		return Player{}, errors.New(fmt.Sprintf("Player with name %s exists", name))
+		return Player{}, fmt.Errorf("%w: %s", ErrPlayerExists, name)
 	}
 	if !errors.Is(err, ErrPlayerNotFound) {
 		return Player{}, fmt.Errorf("Check for player with name in CreatePlayer: %w", err)
@@ -34,16 +60,49 @@ func (manager Manager) CreatePlayer(name string) (Player, error) {
 
 	player := Player{Name: name}
 
-	err = manager.playerRepository.CreatePlayer(&player)
+	err = manager.playerRepository.CreatePlayer(ctx, &player)
 	if err != nil {
 		return Player{}, err
 	}
 
+	manager.cache.Invalidate()
+
+	return player, nil
+}
+
+func (manager Manager) UpdateProfile(ctx context.Context, player Player, profile Profile) (Player, error) {
+	player.DisplayName = profile.DisplayName
+	player.PreferredPosition = profile.PreferredPosition
+	player.FavoriteSide = profile.FavoriteSide
+	if !profile.JoinedAt.IsZero() {
+		player.JoinedAt = profile.JoinedAt
+	}
+
+	err := manager.playerRepository.UpdatePlayer(ctx, &player)
+	if err != nil {
+		return Player{}, fmt.Errorf("update profile: %w", err)
+	}
+
+	manager.cache.Invalidate()
+
+	return player, nil
+}
+
+func (manager Manager) SetAvatar(ctx context.Context, player Player, avatar string) (Player, error) {
+	player.Avatar = avatar
+
+	err := manager.playerRepository.UpdatePlayer(ctx, &player)
+	if err != nil {
+		return Player{}, fmt.Errorf("set avatar: %w", err)
+	}
+
+	manager.cache.Invalidate()
+
 	return player, nil
 }
 
-func (manager Manager) GetPlayerByUUID(uuid string) (Player, error) {
-	player, err := manager.playerRepository.FindPlayerByUUID(uuid)
+func (manager Manager) GetPlayerByUUID(ctx context.Context, uuid string) (Player, error) {
+	player, err := manager.playerRepository.FindPlayerByUUID(ctx, uuid)
 	if err != nil {
 		return Player{}, fmt.Errorf("get player by uuid: %w", err)
 	}
@@ -51,8 +110,8 @@ func (manager Manager) GetPlayerByUUID(uuid string) (Player, error) {
 	return player, nil
 }
 
-func (manager Manager) GetPlayerByName(name string) (Player, error) {
-	player, err := manager.playerRepository.FindPlayerByName(name)
+func (manager Manager) GetPlayerByName(ctx context.Context, name string) (Player, error) {
+	player, err := manager.playerRepository.FindPlayerByName(ctx, name)
 	if err != nil {
 		return Player{}, fmt.Errorf("get player by name: %w", err)
 	}
@@ -60,13 +119,17 @@ func (manager Manager) GetPlayerByName(name string) (Player, error) {
 	return player, nil
 }
 
-func (manager Manager) GetTeamsByNames(winnerNames []string, loserNames []string) (Team, Team, error) {
-	winners, err := manager.getTeamByNames(winnerNames)
+func (manager Manager) GetPlayers(ctx context.Context) ([]Player, error) {
+	return manager.playerRepository.FindAllPlayers(ctx)
+}
+
+func (manager Manager) GetTeamsByNames(ctx context.Context, winnerNames []string, loserNames []string) (Team, Team, error) {
+	winners, err := manager.getTeamByNames(ctx, winnerNames)
 	if err != nil {
 		return []Player{}, []Player{}, err
 	}
 
-	losers, err := manager.getTeamByNames(loserNames)
+	losers, err := manager.getTeamByNames(ctx, loserNames)
 	if err != nil {
 		return []Player{}, []Player{}, err
 	}
@@ -74,12 +137,12 @@ func (manager Manager) GetTeamsByNames(winnerNames []string, loserNames []string
 	return winners, losers, nil
 }
 
-func (manager Manager) getTeamByNames(names []string) (Team, error) {
+func (manager Manager) getTeamByNames(ctx context.Context, names []string) (Team, error) {
 	if len(names) < 1 {
 		return []Player{}, nil
 	}
 
-	players, err := manager.playerRepository.FindPlayersByNames(names)
+	players, err := manager.playerRepository.FindPlayersByNames(ctx, names)
 	if err != nil {
 		return []Player{}, err
 	}
@@ -106,3 +169,25 @@ func getIncorrectPlayerNames(names []string, players []Player) []string {
 
 	return incorrectNames
 }
+
+func ParsePosition(position string) (Position, error) {
+	switch position {
+	case "":
+		return PositionNone, nil
+	case "def", string(PositionDefense):
+		return PositionDefense, nil
+	case "off", string(PositionOffense):
+		return PositionOffense, nil
+	}
+
+	return PositionNone, ErrInvalidPosition
+}
+
+func ParseSide(side string) (Side, error) {
+	switch Side(side) {
+	case SideNone, SideLeft, SideRight:
+		return Side(side), nil
+	}
+
+	return SideNone, ErrInvalidSide
+}
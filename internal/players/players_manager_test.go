@@ -1,6 +1,7 @@
 package players
 
 import (
+	"context"
 	"testing"
 
 	"github.com/spie/fskick/internal/db"
@@ -12,25 +13,33 @@ type mockPlayerRepository struct {
 	err    error
 }
 
-func (repo mockPlayerRepository) FindPlayerByName(name string) (Player, error) {
+func (repo mockPlayerRepository) FindPlayerByName(_ context.Context, name string) (Player, error) {
 	return repo.player, repo.err
 }
 
-func (repo mockPlayerRepository) CreatePlayer(player *Player) error {
+func (repo mockPlayerRepository) CreatePlayer(_ context.Context, player *Player) error {
 	// Mock implementation: do nothing and return nil error
 	return nil
 }
 
-func (repo mockPlayerRepository) FindPlayerByUUID(uuid string) (Player, error) {
+func (repo mockPlayerRepository) FindPlayerByUUID(_ context.Context, uuid string) (Player, error) {
 	// Mock implementation: return zero value and nil error
 	return Player{}, nil
 }
 
-func (repo mockPlayerRepository) FindPlayersByNames(names []string) ([]Player, error) {
+func (repo mockPlayerRepository) FindPlayersByNames(_ context.Context, names []string) ([]Player, error) {
 	// Mock implementation: return empty slice and nil error
 	return []Player{}, nil
 }
 
+func (repo mockPlayerRepository) FindAllPlayers(_ context.Context) ([]Player, error) {
+	return []Player{}, nil
+}
+
+func (repo mockPlayerRepository) UpdatePlayer(_ context.Context, player *Player) error {
+	return repo.err
+}
+
 func TestPlayersManager_GetPlayerByName(t *testing.T) {
 	tests := map[string]struct {
 		playerName string
@@ -84,7 +93,7 @@ func TestPlayersManager_GetPlayerByName(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			manager := tt.setupMocks()
 
-			player, err := manager.GetPlayerByName(tt.playerName)
+			player, err := manager.GetPlayerByName(context.Background(), tt.playerName)
 
 			for _, assertion := range tt.assertions {
 				assertion(t, player, err)
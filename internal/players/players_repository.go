@@ -1,7 +1,9 @@
 package players
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -9,13 +11,43 @@ import (
 	"github.com/spie/fskick/internal/db"
 )
 
+type Position string
+
+const (
+	PositionNone    Position = ""
+	PositionDefense Position = "defense"
+	PositionOffense Position = "offense"
+)
+
+type Side string
+
+const (
+	SideNone  Side = ""
+	SideLeft  Side = "left"
+	SideRight Side = "right"
+)
+
 type Player struct {
 	db.Model
-	Name string
+	Name              string
+	DisplayName       string
+	PreferredPosition Position
+	FavoriteSide      Side
+	JoinedAt          time.Time
+	Avatar            string
+}
+
+func (player Player) PublicName() string {
+	if player.DisplayName != "" {
+		return player.DisplayName
+	}
+
+	return player.Name
 }
 
 var (
 	ErrPlayerNotFound = db.ErrNotFound
+	ErrPlayerExists   = errors.New("player with name exists")
 )
 
 type PlayerRepository struct {
@@ -28,7 +60,7 @@ func NewPlayerRepository(conn db.Connection) PlayerRepository {
 	}
 }
 
-func (repository PlayerRepository) CreatePlayer(player *Player) error {
+func (repository PlayerRepository) CreatePlayer(ctx context.Context, player *Player) error {
 	err := player.CreateUUID()
 	if err != nil {
 		return fmt.Errorf("create uuid for insert player: %w", err)
@@ -36,18 +68,41 @@ func (repository PlayerRepository) CreatePlayer(player *Player) error {
 
 	player.CreatedAt = time.Now()
 	player.UpdatedAt = time.Now()
+	if player.JoinedAt.IsZero() {
+		player.JoinedAt = player.CreatedAt
+	}
 
-	row := repository.conn.QueryRow(
-		`INSERT INTO players (uuid, name, created_at, updated_at, deleted_at)
-		VALUES ($1, $2, $3, $4, $5)
+	row := repository.conn.QueryRowContext(
+		ctx,
+		`INSERT INTO players (
+			uuid,
+			name,
+			display_name,
+			preferred_position,
+			favorite_side,
+			joined_at,
+			avatar,
+			created_at,
+			updated_at,
+			deleted_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		RETURNING id`,
 		player.UUID,
 		player.Name,
+		player.DisplayName,
+		player.PreferredPosition,
+		player.FavoriteSide,
+		player.JoinedAt,
+		player.Avatar,
 		player.CreatedAt,
 		player.UpdatedAt,
 		nil,
 	)
 	err = row.Scan(&player.ID)
+	if db.IsUniqueViolation(err) {
+		return fmt.Errorf("insert player: %w: %s", ErrPlayerExists, player.Name)
+	}
 	if err != nil {
 		return fmt.Errorf("insert player: %w", err)
 	}
@@ -55,13 +110,42 @@ func (repository PlayerRepository) CreatePlayer(player *Player) error {
 	return nil
 }
 
-func (repository PlayerRepository) FindPlayerByUUID(uuid string) (Player, error) {
-	row := repository.conn.QueryRow(
+func (repository PlayerRepository) UpdatePlayer(ctx context.Context, player *Player) error {
+	player.UpdatedAt = time.Now()
+
+	_, err := repository.conn.ExecContext(
+		ctx,
+		`UPDATE players
+		SET display_name = $1,
+			preferred_position = $2,
+			favorite_side = $3,
+			joined_at = $4,
+			avatar = $5,
+			updated_at = $6
+		WHERE id = $7`,
+		player.DisplayName,
+		player.PreferredPosition,
+		player.FavoriteSide,
+		db.NullTimeValue(player.JoinedAt),
+		player.Avatar,
+		player.UpdatedAt,
+		player.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("update player: %w", err)
+	}
+
+	return nil
+}
+
+func (repository PlayerRepository) FindPlayerByUUID(ctx context.Context, uuid string) (Player, error) {
+	row := repository.conn.QueryRowContext(
+		ctx,
 		fmt.Sprintf(
 			`SELECT %s
 			FROM players
 			WHERE uuid = $1`,
-			getPlayerColumns(),
+			GetPlayerColumns(""),
 		),
 		uuid,
 	)
@@ -74,13 +158,14 @@ func (repository PlayerRepository) FindPlayerByUUID(uuid string) (Player, error)
 	return player, nil
 }
 
-func (repository PlayerRepository) FindPlayerByName(name string) (Player, error) {
-	row := repository.conn.QueryRow(
+func (repository PlayerRepository) FindPlayerByName(ctx context.Context, name string) (Player, error) {
+	row := repository.conn.QueryRowContext(
+		ctx,
 		fmt.Sprintf(
 			`SELECT %s
 			FROM players
 			WHERE name = $1`,
-			getPlayerColumns(),
+			GetPlayerColumns(""),
 		),
 		name,
 	)
@@ -93,14 +178,15 @@ func (repository PlayerRepository) FindPlayerByName(name string) (Player, error)
 	return player, nil
 }
 
-func (repository PlayerRepository) FindPlayersByNames(names []string) ([]Player, error) {
-	rows, err := repository.conn.Query(
+func (repository PlayerRepository) FindPlayersByNames(ctx context.Context, names []string) ([]Player, error) {
+	rows, err := repository.conn.QueryContext(
+		ctx,
 		fmt.Sprintf(
 			`SELECT
 			%s
 			FROM players
 			WHERE name IN (%s)`,
-			getPlayerColumns(),
+			GetPlayerColumns(""),
 			getInPlaceholders(names),
 		),
 		nameStringsToParameters(names)...,
@@ -118,25 +204,72 @@ func (repository PlayerRepository) FindPlayersByNames(names []string) ([]Player,
 	return players, nil
 }
 
-func getPlayerColumns() string {
-	return `
-		id,
-		uuid,
-		name,
-		created_at,
-		updated_at
-	`
+func (repository PlayerRepository) FindAllPlayers(ctx context.Context) ([]Player, error) {
+	rows, err := repository.conn.QueryContext(
+		ctx,
+		fmt.Sprintf(
+			`SELECT
+			%s
+			FROM players
+			ORDER BY name ASC`,
+			GetPlayerColumns(""),
+		),
+	)
+	if err != nil {
+		return []Player{}, fmt.Errorf("Query all players: %w", err)
+	}
+	defer rows.Close()
+
+	players, err := scanPlayers(rows)
+	if err != nil {
+		return []Player{}, fmt.Errorf("Scan player rows: %w", err)
+	}
+
+	return players, nil
 }
 
-func scanPlayer(row *sql.Row) (Player, error) {
-	var player Player
-	err := row.Scan(
+// GetPlayerColumns returns the columns matching GetPlayerScanFields, prefixed
+// with the given table alias if the players table is joined.
+func GetPlayerColumns(alias string) string {
+	prefix := ""
+	if alias != "" {
+		prefix = alias + "."
+	}
+
+	return fmt.Sprintf(
+		`
+		%[1]sid,
+		%[1]suuid,
+		%[1]sname,
+		COALESCE(%[1]sdisplay_name, ''),
+		COALESCE(%[1]spreferred_position, ''),
+		COALESCE(%[1]sfavorite_side, ''),
+		%[1]sjoined_at,
+		COALESCE(%[1]savatar, ''),
+		%[1]screated_at,
+		%[1]supdated_at`,
+		prefix,
+	)
+}
+
+func GetPlayerScanFields(player *Player) []any {
+	return []any{
 		&player.ID,
 		&player.UUID,
 		&player.Name,
+		&player.DisplayName,
+		&player.PreferredPosition,
+		&player.FavoriteSide,
+		db.NullTime(&player.JoinedAt),
+		&player.Avatar,
 		&player.CreatedAt,
 		&player.UpdatedAt,
-	)
+	}
+}
+
+func scanPlayer(row *sql.Row) (Player, error) {
+	var player Player
+	err := row.Scan(GetPlayerScanFields(&player)...)
 	if err != nil {
 		return Player{}, err
 	}
@@ -148,13 +281,7 @@ func scanPlayers(rows *sql.Rows) ([]Player, error) {
 	players := []Player{}
 	for rows.Next() {
 		var player Player
-		err := rows.Scan(
-			&player.ID,
-			&player.UUID,
-			&player.Name,
-			&player.CreatedAt,
-			&player.UpdatedAt,
-		)
+		err := rows.Scan(GetPlayerScanFields(&player)...)
 		if err != nil {
 			return []Player{}, err
 		}
@@ -168,7 +295,7 @@ func scanPlayers(rows *sql.Rows) ([]Player, error) {
 func getInPlaceholders(names []string) string {
 	placeholders := make([]string, len(names))
 	for i := range names {
-		placeholders[i] = fmt.Sprintf("$%d", i)
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
 	}
 
 	return strings.Join(placeholders, ",")
@@ -1,54 +1,93 @@
 package seasons
 
 import (
+	"context"
 	"errors"
 	"fmt"
+
+	"github.com/spie/fskick/internal/cache"
 )
 
 type Manager struct {
 	seasonsRepository SeasonsRepository
+	cache             *cache.Cache
 }
 
 func NewManager(seasonRepository SeasonsRepository) Manager {
 	return Manager{seasonsRepository: seasonRepository}
 }
 
-func (manager Manager) CreateSeason(name string) (Season, error) {
-	_, err := manager.seasonsRepository.FindSeasonByName(name)
+// WithCache returns a manager invalidating the cached stats in c when a season changes.
+func (manager Manager) WithCache(c *cache.Cache) Manager {
+	manager.cache = c
+
+	return manager
+}
+
+func (manager Manager) CreateSeason(ctx context.Context, name string) (Season, error) {
+	_, err := manager.seasonsRepository.FindSeasonByName(ctx, name)
 	if err == nil {
-		return Season{}, errors.New(fmt.Sprintf("Season with name %s exists", name))
+		return Season{}, fmt.Errorf("%w: %s", ErrSeasonExists, name)
 	}
 	if !errors.Is(err, ErrSeasonNotFound) {
 		return Season{}, fmt.Errorf("Check for season with name in CreateSeason: %w", err)
 	}
 
-	season := Season{Name: name, Active: false}
+	season := Season{Name: name, Active: false, TeamSizes: TeamSizesBoth}
 
-	err = manager.seasonsRepository.CreateSeason(&season)
+	err = manager.seasonsRepository.CreateSeason(ctx, &season)
 	if err != nil {
 		return Season{}, err
 	}
 
+	manager.cache.Invalidate()
+
 	return season, nil
 }
 
-func (manager Manager) GetSeasons() ([]Season, error) {
-	return manager.seasonsRepository.GetAll()
+func (manager Manager) GetSeasons(ctx context.Context) ([]Season, error) {
+	return manager.seasonsRepository.GetAll(ctx)
+}
+
+func (manager Manager) ActivateSeason(ctx context.Context, name string) (Season, error) {
+	season, err := manager.seasonsRepository.FindSeasonByName(ctx, name)
+	if err != nil {
+		return Season{}, err
+	}
+
+	err = manager.seasonsRepository.ActivateSeason(ctx, &season)
+	if err != nil {
+		return Season{}, err
+	}
+
+	manager.cache.Invalidate()
+
+	return season, nil
 }
 
-func (manager Manager) ActivateSeason(name string) (Season, error) {
-	season, err := manager.seasonsRepository.FindSeasonByName(name)
+// SetTeamSizes changes the team sizes allowed in the games of the season.
+func (manager Manager) SetTeamSizes(ctx context.Context, name string, teamSizes TeamSizes) (Season, error) {
+	teamSizes, err := ParseTeamSizes(string(teamSizes))
+	if err != nil {
+		return Season{}, err
+	}
+
+	season, err := manager.seasonsRepository.FindSeasonByName(ctx, name)
 	if err != nil {
 		return Season{}, err
 	}
 
-	manager.seasonsRepository.ActivateSeason(&season)
+	season.TeamSizes = teamSizes
+	err = manager.seasonsRepository.UpdateTeamSizes(ctx, &season)
+	if err != nil {
+		return Season{}, err
+	}
 
 	return season, nil
 }
 
-func (manager Manager) ActiveSeason() (Season, error) {
-	activeSeason, err := manager.seasonsRepository.FindActiveSeason()
+func (manager Manager) ActiveSeason(ctx context.Context) (Season, error) {
+	activeSeason, err := manager.seasonsRepository.FindActiveSeason(ctx)
 	if err != nil {
 		return Season{}, err
 	}
@@ -56,10 +95,10 @@ func (manager Manager) ActiveSeason() (Season, error) {
 	return activeSeason, nil
 }
 
-func (manager Manager) GetSeasonByUuid(uuid string) (Season, error) {
-	return manager.seasonsRepository.FindSeasonByUuid(uuid)
+func (manager Manager) GetSeasonByUuid(ctx context.Context, uuid string) (Season, error) {
+	return manager.seasonsRepository.FindSeasonByUuid(ctx, uuid)
 }
 
-func (manager Manager) GetSeasonByName(name string) (Season, error) {
-	return manager.seasonsRepository.FindSeasonByName(name)
+func (manager Manager) GetSeasonByName(ctx context.Context, name string) (Season, error) {
+	return manager.seasonsRepository.FindSeasonByName(ctx, name)
 }
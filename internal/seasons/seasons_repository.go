@@ -1,7 +1,9 @@
 package seasons
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
@@ -9,13 +11,16 @@ import (
 )
 
 var (
-	ErrSeasonNotFound = db.ErrNotFound
+	ErrSeasonNotFound     = db.ErrNotFound
+	ErrSeasonExists       = errors.New("season with name exists")
+	ErrActiveSeasonExists = errors.New("another season is active")
 )
 
 type Season struct {
 	db.Model
-	Name   string
-	Active bool
+	Name      string
+	Active    bool
+	TeamSizes TeamSizes
 }
 
 type SeasonsRepository struct {
@@ -26,7 +31,7 @@ func NewSeasonsRepository(conn db.Connection) SeasonsRepository {
 	return SeasonsRepository{conn: conn}
 }
 
-func (repository SeasonsRepository) CreateSeason(season *Season) error {
+func (repository SeasonsRepository) CreateSeason(ctx context.Context, season *Season) error {
 	err := season.CreateUUID()
 	if err != nil {
 		return fmt.Errorf("create uuid for insert season: %w", err)
@@ -35,28 +40,31 @@ func (repository SeasonsRepository) CreateSeason(season *Season) error {
 	season.CreatedAt = time.Now()
 	season.UpdatedAt = time.Now()
 
-	row := repository.conn.QueryRow(
-		`INSERT INTO seasons (uuid, name, created_at, updated_at, deleted_at, active)
-		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`,
+	row := repository.conn.QueryRowContext(
+		ctx,
+		`INSERT INTO seasons (uuid, name, created_at, updated_at, deleted_at, active, team_sizes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`,
 		season.UUID,
 		season.Name,
 		season.CreatedAt,
 		season.UpdatedAt,
 		nil,
 		season.Active,
+		season.TeamSizes,
 	)
 	err = row.Scan(&season.ID)
+	if db.IsUniqueViolation(err) {
+		return fmt.Errorf("insert season: %w: %s", ErrSeasonExists, season.Name)
+	}
 	if err != nil {
 		return fmt.Errorf("insert season: %w", err)
 	}
 
-	fmt.Printf("NEW SEASON: %d", season.ID)
-
 	return nil
 }
 
-func (repository SeasonsRepository) FindSeasonByName(name string) (Season, error) {
-	season, err := repository.selectSeason("name = $1", name)
+func (repository SeasonsRepository) FindSeasonByName(ctx context.Context, name string) (Season, error) {
+	season, err := repository.selectSeason(ctx, "name = $1", name)
 	if err != nil {
 		return Season{}, fmt.Errorf("query season by name: %w", err)
 	}
@@ -64,8 +72,8 @@ func (repository SeasonsRepository) FindSeasonByName(name string) (Season, error
 	return season, nil
 }
 
-func (repository SeasonsRepository) FindSeasonByUuid(uuid string) (Season, error) {
-	season, err := repository.selectSeason("uuid = $1", uuid)
+func (repository SeasonsRepository) FindSeasonByUuid(ctx context.Context, uuid string) (Season, error) {
+	season, err := repository.selectSeason(ctx, "uuid = $1", uuid)
 	if err != nil {
 		return Season{}, fmt.Errorf("query season by uuid: %w", err)
 	}
@@ -73,8 +81,8 @@ func (repository SeasonsRepository) FindSeasonByUuid(uuid string) (Season, error
 	return season, nil
 }
 
-func (repository SeasonsRepository) GetAll() ([]Season, error) {
-	rows, err := repository.conn.Query(fmt.Sprintf(`SELECT %s FROM seasons`, getSeasonsColumns()))
+func (repository SeasonsRepository) GetAll(ctx context.Context) ([]Season, error) {
+	rows, err := repository.conn.QueryContext(ctx, fmt.Sprintf(`SELECT %s FROM seasons ORDER BY id ASC`, getSeasonsColumns()))
 	if err != nil {
 		return []Season{}, fmt.Errorf("query all seasons: %w", err)
 	}
@@ -88,8 +96,8 @@ func (repository SeasonsRepository) GetAll() ([]Season, error) {
 	return seasons, nil
 }
 
-func (repository SeasonsRepository) FindActiveSeason() (Season, error) {
-	season, err := repository.selectSeason("active = true")
+func (repository SeasonsRepository) FindActiveSeason(ctx context.Context) (Season, error) {
+	season, err := repository.selectSeason(ctx, "active = true")
 	if err != nil {
 		return Season{}, fmt.Errorf("scan row in query active season: %w", err)
 	}
@@ -97,40 +105,56 @@ func (repository SeasonsRepository) FindActiveSeason() (Season, error) {
 	return season, nil
 }
 
-func (repository SeasonsRepository) ActivateSeason(season *Season) error {
-	tx, err := repository.conn.Begin()
-	if err != nil {
-		return fmt.Errorf("begin transaction in activate season: %w", err)
-	}
-	defer func() {
+func (repository SeasonsRepository) ActivateSeason(ctx context.Context, season *Season) error {
+	err := db.Transaction(ctx, repository.conn, func(tx db.Connection) error {
+		_, err := tx.ExecContext(ctx, "UPDATE seasons SET active = false WHERE active = true")
 		if err != nil {
-			tx.Rollback()
+			return fmt.Errorf("deactivate active seasons in activate season: %w", err)
 		}
-	}()
 
-	_, err = tx.Exec("UPDATE seasons SET active = false WHERE active = true")
-	if err != nil {
-		return fmt.Errorf("deactivate active seasons in activate season: %w", err)
-	}
+		_, err = tx.ExecContext(ctx, "UPDATE seasons SET active = true WHERE id = $1", season.ID)
+		if db.IsUniqueViolation(err) {
+			return fmt.Errorf("activate season: %w", ErrActiveSeasonExists)
+		}
+		if err != nil {
+			return fmt.Errorf("activate season: %w", err)
+		}
 
-	_, err = tx.Exec("UPDATE seasons SET active = true WHERE id = $1", season.ID)
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("activate season: %w", err)
+		return err
 	}
 
-	tx.Commit()
-
 	season.Active = true
 
 	return nil
 }
 
+func (repository SeasonsRepository) UpdateTeamSizes(ctx context.Context, season *Season) error {
+	season.UpdatedAt = time.Now()
+
+	_, err := repository.conn.ExecContext(
+		ctx,
+		"UPDATE seasons SET team_sizes = $1, updated_at = $2 WHERE id = $3",
+		season.TeamSizes,
+		season.UpdatedAt,
+		season.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("update team sizes of season: %w", err)
+	}
+
+	return nil
+}
+
 func getSeasonsColumns() string {
-	return "id, uuid, created_at, updated_at, name, active"
+	return "id, uuid, created_at, updated_at, name, active, team_sizes"
 }
 
-func (repository SeasonsRepository) selectSeason(whereQuery string, args ...any) (Season, error) {
-	row := repository.conn.QueryRow(
+func (repository SeasonsRepository) selectSeason(ctx context.Context, whereQuery string, args ...any) (Season, error) {
+	row := repository.conn.QueryRowContext(
+		ctx,
 		fmt.Sprintf(
 			`SELECT %s
 			FROM seasons
@@ -158,6 +182,7 @@ func scanSeason(row *sql.Row) (Season, error) {
 		&season.UpdatedAt,
 		&season.Name,
 		&season.Active,
+		&season.TeamSizes,
 	)
 	if err != nil {
 		return Season{}, err
@@ -178,6 +203,7 @@ func scanSeasons(rows *sql.Rows) ([]Season, error) {
 			&season.UpdatedAt,
 			&season.Name,
 			&season.Active,
+			&season.TeamSizes,
 		)
 		if err != nil {
 			return []Season{}, fmt.Errorf("scan season rows: %w", err)
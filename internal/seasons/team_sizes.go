@@ -0,0 +1,47 @@
+package seasons
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// TeamSizes are the sizes of the teams allowed in the games of a season.
+type TeamSizes string
+
+const (
+	TeamSizesSingles TeamSizes = "1v1"
+	TeamSizesDoubles TeamSizes = "2v2"
+	TeamSizesBoth    TeamSizes = "both"
+)
+
+var ErrUnknownTeamSizes = errors.New("team sizes have to be 1v1, 2v2 or both")
+
+// ParseTeamSizes reads the team sizes, no value allows both sizes.
+func ParseTeamSizes(value string) (TeamSizes, error) {
+	switch TeamSizes(value) {
+	case "":
+		return TeamSizesBoth, nil
+	case TeamSizesSingles, TeamSizesDoubles, TeamSizesBoth:
+		return TeamSizes(value), nil
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrUnknownTeamSizes, value)
+}
+
+// Sizes returns the allowed numbers of players in a team.
+func (teamSizes TeamSizes) Sizes() []int {
+	switch teamSizes {
+	case TeamSizesSingles:
+		return []int{1}
+	case TeamSizesDoubles:
+		return []int{2}
+	}
+
+	return []int{1, 2}
+}
+
+// Allows tells if a team of size players may play in the season.
+func (teamSizes TeamSizes) Allows(size int) bool {
+	return slices.Contains(teamSizes.Sizes(), size)
+}
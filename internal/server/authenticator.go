@@ -0,0 +1,130 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/spie/fskick/internal/users"
+)
+
+type userContextKey struct{}
+
+type adminContextKey struct{}
+
+type Authenticator struct {
+	usersManager users.Manager
+	admins       map[string]bool
+}
+
+func NewAuthenticator(usersManager users.Manager) Authenticator {
+	return Authenticator{usersManager: usersManager, admins: map[string]bool{}}
+}
+
+// WithAdmins returns the authenticator treating the users with the emails as admins.
+func (authenticator Authenticator) WithAdmins(emails []string) Authenticator {
+	authenticator.admins = make(map[string]bool, len(emails))
+	for _, email := range emails {
+		authenticator.admins[email] = true
+	}
+
+	return authenticator
+}
+
+// Authenticated only calls the handler for requests with valid basic auth credentials or a
+// valid api token, sent as bearer token, of a user.
+func (authenticator Authenticator) Authenticated(
+	handler func(http.ResponseWriter, *http.Request),
+) func(http.ResponseWriter, *http.Request) {
+	return func(res http.ResponseWriter, req *http.Request) {
+		user, err := authenticator.authenticate(req)
+		if errors.Is(err, users.ErrInvalidCredentials) {
+			handleUnauthorized(res)
+			return
+		}
+		if err != nil {
+			handleInternalServerError(res, req, err)
+			return
+		}
+
+		ctx := context.WithValue(req.Context(), userContextKey{}, user)
+		ctx = context.WithValue(ctx, adminContextKey{}, authenticator.admins[user.Email])
+		handler(res, req.WithContext(ctx))
+	}
+}
+
+// Admin only calls the handler for authenticated admins, other users are forbidden.
+func (authenticator Authenticator) Admin(
+	handler func(http.ResponseWriter, *http.Request),
+) func(http.ResponseWriter, *http.Request) {
+	return authenticator.Authenticated(func(res http.ResponseWriter, req *http.Request) {
+		if !isAdmin(req) {
+			http.Error(res, "Only admins are allowed.", http.StatusForbidden)
+			return
+		}
+
+		handler(res, req)
+	})
+}
+
+// SameOrigin only calls the handler for requests sent by the pages of the server itself.
+// Browsers send the Sec-Fetch-Site and Origin headers with form posts, so forms of other sites
+// can not use the basic auth credentials of a user. Requests without the headers are not sent
+// by browsers and are allowed.
+func SameOrigin(handler func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
+	return func(res http.ResponseWriter, req *http.Request) {
+		site := req.Header.Get("Sec-Fetch-Site")
+		if site != "" && site != "same-origin" && site != "none" {
+			http.Error(res, "Cross origin request.", http.StatusForbidden)
+			return
+		}
+
+		if origin := req.Header.Get("Origin"); origin != "" {
+			originURL, err := url.Parse(origin)
+			if err != nil || originURL.Host != req.Host {
+				http.Error(res, "Cross origin request.", http.StatusForbidden)
+				return
+			}
+		}
+
+		handler(res, req)
+	}
+}
+
+func (authenticator Authenticator) authenticate(req *http.Request) (users.User, error) {
+	if token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer "); ok {
+		return authenticator.usersManager.AuthenticateApiToken(req.Context(), strings.TrimSpace(token))
+	}
+
+	email, password, ok := req.BasicAuth()
+	if !ok {
+		return users.User{}, users.ErrInvalidCredentials
+	}
+
+	return authenticator.usersManager.Authenticate(req.Context(), email, password)
+}
+
+func getAuthenticatedUser(req *http.Request) (users.User, bool) {
+	user, ok := req.Context().Value(userContextKey{}).(users.User)
+
+	return user, ok
+}
+
+func isAdmin(req *http.Request) bool {
+	admin, _ := req.Context().Value(adminContextKey{}).(bool)
+
+	return admin
+}
+
+// canChangePlayer tells if the user may change the profile and avatar of the player, users
+// change their own player and admins all players.
+func canChangePlayer(req *http.Request, user users.User, playerUuid string) bool {
+	return user.UUID == playerUuid || isAdmin(req)
+}
+
+func handleUnauthorized(res http.ResponseWriter) {
+	res.Header().Set("WWW-Authenticate", `Basic realm="fskick"`)
+	http.Error(res, "Unauthorized.", http.StatusUnauthorized)
+}
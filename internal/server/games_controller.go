@@ -1,14 +1,19 @@
 package server
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/spie/fskick/internal/games"
 	"github.com/spie/fskick/internal/players"
 	"github.com/spie/fskick/internal/seasons"
 	"github.com/spie/fskick/internal/streaks"
+	"github.com/spie/fskick/internal/tables"
+	"github.com/spie/fskick/internal/templates"
 	"github.com/spie/fskick/internal/views"
 )
 
@@ -20,6 +25,12 @@ type GamesViews struct {
 	PlayersTableUpdate     views.PlayersTableUpdate
 	FavoriteTeamUpdate     views.FavoriteTeamUpdate
 	FavoriteOponentsUpdate views.FavoriteOponentsUpdate
+	GamesPage              views.GamesPage
+	GamesRows              views.GamesRows
+	GameInfo               views.GameInfo
+	NewGame                views.NewGame
+	NewGameForm            views.NewGameForm
+	GameCreated            views.GameCreated
 }
 
 func NewGamesViews() GamesViews {
@@ -51,15 +62,21 @@ func NewGamesController(
 }
 
 func (controller GamesController) SeasonsTable(res http.ResponseWriter, req *http.Request) {
-	seasons, err := controller.seasonsManager.GetSeasons()
+	mode, err := getMode(req)
 	if err != nil {
-		handleInternalServerError(res, err)
+		http.Error(res, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	seasonTableData, err := controller.getSeasonsTableData("", getSort(req))
+	seasons, err := controller.seasonsManager.GetSeasons(req.Context())
 	if err != nil {
-		handleInternalServerError(res, err)
+		handleInternalServerError(res, req, err)
+		return
+	}
+
+	seasonTableData, err := controller.getSeasonsTableData(req.Context(), "", mode, getSort(req))
+	if err != nil {
+		handleInternalServerError(res, req, err)
 		return
 	}
 
@@ -68,20 +85,27 @@ func (controller GamesController) SeasonsTable(res http.ResponseWriter, req *htt
 		seasonTableData.season,
 		seasonTableData.playerStats,
 		seasonTableData.gamesCount,
+		mode,
 		req.Context(),
 		res,
 	); err != nil {
-		handleInternalServerError(res, err)
+		handleInternalServerError(res, req, err)
 		return
 	}
 }
 
 func (controller GamesController) SeasonsTableUpdate(res http.ResponseWriter, req *http.Request) {
+	mode, err := getMode(req)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	sort := getSort(req)
 
-	seasonTableData, err := controller.getSeasonsTableData(req.URL.Query().Get("season"), sort)
+	seasonTableData, err := controller.getSeasonsTableData(req.Context(), req.URL.Query().Get("season"), mode, sort)
 	if err != nil {
-		handleInternalServerError(res, err)
+		handleInternalServerError(res, req, err)
 		return
 	}
 
@@ -92,37 +116,50 @@ func (controller GamesController) SeasonsTableUpdate(res http.ResponseWriter, re
 		req.Context(),
 		res,
 	); err != nil {
-		handleInternalServerError(res, err)
+		handleInternalServerError(res, req, err)
 		return
 	}
 }
 
 func (controller GamesController) PlayersTable(res http.ResponseWriter, req *http.Request) {
-	playersTableData, err := controller.getPlayersTableData("", getSort(req))
+	mode, err := getMode(req)
 	if err != nil {
-		handleInternalServerError(res, err)
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	playersTableData, err := controller.getPlayersTableData(req.Context(), "", mode, getSort(req))
+	if err != nil {
+		handleInternalServerError(res, req, err)
 		return
 	}
 
 	err = controller.views.PlayersTable.Render(
 		playersTableData.playerStats,
 		playersTableData.gamesCount,
+		mode,
 		req.Context(),
 		res,
 	)
 	if err != nil {
-		handleInternalServerError(res, err)
+		handleInternalServerError(res, req, err)
 		return
 	}
 }
 
 func (controller GamesController) PlayersTableUpdate(res http.ResponseWriter, req *http.Request) {
+	mode, err := getMode(req)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	playerUuid := req.PathValue("player")
 	sort := getSort(req)
 
-	playersTableData, err := controller.getPlayersTableData(playerUuid, sort)
+	playersTableData, err := controller.getPlayersTableData(req.Context(), playerUuid, mode, sort)
 	if err != nil {
-		handleInternalServerError(res, err)
+		handleInternalServerError(res, req, err)
 		return
 	}
 
@@ -134,7 +171,7 @@ func (controller GamesController) PlayersTableUpdate(res http.ResponseWriter, re
 		req.Context(),
 		res,
 	); err != nil {
-		handleInternalServerError(res, err)
+		handleInternalServerError(res, req, err)
 		return
 	}
 }
@@ -144,9 +181,9 @@ func (controller GamesController) PlayerInfo(res http.ResponseWriter, req *http.
 
 	sort := getSort(req)
 
-	playersTableData, err := controller.getPlayersTableData(playerUuid, sort)
+	playersTableData, err := controller.getPlayersTableData(req.Context(), playerUuid, games.ModeAll, sort)
 	if err != nil {
-		handleInternalServerError(res, err)
+		handleInternalServerError(res, req, err)
 		return
 	}
 	if len(playersTableData.playerStats) != 1 {
@@ -154,27 +191,29 @@ func (controller GamesController) PlayerInfo(res http.ResponseWriter, req *http.
 		return
 	}
 
-	attendances, err := controller.gamesManager.GetAttendancesForPlayer(playersTableData.playerStats[0].Player)
+	attendances, err := controller.gamesManager.GetAttendancesForPlayer(req.Context(), playersTableData.playerStats[0].Player)
 	if err != nil {
-		handleInternalServerError(res, err)
+		handleInternalServerError(res, req, err)
 		return
 	}
 
 	teamPlayerStats, err := controller.gamesManager.GetFellowPlayerStats(
+		req.Context(),
 		playersTableData.playerStats[0].Player,
 		sort,
 	)
 	if err != nil {
-		handleInternalServerError(res, err)
+		handleInternalServerError(res, req, err)
 		return
 	}
 
 	oponentPlayerStats, err := controller.gamesManager.GetOponentPlayerStats(
+		req.Context(),
 		playersTableData.playerStats[0].Player,
 		sort,
 	)
 	if err != nil {
-		handleInternalServerError(res, err)
+		handleInternalServerError(res, req, err)
 		return
 	}
 
@@ -182,15 +221,15 @@ func (controller GamesController) PlayerInfo(res http.ResponseWriter, req *http.
 		playersTableData.playerStats[0],
 		playersTableData.gamesCount,
 		attendances,
-		streaks.GetLongestStreakForPlayer(playersTableData.playerStats[0].Player, attendances, true),
-		streaks.GetLongestStreakForPlayer(playersTableData.playerStats[0].Player, attendances, false),
+		streaks.GetLongestStreakForPlayer(playersTableData.playerStats[0].Player, attendances, games.OutcomeWin),
+		streaks.GetLongestStreakForPlayer(playersTableData.playerStats[0].Player, attendances, games.OutcomeLoss),
 		teamPlayerStats,
 		oponentPlayerStats,
 		req.Context(),
 		res,
 	)
 	if err != nil {
-		handleInternalServerError(res, err)
+		handleInternalServerError(res, req, err)
 		return
 	}
 }
@@ -198,23 +237,23 @@ func (controller GamesController) PlayerInfo(res http.ResponseWriter, req *http.
 func (controller GamesController) FavoriteTeamUpdate(res http.ResponseWriter, req *http.Request) {
 	playerUuid := req.PathValue("player")
 
-	player, err := controller.getPlayer(playerUuid)
+	player, err := controller.getPlayer(req.Context(), playerUuid)
 	if err != nil {
-		handleInternalServerError(res, err)
+		handleInternalServerError(res, req, err)
 		return
 	}
 
 	sort := getSort(req)
 
-	teamPlayerStats, err := controller.gamesManager.GetFellowPlayerStats(player, sort)
+	teamPlayerStats, err := controller.gamesManager.GetFellowPlayerStats(req.Context(), player, sort)
 	if err != nil {
-		handleInternalServerError(res, err)
+		handleInternalServerError(res, req, err)
 		return
 	}
 
-	gamesCount, err := controller.gamesManager.GetGamesCountForPlayer(player)
+	gamesCount, err := controller.gamesManager.GetGamesCountForPlayer(req.Context(), player)
 	if err != nil {
-		handleInternalServerError(res, err)
+		handleInternalServerError(res, req, err)
 		return
 	}
 
@@ -226,7 +265,7 @@ func (controller GamesController) FavoriteTeamUpdate(res http.ResponseWriter, re
 		req.Context(),
 		res,
 	); err != nil {
-		handleInternalServerError(res, err)
+		handleInternalServerError(res, req, err)
 		return
 	}
 }
@@ -234,23 +273,23 @@ func (controller GamesController) FavoriteTeamUpdate(res http.ResponseWriter, re
 func (controller GamesController) FavoriteOponentsUpdate(res http.ResponseWriter, req *http.Request) {
 	playerUuid := req.PathValue("player")
 
-	player, err := controller.getPlayer(playerUuid)
+	player, err := controller.getPlayer(req.Context(), playerUuid)
 	if err != nil {
-		handleInternalServerError(res, err)
+		handleInternalServerError(res, req, err)
 		return
 	}
 
 	sort := getSort(req)
 
-	teamPlayerStats, err := controller.gamesManager.GetOponentPlayerStats(player, sort)
+	teamPlayerStats, err := controller.gamesManager.GetOponentPlayerStats(req.Context(), player, sort)
 	if err != nil {
-		handleInternalServerError(res, err)
+		handleInternalServerError(res, req, err)
 		return
 	}
 
-	gamesCount, err := controller.gamesManager.GetGamesCountForPlayer(player)
+	gamesCount, err := controller.gamesManager.GetGamesCountForPlayer(req.Context(), player)
 	if err != nil {
-		handleInternalServerError(res, err)
+		handleInternalServerError(res, req, err)
 		return
 	}
 
@@ -262,29 +301,356 @@ func (controller GamesController) FavoriteOponentsUpdate(res http.ResponseWriter
 		req.Context(),
 		res,
 	); err != nil {
-		handleInternalServerError(res, err)
+		handleInternalServerError(res, req, err)
+		return
+	}
+}
+
+func (controller GamesController) CreateGame(res http.ResponseWriter, req *http.Request) {
+	var request createGameRequest
+	err := readJsonRequest(res, req, &request)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	winners, losers, err := controller.playersManager.GetTeamsByNames(
+		req.Context(),
+		getTeamMemberNames(request.Winners),
+		getTeamMemberNames(request.Losers),
+	)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	positions, err := getPositions(append(request.Winners, request.Losers...), append(winners, losers...))
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	var score *games.Score
+	if request.Score != nil {
+		score = &games.Score{Team1: request.Score.Winners, Team2: request.Score.Losers}
+	}
+
+	user, _ := getAuthenticatedUser(req)
+
+	game, err := controller.gamesManager.CreateGame(req.Context(), games.GameEntry{
+		PlayedAt:       request.PlayedAt,
+		Winners:        winners,
+		Losers:         losers,
+		Draw:           request.Draw,
+		Positions:      positions,
+		Score:          score,
+		RecordedBy:     &user.Player,
+		IdempotencyKey: req.Header.Get(idempotencyKeyHeader),
+		AllowDuplicate: request.AllowDuplicate,
+	})
+	if errors.Is(err, games.ErrInvalidIdempotencyKey) {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, games.ErrDuplicateGame) {
+		http.Error(res, err.Error(), http.StatusConflict)
+		return
+	}
+	var validationErr *games.ValidationError
+	if errors.As(err, &validationErr) {
+		err = writeJsonResponseWithStatus(res, http.StatusUnprocessableEntity, newValidationErrorResponse(validationErr))
+		if err != nil {
+			handleInternalServerError(res, req, err)
+		}
+		return
+	}
+	if errors.Is(err, games.ErrDuplicateAttendance) ||
+		errors.Is(err, games.ErrUnknownReference) ||
+		errors.Is(err, games.ErrIdempotencyKeyReused) {
+		http.Error(res, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+
+	err = writeJsonResponseWithStatus(
+		res,
+		http.StatusCreated,
+		map[string]gameResponse{"game": newGameResponse(*game, winners, losers)},
+	)
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+}
+
+func (controller GamesController) NewGame(res http.ResponseWriter, req *http.Request) {
+	playersList, err := controller.playersManager.GetPlayers(req.Context())
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+
+	err = controller.views.NewGame.Render(playersList, templates.GameForm{}, req.Context(), res)
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+}
+
+// SubmitGame creates the game of the game form. Invalid forms are sent back with the
+// messages, otherwise the updated table of the season replaces the form. Forms queued by the
+// offline queue of the web ui are sent again with the same idempotency key. Duplicate games
+// are answered with 409 instead of 422, so the offline queue can tell them apart.
+func (controller GamesController) SubmitGame(res http.ResponseWriter, req *http.Request) {
+	form, err := readGameForm(res, req)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	playersList, err := controller.playersManager.GetPlayers(req.Context())
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+
+	entry, messages := validateGameForm(form, playersList)
+	if len(messages) == 0 {
+		user, _ := getAuthenticatedUser(req)
+
+		entry.RecordedBy = &user.Player
+
+		_, err = controller.gamesManager.CreateGame(req.Context(), entry)
+		var validationErr *games.ValidationError
+		form.Duplicate = errors.Is(err, games.ErrDuplicateGame)
+		if form.Duplicate {
+			messages = append(messages, "The same game was already recorded, check it in the games or save it anyway.")
+		} else if errors.As(err, &validationErr) {
+			messages = append(messages, getGameFormMessages(validationErr, form.Result)...)
+		} else if errors.Is(err, games.ErrDuplicateAttendance) ||
+			errors.Is(err, games.ErrInvalidIdempotencyKey) ||
+			errors.Is(err, games.ErrIdempotencyKeyReused) {
+			messages = append(messages, err.Error())
+		} else if err != nil {
+			handleInternalServerError(res, req, err)
+			return
+		}
+	}
+
+	if len(messages) > 0 {
+		form.Errors = messages
+		if form.Duplicate {
+			res.WriteHeader(http.StatusConflict)
+		} else {
+			res.WriteHeader(http.StatusUnprocessableEntity)
+		}
+
+		err = controller.views.NewGameForm.Render(playersList, form, req.Context(), res)
+		if err != nil {
+			handleInternalServerError(res, req, err)
+		}
+		return
+	}
+
+	seasonTableData, err := controller.getSeasonsTableData(req.Context(), "", games.ModeAll, "pointsRatio")
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+
+	if err = controller.views.GameCreated.Render(
+		seasonTableData.season,
+		seasonTableData.playerStats,
+		seasonTableData.gamesCount,
+		req.Context(),
+		res,
+	); err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+}
+
+func (controller GamesController) Games(res http.ResponseWriter, req *http.Request) {
+	filter, err := controller.getGamesFilter(req)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	gamesPage, err := controller.gamesManager.ListGames(req.Context(), filter)
+	if errors.Is(err, games.ErrInvalidFilter) || errors.Is(err, games.ErrInvalidCursor) {
+		http.Error(res, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+
+	seasons, err := controller.seasonsManager.GetSeasons(req.Context())
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+
+	playersList, err := controller.playersManager.GetPlayers(req.Context())
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+
+	if err = controller.views.GamesPage.Render(
+		seasons,
+		playersList,
+		gamesPage,
+		getNextGamesUrl(req, gamesPage.NextCursor),
+		req.Context(),
+		res,
+	); err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+}
+
+func (controller GamesController) GamesUpdate(res http.ResponseWriter, req *http.Request) {
+	filter, err := controller.getGamesFilter(req)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	gamesPage, err := controller.gamesManager.ListGames(req.Context(), filter)
+	if errors.Is(err, games.ErrInvalidFilter) || errors.Is(err, games.ErrInvalidCursor) {
+		http.Error(res, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+
+	if err = controller.views.GamesRows.Render(
+		gamesPage,
+		getNextGamesUrl(req, gamesPage.NextCursor),
+		req.Context(),
+		res,
+	); err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+}
+
+func (controller GamesController) ListGames(res http.ResponseWriter, req *http.Request) {
+	filter, err := controller.getGamesFilter(req)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	gamesPage, err := controller.gamesManager.ListGames(req.Context(), filter)
+	if errors.Is(err, games.ErrInvalidFilter) || errors.Is(err, games.ErrInvalidCursor) {
+		http.Error(res, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+
+	err = writeJsonResponse(res, newGamesPageResponse(gamesPage))
+	if err != nil {
+		handleInternalServerError(res, req, err)
 		return
 	}
 }
 
-func (controller GamesController) GetGamesCount(res http.ResponseWriter, _ *http.Request) {
-	gamesCount, err := controller.gamesManager.GetGamesCount()
+func (controller GamesController) GameInfo(res http.ResponseWriter, req *http.Request) {
+	game, impacts, err := controller.getGameWithImpacts(req.Context(), req.PathValue("game"))
+	if errors.Is(err, games.ErrGameNotFound) {
+		http.Error(res, err.Error(), http.StatusNotFound)
+		return
+	}
 	if err != nil {
-		handleInternalServerError(res, err)
+		handleInternalServerError(res, req, err)
+		return
+	}
+
+	err = controller.views.GameInfo.Render(game, impacts, req.Context(), res)
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+}
+
+func (controller GamesController) GetGame(res http.ResponseWriter, req *http.Request) {
+	game, impacts, err := controller.getGameWithImpacts(req.Context(), req.PathValue("game"))
+	if errors.Is(err, games.ErrGameNotFound) {
+		http.Error(res, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+
+	err = writeJsonResponse(res, map[string]gameDetailResponse{"game": newGameDetailResponse(game, impacts)})
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+}
+
+func (controller GamesController) GetGamesCount(res http.ResponseWriter, req *http.Request) {
+	mode, err := getMode(req)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	gamesCount, err := controller.gamesManager.GetGamesCount(req.Context(), mode)
+	if err != nil {
+		handleInternalServerError(res, req, err)
 		return
 	}
 
 	err = writeJsonResponse(res, map[string]int{"gamesCount": gamesCount})
 	if err != nil {
-		handleInternalServerError(res, err)
+		handleInternalServerError(res, req, err)
 		return
 	}
 }
 
 func (controller GamesController) GetSeasonsTable(res http.ResponseWriter, req *http.Request) {
-	seasonTableData, err := controller.getSeasonsTableData(req.PathValue("season"), getSort(req))
+	format, err := getTableFormat(req)
 	if err != nil {
-		handleInternalServerError(res, err)
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	mode, err := getMode(req)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	seasonTableData, err := controller.getSeasonsTableData(req.Context(), req.PathValue("season"), mode, getSort(req))
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+
+	if format != tables.FormatJSON {
+		err = writeTableResponse(
+			res,
+			tables.NewPlayerStatsTable(seasonTableData.gamesCount, seasonTableData.playerStats),
+			format,
+		)
+		if err != nil {
+			handleInternalServerError(res, req, err)
+		}
 		return
 	}
 
@@ -293,15 +659,27 @@ func (controller GamesController) GetSeasonsTable(res http.ResponseWriter, req *
 		newTableResponse(seasonTableData.season, seasonTableData.gamesCount, seasonTableData.playerStats),
 	)
 	if err != nil {
-		handleInternalServerError(res, err)
+		handleInternalServerError(res, req, err)
 		return
 	}
 }
 
 func (controller GamesController) GetPlayers(res http.ResponseWriter, req *http.Request) {
-	playerStats, err := controller.gamesManager.GetAllPlayerStats(getSort(req))
+	format, err := getTableFormat(req)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	mode, err := getMode(req)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	playerStats, err := controller.gamesManager.GetAllPlayerStats(req.Context(), mode, getSort(req))
 	if err != nil {
-		handleInternalServerError(res, err)
+		handleInternalServerError(res, req, err)
 		return
 	}
 
@@ -310,12 +688,26 @@ func (controller GamesController) GetPlayers(res http.ResponseWriter, req *http.
 		playerStats = filterPlayersStatsForUuid(playerStats, playerUuid)
 	}
 
+	if format != tables.FormatJSON {
+		gamesCount, err := controller.gamesManager.GetGamesCount(req.Context(), mode)
+		if err != nil {
+			handleInternalServerError(res, req, err)
+			return
+		}
+
+		err = writeTableResponse(res, tables.NewPlayerStatsTable(gamesCount, playerStats), format)
+		if err != nil {
+			handleInternalServerError(res, req, err)
+		}
+		return
+	}
+
 	err = writeJsonResponse(
 		res,
 		map[string][]playerStatsResponse{"playerStats": newPlayerStatsResponsesFromPlayerStats(playerStats)},
 	)
 	if err != nil {
-		handleInternalServerError(res, err)
+		handleInternalServerError(res, req, err)
 		return
 	}
 }
@@ -323,15 +715,15 @@ func (controller GamesController) GetPlayers(res http.ResponseWriter, req *http.
 func (controller GamesController) GetFavoriteTeam(res http.ResponseWriter, req *http.Request) {
 	playerUuid := req.PathValue("player")
 
-	player, err := controller.getPlayer(playerUuid)
+	player, err := controller.getPlayer(req.Context(), playerUuid)
 	if err != nil {
-		handleInternalServerError(res, err)
+		handleInternalServerError(res, req, err)
 		return
 	}
 
-	teamPlayerStats, err := controller.gamesManager.GetFellowPlayerStats(player, getSort(req))
+	teamPlayerStats, err := controller.gamesManager.GetFellowPlayerStats(req.Context(), player, getSort(req))
 	if err != nil {
-		handleInternalServerError(res, err)
+		handleInternalServerError(res, req, err)
 		return
 	}
 
@@ -340,31 +732,85 @@ func (controller GamesController) GetFavoriteTeam(res http.ResponseWriter, req *
 		map[string][]playerStatsResponse{"playerStats": newPlayerStatsResponsesFromPlayerStats(teamPlayerStats)},
 	)
 	if err != nil {
-		handleInternalServerError(res, err)
+		handleInternalServerError(res, req, err)
 		return
 	}
 }
 
+// MatchPlayers splits the players of the player query params, by name, into balanced teams.
+func (controller GamesController) MatchPlayers(res http.ResponseWriter, req *http.Request) {
+	names := req.URL.Query()["player"]
+
+	playersToMatch := make([]players.Player, len(names))
+	for i, name := range names {
+		player, err := controller.playersManager.GetPlayerByName(req.Context(), name)
+		if errors.Is(err, players.ErrPlayerNotFound) {
+			http.Error(res, fmt.Sprintf("Player %s not found", name), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			handleInternalServerError(res, req, err)
+			return
+		}
+		playersToMatch[i] = player
+	}
+
+	matchup, err := controller.gamesManager.MatchPlayers(req.Context(), playersToMatch)
+	if errors.Is(err, games.ErrMatchPlayers) {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+
+	err = writeJsonResponse(res, map[string]matchupResponse{"matchup": newMatchupResponse(matchup)})
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+}
+
+func (controller GamesController) getGameWithImpacts(ctx context.Context, gameUuid string) (games.Game, []games.PlayerImpact, error) {
+	game, err := controller.gamesManager.GetGameByUUID(ctx, gameUuid)
+	if errors.Is(err, games.ErrGameNotFound) {
+		return games.Game{}, nil, fmt.Errorf("Game %s not found: %w", gameUuid, err)
+	}
+	if err != nil {
+		return games.Game{}, nil, err
+	}
+
+	impacts, err := controller.gamesManager.GetGameImpacts(ctx, game)
+	if err != nil {
+		return games.Game{}, nil, err
+	}
+
+	return game, impacts, nil
+}
+
 type seasonTableData struct {
 	playerTableData
 	season seasons.Season
 }
 
 func (controller GamesController) getSeasonsTableData(
+	ctx context.Context,
 	seasonUuid string,
+	mode games.Mode,
 	sort string,
 ) (seasonTableData, error) {
-	season, err := controller.getSeason(seasonUuid)
+	season, err := controller.getSeason(ctx, seasonUuid)
 	if err != nil {
 		return seasonTableData{}, err
 	}
 
-	playerStats, err := controller.gamesManager.GetPlayerStatsForSeason(season, sort)
+	playerStats, err := controller.gamesManager.GetPlayerStatsForSeason(ctx, season, mode, sort)
 	if err != nil {
 		return seasonTableData{}, err
 	}
 
-	gamesCount, err := controller.gamesManager.GetGamesCountForSeason(season)
+	gamesCount, err := controller.gamesManager.GetGamesCountForSeason(ctx, season, mode)
 	if err != nil {
 		return seasonTableData{}, err
 	}
@@ -383,8 +829,13 @@ type playerTableData struct {
 	gamesCount  int
 }
 
-func (controller GamesController) getPlayersTableData(playerUuid string, sort string) (playerTableData, error) {
-	playerStats, err := controller.gamesManager.GetAllPlayerStats(sort)
+func (controller GamesController) getPlayersTableData(
+	ctx context.Context,
+	playerUuid string,
+	mode games.Mode,
+	sort string,
+) (playerTableData, error) {
+	playerStats, err := controller.gamesManager.GetAllPlayerStats(ctx, mode, sort)
 	if err != nil {
 		return playerTableData{}, err
 	}
@@ -392,7 +843,7 @@ func (controller GamesController) getPlayersTableData(playerUuid string, sort st
 		playerStats = filterPlayersStatsForUuid(playerStats, playerUuid)
 	}
 
-	gamesCount, err := controller.gamesManager.GetGamesCount()
+	gamesCount, err := controller.gamesManager.GetGamesCount(ctx, mode)
 	if err != nil {
 		return playerTableData{}, err
 	}
@@ -400,16 +851,16 @@ func (controller GamesController) getPlayersTableData(playerUuid string, sort st
 	return playerTableData{playerStats: playerStats, gamesCount: gamesCount}, nil
 }
 
-func (controller GamesController) getSeason(seasonUuid string) (seasons.Season, error) {
+func (controller GamesController) getSeason(ctx context.Context, seasonUuid string) (seasons.Season, error) {
 	if seasonUuid != "" {
-		return controller.seasonsManager.GetSeasonByUuid(seasonUuid)
+		return controller.seasonsManager.GetSeasonByUuid(ctx, seasonUuid)
 	}
 
-	return controller.seasonsManager.ActiveSeason()
+	return controller.seasonsManager.ActiveSeason(ctx)
 }
 
-func (controller GamesController) getPlayer(playerUuid string) (players.Player, error) {
-	player, err := controller.playersManager.GetPlayerByUUID(playerUuid)
+func (controller GamesController) getPlayer(ctx context.Context, playerUuid string) (players.Player, error) {
+	player, err := controller.playersManager.GetPlayerByUUID(ctx, playerUuid)
 	if errors.Is(err, players.ErrPlayerNotFound) {
 		return players.Player{}, errors.New(fmt.Sprintf("Player %s not found", playerUuid))
 	}
@@ -420,6 +871,112 @@ func (controller GamesController) getPlayer(playerUuid string) (players.Player,
 	return player, nil
 }
 
+// getGamesFilter reads the games filter from the query. Players and the season are given by
+// their uuids, from and to are inclusive dates.
+func (controller GamesController) getGamesFilter(req *http.Request) (games.GamesFilter, error) {
+	query := req.URL.Query()
+	filter := games.GamesFilter{Cursor: query.Get("cursor")}
+
+	if seasonUuid := query.Get("season"); seasonUuid != "" {
+		season, err := controller.seasonsManager.GetSeasonByUuid(req.Context(), seasonUuid)
+		if err != nil {
+			return games.GamesFilter{}, fmt.Errorf("Season %s not found", seasonUuid)
+		}
+
+		filter.Season = &season
+	}
+
+	var err error
+	filter.Player, err = controller.getOptionalPlayer(req.Context(), query.Get("player"))
+	if err != nil {
+		return games.GamesFilter{}, err
+	}
+
+	filter.Teammate, err = controller.getOptionalPlayer(req.Context(), query.Get("teammate"))
+	if err != nil {
+		return games.GamesFilter{}, err
+	}
+
+	filter.Oponent, err = controller.getOptionalPlayer(req.Context(), query.Get("opponent"))
+	if err != nil {
+		return games.GamesFilter{}, err
+	}
+
+	filter.Outcome, err = games.ParseOutcome(query.Get("outcome"))
+	if err != nil {
+		return games.GamesFilter{}, err
+	}
+
+	if from := query.Get("from"); from != "" {
+		filter.From, err = time.Parse("2006-01-02", from)
+		if err != nil {
+			return games.GamesFilter{}, errors.New("from has to be a date like 2006-01-02")
+		}
+	}
+
+	if to := query.Get("to"); to != "" {
+		filter.To, err = time.Parse("2006-01-02", to)
+		if err != nil {
+			return games.GamesFilter{}, errors.New("to has to be a date like 2006-01-02")
+		}
+
+		filter.To = filter.To.AddDate(0, 0, 1)
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil {
+			return games.GamesFilter{}, errors.New("limit has to be a number")
+		}
+	}
+
+	return filter, nil
+}
+
+func (controller GamesController) getOptionalPlayer(ctx context.Context, playerUuid string) (*players.Player, error) {
+	if playerUuid == "" {
+		return nil, nil
+	}
+
+	player, err := controller.getPlayer(ctx, playerUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	return &player, nil
+}
+
+// getNextGamesUrl keeps the filters of the request for loading the next page of games.
+func getNextGamesUrl(req *http.Request, nextCursor string) string {
+	if nextCursor == "" {
+		return ""
+	}
+
+	query := req.URL.Query()
+	query.Set("cursor", nextCursor)
+
+	return "/table/games?" + query.Encode()
+}
+
+func getPositions(members []teamMemberRequest, team players.Team) (games.Positions, error) {
+	playerUuids := map[string]string{}
+	for _, player := range team {
+		playerUuids[player.Name] = player.UUID
+	}
+
+	positions := games.Positions{}
+	for _, member := range members {
+		position, err := players.ParsePosition(member.Position)
+		if err != nil {
+			return nil, fmt.Errorf("position of %s: %w", member.Name, err)
+		}
+
+		positions[playerUuids[member.Name]] = position
+	}
+
+	return positions, nil
+}
+
 func filterPlayersStatsForUuid(playersStats []games.PlayerStats, uuid string) []games.PlayerStats {
 	for _, playerStats := range playersStats {
 		if playerStats.Player.UUID == uuid {
@@ -430,6 +987,24 @@ func filterPlayersStatsForUuid(playersStats []games.PlayerStats, uuid string) []
 	return []games.PlayerStats{}
 }
 
+// getTableFormat returns the format of the format query param, or the first format accepted by
+// the Accept header. JSON is used if neither is set.
+func getTableFormat(req *http.Request) (string, error) {
+	if format := req.URL.Query().Get("format"); format != "" {
+		return tables.ParseFormat(format)
+	}
+
+	if format, ok := tables.FormatFromAccept(req.Header.Get("Accept")); ok {
+		return format, nil
+	}
+
+	return tables.FormatJSON, nil
+}
+
+func getMode(req *http.Request) (games.Mode, error) {
+	return games.ParseMode(req.URL.Query().Get("mode"))
+}
+
 func getSort(req *http.Request) string {
 	sort := req.URL.Query().Get("sort")
 	if sort == "" {
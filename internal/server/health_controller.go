@@ -0,0 +1,89 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+)
+
+var errPendingMigrations = errors.New("migrations are pending")
+
+type databasePinger interface {
+	PingContext(ctx context.Context) error
+}
+
+type migrationsState interface {
+	HasPending(ctx context.Context) (bool, error)
+}
+
+type healthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// HealthController answers the health checks of the container. The server is healthy if the
+// database is reachable and ready if the migrations are applied too.
+type HealthController struct {
+	database   databasePinger
+	migrations migrationsState
+}
+
+func NewHealthController(database databasePinger, migrations migrationsState) HealthController {
+	return HealthController{
+		database:   database,
+		migrations: migrations,
+	}
+}
+
+func (controller HealthController) Healthz(res http.ResponseWriter, req *http.Request) {
+	writeHealthResponse(res, req, map[string]error{
+		"database": controller.checkDatabase(req.Context()),
+	})
+}
+
+func (controller HealthController) Readyz(res http.ResponseWriter, req *http.Request) {
+	writeHealthResponse(res, req, map[string]error{
+		"database":   controller.checkDatabase(req.Context()),
+		"migrations": controller.checkMigrations(req.Context()),
+	})
+}
+
+func (controller HealthController) checkDatabase(ctx context.Context) error {
+	return controller.database.PingContext(ctx)
+}
+
+func (controller HealthController) checkMigrations(ctx context.Context) error {
+	pending, err := controller.migrations.HasPending(ctx)
+	if err != nil {
+		return err
+	}
+	if pending {
+		return errPendingMigrations
+	}
+
+	return nil
+}
+
+// writeHealthResponse answers with 503 if a check failed. The errors are logged, not sent.
+func writeHealthResponse(res http.ResponseWriter, req *http.Request, checks map[string]error) {
+	response := healthResponse{Status: "ok", Checks: map[string]string{}}
+	status := http.StatusOK
+
+	for name, err := range checks {
+		if err == nil {
+			response.Checks[name] = "ok"
+			continue
+		}
+
+		slog.WarnContext(req.Context(), "health check failed", slog.String("check", name), slog.String("error", err.Error()))
+		response.Checks[name] = "failed"
+		response.Status = "failed"
+		status = http.StatusServiceUnavailable
+	}
+
+	err := writeJsonResponseWithStatus(res, status, response)
+	if err != nil {
+		handleInternalServerError(res, req, err)
+	}
+}
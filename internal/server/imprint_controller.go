@@ -21,7 +21,7 @@ func NewImprintController(imprintText string, imprintView views.ImprintView) Imp
 func (controller ImprintController) Imprint(res http.ResponseWriter, req *http.Request) {
 	err := controller.imprintView.Render(controller.imprintText, req.Context(), res)
 	if err != nil {
-		handleInternalServerError(res, err)
+		handleInternalServerError(res, req, err)
 		return
 	}
 }
@@ -0,0 +1,227 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/spie/fskick/internal/avatars"
+	"github.com/spie/fskick/internal/players"
+)
+
+const maxAvatarUploadSize = 10 << 20
+
+type PlayersController struct {
+	playersManager players.Manager
+	avatarsManager avatars.Manager
+}
+
+func NewPlayersController(playersManager players.Manager, avatarsManager avatars.Manager) PlayersController {
+	return PlayersController{
+		playersManager: playersManager,
+		avatarsManager: avatarsManager,
+	}
+}
+
+func (controller PlayersController) CreatePlayer(res http.ResponseWriter, req *http.Request) {
+	var request createPlayerRequest
+	err := readJsonRequest(res, req, &request)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		http.Error(res, "Missing player name.", http.StatusUnprocessableEntity)
+		return
+	}
+
+	player, err := controller.playersManager.CreatePlayer(req.Context(), name)
+	if errors.Is(err, players.ErrPlayerExists) {
+		http.Error(res, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+
+	err = writeJsonResponseWithStatus(
+		res,
+		http.StatusCreated,
+		map[string]playerResponse{"player": newPlayerResponseFromPlayer(player)},
+	)
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+}
+
+// GetProfiles returns all players, also the ones without games.
+func (controller PlayersController) GetProfiles(res http.ResponseWriter, req *http.Request) {
+	allPlayers, err := controller.playersManager.GetPlayers(req.Context())
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+
+	playersResponse := make([]playerResponse, len(allPlayers))
+	for i, player := range allPlayers {
+		playersResponse[i] = newPlayerResponseFromPlayer(player)
+	}
+
+	err = writeJsonResponse(res, map[string][]playerResponse{"players": playersResponse})
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+}
+
+// LookupPlayer returns the player with the name of the name query param.
+func (controller PlayersController) LookupPlayer(res http.ResponseWriter, req *http.Request) {
+	name := req.URL.Query().Get("name")
+	if name == "" {
+		http.Error(res, "Missing name.", http.StatusBadRequest)
+		return
+	}
+
+	player, err := controller.playersManager.GetPlayerByName(req.Context(), name)
+	if errors.Is(err, players.ErrPlayerNotFound) {
+		http.Error(res, fmt.Sprintf("Player %s not found", name), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+
+	err = writeJsonResponse(res, map[string]playerResponse{"player": newPlayerResponseFromPlayer(player)})
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+}
+
+// UpdateProfile replaces the profile of a player. Users can update their own profile, admins
+// the profiles of all players.
+func (controller PlayersController) UpdateProfile(res http.ResponseWriter, req *http.Request) {
+	playerUuid := req.PathValue("player")
+
+	user, ok := getAuthenticatedUser(req)
+	if !ok {
+		handleUnauthorized(res)
+		return
+	}
+	if !canChangePlayer(req, user, playerUuid) {
+		http.Error(res, "Only the own profile can be changed.", http.StatusForbidden)
+		return
+	}
+
+	var request updateProfileRequest
+	err := readJsonRequest(res, req, &request)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	profile := players.Profile{DisplayName: request.DisplayName, JoinedAt: request.JoinedAt}
+	profile.PreferredPosition, err = players.ParsePosition(request.PreferredPosition)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	profile.FavoriteSide, err = players.ParseSide(request.FavoriteSide)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	player, err := controller.playersManager.GetPlayerByUUID(req.Context(), playerUuid)
+	if errors.Is(err, players.ErrPlayerNotFound) {
+		http.Error(res, fmt.Sprintf("Player %s not found", playerUuid), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+
+	player, err = controller.playersManager.UpdateProfile(req.Context(), player, profile)
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+
+	err = writeJsonResponse(res, map[string]playerResponse{"player": newPlayerResponseFromPlayer(player)})
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+}
+
+func (controller PlayersController) UploadAvatar(res http.ResponseWriter, req *http.Request) {
+	playerUuid := req.PathValue("player")
+
+	user, ok := getAuthenticatedUser(req)
+	if !ok {
+		handleUnauthorized(res)
+		return
+	}
+	if !canChangePlayer(req, user, playerUuid) {
+		http.Error(res, "Only the own avatar can be changed.", http.StatusForbidden)
+		return
+	}
+
+	player, err := controller.playersManager.GetPlayerByUUID(req.Context(), playerUuid)
+	if errors.Is(err, players.ErrPlayerNotFound) {
+		http.Error(res, fmt.Sprintf("Player %s not found", playerUuid), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+
+	req.Body = http.MaxBytesReader(res, req.Body, maxAvatarUploadSize)
+	upload, _, err := req.FormFile("avatar")
+	if err != nil {
+		http.Error(res, "Missing avatar file.", http.StatusBadRequest)
+		return
+	}
+	defer upload.Close()
+
+	player, err = controller.avatarsManager.UploadAvatar(req.Context(), player, upload)
+	if errors.Is(err, avatars.ErrInvalidImage) || errors.Is(err, avatars.ErrImageTooLarge) {
+		http.Error(res, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+
+	err = writeJsonResponse(res, map[string]playerResponse{"player": newPlayerResponseFromPlayer(player)})
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+}
+
+func (controller PlayersController) Avatar(res http.ResponseWriter, req *http.Request) {
+	avatar, err := controller.avatarsManager.GetAvatar(req.Context(), req.PathValue("avatar"))
+	if errors.Is(err, avatars.ErrAvatarNotFound) {
+		http.NotFound(res, req)
+		return
+	}
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+
+	res.Header().Set("Content-Type", avatars.AvatarContentType)
+	// avatar names change on every upload, so they can be cached forever
+	res.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	res.Write(avatar)
+}
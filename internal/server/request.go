@@ -0,0 +1,244 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spie/fskick/internal/games"
+	"github.com/spie/fskick/internal/players"
+	"github.com/spie/fskick/internal/templates"
+)
+
+const (
+	maxJsonRequestSize = 1 << 20
+	maxFormRequestSize = 1 << 16
+	playedAtFormLayout = "2006-01-02T15:04"
+
+	idempotencyKeyHeader = "Idempotency-Key"
+)
+
+type teamMemberRequest struct {
+	Name     string `json:"name"`
+	Position string `json:"position"`
+}
+
+type scoreRequest struct {
+	Winners int `json:"winners"`
+	Losers  int `json:"losers"`
+}
+
+type createGameRequest struct {
+	PlayedAt time.Time           `json:"playedAt"`
+	Winners  []teamMemberRequest `json:"winners"`
+	Losers   []teamMemberRequest `json:"losers"`
+	Draw     bool                `json:"draw"`
+	Score    *scoreRequest       `json:"score"`
+	// AllowDuplicate creates the game even if the same game was recorded recently.
+	AllowDuplicate bool `json:"allowDuplicate"`
+}
+
+type createSeasonRequest struct {
+	Name string `json:"name"`
+}
+
+type updateSeasonRulesRequest struct {
+	TeamSizes string `json:"teamSizes"`
+}
+
+type createTournamentRequest struct {
+	Name     string `json:"name"`
+	Format   string `json:"format"`
+	TeamSize int    `json:"teamSize"`
+}
+
+// registerTeamRequest registers the players by name, without a name the team is named after
+// its players.
+type registerTeamRequest struct {
+	Name    string   `json:"name"`
+	Players []string `json:"players"`
+}
+
+type scheduleTournamentRequest struct {
+	Groups    int `json:"groups"`
+	Advancing int `json:"advancing"`
+}
+
+type matchScoreRequest struct {
+	Team1 int `json:"team1"`
+	Team2 int `json:"team2"`
+}
+
+// recordResultRequest is the result of a tournament match, the winner is team 1 or 2 of the
+// match and the score is seen from team 1.
+type recordResultRequest struct {
+	PlayedAt time.Time          `json:"playedAt"`
+	Winner   int                `json:"winner"`
+	Draw     bool               `json:"draw"`
+	Score    *matchScoreRequest `json:"score"`
+}
+
+type createPlayerRequest struct {
+	Name string `json:"name"`
+}
+
+// updateProfileRequest replaces the profile of a player, a zero joinedAt keeps the date.
+type updateProfileRequest struct {
+	DisplayName       string    `json:"displayName"`
+	PreferredPosition string    `json:"preferredPosition"`
+	FavoriteSide      string    `json:"favoriteSide"`
+	JoinedAt          time.Time `json:"joinedAt"`
+}
+
+func readJsonRequest(res http.ResponseWriter, req *http.Request, request any) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(res, req.Body, maxJsonRequestSize))
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(request)
+	if err != nil {
+		return fmt.Errorf("read json request: %w", err)
+	}
+
+	return nil
+}
+
+func getTeamMemberNames(members []teamMemberRequest) []string {
+	names := make([]string, len(members))
+	for i, member := range members {
+		names[i] = member.Name
+	}
+
+	return names
+}
+
+// readGameForm reads the game form of the web ui, the teams are the uuids of the players.
+func readGameForm(res http.ResponseWriter, req *http.Request) (templates.GameForm, error) {
+	req.Body = http.MaxBytesReader(res, req.Body, maxFormRequestSize)
+
+	err := req.ParseForm()
+	if err != nil {
+		return templates.GameForm{}, fmt.Errorf("read game form: %w", err)
+	}
+
+	return templates.GameForm{
+		Team1:          req.PostForm["team1"],
+		Team2:          req.PostForm["team2"],
+		Result:         req.PostForm.Get("result"),
+		Score1:         strings.TrimSpace(req.PostForm.Get("score1")),
+		Score2:         strings.TrimSpace(req.PostForm.Get("score2")),
+		PlayedAt:       req.PostForm.Get("playedAt"),
+		IdempotencyKey: req.PostForm.Get("idempotencyKey"),
+		AllowDuplicate: req.PostForm.Get("allowDuplicate") != "",
+	}, nil
+}
+
+// validateGameForm checks the entered teams, score and date. All problems are returned as
+// messages to show with the form.
+func validateGameForm(form templates.GameForm, playersList []players.Player) (games.GameEntry, []string) {
+	playersByUuid := map[string]players.Player{}
+	for _, player := range playersList {
+		playersByUuid[player.UUID] = player
+	}
+
+	messages := []string{}
+	teams := [2]players.Team{}
+	unknown := []string{}
+	for i, uuids := range [][]string{form.Team1, form.Team2} {
+		if len(uuids) == 0 {
+			messages = append(messages, fmt.Sprintf("Team %d has no players.", i+1))
+		}
+
+		for _, uuid := range uuids {
+			player, ok := playersByUuid[uuid]
+			if !ok {
+				unknown = append(unknown, uuid)
+				continue
+			}
+
+			teams[i] = append(teams[i], player)
+		}
+	}
+	if len(unknown) > 0 {
+		messages = append(messages, fmt.Sprintf("Unknown players: %s.", strings.Join(unknown, ", ")))
+	}
+
+	for _, player := range teams[0] {
+		if slices.Contains(form.Team2, player.UUID) {
+			messages = append(messages, fmt.Sprintf("%s plays in both teams.", player.PublicName()))
+		}
+	}
+
+	entry := games.GameEntry{
+		Winners:        teams[0],
+		Losers:         teams[1],
+		Positions:      games.Positions{},
+		IdempotencyKey: form.IdempotencyKey,
+		AllowDuplicate: form.AllowDuplicate,
+	}
+
+	score, err := parseFormScore(form.Score1, form.Score2)
+	if err != nil {
+		messages = append(messages, err.Error())
+	}
+
+	if form.PlayedAt != "" {
+		entry.PlayedAt, err = time.ParseInLocation(playedAtFormLayout, form.PlayedAt, time.Local)
+		if err != nil {
+			messages = append(messages, "Played at is not a valid date.")
+		}
+	}
+
+	switch form.Result {
+	case "", "team1":
+	case "team2":
+		entry.Winners, entry.Losers = entry.Losers, entry.Winners
+		if score != nil {
+			score = &games.Score{Team1: score.Team2, Team2: score.Team1}
+		}
+	case "draw":
+		entry.Draw = true
+	default:
+		messages = append(messages, fmt.Sprintf("Unknown result %s.", form.Result))
+	}
+	entry.Score = score
+
+	return entry, messages
+}
+
+// getGameFormMessages names the fields of the validation error like the game form, the winners
+// are team 2 if team 2 won.
+func getGameFormMessages(validationErr *games.ValidationError, result string) []string {
+	labels := map[string]string{"winners": "Team 1", "losers": "Team 2", "playedAt": "Played at", "score": "Score"}
+	if result == "team2" {
+		labels["winners"], labels["losers"] = labels["losers"], labels["winners"]
+	}
+
+	messages := make([]string, len(validationErr.Fields))
+	for i, field := range validationErr.Fields {
+		messages[i] = fmt.Sprintf("%s: %v.", labels[field.Field], field.Err)
+	}
+
+	return messages
+}
+
+// parseFormScore reads the goals of team 1 and team 2, nil if none were entered.
+func parseFormScore(team1Goals string, team2Goals string) (*games.Score, error) {
+	if team1Goals == "" && team2Goals == "" {
+		return nil, nil
+	}
+	if team1Goals == "" || team2Goals == "" {
+		return nil, fmt.Errorf("Enter the goals of both teams or none.")
+	}
+
+	team1, err1 := strconv.Atoi(team1Goals)
+	team2, err2 := strconv.Atoi(team2Goals)
+	if err1 != nil || err2 != nil {
+		return nil, fmt.Errorf("The goals have to be numbers.")
+	}
+
+	return &games.Score{Team1: team1, Team2: team2}, nil
+}
@@ -1,19 +1,27 @@
 package server
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"time"
 
 	"github.com/spie/fskick/internal/games"
+	"github.com/spie/fskick/internal/players"
 	"github.com/spie/fskick/internal/seasons"
+	"github.com/spie/fskick/internal/tables"
+	"github.com/spie/fskick/internal/tournaments"
 )
 
 type seasonResponse struct {
 	UUID      string    `json:"uuid"`
 	Name      string    `json:"name"`
 	Active    bool      `json:"active"`
+	TeamSizes string    `json:"teamSizes"`
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
 }
@@ -23,6 +31,7 @@ func newSeasonResponseFromSeason(season seasons.Season) seasonResponse {
 		UUID:      season.UUID,
 		Name:      season.Name,
 		Active:    season.Active,
+		TeamSizes: string(season.TeamSizes),
 		CreatedAt: season.CreatedAt,
 		UpdatedAt: season.UpdatedAt,
 	}
@@ -40,6 +49,45 @@ func newSeasonsWithGamesCountResponse(season seasons.Season, gamesCount int) sea
 	}
 }
 
+type playerResponse struct {
+	UUID              string    `json:"uuid"`
+	Name              string    `json:"name"`
+	DisplayName       string    `json:"displayName"`
+	PreferredPosition string    `json:"preferredPosition"`
+	FavoriteSide      string    `json:"favoriteSide"`
+	JoinedAt          time.Time `json:"joinedAt"`
+	AvatarUrl         string    `json:"avatarUrl"`
+	CreatedAt         time.Time `json:"createdAt"`
+	UpdatedAt         time.Time `json:"updatedAt"`
+}
+
+func newPlayerResponseFromPlayer(player players.Player) playerResponse {
+	return playerResponse{
+		UUID:              player.UUID,
+		Name:              player.Name,
+		DisplayName:       player.DisplayName,
+		PreferredPosition: string(player.PreferredPosition),
+		FavoriteSide:      string(player.FavoriteSide),
+		JoinedAt:          player.JoinedAt,
+		AvatarUrl:         getAvatarUrl(player),
+		CreatedAt:         player.CreatedAt,
+		UpdatedAt:         player.UpdatedAt,
+	}
+}
+
+func getAvatarUrl(player players.Player) string {
+	if player.Avatar == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("/avatars/%s", player.Avatar)
+}
+
+type streakResponse struct {
+	Number int            `json:"number"`
+	Player playerResponse `json:"player"`
+}
+
 type playerStatsResponses []playerStatsResponse
 
 func newPlayerStatsResponsesFromPlayerStats(playerStats []games.PlayerStats) playerStatsResponses {
@@ -52,32 +100,40 @@ func newPlayerStatsResponsesFromPlayerStats(playerStats []games.PlayerStats) pla
 }
 
 type playerStatsResponse struct {
-	UUID        string    `json:"uuid"`
-	Name        string    `json:"name"`
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAt"`
-	Wins        int       `json:"wins"`
-	Games       int       `json:"games"`
-	GamesRatio  float64   `json:"gamesRatio"`
-	PointsRatio float64   `json:"pointsRatio"`
-	Points      int       `json:"points"`
-	WinRatio    float64   `json:"winRatio"`
-	Position    int       `json:"position"`
+	playerResponse
+	Wins            int     `json:"wins"`
+	Draws           int     `json:"draws"`
+	Games           int     `json:"games"`
+	GamesRatio      float64 `json:"gamesRatio"`
+	PointsRatio     float64 `json:"pointsRatio"`
+	Points          int     `json:"points"`
+	WinRatio        float64 `json:"winRatio"`
+	Position        int     `json:"position"`
+	DefenseWins     int     `json:"defenseWins"`
+	DefenseGames    int     `json:"defenseGames"`
+	DefenseWinRatio float64 `json:"defenseWinRatio"`
+	OffenseWins     int     `json:"offenseWins"`
+	OffenseGames    int     `json:"offenseGames"`
+	OffenseWinRatio float64 `json:"offenseWinRatio"`
 }
 
 func newPlayerStatsResponseFromPlayerStats(playerStats games.PlayerStats) playerStatsResponse {
 	return playerStatsResponse{
-		UUID:        playerStats.UUID,
-		Name:        playerStats.Name,
-		CreatedAt:   playerStats.CreatedAt,
-		UpdatedAt:   playerStats.UpdatedAt,
-		Wins:        playerStats.Wins,
-		Games:       playerStats.Games,
-		GamesRatio:  playerStats.GamesRatio,
-		PointsRatio: playerStats.PointsRatio,
-		Points:      playerStats.Points,
-		WinRatio:    playerStats.WinRatio,
-		Position:    playerStats.Position,
+		playerResponse:  newPlayerResponseFromPlayer(playerStats.Player),
+		Wins:            playerStats.Wins,
+		Draws:           playerStats.Draws,
+		Games:           playerStats.Games,
+		GamesRatio:      playerStats.GamesRatio,
+		PointsRatio:     playerStats.PointsRatio,
+		Points:          playerStats.Points,
+		WinRatio:        playerStats.WinRatio,
+		Position:        playerStats.Position,
+		DefenseWins:     playerStats.DefenseWins,
+		DefenseGames:    playerStats.DefenseGames,
+		DefenseWinRatio: playerStats.DefenseWinRatio,
+		OffenseWins:     playerStats.OffenseWins,
+		OffenseGames:    playerStats.OffenseGames,
+		OffenseWinRatio: playerStats.OffenseWinRatio,
 	}
 }
 
@@ -93,19 +149,396 @@ func newTableResponse(season seasons.Season, gamesCount int, playerStats []games
 	}
 }
 
+type teamMemberResponse struct {
+	playerResponse
+	Position string `json:"position"`
+}
+
+type gameResponse struct {
+	UUID     string               `json:"uuid"`
+	PlayedAt time.Time            `json:"playedAt"`
+	Season   seasonResponse       `json:"season"`
+	Winners  []teamMemberResponse `json:"winners"`
+	Losers   []teamMemberResponse `json:"losers"`
+	Draw     bool                 `json:"draw"`
+	Score    *scoreResponse       `json:"score"`
+}
+
+type scoreResponse struct {
+	Winners int `json:"winners"`
+	Losers  int `json:"losers"`
+}
+
+func newGameResponse(game games.Game, winners players.Team, losers players.Team) gameResponse {
+	playerPositions := map[uint]players.Position{}
+	draw := false
+	for _, attendance := range game.Attendances {
+		playerPositions[attendance.PlayerID] = attendance.Position
+		draw = attendance.Outcome == games.OutcomeDraw
+	}
+
+	positions := games.Positions{}
+	for _, player := range append(append(players.Team{}, winners...), losers...) {
+		positions[player.UUID] = playerPositions[player.ID]
+	}
+
+	response := gameResponse{
+		UUID:     game.UUID,
+		PlayedAt: game.PlayedAt,
+		Winners:  newTeamMemberResponses(winners, positions),
+		Losers:   newTeamMemberResponses(losers, positions),
+		Draw:     draw,
+	}
+	if game.Score != nil {
+		response.Score = &scoreResponse{Winners: game.Score.Team1, Losers: game.Score.Team2}
+	}
+	if game.Season != nil {
+		response.Season = newSeasonResponseFromSeason(*game.Season)
+	}
+
+	return response
+}
+
+// newGameResponseFromGame maps a game with loaded attendances. Team 1 is returned as
+// winners unless team 2 won the game.
+func newGameResponseFromGame(game games.Game) gameResponse {
+	winners := newTeamMemberResponsesFromAttendances(game.Team(1))
+	losers := newTeamMemberResponsesFromAttendances(game.Team(2))
+	var score *scoreResponse
+	if game.Score != nil {
+		score = &scoreResponse{Winners: game.Score.Team1, Losers: game.Score.Team2}
+	}
+	if game.Outcome() == games.OutcomeLoss {
+		winners, losers = losers, winners
+		if score != nil {
+			score.Winners, score.Losers = score.Losers, score.Winners
+		}
+	}
+
+	response := gameResponse{
+		UUID:     game.UUID,
+		PlayedAt: game.PlayedAt,
+		Winners:  winners,
+		Losers:   losers,
+		Draw:     game.Outcome() == games.OutcomeDraw,
+		Score:    score,
+	}
+	if game.Season != nil {
+		response.Season = newSeasonResponseFromSeason(*game.Season)
+	}
+
+	return response
+}
+
+func newTeamMemberResponsesFromAttendances(attendances []games.Attendance) []teamMemberResponse {
+	members := make([]teamMemberResponse, len(attendances))
+	for i, attendance := range attendances {
+		members[i] = teamMemberResponse{
+			playerResponse: newPlayerResponseFromPlayer(attendance.Player),
+			Position:       string(attendance.Position),
+		}
+	}
+
+	return members
+}
+
+type matchupResponse struct {
+	Team1      []teamMemberResponse `json:"team1"`
+	Team2      []teamMemberResponse `json:"team2"`
+	Difference float64              `json:"difference"`
+}
+
+func newMatchupResponse(matchup games.Matchup) matchupResponse {
+	return matchupResponse{
+		Team1:      newTeamMemberResponses(matchup.Team1, matchup.Positions),
+		Team2:      newTeamMemberResponses(matchup.Team2, matchup.Positions),
+		Difference: matchup.Difference,
+	}
+}
+
+type impactResponse struct {
+	Player              playerResponse `json:"player"`
+	Team                int            `json:"team"`
+	Outcome             string         `json:"outcome"`
+	Position            string         `json:"position"`
+	PointsRatioBefore   float64        `json:"pointsRatioBefore"`
+	PointsRatioAfter    float64        `json:"pointsRatioAfter"`
+	PointsBefore        int            `json:"pointsBefore"`
+	PointsAfter         int            `json:"pointsAfter"`
+	TablePositionBefore int            `json:"tablePositionBefore"`
+	TablePositionAfter  int            `json:"tablePositionAfter"`
+}
+
+type gameDetailResponse struct {
+	gameResponse
+	RecordedBy *playerResponse  `json:"recordedBy"`
+	RecordedAt time.Time        `json:"recordedAt"`
+	Impacts    []impactResponse `json:"impacts"`
+}
+
+func newGameDetailResponse(game games.Game, impacts []games.PlayerImpact) gameDetailResponse {
+	response := gameDetailResponse{
+		gameResponse: newGameResponseFromGame(game),
+		RecordedAt:   game.CreatedAt,
+		Impacts:      make([]impactResponse, len(impacts)),
+	}
+	if game.RecordedBy != nil {
+		recordedBy := newPlayerResponseFromPlayer(*game.RecordedBy)
+		response.RecordedBy = &recordedBy
+	}
+
+	for i, impact := range impacts {
+		response.Impacts[i] = impactResponse{
+			Player:              newPlayerResponseFromPlayer(impact.Player),
+			Team:                impact.Team,
+			Outcome:             string(impact.Outcome),
+			Position:            string(impact.Position),
+			PointsRatioBefore:   impact.Before.PointsRatio,
+			PointsRatioAfter:    impact.After.PointsRatio,
+			PointsBefore:        impact.Before.Points,
+			PointsAfter:         impact.After.Points,
+			TablePositionBefore: impact.Before.Position,
+			TablePositionAfter:  impact.After.Position,
+		}
+	}
+
+	return response
+}
+
+type gamesPageResponse struct {
+	Games      []gameResponse `json:"games"`
+	NextCursor string         `json:"nextCursor"`
+}
+
+func newGamesPageResponse(gamesPage games.GamesPage) gamesPageResponse {
+	gamesRes := make([]gameResponse, len(gamesPage.Games))
+	for i, game := range gamesPage.Games {
+		gamesRes[i] = newGameResponseFromGame(game)
+	}
+
+	return gamesPageResponse{Games: gamesRes, NextCursor: gamesPage.NextCursor}
+}
+
+func newTeamMemberResponses(team players.Team, positions games.Positions) []teamMemberResponse {
+	members := make([]teamMemberResponse, len(team))
+	for i, player := range team {
+		members[i] = teamMemberResponse{
+			playerResponse: newPlayerResponseFromPlayer(player),
+			Position:       string(positions[player.UUID]),
+		}
+	}
+
+	return members
+}
+
+type tournamentResponse struct {
+	UUID      string    `json:"uuid"`
+	Name      string    `json:"name"`
+	Format    string    `json:"format"`
+	TeamSize  int       `json:"teamSize"`
+	Groups    int       `json:"groups"`
+	Advancing int       `json:"advancing"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func newTournamentResponse(tournament tournaments.Tournament) tournamentResponse {
+	return tournamentResponse{
+		UUID:      tournament.UUID,
+		Name:      tournament.Name,
+		Format:    string(tournament.Format),
+		TeamSize:  tournament.TeamSize,
+		Groups:    tournament.Groups,
+		Advancing: tournament.Advancing,
+		Status:    string(tournament.Status),
+		CreatedAt: tournament.CreatedAt,
+		UpdatedAt: tournament.UpdatedAt,
+	}
+}
+
+type tournamentTeamResponse struct {
+	UUID    string           `json:"uuid"`
+	Name    string           `json:"name"`
+	Seed    int              `json:"seed"`
+	Players []playerResponse `json:"players"`
+}
+
+func newTournamentTeamResponse(team tournaments.Team) tournamentTeamResponse {
+	teamPlayers := make([]playerResponse, len(team.Players))
+	for i, player := range team.Players {
+		teamPlayers[i] = newPlayerResponseFromPlayer(player)
+	}
+
+	return tournamentTeamResponse{UUID: team.UUID, Name: team.Name, Seed: team.Seed, Players: teamPlayers}
+}
+
+type matchScoreResponse struct {
+	Team1 int `json:"team1"`
+	Team2 int `json:"team2"`
+}
+
+// tournamentMatchResponse refers to the teams by uuid, undecided teams are empty and described
+// by their source, W3 or L3 for the winner or loser of match 3 and G1:2 for the second of group 1.
+type tournamentMatchResponse struct {
+	UUID        string              `json:"uuid"`
+	Number      int                 `json:"number"`
+	Stage       string              `json:"stage"`
+	Round       int                 `json:"round"`
+	Group       int                 `json:"group"`
+	Team1Source string              `json:"team1Source"`
+	Team2Source string              `json:"team2Source"`
+	Team1       string              `json:"team1"`
+	Team2       string              `json:"team2"`
+	Winner      string              `json:"winner"`
+	Draw        bool                `json:"draw"`
+	Score       *matchScoreResponse `json:"score"`
+	Game        string              `json:"game"`
+	Done        bool                `json:"done"`
+}
+
+type standingResponse struct {
+	Team         string `json:"team"`
+	Rank         int    `json:"rank"`
+	Played       int    `json:"played"`
+	Wins         int    `json:"wins"`
+	Draws        int    `json:"draws"`
+	Losses       int    `json:"losses"`
+	Points       int    `json:"points"`
+	GoalsFor     int    `json:"goalsFor"`
+	GoalsAgainst int    `json:"goalsAgainst"`
+}
+
+type groupResponse struct {
+	Number    int                `json:"number"`
+	Standings []standingResponse `json:"standings"`
+}
+
+type tournamentDetailResponse struct {
+	tournamentResponse
+	Teams    []tournamentTeamResponse  `json:"teams"`
+	Groups   []groupResponse           `json:"groupStandings"`
+	Matches  []tournamentMatchResponse `json:"matches"`
+	Champion string                    `json:"champion"`
+}
+
+func newTournamentDetailResponse(tournament tournaments.Tournament) tournamentDetailResponse {
+	teamUuids := map[uint]string{}
+	teams := make([]tournamentTeamResponse, len(tournament.Teams))
+	for i, team := range tournament.Teams {
+		teamUuids[team.ID] = team.UUID
+		teams[i] = newTournamentTeamResponse(team)
+	}
+
+	groups := []groupResponse{}
+	for _, group := range tournament.GroupNumbers() {
+		standings := []standingResponse{}
+		for _, standing := range tournament.Standings(group) {
+			standings = append(standings, standingResponse{
+				Team:         standing.Team.UUID,
+				Rank:         standing.Rank,
+				Played:       standing.Played,
+				Wins:         standing.Wins,
+				Draws:        standing.Draws,
+				Losses:       standing.Losses,
+				Points:       standing.Points,
+				GoalsFor:     standing.GoalsFor,
+				GoalsAgainst: standing.GoalsAgainst,
+			})
+		}
+		groups = append(groups, groupResponse{Number: group, Standings: standings})
+	}
+
+	matches := make([]tournamentMatchResponse, len(tournament.Matches))
+	for i, match := range tournament.Matches {
+		matches[i] = tournamentMatchResponse{
+			UUID:        match.UUID,
+			Number:      match.Number,
+			Stage:       string(match.Stage),
+			Round:       match.Round,
+			Group:       match.Group,
+			Team1Source: match.Source1.String(),
+			Team2Source: match.Source2.String(),
+			Team1:       teamUuids[match.Team1ID],
+			Team2:       teamUuids[match.Team2ID],
+			Winner:      teamUuids[match.WinnerID],
+			Draw:        match.Draw,
+			Game:        match.GameUUID,
+			Done:        match.Done,
+		}
+		if match.Score != nil {
+			matches[i].Score = &matchScoreResponse{Team1: match.Score.Team1, Team2: match.Score.Team2}
+		}
+	}
+
+	response := tournamentDetailResponse{
+		tournamentResponse: newTournamentResponse(tournament),
+		Teams:              teams,
+		Groups:             groups,
+		Matches:            matches,
+	}
+	if champion, ok := tournament.Champion(); ok {
+		response.Champion = champion.UUID
+	}
+
+	return response
+}
+
+type fieldErrorResponse struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type validationErrorResponse struct {
+	Message string               `json:"message"`
+	Errors  []fieldErrorResponse `json:"errors"`
+}
+
+func newValidationErrorResponse(validationErr *games.ValidationError) validationErrorResponse {
+	fieldErrors := make([]fieldErrorResponse, len(validationErr.Fields))
+	for i, field := range validationErr.Fields {
+		fieldErrors[i] = fieldErrorResponse{Field: field.Field, Message: field.Err.Error()}
+	}
+
+	return validationErrorResponse{Message: games.ErrInvalidGame.Error(), Errors: fieldErrors}
+}
+
 func writeJsonResponse(res http.ResponseWriter, response interface{}) error {
+	return writeJsonResponseWithStatus(res, http.StatusOK, response)
+}
+
+func writeJsonResponseWithStatus(res http.ResponseWriter, status int, response interface{}) error {
 	jsonRes, err := json.Marshal(response)
 	if err != nil {
 		return fmt.Errorf("write json response: %w", err)
 	}
 
 	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(status)
 	res.Write(jsonRes)
 
 	return nil
 }
 
-func handleInternalServerError(res http.ResponseWriter, err error) {
-	fmt.Println(err)
+func writeTableResponse(res http.ResponseWriter, table tables.Table, format string) error {
+	buffer := bytes.Buffer{}
+	err := tables.Render(&buffer, table, format)
+	if err != nil {
+		return fmt.Errorf("write table response: %w", err)
+	}
+
+	res.Header().Set("Content-Type", tables.ContentType(format))
+	res.Write(buffer.Bytes())
+
+	return nil
+}
+
+func handleInternalServerError(res http.ResponseWriter, req *http.Request, err error) {
+	slog.ErrorContext(req.Context(), "request failed", slog.String("error", err.Error()))
+	if errors.Is(err, context.DeadlineExceeded) {
+		http.Error(res, "The request took too long.", http.StatusServiceUnavailable)
+		return
+	}
+
 	http.Error(res, "Something went wrong.", http.StatusInternalServerError)
 }
@@ -1,7 +1,9 @@
 package server
 
 import (
+	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/spie/fskick/internal/seasons"
 )
@@ -14,10 +16,10 @@ func NewSeasonsController(seasonsManager seasons.Manager) SeasonsController {
 	return SeasonsController{seasonsManager: seasonsManager}
 }
 
-func (controller SeasonsController) GetSeasons(res http.ResponseWriter, _ *http.Request) {
-	seasons, err := controller.seasonsManager.GetSeasons()
+func (controller SeasonsController) GetSeasons(res http.ResponseWriter, req *http.Request) {
+	seasons, err := controller.seasonsManager.GetSeasons(req.Context())
 	if err != nil {
-		handleInternalServerError(res, err)
+		handleInternalServerError(res, req, err)
 		return
 	}
 
@@ -28,7 +30,106 @@ func (controller SeasonsController) GetSeasons(res http.ResponseWriter, _ *http.
 
 	err = writeJsonResponse(res, map[string][]seasonResponse{"seasons": seasonsResponse})
 	if err != nil {
-		handleInternalServerError(res, err)
+		handleInternalServerError(res, req, err)
+		return
+	}
+}
+
+func (controller SeasonsController) CreateSeason(res http.ResponseWriter, req *http.Request) {
+	var request createSeasonRequest
+	err := readJsonRequest(res, req, &request)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		http.Error(res, "Missing season name.", http.StatusUnprocessableEntity)
+		return
+	}
+
+	season, err := controller.seasonsManager.CreateSeason(req.Context(), name)
+	if errors.Is(err, seasons.ErrSeasonExists) {
+		http.Error(res, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+
+	err = writeJsonResponseWithStatus(
+		res,
+		http.StatusCreated,
+		map[string]seasonResponse{"season": newSeasonResponseFromSeason(season)},
+	)
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+}
+
+func (controller SeasonsController) ActivateSeason(res http.ResponseWriter, req *http.Request) {
+	season, err := controller.seasonsManager.GetSeasonByUuid(req.Context(), req.PathValue("season"))
+	if errors.Is(err, seasons.ErrSeasonNotFound) {
+		http.Error(res, "Season not found.", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+
+	season, err = controller.seasonsManager.ActivateSeason(req.Context(), season.Name)
+	if errors.Is(err, seasons.ErrActiveSeasonExists) {
+		http.Error(res, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+
+	err = writeJsonResponse(res, map[string]seasonResponse{"season": newSeasonResponseFromSeason(season)})
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+}
+
+// UpdateRules changes the rules for the games of the season, the allowed team sizes.
+func (controller SeasonsController) UpdateRules(res http.ResponseWriter, req *http.Request) {
+	var request updateSeasonRulesRequest
+	err := readJsonRequest(res, req, &request)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	season, err := controller.seasonsManager.GetSeasonByUuid(req.Context(), req.PathValue("season"))
+	if errors.Is(err, seasons.ErrSeasonNotFound) {
+		http.Error(res, "Season not found.", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+
+	season, err = controller.seasonsManager.SetTeamSizes(req.Context(), season.Name, seasons.TeamSizes(request.TeamSizes))
+	if errors.Is(err, seasons.ErrUnknownTeamSizes) {
+		http.Error(res, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+
+	err = writeJsonResponse(res, map[string]seasonResponse{"season": newSeasonResponseFromSeason(season)})
+	if err != nil {
+		handleInternalServerError(res, req, err)
 		return
 	}
 }
@@ -1,31 +1,218 @@
 package server
 
 import (
+	"context"
 	"embed"
 	"fmt"
+	"log/slog"
+	"mime"
 	"net/http"
+	"time"
+
+	"github.com/spie/fskick/internal/logging"
+	"github.com/spie/fskick/internal/metrics"
+	"github.com/spie/fskick/internal/uuid"
 )
 
+const requestIDHeader = "X-Request-ID"
+
+// Timeouts limit the reading and writing of requests, keeping idle connections and waiting
+// for running requests on shutdown.
+type Timeouts struct {
+	Read     time.Duration
+	Write    time.Duration
+	Idle     time.Duration
+	Shutdown time.Duration
+}
+
 type Server struct {
-	mux  *http.ServeMux
-	addr string
+	mux          *http.ServeMux
+	probes       *http.ServeMux
+	addr         string
+	timeouts     Timeouts
+	queryTimeout time.Duration
+	metrics      *metrics.Metrics
 }
 
 func New(addr string) *Server {
 	return &Server{
-		mux:  http.NewServeMux(),
-		addr: addr,
+		mux:    http.NewServeMux(),
+		probes: http.NewServeMux(),
+		addr:   addr,
 	}
 }
 
+func (server *Server) SetTimeouts(timeouts Timeouts) {
+	server.timeouts = timeouts
+}
+
+// SetQueryTimeout limits how long the queries of a request may run. The context of the
+// request is cancelled after the timeout, a timeout of 0 disables it.
+func (server *Server) SetQueryTimeout(timeout time.Duration) {
+	server.queryTimeout = timeout
+}
+
+// SetMetrics counts the requests and measures their duration per registered route. It has to
+// be set before the routes are registered.
+func (server *Server) SetMetrics(m *metrics.Metrics) {
+	server.metrics = m
+}
+
 func (server *Server) Get(route string, handler func(http.ResponseWriter, *http.Request)) {
-	server.mux.HandleFunc(fmt.Sprintf("GET %s", route), handler)
+	server.handle(http.MethodGet, route, handler)
+}
+
+func (server *Server) Post(route string, handler func(http.ResponseWriter, *http.Request)) {
+	server.handle(http.MethodPost, route, handler)
+}
+
+func (server *Server) Put(route string, handler func(http.ResponseWriter, *http.Request)) {
+	server.handle(http.MethodPut, route, handler)
 }
 
+func (server *Server) handle(method string, route string, handler func(http.ResponseWriter, *http.Request)) {
+	handler = server.withQueryTimeout(handler)
+	if server.metrics != nil {
+		handler = server.metrics.InstrumentRoute(route, handler)
+	}
+
+	server.mux.HandleFunc(fmt.Sprintf("%s %s", method, route), handler)
+}
+
+func (server *Server) withQueryTimeout(handler func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
+	return func(res http.ResponseWriter, req *http.Request) {
+		if server.queryTimeout <= 0 {
+			handler(res, req)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(req.Context(), server.queryTimeout)
+		defer cancel()
+
+		handler(res, req.WithContext(ctx))
+	}
+}
+
+// Probe registers a health check route. Probes are answered outside of the metrics, the query
+// timeout and the request log, so frequent checks by an orchestrator do not flood them.
+func (server *Server) Probe(route string, handler func(http.ResponseWriter, *http.Request)) {
+	server.probes.HandleFunc(fmt.Sprintf("%s %s", http.MethodGet, route), handler)
+}
+
+// HandleStatic serves the static files below /static/. The service worker in js/sw.js may
+// control the whole site, not only /static/.
 func (server *Server) HandleStatic(static embed.FS) {
-	server.mux.Handle("GET /static/", http.StripPrefix("/static/", http.FileServerFS(static)))
+	mime.AddExtensionType(".webmanifest", "application/manifest+json")
+
+	files := http.StripPrefix("/static/", http.FileServerFS(static))
+	server.mux.Handle("GET /static/", http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/static/js/sw.js" {
+			res.Header().Set("Service-Worker-Allowed", "/")
+			res.Header().Set("Cache-Control", "no-cache")
+		}
+
+		files.ServeHTTP(res, req)
+	}))
+}
+
+// Run serves the requests until ctx is done. Then the server stops accepting connections and
+// waits for the running requests up to the shutdown timeout.
+func (server *Server) Run(ctx context.Context) error {
+	httpServer := &http.Server{
+		Addr:              server.addr,
+		Handler:           server.handler(),
+		ReadHeaderTimeout: server.timeouts.Read,
+		ReadTimeout:       server.timeouts.Read,
+		WriteTimeout:      server.timeouts.Write,
+		IdleTimeout:       server.timeouts.Idle,
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return fmt.Errorf("run server: %w", err)
+	case <-ctx.Done():
+	}
+
+	slog.Info("shutting down the server")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), server.timeouts.Shutdown)
+	defer cancel()
+
+	err := httpServer.Shutdown(shutdownCtx)
+	if err != nil {
+		return fmt.Errorf("shut down server: %w", err)
+	}
+
+	return nil
+}
+
+// handler answers the probes directly and passes every other request to the logged routes.
+func (server *Server) handler() http.Handler {
+	routes := logRequests(server.mux)
+
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if _, pattern := server.probes.Handler(req); pattern != "" {
+			server.probes.ServeHTTP(res, req)
+			return
+		}
+
+		routes.ServeHTTP(res, req)
+	})
+}
+
+// logRequests logs every request after it is handled. The request gets the id of the
+// X-Request-ID header or a new one, it is added to the logs of the request and sent back.
+func logRequests(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+
+		requestID := req.Header.Get(requestIDHeader)
+		if requestID == "" || len(requestID) > 64 {
+			requestID, _ = uuid.GenerateUuidString()
+		}
+		res.Header().Set(requestIDHeader, requestID)
+
+		ctx := logging.WithRequestID(req.Context(), requestID)
+		recorder := &statusRecorder{ResponseWriter: res, status: http.StatusOK}
+
+		handler.ServeHTTP(recorder, req.WithContext(ctx))
+
+		slog.InfoContext(
+			ctx,
+			"request",
+			slog.String("method", req.Method),
+			slog.String("path", req.URL.Path),
+			slog.Int("status", recorder.status),
+			slog.Int("bytes", recorder.bytes),
+			slog.Duration("duration", time.Since(start)),
+			slog.String("remote", req.RemoteAddr),
+		)
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (recorder *statusRecorder) WriteHeader(status int) {
+	recorder.status = status
+	recorder.ResponseWriter.WriteHeader(status)
+}
+
+func (recorder *statusRecorder) Write(data []byte) (int, error) {
+	n, err := recorder.ResponseWriter.Write(data)
+	recorder.bytes += n
+
+	return n, err
 }
 
-func (server *Server) Run() error {
-	return http.ListenAndServe(server.addr, server.mux)
+func (recorder *statusRecorder) Unwrap() http.ResponseWriter {
+	return recorder.ResponseWriter
 }
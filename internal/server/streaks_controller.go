@@ -3,6 +3,7 @@ package server
 import (
 	"net/http"
 
+	"github.com/spie/fskick/internal/games"
 	"github.com/spie/fskick/internal/streaks"
 	"github.com/spie/fskick/internal/views"
 )
@@ -30,15 +31,15 @@ func NewStreaksController(streaksManager streaks.Manager, views StreaksViews) St
 
 func (controller StreaksController) StreaksPage(res http.ResponseWriter, req *http.Request) {
 	longestWiningStreak, longestLosingStreak, err := controller.streaksManager.
-		GetLongestWinningAndLosingStreaks()
+		GetLongestWinningAndLosingStreaks(req.Context())
 	if err != nil {
-		handleInternalServerError(res, err)
+		handleInternalServerError(res, req, err)
 		return
 	}
 
-	currentStreaks, err := controller.streaksManager.GetCurrentStreaks(true)
+	currentStreaks, err := controller.streaksManager.GetCurrentStreaks(req.Context(), games.OutcomeWin)
 	if err != nil {
-		handleInternalServerError(res, err)
+		handleInternalServerError(res, req, err)
 		return
 	}
 
@@ -49,20 +50,52 @@ func (controller StreaksController) StreaksPage(res http.ResponseWriter, req *ht
 		req.Context(),
 		res,
 	); err != nil {
-		handleInternalServerError(res, err)
+		handleInternalServerError(res, req, err)
 		return
 	}
 }
 
 func (controller StreaksController) CurrentStreaks(res http.ResponseWriter, req *http.Request) {
-	currentStreaks, err := controller.streaksManager.GetCurrentStreaks(req.URL.Query().Get("win") == "on")
+	outcome := games.OutcomeLoss
+	if req.URL.Query().Get("win") == "on" {
+		outcome = games.OutcomeWin
+	}
+
+	currentStreaks, err := controller.streaksManager.GetCurrentStreaks(req.Context(), outcome)
 	if err != nil {
-		handleInternalServerError(res, err)
+		handleInternalServerError(res, req, err)
 		return
 	}
 
 	if err = controller.views.CurrentStreaks.Render(currentStreaks, req.Context(), res); err != nil {
-		handleInternalServerError(res, err)
+		handleInternalServerError(res, req, err)
+		return
+	}
+}
+
+// GetCurrentStreaks returns the current streaks of all players for the outcome query param,
+// win or loss.
+func (controller StreaksController) GetCurrentStreaks(res http.ResponseWriter, req *http.Request) {
+	outcome := games.Outcome(req.URL.Query().Get("outcome"))
+	if outcome != games.OutcomeWin && outcome != games.OutcomeLoss {
+		http.Error(res, "Outcome has to be win or loss.", http.StatusBadRequest)
+		return
+	}
+
+	currentStreaks, err := controller.streaksManager.GetCurrentStreaks(req.Context(), outcome)
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+
+	streaksResponse := make([]streakResponse, len(currentStreaks))
+	for i, streak := range currentStreaks {
+		streaksResponse[i] = streakResponse{Number: streak.Number, Player: newPlayerResponseFromPlayer(streak.Player)}
+	}
+
+	err = writeJsonResponse(res, map[string][]streakResponse{"streaks": streaksResponse})
+	if err != nil {
+		handleInternalServerError(res, req, err)
 		return
 	}
 }
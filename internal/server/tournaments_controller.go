@@ -0,0 +1,314 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/spie/fskick/internal/db"
+	"github.com/spie/fskick/internal/games"
+	"github.com/spie/fskick/internal/tournaments"
+	"github.com/spie/fskick/internal/views"
+)
+
+type TournamentsViews struct {
+	TournamentsPage   views.TournamentsPage
+	TournamentPage    views.TournamentPage
+	TournamentBracket views.TournamentBracket
+}
+
+func NewTournamentsViews() TournamentsViews {
+	return TournamentsViews{}
+}
+
+type TournamentsController struct {
+	tournamentsManager tournaments.Manager
+	views              TournamentsViews
+}
+
+func NewTournamentsController(tournamentsManager tournaments.Manager, views TournamentsViews) TournamentsController {
+	return TournamentsController{
+		tournamentsManager: tournamentsManager,
+		views:              views,
+	}
+}
+
+func (controller TournamentsController) TournamentsPage(res http.ResponseWriter, req *http.Request) {
+	tournamentsList, err := controller.tournamentsManager.GetTournaments(req.Context())
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+
+	err = controller.views.TournamentsPage.Render(tournamentsList, req.Context(), res)
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+}
+
+func (controller TournamentsController) TournamentPage(res http.ResponseWriter, req *http.Request) {
+	tournament, ok := controller.getTournament(res, req)
+	if !ok {
+		return
+	}
+
+	err := controller.views.TournamentPage.Render(tournament, req.Context(), res)
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+}
+
+// TournamentBracket renders the bracket polled by the tournament page.
+func (controller TournamentsController) TournamentBracket(res http.ResponseWriter, req *http.Request) {
+	tournament, ok := controller.getTournament(res, req)
+	if !ok {
+		return
+	}
+
+	err := controller.views.TournamentBracket.Render(tournament, req.Context(), res)
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+}
+
+func (controller TournamentsController) GetTournaments(res http.ResponseWriter, req *http.Request) {
+	tournamentsList, err := controller.tournamentsManager.GetTournaments(req.Context())
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+
+	tournamentsResponse := make([]tournamentResponse, len(tournamentsList))
+	for i, tournament := range tournamentsList {
+		tournamentsResponse[i] = newTournamentResponse(tournament)
+	}
+
+	err = writeJsonResponse(res, map[string][]tournamentResponse{"tournaments": tournamentsResponse})
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+}
+
+func (controller TournamentsController) GetTournament(res http.ResponseWriter, req *http.Request) {
+	tournament, ok := controller.getTournament(res, req)
+	if !ok {
+		return
+	}
+
+	controller.writeTournament(res, req, http.StatusOK, tournament)
+}
+
+func (controller TournamentsController) CreateTournament(res http.ResponseWriter, req *http.Request) {
+	var request createTournamentRequest
+	err := readJsonRequest(res, req, &request)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		http.Error(res, "Missing tournament name.", http.StatusUnprocessableEntity)
+		return
+	}
+
+	tournament, err := controller.tournamentsManager.CreateTournament(
+		req.Context(),
+		name,
+		tournaments.Format(request.Format),
+		request.TeamSize,
+	)
+	if errors.Is(err, tournaments.ErrUnknownFormat) || errors.Is(err, tournaments.ErrInvalidTeamSize) {
+		http.Error(res, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	if errors.Is(err, tournaments.ErrTournamentExists) {
+		http.Error(res, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+
+	controller.writeTournament(res, req, http.StatusCreated, tournament)
+}
+
+// RegisterTeam registers the players of the request as a team of the tournament.
+func (controller TournamentsController) RegisterTeam(res http.ResponseWriter, req *http.Request) {
+	var request registerTeamRequest
+	err := readJsonRequest(res, req, &request)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tournament, ok := controller.getTournament(res, req)
+	if !ok {
+		return
+	}
+
+	team, err := controller.tournamentsManager.RegisterTeam(req.Context(), tournament.Name, request.Name, request.Players)
+	if errors.Is(err, tournaments.ErrRegistrationClosed) || errors.Is(err, tournaments.ErrTeamExists) {
+		http.Error(res, err.Error(), http.StatusConflict)
+		return
+	}
+	// the tournament was found, so not found are the players
+	if errors.Is(err, tournaments.ErrWrongTeamSize) ||
+		errors.Is(err, tournaments.ErrPlayerRegistered) ||
+		errors.Is(err, db.ErrNotFound) {
+		http.Error(res, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+
+	err = writeJsonResponseWithStatus(
+		res,
+		http.StatusCreated,
+		map[string]tournamentTeamResponse{"team": newTournamentTeamResponse(team)},
+	)
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+}
+
+// ScheduleTournament closes the registration and generates the matches.
+func (controller TournamentsController) ScheduleTournament(res http.ResponseWriter, req *http.Request) {
+	var request scheduleTournamentRequest
+	err := readJsonRequest(res, req, &request)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tournament, ok := controller.getTournament(res, req)
+	if !ok {
+		return
+	}
+
+	tournament, err = controller.tournamentsManager.Schedule(req.Context(), tournament.Name, tournaments.ScheduleOptions{
+		Groups:    request.Groups,
+		Advancing: request.Advancing,
+	})
+	if errors.Is(err, tournaments.ErrRegistrationClosed) {
+		http.Error(res, err.Error(), http.StatusConflict)
+		return
+	}
+	if errors.Is(err, tournaments.ErrNotEnoughTeams) || errors.Is(err, tournaments.ErrInvalidGroups) {
+		http.Error(res, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+
+	controller.writeTournament(res, req, http.StatusOK, tournament)
+}
+
+// RecordResult records the result of the match with the number of the path as a game of the
+// active season and returns the advanced tournament.
+func (controller TournamentsController) RecordResult(res http.ResponseWriter, req *http.Request) {
+	var request recordResultRequest
+	err := readJsonRequest(res, req, &request)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	number, err := strconv.Atoi(req.PathValue("match"))
+	if err != nil {
+		http.Error(res, "Match has to be the number of the match.", http.StatusBadRequest)
+		return
+	}
+
+	tournament, ok := controller.getTournament(res, req)
+	if !ok {
+		return
+	}
+
+	user, _ := getAuthenticatedUser(req)
+
+	result := tournaments.Result{
+		Winner:     request.Winner,
+		Draw:       request.Draw,
+		PlayedAt:   request.PlayedAt,
+		RecordedBy: &user.Player,
+	}
+	if request.Score != nil {
+		result.Score = &games.Score{Team1: request.Score.Team1, Team2: request.Score.Team2}
+	}
+
+	tournament, err = controller.tournamentsManager.RecordResult(req.Context(), tournament.Name, number, result)
+	if errors.Is(err, tournaments.ErrMatchNotFound) {
+		http.Error(res, err.Error(), http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, tournaments.ErrTournamentNotActive) ||
+		errors.Is(err, tournaments.ErrMatchPlayed) ||
+		errors.Is(err, tournaments.ErrMatchNotReady) ||
+		errors.Is(err, tournaments.ErrNoActiveSeason) {
+		http.Error(res, err.Error(), http.StatusConflict)
+		return
+	}
+	if errors.Is(err, tournaments.ErrInvalidResult) || errors.Is(err, tournaments.ErrDrawInKnockout) {
+		http.Error(res, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	var validationErr *games.ValidationError
+	if errors.As(err, &validationErr) {
+		err = writeJsonResponseWithStatus(res, http.StatusUnprocessableEntity, newValidationErrorResponse(validationErr))
+		if err != nil {
+			handleInternalServerError(res, req, err)
+		}
+		return
+	}
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+
+	controller.writeTournament(res, req, http.StatusOK, tournament)
+}
+
+// getTournament loads the tournament with the uuid of the path, the response is written if it
+// fails.
+func (controller TournamentsController) getTournament(res http.ResponseWriter, req *http.Request) (tournaments.Tournament, bool) {
+	tournament, err := controller.tournamentsManager.GetTournamentByUUID(req.Context(), req.PathValue("tournament"))
+	if errors.Is(err, tournaments.ErrTournamentNotFound) {
+		http.Error(res, "Tournament not found.", http.StatusNotFound)
+		return tournaments.Tournament{}, false
+	}
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return tournaments.Tournament{}, false
+	}
+
+	return tournament, true
+}
+
+func (controller TournamentsController) writeTournament(
+	res http.ResponseWriter,
+	req *http.Request,
+	status int,
+	tournament tournaments.Tournament,
+) {
+	err := writeJsonResponseWithStatus(
+		res,
+		status,
+		map[string]tournamentDetailResponse{"tournament": newTournamentDetailResponse(tournament)},
+	)
+	if err != nil {
+		handleInternalServerError(res, req, err)
+		return
+	}
+}
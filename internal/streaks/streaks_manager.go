@@ -1,27 +1,47 @@
 package streaks
 
 import (
+	"context"
 	"fmt"
 	"slices"
 
+	"github.com/spie/fskick/internal/cache"
 	"github.com/spie/fskick/internal/games"
 	"github.com/spie/fskick/internal/players"
 )
 
 type Manager struct {
     attendanceRepository games.AttendanceRepository
+    cache                *cache.Cache
 }
 
 func NewManager(attendanceRepository games.AttendanceRepository) Manager {
     return Manager{attendanceRepository: attendanceRepository}
 }
 
-func (manager Manager) GetLongestWinningAndLosingStreaks() (
+// WithCache returns a manager caching the streaks in c.
+func (manager Manager) WithCache(c *cache.Cache) Manager {
+    manager.cache = c
+
+    return manager
+}
+
+func (manager Manager) GetLongestWinningAndLosingStreaks(ctx context.Context) (
     winningStreak Streak,
     logingStreak Streak,
     err error,
 ) {
-    allPlayersWithAttendances, err := manager.attendanceRepository.GetAttendancesForAllPlayers()
+    streaks, err := cache.Remember(manager.cache, "streaks:longest", func() ([2]Streak, error) {
+        winningStreak, losingStreak, err := manager.loadLongestWinningAndLosingStreaks(ctx)
+
+        return [2]Streak{winningStreak, losingStreak}, err
+    })
+
+    return streaks[0], streaks[1], err
+}
+
+func (manager Manager) loadLongestWinningAndLosingStreaks(ctx context.Context) (Streak, Streak, error) {
+    allPlayersWithAttendances, err := manager.attendanceRepository.GetAttendancesForAllPlayers(ctx)
     if err != nil {
         return Streak{}, Streak{}, fmt.Errorf("get longest streak: %w", err)
     }
@@ -32,7 +52,7 @@ func (manager Manager) GetLongestWinningAndLosingStreaks() (
         winningStreak := GetLongestStreakForPlayer(
             playerWithAttendance.Player,
             playerWithAttendance.Attendances,
-            true,
+            games.OutcomeWin,
         )
 
         if winningStreak.Number > longestWinningStreak.Number {
@@ -42,7 +62,7 @@ func (manager Manager) GetLongestWinningAndLosingStreaks() (
         losingStreak := GetLongestStreakForPlayer(
             playerWithAttendance.Player,
             playerWithAttendance.Attendances,
-            false,
+            games.OutcomeLoss,
         )
 
         if losingStreak.Number > longestLosingStreak.Number {
@@ -53,8 +73,18 @@ func (manager Manager) GetLongestWinningAndLosingStreaks() (
     return longestWinningStreak, longestLosingStreak, nil
 }
 
-func (manager Manager) GetCurrentStreaks(win bool) ([]Streak, error) {
-    allPlayersWithAttendances, err := manager.attendanceRepository.GetAttendancesForAllPlayers()
+// GetCurrentStreaks counts the latest games of every player with the given outcome. A draw
+// ends winning and losing streaks.
+func (manager Manager) GetCurrentStreaks(ctx context.Context, outcome games.Outcome) ([]Streak, error) {
+    currentStreaks, err := cache.Remember(manager.cache, fmt.Sprintf("streaks:current:%s", outcome), func() ([]Streak, error) {
+        return manager.loadCurrentStreaks(ctx, outcome)
+    })
+
+    return slices.Clone(currentStreaks), err
+}
+
+func (manager Manager) loadCurrentStreaks(ctx context.Context, outcome games.Outcome) ([]Streak, error) {
+    allPlayersWithAttendances, err := manager.attendanceRepository.GetAttendancesForAllPlayers(ctx)
     if err != nil {
         return nil, fmt.Errorf("get current streaks: %w", err)
     }
@@ -64,7 +94,7 @@ func (manager Manager) GetCurrentStreaks(win bool) ([]Streak, error) {
         streak := Streak{Player: playerWithAttendance.Player, Number: 0}
         slices.Reverse(playerWithAttendance.Attendances)
         for _, attendance := range playerWithAttendance.Attendances {
-            if attendance.Win != win {
+            if attendance.Outcome != outcome {
                 break
             }
 
@@ -92,12 +122,16 @@ func (manager Manager) GetCurrentStreaks(win bool) ([]Streak, error) {
     return currentStreaks, nil
 }
 
-func GetLongestStreakForPlayer(player players.Player, attendances []games.Attendance, win bool) Streak {
+func GetLongestStreakForPlayer(
+    player players.Player,
+    attendances []games.Attendance,
+    outcome games.Outcome,
+) Streak {
     streak := Streak{Player: player, Number: 0}
 
     number := 0
     for _, attendance := range attendances {
-        if attendance.Win !=  win {
+        if attendance.Outcome != outcome {
             if number > streak.Number {
                 streak.Number = number
             }
@@ -0,0 +1,72 @@
+package streaks
+
+import (
+	"testing"
+
+	"github.com/spie/fskick/internal/games"
+	"github.com/spie/fskick/internal/players"
+	"github.com/stretchr/testify/assert"
+)
+
+func createAttendances(outcomes ...games.Outcome) []games.Attendance {
+	attendances := make([]games.Attendance, len(outcomes))
+	for i, outcome := range outcomes {
+		attendances[i] = games.Attendance{Outcome: outcome}
+	}
+
+	return attendances
+}
+
+func TestGetLongestStreakForPlayer(t *testing.T) {
+	tests := map[string]struct {
+		attendances []games.Attendance
+		outcome     games.Outcome
+		expected    int
+	}{
+		"with longest winning streak": {
+			attendances: createAttendances(
+				games.OutcomeWin,
+				games.OutcomeLoss,
+				games.OutcomeWin,
+				games.OutcomeWin,
+			),
+			outcome:  games.OutcomeWin,
+			expected: 2,
+		},
+		"with draw ending a winning streak": {
+			attendances: createAttendances(
+				games.OutcomeWin,
+				games.OutcomeWin,
+				games.OutcomeDraw,
+				games.OutcomeWin,
+			),
+			outcome:  games.OutcomeWin,
+			expected: 2,
+		},
+		"with draw ending a losing streak": {
+			attendances: createAttendances(
+				games.OutcomeLoss,
+				games.OutcomeDraw,
+				games.OutcomeLoss,
+			),
+			outcome:  games.OutcomeLoss,
+			expected: 1,
+		},
+		"without attendances": {
+			attendances: []games.Attendance{},
+			outcome:     games.OutcomeWin,
+			expected:    0,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			player := players.Player{Name: "test_player"}
+
+			streak := GetLongestStreakForPlayer(player, tt.attendances, tt.outcome)
+
+			assert.Equal(t, tt.expected, streak.Number)
+			assert.Equal(t, player, streak.Player)
+		})
+	}
+}
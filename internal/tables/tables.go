@@ -0,0 +1,167 @@
+package tables
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+
+	"github.com/spie/fskick/internal/games"
+)
+
+const (
+	FormatTable    = "table"
+	FormatJSON     = "json"
+	FormatCSV      = "csv"
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+)
+
+var ErrUnknownFormat = errors.New("format has to be table, json, csv, markdown or html")
+
+// contentTypes maps the formats to their media types for HTTP content negotiation.
+var contentTypes = map[string]string{
+	FormatJSON:     "application/json",
+	FormatCSV:      "text/csv",
+	FormatMarkdown: "text/markdown",
+	FormatHTML:     "text/html",
+	FormatTable:    "text/plain",
+}
+
+type Column struct {
+	Key   string
+	Title string
+}
+
+// Table holds rows of values in the order of the columns. The column keys are used as
+// object keys of the JSON format, the titles as head of all other formats.
+type Table struct {
+	Columns []Column
+	Rows    [][]any
+}
+
+func ParseFormat(format string) (string, error) {
+	switch strings.ToLower(format) {
+	case "", FormatTable:
+		return FormatTable, nil
+	case FormatJSON, FormatCSV, FormatHTML:
+		return strings.ToLower(format), nil
+	case FormatMarkdown, "md":
+		return FormatMarkdown, nil
+	}
+
+	return "", ErrUnknownFormat
+}
+
+// FormatFromAccept returns the first format matching a media type of the Accept header.
+func FormatFromAccept(accept string) (string, bool) {
+	for _, mediaType := range strings.Split(accept, ",") {
+		mediaType, _, _ = strings.Cut(strings.TrimSpace(mediaType), ";")
+		for format, contentType := range contentTypes {
+			if mediaType == contentType {
+				return format, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+func ContentType(format string) string {
+	return contentTypes[format] + "; charset=utf-8"
+}
+
+func Render(w io.Writer, t Table, format string) error {
+	if format == FormatJSON {
+		return renderJSON(w, t)
+	}
+
+	writer := table.NewWriter()
+	writer.SetOutputMirror(w)
+	head := table.Row{}
+	for _, column := range t.Columns {
+		head = append(head, column.Title)
+	}
+	writer.AppendHeader(head)
+	for _, values := range t.Rows {
+		row := table.Row{}
+		for _, value := range values {
+			row = append(row, formatValue(value))
+		}
+		writer.AppendRow(row)
+	}
+
+	switch format {
+	case FormatTable:
+		writer.Render()
+	case FormatCSV:
+		writer.RenderCSV()
+	case FormatMarkdown:
+		writer.RenderMarkdown()
+	case FormatHTML:
+		writer.RenderHTML()
+	default:
+		return ErrUnknownFormat
+	}
+
+	return nil
+}
+
+func renderJSON(w io.Writer, t Table) error {
+	rows := make([]map[string]any, len(t.Rows))
+	for i, values := range t.Rows {
+		rows[i] = map[string]any{}
+		for j, column := range t.Columns {
+			rows[i][column.Key] = values[j]
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(rows)
+}
+
+func formatValue(value any) string {
+	if float, ok := value.(float64); ok {
+		return fmt.Sprintf("%0.2f", float)
+	}
+
+	return fmt.Sprint(value)
+}
+
+func NewPlayerStatsTable(gamesCount int, playerStats []games.PlayerStats) Table {
+	t := Table{
+		Columns: []Column{
+			{Key: "position", Title: fmt.Sprintf("Position (%d)", len(playerStats))},
+			{Key: "name", Title: "Name"},
+			{Key: "pointsRatio", Title: "Points Ratio"},
+			{Key: "points", Title: "Points"},
+			{Key: "wins", Title: "Wins"},
+			{Key: "draws", Title: "Draws"},
+			{Key: "games", Title: fmt.Sprintf("Games (%d)", gamesCount)},
+			{Key: "winRatio", Title: "Win Ratio"},
+			{Key: "gamesRatio", Title: "Games Ratio"},
+		},
+		Rows: make([][]any, len(playerStats)),
+	}
+
+	for i, stats := range playerStats {
+		t.Rows[i] = []any{
+			stats.Position,
+			stats.Name,
+			stats.PointsRatio,
+			stats.Points,
+			stats.Wins,
+			stats.Draws,
+			stats.Games,
+			stats.WinRatio,
+			stats.GamesRatio,
+		}
+	}
+
+	return t
+}
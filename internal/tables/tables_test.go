@@ -0,0 +1,122 @@
+package tables
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	table := Table{
+		Columns: []Column{{Key: "name", Title: "Name"}, {Key: "ratio", Title: "Ratio"}},
+		Rows:    [][]any{{"alice", 0.5}, {"bob", 1.0}},
+	}
+
+	tests := map[string]struct {
+		format     string
+		assertions []func(t *testing.T, output string, err error)
+	}{
+		"as json": {
+			format: FormatJSON,
+			assertions: []func(t *testing.T, output string, err error){
+				func(t *testing.T, output string, err error) {
+					assert.NoError(t, err)
+					assert.JSONEq(t, `[{"name": "alice", "ratio": 0.5}, {"name": "bob", "ratio": 1}]`, output)
+				},
+			},
+		},
+		"as csv": {
+			format: FormatCSV,
+			assertions: []func(t *testing.T, output string, err error){
+				func(t *testing.T, output string, err error) {
+					assert.NoError(t, err)
+					assert.Equal(t, "Name,Ratio\nalice,0.50\nbob,1.00\n", output)
+				},
+			},
+		},
+		"as markdown": {
+			format: FormatMarkdown,
+			assertions: []func(t *testing.T, output string, err error){
+				func(t *testing.T, output string, err error) {
+					assert.NoError(t, err)
+					assert.Contains(t, output, "| Name | Ratio |")
+					assert.Contains(t, output, "| alice | 0.50 |")
+				},
+			},
+		},
+		"as html": {
+			format: FormatHTML,
+			assertions: []func(t *testing.T, output string, err error){
+				func(t *testing.T, output string, err error) {
+					assert.NoError(t, err)
+					assert.Contains(t, output, "<table")
+					assert.Contains(t, output, "<td>alice</td>")
+				},
+			},
+		},
+		"with unknown format": {
+			format: "xlsx",
+			assertions: []func(t *testing.T, output string, err error){
+				func(t *testing.T, output string, err error) {
+					assert.ErrorIs(t, err, ErrUnknownFormat)
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buffer bytes.Buffer
+			err := Render(&buffer, table, test.format)
+
+			for _, assertion := range test.assertions {
+				assertion(t, buffer.String(), err)
+			}
+		})
+	}
+}
+
+func TestFormatFromAccept(t *testing.T) {
+	tests := map[string]struct {
+		accept     string
+		assertions []func(t *testing.T, format string, ok bool)
+	}{
+		"with csv": {
+			accept: "text/csv",
+			assertions: []func(t *testing.T, format string, ok bool){
+				func(t *testing.T, format string, ok bool) {
+					assert.True(t, ok)
+					assert.Equal(t, FormatCSV, format)
+				},
+			},
+		},
+		"with parameters and several media types": {
+			accept: "application/xml, text/markdown; charset=utf-8, text/csv",
+			assertions: []func(t *testing.T, format string, ok bool){
+				func(t *testing.T, format string, ok bool) {
+					assert.True(t, ok)
+					assert.Equal(t, FormatMarkdown, format)
+				},
+			},
+		},
+		"with wildcard": {
+			accept: "*/*",
+			assertions: []func(t *testing.T, format string, ok bool){
+				func(t *testing.T, format string, ok bool) {
+					assert.False(t, ok)
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			format, ok := FormatFromAccept(test.accept)
+
+			for _, assertion := range test.assertions {
+				assertion(t, format, ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,289 @@
+package tournaments
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/spie/fskick/internal/games"
+	"github.com/spie/fskick/internal/players"
+)
+
+const (
+	winPoints  = 3
+	drawPoints = 1
+)
+
+var (
+	ErrMatchNotFound  = errors.New("match not found")
+	ErrMatchNotReady  = errors.New("teams of the match are not decided yet")
+	ErrMatchPlayed    = errors.New("match was already played")
+	ErrInvalidResult  = errors.New("result needs the winner, team 1 or team 2, or a draw")
+	ErrDrawInKnockout = errors.New("matches after the group stage can not end in a draw")
+)
+
+// Result of a match, the winner is team 1 or 2 of the match and the score is seen from team 1.
+type Result struct {
+	Winner     int
+	Draw       bool
+	Score      *games.Score
+	PlayedAt   time.Time
+	RecordedBy *players.Player
+}
+
+func (result Result) validate(match Match) error {
+	if result.Draw && match.Stage != StageGroup {
+		return ErrDrawInKnockout
+	}
+	if !result.Draw && result.Winner != 1 && result.Winner != 2 {
+		return ErrInvalidResult
+	}
+
+	if result.Score == nil {
+		return nil
+	}
+
+	team1, team2 := result.Score.Team1, result.Score.Team2
+	if result.Winner == 2 {
+		team1, team2 = team2, team1
+	}
+	if (result.Draw && team1 != team2) || (!result.Draw && team1 <= team2) {
+		return fmt.Errorf("%w: score %d:%d does not match the result", ErrInvalidResult, result.Score.Team1, result.Score.Team2)
+	}
+
+	return nil
+}
+
+// apply finishes the match with the result of the game.
+func (result Result) apply(match *Match, game games.Game) {
+	match.Done = true
+	match.Draw = result.Draw
+	match.Score = result.Score
+	match.GameID = game.ID
+	match.GameUUID = game.UUID
+
+	switch {
+	case result.Draw:
+		match.WinnerID, match.LoserID = 0, 0
+	case result.Winner == 1:
+		match.WinnerID, match.LoserID = match.Team1ID, match.Team2ID
+	default:
+		match.WinnerID, match.LoserID = match.Team2ID, match.Team1ID
+	}
+}
+
+// advance fills in the teams decided by finished matches and groups and finishes the matches
+// without an opponent, until nothing changes. The tournament is finished with its last match.
+// The numbers of the changed matches are returned.
+func (tournament *Tournament) advance() []int {
+	changed := []int{}
+	for progress := true; progress; {
+		progress = false
+
+		for i := range tournament.Matches {
+			match := &tournament.Matches[i]
+			if match.Done {
+				continue
+			}
+
+			team1, known1 := tournament.resolve(match.Team1ID, match.Source1)
+			team2, known2 := tournament.resolve(match.Team2ID, match.Source2)
+			bye := known1 && known2 && (team1 == 0 || team2 == 0)
+			if team1 == match.Team1ID && team2 == match.Team2ID && !bye {
+				continue
+			}
+
+			match.Team1ID, match.Team2ID = team1, team2
+			if bye {
+				match.Done = true
+				match.WinnerID = max(team1, team2)
+			}
+
+			progress = true
+			if !slices.Contains(changed, match.Number) {
+				changed = append(changed, match.Number)
+			}
+		}
+	}
+
+	if len(tournament.Matches) > 0 && !slices.ContainsFunc(tournament.Matches, func(match Match) bool { return !match.Done }) {
+		tournament.Status = StatusFinished
+	}
+
+	return changed
+}
+
+// resolve returns the team of a match slot and if it is decided. A decided slot without a team
+// is a bye.
+func (tournament Tournament) resolve(teamID uint, source Source) (uint, bool) {
+	if teamID != 0 {
+		return teamID, true
+	}
+
+	switch source.Kind {
+	case SourceWinner, SourceLoser:
+		match, ok := tournament.Match(source.Match)
+		if !ok || !match.Done {
+			return 0, false
+		}
+		if source.Kind == SourceWinner {
+			return match.WinnerID, true
+		}
+
+		return match.LoserID, true
+	case SourceGroup:
+		if !tournament.groupFinished(source.Group) {
+			return 0, false
+		}
+
+		standings := tournament.Standings(source.Group)
+		if source.Rank > len(standings) {
+			return 0, true
+		}
+
+		return standings[source.Rank-1].Team.ID, true
+	}
+
+	return 0, true
+}
+
+func (tournament Tournament) groupFinished(group int) bool {
+	for _, match := range tournament.Matches {
+		if match.Stage == StageGroup && match.Group == group && !match.Done {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Standing is the record of a team in its group. Wins give 3 points and draws 1.
+type Standing struct {
+	Team         Team
+	Rank         int
+	Played       int
+	Wins         int
+	Draws        int
+	Losses       int
+	Points       int
+	GoalsFor     int
+	GoalsAgainst int
+}
+
+func (standing Standing) GoalDifference() int {
+	return standing.GoalsFor - standing.GoalsAgainst
+}
+
+// Standings ranks the teams of the group by points, goal difference, goals and seed.
+func (tournament Tournament) Standings(group int) []Standing {
+	standingsByTeam := map[uint]*Standing{}
+	getStanding := func(teamID uint) *Standing {
+		if _, ok := standingsByTeam[teamID]; !ok {
+			team, _ := tournament.Team(teamID)
+			standingsByTeam[teamID] = &Standing{Team: team}
+		}
+
+		return standingsByTeam[teamID]
+	}
+
+	for _, match := range tournament.Matches {
+		if match.Stage != StageGroup || match.Group != group {
+			continue
+		}
+
+		team1, team2 := getStanding(match.Team1ID), getStanding(match.Team2ID)
+		if !match.Done {
+			continue
+		}
+
+		team1.Played++
+		team2.Played++
+		if match.Score != nil {
+			team1.GoalsFor += match.Score.Team1
+			team1.GoalsAgainst += match.Score.Team2
+			team2.GoalsFor += match.Score.Team2
+			team2.GoalsAgainst += match.Score.Team1
+		}
+
+		switch {
+		case match.Draw:
+			team1.Draws++
+			team2.Draws++
+		case match.WinnerID == match.Team1ID:
+			team1.Wins++
+			team2.Losses++
+		default:
+			team2.Wins++
+			team1.Losses++
+		}
+	}
+
+	standings := make([]Standing, 0, len(standingsByTeam))
+	for _, standing := range standingsByTeam {
+		standing.Points = standing.Wins*winPoints + standing.Draws*drawPoints
+		standings = append(standings, *standing)
+	}
+
+	slices.SortFunc(standings, func(a, b Standing) int {
+		return cmp.Or(
+			cmp.Compare(b.Points, a.Points),
+			cmp.Compare(b.GoalDifference(), a.GoalDifference()),
+			cmp.Compare(b.GoalsFor, a.GoalsFor),
+			cmp.Compare(a.Team.Seed, b.Team.Seed),
+		)
+	})
+	for i := range standings {
+		standings[i].Rank = i + 1
+	}
+
+	return standings
+}
+
+// GroupNumbers returns the numbers of the groups of the tournament.
+func (tournament Tournament) GroupNumbers() []int {
+	groups := []int{}
+	for _, match := range tournament.Matches {
+		if match.Stage == StageGroup && !slices.Contains(groups, match.Group) {
+			groups = append(groups, match.Group)
+		}
+	}
+	slices.Sort(groups)
+
+	return groups
+}
+
+// Rounds returns the matches of the stage grouped by round.
+func (tournament Tournament) Rounds(stage Stage) [][]Match {
+	rounds := [][]Match{}
+	for _, match := range tournament.Matches {
+		if match.Stage != stage {
+			continue
+		}
+
+		for len(rounds) < match.Round {
+			rounds = append(rounds, []Match{})
+		}
+		rounds[match.Round-1] = append(rounds[match.Round-1], match)
+	}
+
+	return rounds
+}
+
+// TeamLabel names team 1 or 2 of the match, the source while the team is not decided yet.
+func (tournament Tournament) TeamLabel(match Match, team int) string {
+	teamID, source := match.Team1ID, match.Source1
+	if team == 2 {
+		teamID, source = match.Team2ID, match.Source2
+	}
+
+	if teamID != 0 {
+		return tournament.TeamName(teamID)
+	}
+	if match.Done {
+		return Source{}.Label()
+	}
+
+	return source.Label()
+}
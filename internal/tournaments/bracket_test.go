@@ -0,0 +1,215 @@
+package tournaments
+
+import (
+	"testing"
+
+	"github.com/spie/fskick/internal/games"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createScheduledTournament(t *testing.T, format Format, teams int, options ScheduleOptions) Tournament {
+	t.Helper()
+
+	tournament := Tournament{Format: format, Status: StatusRunning, Teams: createTeams(teams)}
+	matches, _, err := generateSchedule(format, tournament.Teams, options)
+	require.NoError(t, err)
+	tournament.Matches = matches
+	tournament.advance()
+
+	return tournament
+}
+
+func playMatch(t *testing.T, tournament *Tournament, number int, result Result) []int {
+	t.Helper()
+
+	match := &tournament.Matches[number-1]
+	require.True(t, match.Ready(), "match %d is not ready", number)
+	require.NoError(t, result.validate(*match))
+	result.apply(match, games.Game{})
+
+	return tournament.advance()
+}
+
+// playBySeed plays the ready matches in order until the tournament is finished, the better
+// seed wins every match.
+func playBySeed(t *testing.T, tournament *Tournament) {
+	t.Helper()
+
+	for tournament.Status == StatusRunning {
+		played := false
+		for _, match := range tournament.Matches {
+			if !match.Ready() {
+				continue
+			}
+
+			team1, _ := tournament.Team(match.Team1ID)
+			team2, _ := tournament.Team(match.Team2ID)
+			winner := 1
+			if team2.Seed < team1.Seed {
+				winner = 2
+			}
+
+			playMatch(t, tournament, match.Number, Result{Winner: winner})
+			played = true
+		}
+		require.True(t, played, "no match is ready in the running tournament")
+	}
+}
+
+func TestAdvanceByes(t *testing.T) {
+	tournament := createScheduledTournament(t, FormatSingleElimination, 3, ScheduleOptions{})
+
+	bye, _ := tournament.Match(1)
+	assert.True(t, bye.Bye())
+	assert.Equal(t, uint(1), bye.WinnerID)
+
+	final, _ := tournament.Match(3)
+	assert.Equal(t, uint(1), final.Team1ID)
+	assert.Zero(t, final.Team2ID)
+	assert.False(t, final.Ready())
+
+	changed := playMatch(t, &tournament, 2, Result{Winner: 2, Score: &games.Score{Team1: 3, Team2: 10}})
+	assert.Equal(t, []int{3}, changed)
+
+	final, _ = tournament.Match(3)
+	assert.Equal(t, uint(3), final.Team2ID)
+	assert.True(t, final.Ready())
+	assert.Equal(t, StatusRunning, tournament.Status)
+
+	playMatch(t, &tournament, 3, Result{Winner: 2})
+
+	champion, ok := tournament.Champion()
+	assert.True(t, ok)
+	assert.Equal(t, "Team 3", champion.Name)
+	assert.Equal(t, StatusFinished, tournament.Status)
+}
+
+func TestDoubleElimination(t *testing.T) {
+	tests := map[string]struct {
+		teams int
+	}{
+		"4 teams":           {teams: 4},
+		"6 teams with byes": {teams: 6},
+		"8 teams":           {teams: 8},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tournament := createScheduledTournament(t, FormatDoubleElimination, test.teams, ScheduleOptions{})
+
+			playBySeed(t, &tournament)
+
+			final := tournament.Matches[len(tournament.Matches)-1]
+			assert.Equal(t, StageFinal, final.Stage)
+			assert.Equal(t, uint(1), final.Team1ID)
+			assert.Equal(t, uint(2), final.Team2ID)
+
+			losses := map[uint]int{}
+			for _, match := range tournament.Matches {
+				if match.LoserID != 0 {
+					losses[match.LoserID]++
+				}
+			}
+			assert.Len(t, losses, test.teams-1)
+			for teamID, lost := range losses {
+				if teamID == 2 {
+					assert.Equal(t, 2, lost)
+				} else {
+					assert.LessOrEqual(t, lost, 2)
+				}
+			}
+			assert.Zero(t, losses[1])
+
+			champion, ok := tournament.Champion()
+			assert.True(t, ok)
+			assert.Equal(t, uint(1), champion.ID)
+		})
+	}
+}
+
+func TestGroupsKnockout(t *testing.T) {
+	tournament := createScheduledTournament(t, FormatGroups, 4, ScheduleOptions{Groups: 1, Advancing: 2})
+	require.Len(t, tournament.Matches, 7)
+
+	results := map[[2]uint]Result{
+		{1, 4}: {Winner: 2, Score: &games.Score{Team1: 5, Team2: 10}},
+		{2, 3}: {Draw: true, Score: &games.Score{Team1: 8, Team2: 8}},
+		{1, 3}: {Winner: 1, Score: &games.Score{Team1: 10, Team2: 2}},
+		{4, 2}: {Winner: 1, Score: &games.Score{Team1: 10, Team2: 9}},
+		{1, 2}: {Draw: true},
+		{3, 4}: {Winner: 2, Score: &games.Score{Team1: 0, Team2: 10}},
+	}
+	for _, match := range tournament.Matches[:6] {
+		result, ok := results[[2]uint{match.Team1ID, match.Team2ID}]
+		if !ok {
+			result, ok = results[[2]uint{match.Team2ID, match.Team1ID}]
+			require.True(t, ok, "no result for %d against %d", match.Team1ID, match.Team2ID)
+			if result.Winner != 0 {
+				result.Winner = 3 - result.Winner
+			}
+			if result.Score != nil {
+				result.Score = &games.Score{Team1: result.Score.Team2, Team2: result.Score.Team1}
+			}
+		}
+
+		final, _ := tournament.Match(7)
+		assert.False(t, final.Ready())
+
+		playMatch(t, &tournament, match.Number, result)
+	}
+
+	standings := tournament.Standings(1)
+	require.Len(t, standings, 4)
+	assert.Equal(t, []uint{4, 1, 2, 3}, []uint{
+		standings[0].Team.ID,
+		standings[1].Team.ID,
+		standings[2].Team.ID,
+		standings[3].Team.ID,
+	})
+	assert.Equal(t, Standing{
+		Team:         tournament.Teams[3],
+		Rank:         1,
+		Played:       3,
+		Wins:         3,
+		Points:       9,
+		GoalsFor:     30,
+		GoalsAgainst: 14,
+	}, standings[0])
+	assert.Equal(t, 4, standings[1].Points)
+	assert.Equal(t, 3, standings[1].GoalDifference())
+	assert.Equal(t, 2, standings[2].Points)
+	assert.Equal(t, 1, standings[3].Points)
+
+	final, _ := tournament.Match(7)
+	assert.Equal(t, StageKnockout, final.Stage)
+	assert.Equal(t, uint(4), final.Team1ID)
+	assert.Equal(t, uint(1), final.Team2ID)
+	assert.True(t, final.Ready())
+}
+
+func TestResultValidate(t *testing.T) {
+	group := Match{Stage: StageGroup}
+	knockout := Match{Stage: StageKnockout}
+
+	tests := map[string]struct {
+		match  Match
+		result Result
+		err    error
+	}{
+		"winner":                 {match: knockout, result: Result{Winner: 2}},
+		"winner with score":      {match: knockout, result: Result{Winner: 2, Score: &games.Score{Team1: 4, Team2: 10}}},
+		"draw in group":          {match: group, result: Result{Draw: true, Score: &games.Score{Team1: 5, Team2: 5}}},
+		"draw in knockout":       {match: knockout, result: Result{Draw: true}, err: ErrDrawInKnockout},
+		"no winner":              {match: group, result: Result{}, err: ErrInvalidResult},
+		"unknown winner":         {match: group, result: Result{Winner: 3}, err: ErrInvalidResult},
+		"score of the loser":     {match: group, result: Result{Winner: 1, Score: &games.Score{Team1: 4, Team2: 10}}, err: ErrInvalidResult},
+		"unequal score for draw": {match: group, result: Result{Draw: true, Score: &games.Score{Team1: 4, Team2: 10}}, err: ErrInvalidResult},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorIs(t, test.result.validate(test.match), test.err)
+		})
+	}
+}
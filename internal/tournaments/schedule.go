@@ -0,0 +1,318 @@
+package tournaments
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+var (
+	ErrNotEnoughTeams = errors.New("not enough teams for the tournament")
+	ErrInvalidGroups  = errors.New("invalid groups")
+)
+
+// ScheduleOptions configure the groups format, no groups make groups of about four teams and
+// no advancing lets the best two teams of each group play the knockout.
+type ScheduleOptions struct {
+	Groups    int
+	Advancing int
+}
+
+const (
+	defaultGroupSize = 4
+	defaultAdvancing = 2
+)
+
+// slot is a team or the source of a team in the first round of a bracket, empty slots are byes.
+type slot struct {
+	teamID uint
+	source Source
+}
+
+type scheduler struct {
+	matches []Match
+}
+
+func (s *scheduler) add(match Match) Match {
+	match.Number = len(s.matches) + 1
+	s.matches = append(s.matches, match)
+
+	return match
+}
+
+// generateSchedule creates the matches of the format for the teams ordered by seed. Groups are
+// returned with the options actually used.
+func generateSchedule(format Format, teams []Team, options ScheduleOptions) ([]Match, ScheduleOptions, error) {
+	s := &scheduler{}
+
+	switch format {
+	case FormatSingleElimination:
+		if len(teams) < 2 {
+			return nil, options, fmt.Errorf("%w: single elimination needs 2 teams", ErrNotEnoughTeams)
+		}
+
+		s.addBracket(StageKnockout, getTeamSlots(teams))
+
+		return s.matches, ScheduleOptions{}, nil
+	case FormatDoubleElimination:
+		if len(teams) < 3 {
+			return nil, options, fmt.Errorf("%w: double elimination needs 3 teams", ErrNotEnoughTeams)
+		}
+
+		s.addDoubleElimination(getTeamSlots(teams))
+
+		return s.matches, ScheduleOptions{}, nil
+	case FormatGroups:
+		options, err := getGroupOptions(len(teams), options)
+		if err != nil {
+			return nil, options, err
+		}
+
+		s.addGroups(teams, options)
+
+		return s.matches, options, nil
+	}
+
+	return nil, options, fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+}
+
+func getTeamSlots(teams []Team) []slot {
+	slots := make([]slot, len(teams))
+	for i, team := range teams {
+		slots[i] = slot{teamID: team.ID}
+	}
+
+	return slots
+}
+
+// addBracket adds a single elimination bracket for the slots ordered by seed. The bracket is
+// filled up with byes for the best seeds. The matches are returned by round.
+func (s *scheduler) addBracket(stage Stage, slots []slot) [][]Match {
+	order := getSeedOrder(getBracketSize(len(slots)))
+
+	round := []Match{}
+	for i := 0; i < len(order); i += 2 {
+		match := Match{Stage: stage, Round: 1}
+		if seed := order[i]; seed <= len(slots) {
+			match.Team1ID, match.Source1 = slots[seed-1].teamID, slots[seed-1].source
+		}
+		if seed := order[i+1]; seed <= len(slots) {
+			match.Team2ID, match.Source2 = slots[seed-1].teamID, slots[seed-1].source
+		}
+
+		round = append(round, s.add(match))
+	}
+
+	rounds := [][]Match{round}
+	for len(round) > 1 {
+		next := []Match{}
+		for i := 0; i < len(round); i += 2 {
+			next = append(next, s.add(Match{
+				Stage:   stage,
+				Round:   len(rounds) + 1,
+				Source1: winnerOf(round[i]),
+				Source2: winnerOf(round[i+1]),
+			}))
+		}
+
+		rounds = append(rounds, next)
+		round = next
+	}
+
+	return rounds
+}
+
+// addDoubleElimination adds the winners bracket, the losers bracket and the final. The losers
+// of a winners round drop into the losers bracket in reversed order to avoid early rematches.
+// The final is a single match, there is no reset if the winner of the losers bracket wins it.
+func (s *scheduler) addDoubleElimination(slots []slot) {
+	winners := s.addBracket(StageWinners, slots)
+
+	round := 1
+	losers := []Match{}
+	for i := 0; i < len(winners[0]); i += 2 {
+		losers = append(losers, s.add(Match{
+			Stage:   StageLosers,
+			Round:   round,
+			Source1: loserOf(winners[0][i]),
+			Source2: loserOf(winners[0][i+1]),
+		}))
+	}
+
+	for _, winnersRound := range winners[1:] {
+		round++
+		dropped := []Match{}
+		for i, match := range losers {
+			dropped = append(dropped, s.add(Match{
+				Stage:   StageLosers,
+				Round:   round,
+				Source1: winnerOf(match),
+				Source2: loserOf(winnersRound[len(winnersRound)-1-i]),
+			}))
+		}
+		losers = dropped
+
+		if len(losers) == 1 {
+			continue
+		}
+
+		round++
+		next := []Match{}
+		for i := 0; i < len(losers); i += 2 {
+			next = append(next, s.add(Match{
+				Stage:   StageLosers,
+				Round:   round,
+				Source1: winnerOf(losers[i]),
+				Source2: winnerOf(losers[i+1]),
+			}))
+		}
+		losers = next
+	}
+
+	s.add(Match{
+		Stage:   StageFinal,
+		Round:   1,
+		Source1: winnerOf(winners[len(winners)-1][0]),
+		Source2: winnerOf(losers[0]),
+	})
+}
+
+// addGroups adds the round-robin matches of the groups, round by round over all groups, and
+// the knockout of the advancing teams. Group winners meet the runners-up of other groups.
+func (s *scheduler) addGroups(teams []Team, options ScheduleOptions) {
+	groups := splitGroups(teams, options.Groups)
+
+	schedules := make([][][][2]uint, len(groups))
+	rounds := 0
+	for i, group := range groups {
+		schedules[i] = getRoundRobin(group)
+		rounds = max(rounds, len(schedules[i]))
+	}
+
+	for round := range rounds {
+		for i, schedule := range schedules {
+			if round >= len(schedule) {
+				continue
+			}
+
+			for _, pairing := range schedule[round] {
+				s.add(Match{
+					Stage:   StageGroup,
+					Round:   round + 1,
+					Group:   i + 1,
+					Team1ID: pairing[0],
+					Team2ID: pairing[1],
+				})
+			}
+		}
+	}
+
+	slots := []slot{}
+	for rank := 1; rank <= options.Advancing; rank++ {
+		for group := 1; group <= options.Groups; group++ {
+			slots = append(slots, slot{source: Source{Kind: SourceGroup, Group: group, Rank: rank}})
+		}
+	}
+
+	s.addBracket(StageKnockout, slots)
+}
+
+func getGroupOptions(teamsCount int, options ScheduleOptions) (ScheduleOptions, error) {
+	if options.Groups == 0 {
+		options.Groups = max(1, teamsCount/defaultGroupSize)
+	}
+	if options.Advancing == 0 {
+		options.Advancing = defaultAdvancing
+	}
+
+	if options.Groups < 0 || options.Advancing < 0 {
+		return options, fmt.Errorf("%w: groups and advancing teams have to be positive", ErrInvalidGroups)
+	}
+	if teamsCount < 2*options.Groups {
+		return options, fmt.Errorf(
+			"%w: %d groups need %d teams, %d registered",
+			ErrNotEnoughTeams,
+			options.Groups,
+			2*options.Groups,
+			teamsCount,
+		)
+	}
+	if options.Advancing > teamsCount/options.Groups {
+		return options, fmt.Errorf(
+			"%w: %d teams of a group can not advance, the smallest group has %d teams",
+			ErrInvalidGroups,
+			options.Advancing,
+			teamsCount/options.Groups,
+		)
+	}
+	if options.Groups*options.Advancing < 2 {
+		return options, fmt.Errorf("%w: the knockout needs 2 teams", ErrInvalidGroups)
+	}
+
+	return options, nil
+}
+
+// splitGroups splits the teams ordered by seed like a snake, so every group gets strong and
+// weak teams.
+func splitGroups(teams []Team, count int) [][]uint {
+	groups := make([][]uint, count)
+	for i, team := range teams {
+		row, column := i/count, i%count
+		if row%2 == 1 {
+			column = count - 1 - column
+		}
+
+		groups[column] = append(groups[column], team.ID)
+	}
+
+	return groups
+}
+
+// getRoundRobin pairs every team with every other team once with the circle method. With an
+// odd number of teams one team pauses each round.
+func getRoundRobin(teamIDs []uint) [][][2]uint {
+	ids := slices.Clone(teamIDs)
+	if len(ids)%2 == 1 {
+		ids = append(ids, 0)
+	}
+
+	rounds := [][][2]uint{}
+	for range len(ids) - 1 {
+		pairings := [][2]uint{}
+		for i := range len(ids) / 2 {
+			team1, team2 := ids[i], ids[len(ids)-1-i]
+			if team1 != 0 && team2 != 0 {
+				pairings = append(pairings, [2]uint{team1, team2})
+			}
+		}
+		rounds = append(rounds, pairings)
+
+		ids = append([]uint{ids[0], ids[len(ids)-1]}, ids[1:len(ids)-1]...)
+	}
+
+	return rounds
+}
+
+func getBracketSize(teamsCount int) int {
+	size := 2
+	for size < teamsCount {
+		size *= 2
+	}
+
+	return size
+}
+
+// getSeedOrder returns the seeds of a bracket in the order of the first round, so the best
+// seeds meet as late as possible: 1, 8, 4, 5, 2, 7, 3, 6 for 8 teams.
+func getSeedOrder(size int) []int {
+	order := []int{1, 2}
+	for len(order) < size {
+		next := make([]int, 0, len(order)*2)
+		for _, seed := range order {
+			next = append(next, seed, len(order)*2+1-seed)
+		}
+		order = next
+	}
+
+	return order
+}
@@ -0,0 +1,115 @@
+package tournaments
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/spie/fskick/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createTeams(count int) []Team {
+	teams := make([]Team, count)
+	for i := range teams {
+		teams[i] = Team{Model: db.Model{ID: uint(i + 1)}, Name: fmt.Sprintf("Team %d", i+1), Seed: i + 1}
+	}
+
+	return teams
+}
+
+func TestGetSeedOrder(t *testing.T) {
+	tests := map[string]struct {
+		size  int
+		order []int
+	}{
+		"2 teams":  {size: 2, order: []int{1, 2}},
+		"4 teams":  {size: 4, order: []int{1, 4, 2, 3}},
+		"8 teams":  {size: 8, order: []int{1, 8, 4, 5, 2, 7, 3, 6}},
+		"16 teams": {size: 16, order: []int{1, 16, 8, 9, 4, 13, 5, 12, 2, 15, 7, 10, 3, 14, 6, 11}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.order, getSeedOrder(test.size))
+		})
+	}
+}
+
+func TestGenerateSchedule(t *testing.T) {
+	tests := map[string]struct {
+		format  Format
+		teams   int
+		options ScheduleOptions
+		matches int
+		used    ScheduleOptions
+		err     error
+	}{
+		"single elimination":             {format: FormatSingleElimination, teams: 8, matches: 7},
+		"single elimination with byes":   {format: FormatSingleElimination, teams: 5, matches: 7},
+		"single elimination of two":      {format: FormatSingleElimination, teams: 2, matches: 1},
+		"single elimination of one":      {format: FormatSingleElimination, teams: 1, err: ErrNotEnoughTeams},
+		"double elimination":             {format: FormatDoubleElimination, teams: 8, matches: 14},
+		"double elimination of four":     {format: FormatDoubleElimination, teams: 4, matches: 6},
+		"double elimination with byes":   {format: FormatDoubleElimination, teams: 6, matches: 14},
+		"double elimination of two":      {format: FormatDoubleElimination, teams: 2, err: ErrNotEnoughTeams},
+		"default groups":                 {format: FormatGroups, teams: 8, matches: 15, used: ScheduleOptions{Groups: 2, Advancing: 2}},
+		"one group":                      {format: FormatGroups, teams: 5, matches: 11, used: ScheduleOptions{Groups: 1, Advancing: 2}},
+		"groups with byes in knockout":   {format: FormatGroups, teams: 6, options: ScheduleOptions{Groups: 3}, matches: 10, used: ScheduleOptions{Groups: 3, Advancing: 2}},
+		"groups with one advancing team": {format: FormatGroups, teams: 9, options: ScheduleOptions{Groups: 3, Advancing: 1}, matches: 12, used: ScheduleOptions{Groups: 3, Advancing: 1}},
+		"too many groups":                {format: FormatGroups, teams: 5, options: ScheduleOptions{Groups: 3}, err: ErrNotEnoughTeams},
+		"too many advancing teams":       {format: FormatGroups, teams: 8, options: ScheduleOptions{Advancing: 5}, err: ErrInvalidGroups},
+		"one team in the knockout":       {format: FormatGroups, teams: 4, options: ScheduleOptions{Groups: 1, Advancing: 1}, err: ErrInvalidGroups},
+		"negative groups":                {format: FormatGroups, teams: 4, options: ScheduleOptions{Groups: -1}, err: ErrInvalidGroups},
+		"unknown format":                 {format: Format("swiss"), teams: 4, err: ErrUnknownFormat},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			matches, options, err := generateSchedule(test.format, createTeams(test.teams), test.options)
+
+			require.ErrorIs(t, err, test.err)
+			if test.err != nil {
+				return
+			}
+			assert.Len(t, matches, test.matches)
+			assert.Equal(t, test.used, options)
+			for i, match := range matches {
+				assert.Equal(t, i+1, match.Number)
+			}
+		})
+	}
+}
+
+func TestGetRoundRobin(t *testing.T) {
+	for _, count := range []int{2, 3, 4, 5, 6} {
+		t.Run(fmt.Sprintf("%d teams", count), func(t *testing.T) {
+			ids := []uint{}
+			for i := range count {
+				ids = append(ids, uint(i+1))
+			}
+
+			pairs := map[[2]uint]int{}
+			for _, round := range getRoundRobin(ids) {
+				played := map[uint]bool{}
+				for _, pairing := range round {
+					assert.False(t, played[pairing[0]] || played[pairing[1]], "team plays twice in a round")
+					played[pairing[0]], played[pairing[1]] = true, true
+
+					pairs[[2]uint{min(pairing[0], pairing[1]), max(pairing[0], pairing[1])}]++
+				}
+			}
+
+			assert.Len(t, pairs, count*(count-1)/2)
+			for pair, played := range pairs {
+				assert.Equal(t, 1, played, "pair %v", pair)
+			}
+		})
+	}
+}
+
+func TestSplitGroups(t *testing.T) {
+	groups := splitGroups(createTeams(10), 3)
+
+	assert.Equal(t, [][]uint{{1, 6, 7}, {2, 5, 8}, {3, 4, 9, 10}}, groups)
+}
@@ -0,0 +1,227 @@
+package tournaments
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spie/fskick/internal/db"
+	"github.com/spie/fskick/internal/games"
+	"github.com/spie/fskick/internal/players"
+)
+
+// Format is how the schedule of a tournament is generated. Groups play round-robin groups
+// followed by a single elimination knockout of the best teams of each group.
+type Format string
+
+const (
+	FormatSingleElimination Format = "single"
+	FormatDoubleElimination Format = "double"
+	FormatGroups            Format = "groups"
+)
+
+var ErrUnknownFormat = errors.New("format has to be single, double or groups")
+
+func ParseFormat(value string) (Format, error) {
+	switch Format(value) {
+	case FormatSingleElimination, FormatDoubleElimination, FormatGroups:
+		return Format(value), nil
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrUnknownFormat, value)
+}
+
+// Status of a tournament, teams register until the schedule is generated.
+type Status string
+
+const (
+	StatusRegistration Status = "registration"
+	StatusRunning      Status = "running"
+	StatusFinished     Status = "finished"
+)
+
+// Stage of a match. Single elimination and the knockout after the groups are played in the
+// knockout stage, double elimination in the winners and losers brackets and the final.
+type Stage string
+
+const (
+	StageGroup    Stage = "group"
+	StageKnockout Stage = "knockout"
+	StageWinners  Stage = "winners"
+	StageLosers   Stage = "losers"
+	StageFinal    Stage = "final"
+)
+
+type Tournament struct {
+	db.Model
+	Name     string
+	Format   Format
+	TeamSize int
+	// Groups and Advancing are the number of groups and the number of teams of each group
+	// playing the knockout, only used by the groups format.
+	Groups    int
+	Advancing int
+	Status    Status
+	Teams     []Team
+	Matches   []Match
+}
+
+// Team registered for a tournament, the seed is the order of the registration.
+type Team struct {
+	db.Model
+	Name    string
+	Seed    int
+	Players players.Team
+}
+
+// Match of a tournament, numbered in the order they are scheduled. The teams are set when
+// their sources are decided, the score is seen from team 1. Done matches without two teams
+// are byes.
+type Match struct {
+	db.Model
+	Number   int
+	Stage    Stage
+	Round    int
+	Group    int
+	Source1  Source
+	Source2  Source
+	Team1ID  uint
+	Team2ID  uint
+	WinnerID uint
+	LoserID  uint
+	Draw     bool
+	Score    *games.Score
+	GameID   uint
+	GameUUID string
+	Done     bool
+}
+
+// Ready tells if both teams of the match are known and the result can be recorded.
+func (match Match) Ready() bool {
+	return !match.Done && match.Team1ID != 0 && match.Team2ID != 0
+}
+
+func (match Match) Bye() bool {
+	return match.Done && (match.Team1ID == 0 || match.Team2ID == 0)
+}
+
+// SourceKind tells if a team comes from the winner or loser of a match or a group rank.
+type SourceKind string
+
+const (
+	SourceWinner SourceKind = "W"
+	SourceLoser  SourceKind = "L"
+	SourceGroup  SourceKind = "G"
+)
+
+var ErrInvalidSource = errors.New("invalid source of a match team")
+
+// Source of a team in a match, stored as W3 or L3 for the winner or loser of match 3 and
+// G1:2 for the second of group 1. Teams set at scheduling have no source.
+type Source struct {
+	Kind  SourceKind
+	Match int
+	Group int
+	Rank  int
+}
+
+func winnerOf(match Match) Source {
+	return Source{Kind: SourceWinner, Match: match.Number}
+}
+
+func loserOf(match Match) Source {
+	return Source{Kind: SourceLoser, Match: match.Number}
+}
+
+func (source Source) String() string {
+	switch source.Kind {
+	case SourceWinner, SourceLoser:
+		return fmt.Sprintf("%s%d", source.Kind, source.Match)
+	case SourceGroup:
+		return fmt.Sprintf("%s%d:%d", source.Kind, source.Group, source.Rank)
+	}
+
+	return ""
+}
+
+// Label describes the source for brackets of undecided matches.
+func (source Source) Label() string {
+	switch source.Kind {
+	case SourceWinner:
+		return fmt.Sprintf("Winner of match %d", source.Match)
+	case SourceLoser:
+		return fmt.Sprintf("Loser of match %d", source.Match)
+	case SourceGroup:
+		return fmt.Sprintf("Group %d, rank %d", source.Group, source.Rank)
+	}
+
+	return "Bye"
+}
+
+// ParseSource reads a source stored with String.
+func ParseSource(value string) (Source, error) {
+	if value == "" {
+		return Source{}, nil
+	}
+
+	kind, rest := SourceKind(value[:1]), value[1:]
+	switch kind {
+	case SourceWinner, SourceLoser:
+		match, err := strconv.Atoi(rest)
+		if err != nil {
+			return Source{}, fmt.Errorf("%w: %s", ErrInvalidSource, value)
+		}
+
+		return Source{Kind: kind, Match: match}, nil
+	case SourceGroup:
+		group, rank, ok := strings.Cut(rest, ":")
+		groupNumber, groupErr := strconv.Atoi(group)
+		rankNumber, rankErr := strconv.Atoi(rank)
+		if !ok || groupErr != nil || rankErr != nil {
+			return Source{}, fmt.Errorf("%w: %s", ErrInvalidSource, value)
+		}
+
+		return Source{Kind: kind, Group: groupNumber, Rank: rankNumber}, nil
+	}
+
+	return Source{}, fmt.Errorf("%w: %s", ErrInvalidSource, value)
+}
+
+// Team returns the registered team with the id.
+func (tournament Tournament) Team(id uint) (Team, bool) {
+	for _, team := range tournament.Teams {
+		if team.ID == id {
+			return team, true
+		}
+	}
+
+	return Team{}, false
+}
+
+// TeamName returns the name of the team with the id, or an empty string for no team.
+func (tournament Tournament) TeamName(id uint) string {
+	team, _ := tournament.Team(id)
+
+	return team.Name
+}
+
+// Match returns the match with the number.
+func (tournament Tournament) Match(number int) (Match, bool) {
+	for _, match := range tournament.Matches {
+		if match.Number == number {
+			return match, true
+		}
+	}
+
+	return Match{}, false
+}
+
+// Champion returns the winner of the last match once the tournament is finished.
+func (tournament Tournament) Champion() (Team, bool) {
+	if tournament.Status != StatusFinished || len(tournament.Matches) == 0 {
+		return Team{}, false
+	}
+
+	return tournament.Team(tournament.Matches[len(tournament.Matches)-1].WinnerID)
+}
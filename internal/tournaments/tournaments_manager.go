@@ -0,0 +1,352 @@
+package tournaments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spie/fskick/internal/games"
+	"github.com/spie/fskick/internal/players"
+	"github.com/spie/fskick/internal/seasons"
+)
+
+var (
+	ErrInvalidTeamSize     = errors.New("team size of a tournament has to be 1 or 2")
+	ErrWrongTeamSize       = errors.New("team has the wrong number of players")
+	ErrPlayerRegistered    = errors.New("player is already registered in another team")
+	ErrRegistrationClosed  = errors.New("registration of the tournament is closed")
+	ErrTournamentNotActive = errors.New("tournament is not running")
+	ErrNoActiveSeason      = errors.New("no active season to record the game of the match in")
+)
+
+type Manager struct {
+	tournamentsRepository TournamentsRepository
+	gamesManager          games.Manager
+	playersManager        players.Manager
+}
+
+func NewManager(
+	tournamentsRepository TournamentsRepository,
+	gamesManager games.Manager,
+	playersManager players.Manager,
+) Manager {
+	return Manager{
+		tournamentsRepository: tournamentsRepository,
+		gamesManager:          gamesManager,
+		playersManager:        playersManager,
+	}
+}
+
+func (manager Manager) CreateTournament(ctx context.Context, name string, format Format, teamSize int) (Tournament, error) {
+	tournament, err := newTournament(name, format, teamSize)
+	if err != nil {
+		return Tournament{}, err
+	}
+
+	err = manager.tournamentsRepository.CreateTournament(ctx, &tournament)
+	if err != nil {
+		return Tournament{}, err
+	}
+
+	return tournament, nil
+}
+
+// newTournament returns the tournament open for the registration of teams.
+func newTournament(name string, format Format, teamSize int) (Tournament, error) {
+	format, err := ParseFormat(string(format))
+	if err != nil {
+		return Tournament{}, err
+	}
+	if teamSize != 1 && teamSize != 2 {
+		return Tournament{}, fmt.Errorf("%w: %d", ErrInvalidTeamSize, teamSize)
+	}
+
+	return Tournament{Name: name, Format: format, TeamSize: teamSize, Status: StatusRegistration}, nil
+}
+
+func (manager Manager) GetTournaments(ctx context.Context) ([]Tournament, error) {
+	return manager.tournamentsRepository.GetAll(ctx)
+}
+
+// GetTournament returns the tournament with its teams and matches.
+func (manager Manager) GetTournament(ctx context.Context, name string) (Tournament, error) {
+	tournament, err := manager.tournamentsRepository.FindTournamentByName(ctx, name)
+	if err != nil {
+		return Tournament{}, err
+	}
+
+	return withTeamsAndMatches(ctx, manager.tournamentsRepository, tournament)
+}
+
+func (manager Manager) GetTournamentByUUID(ctx context.Context, uuid string) (Tournament, error) {
+	tournament, err := manager.tournamentsRepository.FindTournamentByUuid(ctx, uuid)
+	if err != nil {
+		return Tournament{}, err
+	}
+
+	return withTeamsAndMatches(ctx, manager.tournamentsRepository, tournament)
+}
+
+// RegisterTeam registers the players as a team, seeded in the order of the registration.
+// Without a name the team is named after its players.
+func (manager Manager) RegisterTeam(ctx context.Context, tournamentName string, teamName string, playerNames []string) (Team, error) {
+	tournament, err := manager.tournamentsRepository.FindTournamentByName(ctx, tournamentName)
+	if err != nil {
+		return Team{}, err
+	}
+
+	team := Team{Name: strings.TrimSpace(teamName)}
+	for _, playerName := range playerNames {
+		player, err := manager.playersManager.GetPlayerByName(ctx, playerName)
+		if err != nil {
+			return Team{}, fmt.Errorf("get player %s of team: %w", playerName, err)
+		}
+
+		team.Players = append(team.Players, player)
+	}
+
+	if team.Name == "" {
+		names := make([]string, len(team.Players))
+		for i, player := range team.Players {
+			names[i] = player.PublicName()
+		}
+		team.Name = strings.Join(names, " & ")
+	}
+
+	err = manager.tournamentsRepository.Transaction(
+		ctx,
+		func(tournamentsRepository TournamentsRepository, _ games.GamesRepository, _ games.AttendanceRepository) error {
+			tournament, err := getLockedTournament(ctx, tournamentsRepository, tournament)
+			if err != nil {
+				return err
+			}
+
+			err = validateTeam(tournament, team)
+			if err != nil {
+				return err
+			}
+
+			team.Seed = len(tournament.Teams) + 1
+
+			return tournamentsRepository.CreateTeam(ctx, tournament, &team)
+		},
+	)
+	if err != nil {
+		return Team{}, err
+	}
+
+	return team, nil
+}
+
+// validateTeam checks that the team can still register and has the team size of the
+// tournament, without players of other teams.
+func validateTeam(tournament Tournament, team Team) error {
+	if tournament.Status != StatusRegistration {
+		return fmt.Errorf("%w: %s", ErrRegistrationClosed, tournament.Name)
+	}
+	if len(team.Players) != tournament.TeamSize {
+		return fmt.Errorf(
+			"%w: %d, tournament %s is played by teams of %d",
+			ErrWrongTeamSize,
+			len(team.Players),
+			tournament.Name,
+			tournament.TeamSize,
+		)
+	}
+
+	for i, player := range team.Players {
+		for _, registered := range tournament.Teams {
+			if slices.ContainsFunc(registered.Players, func(p players.Player) bool { return p.ID == player.ID }) {
+				return fmt.Errorf("%w: %s plays in %s", ErrPlayerRegistered, player.Name, registered.Name)
+			}
+		}
+		if slices.ContainsFunc(team.Players[:i], func(p players.Player) bool { return p.ID == player.ID }) {
+			return fmt.Errorf("%w: %s", ErrWrongTeamSize, player.Name)
+		}
+	}
+
+	return nil
+}
+
+// Schedule closes the registration and generates the matches of the tournament. Matches
+// against byes are finished right away.
+func (manager Manager) Schedule(ctx context.Context, name string, options ScheduleOptions) (Tournament, error) {
+	tournament, err := manager.tournamentsRepository.FindTournamentByName(ctx, name)
+	if err != nil {
+		return Tournament{}, err
+	}
+
+	err = manager.tournamentsRepository.Transaction(
+		ctx,
+		func(tournamentsRepository TournamentsRepository, _ games.GamesRepository, _ games.AttendanceRepository) error {
+			tournament, err = getLockedTournament(ctx, tournamentsRepository, tournament)
+			if err != nil {
+				return err
+			}
+			if tournament.Status != StatusRegistration {
+				return fmt.Errorf("%w: %s is already scheduled", ErrRegistrationClosed, tournament.Name)
+			}
+			for _, team := range tournament.Teams {
+				if len(team.Players) != tournament.TeamSize {
+					return fmt.Errorf("%w: team %s has %d", ErrWrongTeamSize, team.Name, len(team.Players))
+				}
+			}
+
+			matches, options, err := generateSchedule(tournament.Format, tournament.Teams, options)
+			if err != nil {
+				return err
+			}
+
+			tournament.Matches = matches
+			tournament.Groups = options.Groups
+			tournament.Advancing = options.Advancing
+			tournament.Status = StatusRunning
+			tournament.advance()
+
+			return tournamentsRepository.CreateMatches(ctx, &tournament)
+		},
+	)
+	if err != nil {
+		return Tournament{}, err
+	}
+
+	return tournament, nil
+}
+
+// RecordResult records the game of the match in the active season and advances the teams in
+// the bracket. The game and the matches are stored in one transaction, with the tournament
+// locked against results recorded in the meantime.
+func (manager Manager) RecordResult(ctx context.Context, name string, number int, result Result) (Tournament, error) {
+	tournament, err := manager.tournamentsRepository.FindTournamentByName(ctx, name)
+	if err != nil {
+		return Tournament{}, err
+	}
+
+	err = manager.tournamentsRepository.Transaction(
+		ctx,
+		func(
+			tournamentsRepository TournamentsRepository,
+			gamesRepository games.GamesRepository,
+			attendanceRepository games.AttendanceRepository,
+		) error {
+			tournament, err = getLockedTournament(ctx, tournamentsRepository, tournament)
+			if err != nil {
+				return err
+			}
+
+			match, err := getMatchToRecord(&tournament, number, result)
+			if err != nil {
+				return err
+			}
+
+			entry := newMatchGameEntry(tournament, *match, result)
+			game, err := manager.gamesManager.WithTransaction(gamesRepository, attendanceRepository).CreateGame(ctx, entry)
+			if errors.Is(err, seasons.ErrSeasonNotFound) {
+				return fmt.Errorf("record game of match %d: %w", number, ErrNoActiveSeason)
+			}
+			if err != nil {
+				return fmt.Errorf("record game of match %d: %w", number, err)
+			}
+
+			result.apply(match, *game)
+			changed := append(tournament.advance(), number)
+
+			return tournamentsRepository.UpdateMatches(ctx, &tournament, changed)
+		},
+	)
+	if err != nil {
+		return Tournament{}, err
+	}
+
+	manager.gamesManager.InvalidateCache()
+
+	return tournament, nil
+}
+
+// getMatchToRecord returns the match of the running tournament with the number if the result
+// can be recorded for it.
+func getMatchToRecord(tournament *Tournament, number int, result Result) (*Match, error) {
+	if tournament.Status != StatusRunning {
+		return nil, fmt.Errorf("%w: %s", ErrTournamentNotActive, tournament.Name)
+	}
+
+	index := slices.IndexFunc(tournament.Matches, func(match Match) bool { return match.Number == number })
+	if index < 0 {
+		return nil, fmt.Errorf("%w: %d", ErrMatchNotFound, number)
+	}
+	match := &tournament.Matches[index]
+	if match.Done {
+		return nil, fmt.Errorf("%w: %d", ErrMatchPlayed, number)
+	}
+	if !match.Ready() {
+		return nil, fmt.Errorf("%w: %d", ErrMatchNotReady, number)
+	}
+
+	err := result.validate(*match)
+	if err != nil {
+		return nil, err
+	}
+
+	return match, nil
+}
+
+// newMatchGameEntry returns the game of the match, the winners first. The key of the match
+// keeps a retry from creating the game twice.
+func newMatchGameEntry(tournament Tournament, match Match, result Result) games.GameEntry {
+	team1, _ := tournament.Team(match.Team1ID)
+	team2, _ := tournament.Team(match.Team2ID)
+	entry := games.GameEntry{
+		PlayedAt:       result.PlayedAt,
+		Winners:        team1.Players,
+		Losers:         team2.Players,
+		Draw:           result.Draw,
+		Positions:      games.Positions{},
+		Score:          result.Score,
+		RecordedBy:     result.RecordedBy,
+		IdempotencyKey: fmt.Sprintf("tournament-match:%s", match.UUID),
+		AllowDuplicate: true,
+	}
+	if result.Winner == 2 {
+		entry.Winners, entry.Losers = team2.Players, team1.Players
+		if result.Score != nil {
+			entry.Score = &games.Score{Team1: result.Score.Team2, Team2: result.Score.Team1}
+		}
+	}
+
+	return entry
+}
+
+// getLockedTournament locks the tournament in the transaction of the repository and reads it
+// again with its teams and matches, as left by the transactions it waited for.
+func getLockedTournament(ctx context.Context, tournamentsRepository TournamentsRepository, tournament Tournament) (Tournament, error) {
+	err := tournamentsRepository.LockTournament(ctx, tournament)
+	if err != nil {
+		return Tournament{}, err
+	}
+
+	tournament, err = tournamentsRepository.FindTournamentByUuid(ctx, tournament.UUID)
+	if err != nil {
+		return Tournament{}, err
+	}
+
+	return withTeamsAndMatches(ctx, tournamentsRepository, tournament)
+}
+
+func withTeamsAndMatches(ctx context.Context, tournamentsRepository TournamentsRepository, tournament Tournament) (Tournament, error) {
+	teams, err := tournamentsRepository.FindTeams(ctx, tournament)
+	if err != nil {
+		return Tournament{}, err
+	}
+
+	matches, err := tournamentsRepository.FindMatches(ctx, tournament)
+	if err != nil {
+		return Tournament{}, err
+	}
+
+	tournament.Teams = teams
+	tournament.Matches = matches
+
+	return tournament, nil
+}
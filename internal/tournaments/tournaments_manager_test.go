@@ -0,0 +1,274 @@
+package tournaments
+
+import (
+	"testing"
+
+	"github.com/spie/fskick/internal/db"
+	"github.com/spie/fskick/internal/games"
+	"github.com/spie/fskick/internal/players"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTournament(t *testing.T) {
+	tests := map[string]struct {
+		format     Format
+		teamSize   int
+		assertions []func(t *testing.T, tournament Tournament, err error)
+	}{
+		"open for registration": {
+			format:   FormatGroups,
+			teamSize: 2,
+			assertions: []func(t *testing.T, tournament Tournament, err error){
+				func(t *testing.T, tournament Tournament, err error) {
+					assert.NoError(t, err)
+					assert.Equal(t, "Cup", tournament.Name)
+					assert.Equal(t, FormatGroups, tournament.Format)
+					assert.Equal(t, StatusRegistration, tournament.Status)
+				},
+			},
+		},
+		"unknown format": {
+			format:   Format("swiss"),
+			teamSize: 2,
+			assertions: []func(t *testing.T, tournament Tournament, err error){
+				func(t *testing.T, tournament Tournament, err error) {
+					assert.ErrorIs(t, err, ErrUnknownFormat)
+				},
+			},
+		},
+		"team size of three": {
+			format:   FormatSingleElimination,
+			teamSize: 3,
+			assertions: []func(t *testing.T, tournament Tournament, err error){
+				func(t *testing.T, tournament Tournament, err error) {
+					assert.ErrorIs(t, err, ErrInvalidTeamSize)
+				},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tournament, err := newTournament("Cup", tt.format, tt.teamSize)
+
+			for _, assertion := range tt.assertions {
+				assertion(t, tournament, err)
+			}
+		})
+	}
+}
+
+func TestValidateTeam(t *testing.T) {
+	alice := players.Player{Model: db.Model{ID: 1}, Name: "alice"}
+	bob := players.Player{Model: db.Model{ID: 2}, Name: "bob"}
+	carol := players.Player{Model: db.Model{ID: 3}, Name: "carol"}
+	registered := Team{Name: "alice & dave", Players: []players.Player{alice, {Model: db.Model{ID: 4}, Name: "dave"}}}
+
+	tests := map[string]struct {
+		status     Status
+		team       Team
+		assertions []func(t *testing.T, err error)
+	}{
+		"valid team": {
+			status: StatusRegistration,
+			team:   Team{Players: []players.Player{bob, carol}},
+			assertions: []func(t *testing.T, err error){
+				func(t *testing.T, err error) {
+					assert.NoError(t, err)
+				},
+			},
+		},
+		"registration closed": {
+			status: StatusRunning,
+			team:   Team{Players: []players.Player{bob, carol}},
+			assertions: []func(t *testing.T, err error){
+				func(t *testing.T, err error) {
+					assert.ErrorIs(t, err, ErrRegistrationClosed)
+				},
+			},
+		},
+		"wrong team size": {
+			status: StatusRegistration,
+			team:   Team{Players: []players.Player{bob}},
+			assertions: []func(t *testing.T, err error){
+				func(t *testing.T, err error) {
+					assert.ErrorIs(t, err, ErrWrongTeamSize)
+					assert.ErrorContains(t, err, "1, tournament Cup is played by teams of 2")
+				},
+			},
+		},
+		"player of another team": {
+			status: StatusRegistration,
+			team:   Team{Players: []players.Player{bob, alice}},
+			assertions: []func(t *testing.T, err error){
+				func(t *testing.T, err error) {
+					assert.ErrorIs(t, err, ErrPlayerRegistered)
+					assert.ErrorContains(t, err, "alice plays in alice & dave")
+				},
+			},
+		},
+		"player twice in the team": {
+			status: StatusRegistration,
+			team:   Team{Players: []players.Player{bob, bob}},
+			assertions: []func(t *testing.T, err error){
+				func(t *testing.T, err error) {
+					assert.ErrorIs(t, err, ErrWrongTeamSize)
+					assert.ErrorContains(t, err, ": bob")
+				},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tournament := Tournament{Name: "Cup", TeamSize: 2, Status: tt.status, Teams: []Team{registered}}
+			err := validateTeam(tournament, tt.team)
+
+			for _, assertion := range tt.assertions {
+				assertion(t, err)
+			}
+		})
+	}
+}
+
+func TestGetMatchToRecord(t *testing.T) {
+	tests := map[string]struct {
+		number     int
+		result     Result
+		prepare    func(tournament *Tournament)
+		assertions []func(t *testing.T, match *Match, err error)
+	}{
+		"ready match": {
+			number: 2,
+			result: Result{Winner: 1},
+			assertions: []func(t *testing.T, match *Match, err error){
+				func(t *testing.T, match *Match, err error) {
+					assert.NoError(t, err)
+					assert.Equal(t, 2, match.Number)
+				},
+			},
+		},
+		"tournament not running": {
+			number: 2,
+			result: Result{Winner: 1},
+			prepare: func(tournament *Tournament) {
+				tournament.Status = StatusRegistration
+			},
+			assertions: []func(t *testing.T, match *Match, err error){
+				func(t *testing.T, match *Match, err error) {
+					assert.ErrorIs(t, err, ErrTournamentNotActive)
+				},
+			},
+		},
+		"unknown match": {
+			number: 4,
+			result: Result{Winner: 1},
+			assertions: []func(t *testing.T, match *Match, err error){
+				func(t *testing.T, match *Match, err error) {
+					assert.ErrorIs(t, err, ErrMatchNotFound)
+				},
+			},
+		},
+		"played match": {
+			number: 1,
+			result: Result{Winner: 1},
+			assertions: []func(t *testing.T, match *Match, err error){
+				func(t *testing.T, match *Match, err error) {
+					assert.ErrorIs(t, err, ErrMatchPlayed)
+				},
+			},
+		},
+		"match waiting for a team": {
+			number: 3,
+			result: Result{Winner: 1},
+			assertions: []func(t *testing.T, match *Match, err error){
+				func(t *testing.T, match *Match, err error) {
+					assert.ErrorIs(t, err, ErrMatchNotReady)
+				},
+			},
+		},
+		"draw in knockout": {
+			number: 2,
+			result: Result{Draw: true},
+			assertions: []func(t *testing.T, match *Match, err error){
+				func(t *testing.T, match *Match, err error) {
+					assert.ErrorIs(t, err, ErrDrawInKnockout)
+				},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// match 1 is a bye, the winner of match 2 plays the final
+			tournament := createScheduledTournament(t, FormatSingleElimination, 3, ScheduleOptions{})
+			if tt.prepare != nil {
+				tt.prepare(&tournament)
+			}
+
+			match, err := getMatchToRecord(&tournament, tt.number, tt.result)
+
+			for _, assertion := range tt.assertions {
+				assertion(t, match, err)
+			}
+		})
+	}
+}
+
+func TestNewMatchGameEntry(t *testing.T) {
+	alice := players.Player{Model: db.Model{ID: 1}, Name: "alice"}
+	bob := players.Player{Model: db.Model{ID: 2}, Name: "bob"}
+	tournament := Tournament{Teams: []Team{
+		{Model: db.Model{ID: 1}, Players: []players.Player{alice}},
+		{Model: db.Model{ID: 2}, Players: []players.Player{bob}},
+	}}
+	match := Match{Model: db.Model{UUID: "match"}, Team1ID: 1, Team2ID: 2}
+
+	tests := map[string]struct {
+		result     Result
+		assertions []func(t *testing.T, entry games.GameEntry)
+	}{
+		"team 1 won": {
+			result: Result{Winner: 1, Score: &games.Score{Team1: 10, Team2: 4}},
+			assertions: []func(t *testing.T, entry games.GameEntry){
+				func(t *testing.T, entry games.GameEntry) {
+					assert.Equal(t, players.Team{alice}, entry.Winners)
+					assert.Equal(t, players.Team{bob}, entry.Losers)
+					assert.Equal(t, &games.Score{Team1: 10, Team2: 4}, entry.Score)
+					assert.Equal(t, "tournament-match:match", entry.IdempotencyKey)
+					assert.True(t, entry.AllowDuplicate)
+				},
+			},
+		},
+		"team 2 won": {
+			result: Result{Winner: 2, Score: &games.Score{Team1: 4, Team2: 10}},
+			assertions: []func(t *testing.T, entry games.GameEntry){
+				func(t *testing.T, entry games.GameEntry) {
+					assert.Equal(t, players.Team{bob}, entry.Winners)
+					assert.Equal(t, players.Team{alice}, entry.Losers)
+					assert.Equal(t, &games.Score{Team1: 10, Team2: 4}, entry.Score)
+				},
+			},
+		},
+		"draw": {
+			result: Result{Draw: true},
+			assertions: []func(t *testing.T, entry games.GameEntry){
+				func(t *testing.T, entry games.GameEntry) {
+					assert.True(t, entry.Draw)
+					assert.Equal(t, players.Team{alice}, entry.Winners)
+					assert.Nil(t, entry.Score)
+				},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			entry := newMatchGameEntry(tournament, match, tt.result)
+
+			for _, assertion := range tt.assertions {
+				assertion(t, entry)
+			}
+		})
+	}
+}
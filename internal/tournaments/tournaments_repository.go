@@ -0,0 +1,552 @@
+package tournaments
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/spie/fskick/internal/db"
+	"github.com/spie/fskick/internal/games"
+	"github.com/spie/fskick/internal/players"
+)
+
+var (
+	ErrTournamentNotFound = db.ErrNotFound
+	ErrTournamentExists   = errors.New("tournament with name exists")
+	ErrTeamExists         = errors.New("team with name is already registered")
+)
+
+type TournamentsRepository struct {
+	conn db.Connection
+}
+
+func NewTournamentsRepository(conn db.Connection) TournamentsRepository {
+	return TournamentsRepository{conn: conn}
+}
+
+// Transaction runs fn with the tournaments repository and the repositories of the games in one
+// transaction, so the game of a match is stored with the result of the match or not at all.
+func (repository TournamentsRepository) Transaction(
+	ctx context.Context,
+	fn func(
+		tournamentsRepository TournamentsRepository,
+		gamesRepository games.GamesRepository,
+		attendanceRepository games.AttendanceRepository,
+	) error,
+) error {
+	return db.Transaction(ctx, repository.conn, func(tx db.Connection) error {
+		return fn(NewTournamentsRepository(tx), games.NewGamesRepository(tx), games.NewAttendanceRepository(tx))
+	})
+}
+
+// LockTournament makes other transactions changing the tournament wait until the transaction of
+// the repository ends, like games.GamesRepository.LockSeason.
+func (repository TournamentsRepository) LockTournament(ctx context.Context, tournament Tournament) error {
+	_, err := repository.conn.ExecContext(ctx, "UPDATE tournaments SET updated_at = updated_at WHERE id = $1", tournament.ID)
+	if err != nil {
+		return fmt.Errorf("lock tournament: %w", err)
+	}
+
+	return nil
+}
+
+func (repository TournamentsRepository) CreateTournament(ctx context.Context, tournament *Tournament) error {
+	err := tournament.CreateUUID()
+	if err != nil {
+		return fmt.Errorf("create uuid for insert tournament: %w", err)
+	}
+
+	tournament.CreatedAt = time.Now()
+	tournament.UpdatedAt = time.Now()
+
+	row := repository.conn.QueryRowContext(
+		ctx,
+		`INSERT INTO tournaments (uuid, name, format, team_size, group_count, advancing, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`,
+		tournament.UUID,
+		tournament.Name,
+		tournament.Format,
+		tournament.TeamSize,
+		tournament.Groups,
+		tournament.Advancing,
+		tournament.Status,
+		tournament.CreatedAt,
+		tournament.UpdatedAt,
+	)
+	err = row.Scan(&tournament.ID)
+	if db.IsUniqueViolation(err) {
+		return fmt.Errorf("insert tournament: %w: %s", ErrTournamentExists, tournament.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("insert tournament: %w", err)
+	}
+
+	return nil
+}
+
+func (repository TournamentsRepository) FindTournamentByName(ctx context.Context, name string) (Tournament, error) {
+	tournament, err := repository.selectTournament(ctx, "name = $1", name)
+	if err != nil {
+		return Tournament{}, fmt.Errorf("query tournament by name: %w", err)
+	}
+
+	return tournament, nil
+}
+
+func (repository TournamentsRepository) FindTournamentByUuid(ctx context.Context, uuid string) (Tournament, error) {
+	tournament, err := repository.selectTournament(ctx, "uuid = $1", uuid)
+	if err != nil {
+		return Tournament{}, fmt.Errorf("query tournament by uuid: %w", err)
+	}
+
+	return tournament, nil
+}
+
+func (repository TournamentsRepository) GetAll(ctx context.Context) ([]Tournament, error) {
+	rows, err := repository.conn.QueryContext(
+		ctx,
+		fmt.Sprintf("SELECT %s FROM tournaments ORDER BY id DESC", getTournamentsColumns()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query all tournaments: %w", err)
+	}
+	defer rows.Close()
+
+	tournaments := []Tournament{}
+	for rows.Next() {
+		var tournament Tournament
+		err = rows.Scan(getTournamentScanFields(&tournament)...)
+		if err != nil {
+			return nil, fmt.Errorf("scan row in query all tournaments: %w", err)
+		}
+
+		tournaments = append(tournaments, tournament)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("query all tournaments: %w", err)
+	}
+
+	return tournaments, nil
+}
+
+// CreateTeam registers the team with its players for the tournament. A player can only play in
+// one team of the tournament.
+func (repository TournamentsRepository) CreateTeam(ctx context.Context, tournament Tournament, team *Team) error {
+	err := team.CreateUUID()
+	if err != nil {
+		return fmt.Errorf("create uuid for insert tournament team: %w", err)
+	}
+
+	team.CreatedAt = time.Now()
+	team.UpdatedAt = time.Now()
+
+	return db.Transaction(ctx, repository.conn, func(tx db.Connection) error {
+		row := tx.QueryRowContext(
+			ctx,
+			`INSERT INTO tournament_teams (uuid, tournament_id, name, seed, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`,
+			team.UUID,
+			tournament.ID,
+			team.Name,
+			team.Seed,
+			team.CreatedAt,
+			team.UpdatedAt,
+		)
+		err := row.Scan(&team.ID)
+		if db.IsUniqueViolation(err) {
+			return fmt.Errorf("insert tournament team: %w: %s", ErrTeamExists, team.Name)
+		}
+		if err != nil {
+			return fmt.Errorf("insert tournament team: %w", err)
+		}
+
+		for _, player := range team.Players {
+			_, err = tx.ExecContext(
+				ctx,
+				"INSERT INTO tournament_team_players (tournament_id, tournament_team_id, player_id) VALUES ($1, $2, $3)",
+				tournament.ID,
+				team.ID,
+				player.ID,
+			)
+			if db.IsUniqueViolation(err) {
+				return fmt.Errorf("insert player of tournament team: %w: %s", ErrPlayerRegistered, player.Name)
+			}
+			if err != nil {
+				return fmt.Errorf("insert player of tournament team: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// FindTeams returns the teams of the tournament with their players, ordered by seed. Teams
+// without players are returned too, with no players.
+func (repository TournamentsRepository) FindTeams(ctx context.Context, tournament Tournament) ([]Team, error) {
+	rows, err := repository.conn.QueryContext(
+		ctx,
+		`SELECT id, uuid, created_at, updated_at, name, seed
+		FROM tournament_teams
+		WHERE tournament_id = $1
+		ORDER BY seed ASC`,
+		tournament.ID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query tournament teams: %w", err)
+	}
+	defer rows.Close()
+
+	teams := []Team{}
+	for rows.Next() {
+		var team Team
+		err = rows.Scan(&team.ID, &team.UUID, &team.CreatedAt, &team.UpdatedAt, &team.Name, &team.Seed)
+		if err != nil {
+			return nil, fmt.Errorf("scan row in query tournament teams: %w", err)
+		}
+
+		teams = append(teams, team)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("query tournament teams: %w", err)
+	}
+
+	return teams, repository.findTeamPlayers(ctx, tournament, teams)
+}
+
+// findTeamPlayers adds the players to the teams of the tournament, ordered by name.
+func (repository TournamentsRepository) findTeamPlayers(ctx context.Context, tournament Tournament, teams []Team) error {
+	rows, err := repository.conn.QueryContext(
+		ctx,
+		fmt.Sprintf(
+			`SELECT tp.tournament_team_id, %s
+			FROM tournament_team_players tp
+			JOIN players p ON p.id = tp.player_id
+			WHERE tp.tournament_id = $1
+			ORDER BY p.name ASC`,
+			players.GetPlayerColumns("p"),
+		),
+		tournament.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("query players of tournament teams: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var teamID uint
+		var player players.Player
+		err = rows.Scan(append([]any{&teamID}, players.GetPlayerScanFields(&player)...)...)
+		if err != nil {
+			return fmt.Errorf("scan row in query players of tournament teams: %w", err)
+		}
+
+		index := slices.IndexFunc(teams, func(team Team) bool { return team.ID == teamID })
+		if index >= 0 {
+			teams[index].Players = append(teams[index].Players, player)
+		}
+	}
+	err = rows.Err()
+	if err != nil {
+		return fmt.Errorf("query players of tournament teams: %w", err)
+	}
+
+	return nil
+}
+
+// CreateMatches stores the scheduled matches and the status of the tournament.
+func (repository TournamentsRepository) CreateMatches(ctx context.Context, tournament *Tournament) error {
+	tournament.UpdatedAt = time.Now()
+
+	return db.Transaction(ctx, repository.conn, func(tx db.Connection) error {
+		for i := range tournament.Matches {
+			match := &tournament.Matches[i]
+			err := match.CreateUUID()
+			if err != nil {
+				return fmt.Errorf("create uuid for insert tournament match: %w", err)
+			}
+
+			match.CreatedAt = tournament.UpdatedAt
+			match.UpdatedAt = tournament.UpdatedAt
+
+			team1Score, team2Score := getScoreValues(match.Score)
+			row := tx.QueryRowContext(
+				ctx,
+				`INSERT INTO tournament_matches (
+					uuid,
+					tournament_id,
+					number,
+					stage,
+					round,
+					group_number,
+					team1_source,
+					team2_source,
+					team1_id,
+					team2_id,
+					winner_id,
+					loser_id,
+					draw,
+					team1_score,
+					team2_score,
+					game_id,
+					done,
+					created_at,
+					updated_at
+				)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+				RETURNING id`,
+				match.UUID,
+				tournament.ID,
+				match.Number,
+				match.Stage,
+				match.Round,
+				match.Group,
+				match.Source1.String(),
+				match.Source2.String(),
+				getNullID(match.Team1ID),
+				getNullID(match.Team2ID),
+				getNullID(match.WinnerID),
+				getNullID(match.LoserID),
+				match.Draw,
+				team1Score,
+				team2Score,
+				getNullID(match.GameID),
+				match.Done,
+				match.CreatedAt,
+				match.UpdatedAt,
+			)
+			err = row.Scan(&match.ID)
+			if err != nil {
+				return fmt.Errorf("insert tournament match: %w", err)
+			}
+		}
+
+		return repository.updateTournament(ctx, tx, tournament)
+	})
+}
+
+// UpdateMatches stores the teams and results of the matches with the numbers and the status of
+// the tournament. Matches played in the meantime fail with ErrMatchPlayed.
+func (repository TournamentsRepository) UpdateMatches(ctx context.Context, tournament *Tournament, numbers []int) error {
+	tournament.UpdatedAt = time.Now()
+
+	return db.Transaction(ctx, repository.conn, func(tx db.Connection) error {
+		for i := range tournament.Matches {
+			match := &tournament.Matches[i]
+			if !slices.Contains(numbers, match.Number) {
+				continue
+			}
+
+			match.UpdatedAt = tournament.UpdatedAt
+
+			team1Score, team2Score := getScoreValues(match.Score)
+			result, err := tx.ExecContext(
+				ctx,
+				`UPDATE tournament_matches
+				SET team1_id = $1,
+					team2_id = $2,
+					winner_id = $3,
+					loser_id = $4,
+					draw = $5,
+					team1_score = $6,
+					team2_score = $7,
+					game_id = $8,
+					done = $9,
+					updated_at = $10
+				WHERE id = $11 AND done = false`,
+				getNullID(match.Team1ID),
+				getNullID(match.Team2ID),
+				getNullID(match.WinnerID),
+				getNullID(match.LoserID),
+				match.Draw,
+				team1Score,
+				team2Score,
+				getNullID(match.GameID),
+				match.Done,
+				match.UpdatedAt,
+				match.ID,
+			)
+			if err != nil {
+				return fmt.Errorf("update tournament match %d: %w", match.Number, err)
+			}
+			affected, err := result.RowsAffected()
+			if err != nil {
+				return fmt.Errorf("update tournament match %d: %w", match.Number, err)
+			}
+			if affected == 0 {
+				return fmt.Errorf("update tournament match: %w: %d", ErrMatchPlayed, match.Number)
+			}
+		}
+
+		return repository.updateTournament(ctx, tx, tournament)
+	})
+}
+
+func (repository TournamentsRepository) updateTournament(ctx context.Context, conn db.Connection, tournament *Tournament) error {
+	_, err := conn.ExecContext(
+		ctx,
+		"UPDATE tournaments SET status = $1, group_count = $2, advancing = $3, updated_at = $4 WHERE id = $5",
+		tournament.Status,
+		tournament.Groups,
+		tournament.Advancing,
+		tournament.UpdatedAt,
+		tournament.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("update tournament: %w", err)
+	}
+
+	return nil
+}
+
+// FindMatches returns the matches of the tournament ordered by number.
+func (repository TournamentsRepository) FindMatches(ctx context.Context, tournament Tournament) ([]Match, error) {
+	rows, err := repository.conn.QueryContext(
+		ctx,
+		`SELECT
+			m.id,
+			m.uuid,
+			m.created_at,
+			m.updated_at,
+			m.number,
+			m.stage,
+			m.round,
+			m.group_number,
+			m.team1_source,
+			m.team2_source,
+			m.team1_id,
+			m.team2_id,
+			m.winner_id,
+			m.loser_id,
+			m.draw,
+			m.team1_score,
+			m.team2_score,
+			m.game_id,
+			COALESCE(g.uuid, ''),
+			m.done
+		FROM tournament_matches m
+		LEFT JOIN games g ON g.id = m.game_id
+		WHERE m.tournament_id = $1
+		ORDER BY m.number ASC`,
+		tournament.ID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query tournament matches: %w", err)
+	}
+	defer rows.Close()
+
+	matches := []Match{}
+	for rows.Next() {
+		var match Match
+		var source1, source2 string
+		var team1ID, team2ID, winnerID, loserID, gameID, team1Score, team2Score sql.NullInt64
+		err = rows.Scan(
+			&match.ID,
+			&match.UUID,
+			&match.CreatedAt,
+			&match.UpdatedAt,
+			&match.Number,
+			&match.Stage,
+			&match.Round,
+			&match.Group,
+			&source1,
+			&source2,
+			&team1ID,
+			&team2ID,
+			&winnerID,
+			&loserID,
+			&match.Draw,
+			&team1Score,
+			&team2Score,
+			&gameID,
+			&match.GameUUID,
+			&match.Done,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan row in query tournament matches: %w", err)
+		}
+
+		match.Source1, err = ParseSource(source1)
+		if err != nil {
+			return nil, fmt.Errorf("scan row in query tournament matches: %w", err)
+		}
+		match.Source2, err = ParseSource(source2)
+		if err != nil {
+			return nil, fmt.Errorf("scan row in query tournament matches: %w", err)
+		}
+
+		match.Team1ID = uint(team1ID.Int64)
+		match.Team2ID = uint(team2ID.Int64)
+		match.WinnerID = uint(winnerID.Int64)
+		match.LoserID = uint(loserID.Int64)
+		match.GameID = uint(gameID.Int64)
+		if team1Score.Valid && team2Score.Valid {
+			match.Score = &games.Score{Team1: int(team1Score.Int64), Team2: int(team2Score.Int64)}
+		}
+
+		matches = append(matches, match)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("query tournament matches: %w", err)
+	}
+
+	return matches, nil
+}
+
+func getTournamentsColumns() string {
+	return "id, uuid, created_at, updated_at, name, format, team_size, group_count, advancing, status"
+}
+
+func getTournamentScanFields(tournament *Tournament) []any {
+	return []any{
+		&tournament.ID,
+		&tournament.UUID,
+		&tournament.CreatedAt,
+		&tournament.UpdatedAt,
+		&tournament.Name,
+		&tournament.Format,
+		&tournament.TeamSize,
+		&tournament.Groups,
+		&tournament.Advancing,
+		&tournament.Status,
+	}
+}
+
+func (repository TournamentsRepository) selectTournament(ctx context.Context, whereQuery string, args ...any) (Tournament, error) {
+	row := repository.conn.QueryRowContext(
+		ctx,
+		fmt.Sprintf("SELECT %s FROM tournaments WHERE %s", getTournamentsColumns(), whereQuery),
+		args...,
+	)
+
+	var tournament Tournament
+	err := row.Scan(getTournamentScanFields(&tournament)...)
+	if err != nil {
+		return Tournament{}, fmt.Errorf("scan row in query tournament: %w", err)
+	}
+
+	return tournament, nil
+}
+
+// getNullID stores no team, winner or game as null.
+func getNullID(id uint) *uint {
+	if id == 0 {
+		return nil
+	}
+
+	return &id
+}
+
+func getScoreValues(score *games.Score) (*int, *int) {
+	if score == nil {
+		return nil, nil
+	}
+
+	return &score.Team1, &score.Team2
+}
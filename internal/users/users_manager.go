@@ -1,6 +1,10 @@
 package users
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 
@@ -8,17 +12,25 @@ import (
 )
 
 type playersManager interface {
-	GetPlayerByName(name string) (players.Player, error)
+	GetPlayerByName(ctx context.Context, name string) (players.Player, error)
 }
 
 type usersRepository interface {
-	CreateUser(user *User) error
+	CreateUser(ctx context.Context, user *User) error
+	FindUserByEmail(ctx context.Context, email string) (User, error)
+	SetApiToken(ctx context.Context, user *User, tokenHash string) error
+	FindUserByApiToken(ctx context.Context, tokenHash string) (User, error)
 }
 
 type passwordService interface {
 	HashPassword(password []byte) ([]byte, error)
+	ComparePassword(hashedPassword []byte, plaintextPassword []byte) error
 }
 
+var (
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
 type Manager struct {
 	usersRepository usersRepository
 	playersManager  playersManager
@@ -38,11 +50,12 @@ func NewManager(
 }
 
 func (manager Manager) CreateUserFromPlayer(
+	ctx context.Context,
 	playerName string,
 	email string,
 	plaintextPassword string,
 ) (User, error) {
-	player, err := manager.playersManager.GetPlayerByName(playerName)
+	player, err := manager.playersManager.GetPlayerByName(ctx, playerName)
 	if errors.Is(err, players.ErrPlayerNotFound) {
 		return User{}, fmt.Errorf("Player %s not found", playerName)
 	}
@@ -61,10 +74,77 @@ func (manager Manager) CreateUserFromPlayer(
 		Password: string(hashedPassword),
 	}
 
-	err = manager.usersRepository.CreateUser(&user)
+	err = manager.usersRepository.CreateUser(ctx, &user)
 	if err != nil {
 		return User{}, fmt.Errorf("store user for CreateUserFromPlayer: %w", err)
 	}
 
 	return user, nil
 }
+
+func (manager Manager) Authenticate(ctx context.Context, email string, plaintextPassword string) (User, error) {
+	user, err := manager.usersRepository.FindUserByEmail(ctx, email)
+	if errors.Is(err, ErrUserNotFound) {
+		return User{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return User{}, fmt.Errorf("get user for authenticate: %w", err)
+	}
+
+	err = manager.passwordService.ComparePassword([]byte(user.Password), []byte(plaintextPassword))
+	if err != nil {
+		return User{}, ErrInvalidCredentials
+	}
+
+	return user, nil
+}
+
+// CreateApiToken creates a new api token for the user with the email and returns it. Only
+// the hash of the token is stored, so it can not be shown again. A previous token of the user
+// stops working.
+func (manager Manager) CreateApiToken(ctx context.Context, email string) (string, error) {
+	user, err := manager.usersRepository.FindUserByEmail(ctx, email)
+	if errors.Is(err, ErrUserNotFound) {
+		return "", fmt.Errorf("User %s not found", email)
+	}
+	if err != nil {
+		return "", fmt.Errorf("get user for create api token: %w", err)
+	}
+
+	token := make([]byte, 32)
+	_, err = rand.Read(token)
+	if err != nil {
+		return "", fmt.Errorf("generate api token: %w", err)
+	}
+	plaintextToken := hex.EncodeToString(token)
+
+	err = manager.usersRepository.SetApiToken(ctx, &user, hashApiToken(plaintextToken))
+	if err != nil {
+		return "", fmt.Errorf("store api token: %w", err)
+	}
+
+	return plaintextToken, nil
+}
+
+func (manager Manager) AuthenticateApiToken(ctx context.Context, token string) (User, error) {
+	if token == "" {
+		return User{}, ErrInvalidCredentials
+	}
+
+	user, err := manager.usersRepository.FindUserByApiToken(ctx, hashApiToken(token))
+	if errors.Is(err, ErrUserNotFound) {
+		return User{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return User{}, fmt.Errorf("get user for authenticate api token: %w", err)
+	}
+
+	return user, nil
+}
+
+// hashApiToken hashes the random tokens with sha256, they do not need a slow password hash.
+func hashApiToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(hash[:])
+}
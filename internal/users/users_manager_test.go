@@ -1,6 +1,7 @@
 package users
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -12,10 +13,17 @@ import (
 
 type mockUsersRepository struct {
 	updatedAt time.Time
+	user      User
 	err       error
+	tokenErr  error
+	tokenHash *string
 }
 
-func (mockUserRepository mockUsersRepository) CreateUser(user *User) error {
+func (mockUserRepository mockUsersRepository) FindUserByEmail(_ context.Context, email string) (User, error) {
+	return mockUserRepository.user, mockUserRepository.err
+}
+
+func (mockUserRepository mockUsersRepository) CreateUser(_ context.Context, user *User) error {
 	if mockUserRepository.err != nil {
 		return mockUserRepository.err
 	}
@@ -25,18 +33,39 @@ func (mockUserRepository mockUsersRepository) CreateUser(user *User) error {
 	return nil
 }
 
+func (mockUserRepository mockUsersRepository) SetApiToken(_ context.Context, user *User, tokenHash string) error {
+	if mockUserRepository.tokenHash != nil {
+		*mockUserRepository.tokenHash = tokenHash
+	}
+
+	return mockUserRepository.tokenErr
+}
+
+func (mockUserRepository mockUsersRepository) FindUserByApiToken(_ context.Context, tokenHash string) (User, error) {
+	if mockUserRepository.tokenHash != nil && *mockUserRepository.tokenHash != tokenHash {
+		return User{}, ErrUserNotFound
+	}
+
+	return mockUserRepository.user, mockUserRepository.err
+}
+
 type mockPlayersManager struct {
 	player players.Player
 	err    error
 }
 
-func (mockPlayersManager mockPlayersManager) GetPlayerByName(name string) (players.Player, error) {
+func (mockPlayersManager mockPlayersManager) GetPlayerByName(_ context.Context, name string) (players.Player, error) {
 	return mockPlayersManager.player, mockPlayersManager.err
 }
 
 type mockPasswordService struct {
 	hashedPassword []byte
 	err            error
+	compareErr     error
+}
+
+func (mockPasswordService mockPasswordService) ComparePassword(hashedPassword []byte, password []byte) error {
+	return mockPasswordService.compareErr
 }
 
 func (mockPasswordService mockPasswordService) HashPassword(password []byte) ([]byte, error) {
@@ -176,6 +205,7 @@ func TestCreateUserFromPlayer(t *testing.T) {
 			manager := tt.setupMocks()
 
 			user, err := manager.CreateUserFromPlayer(
+				context.Background(),
 				tt.args.playerName,
 				tt.args.email,
 				tt.args.plaintextPassword,
@@ -187,3 +217,148 @@ func TestCreateUserFromPlayer(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthenticate(t *testing.T) {
+	tests := map[string]struct {
+		email             string
+		plaintextPassword string
+		setupMocks        func() Manager
+		assertions        []func(t *testing.T, user User, err error)
+	}{
+		"successfully authenticated": {
+			email:             "test@example.com",
+			plaintextPassword: "password123",
+			setupMocks: func() Manager {
+				return Manager{
+					usersRepository: mockUsersRepository{user: User{
+						Player:   players.Player{Model: db.Model{ID: 23}, Name: "test_player"},
+						Email:    "test@example.com",
+						Password: "hashedpassword",
+					}},
+					passwordService: mockPasswordService{},
+				}
+			},
+			assertions: []func(t *testing.T, user User, err error){
+				func(t *testing.T, user User, err error) {
+					assert.NoError(t, err)
+					assert.Equal(t, uint(23), user.ID)
+					assert.Equal(t, "test@example.com", user.Email)
+				},
+			},
+		},
+		"user not found": {
+			email:             "test@example.com",
+			plaintextPassword: "password123",
+			setupMocks: func() Manager {
+				return Manager{usersRepository: mockUsersRepository{err: ErrUserNotFound}}
+			},
+			assertions: []func(t *testing.T, user User, err error){
+				func(t *testing.T, user User, err error) {
+					assert.Zero(t, user)
+					assert.ErrorIs(t, err, ErrInvalidCredentials)
+				},
+			},
+		},
+		"error on user retrieve": {
+			email:             "test@example.com",
+			plaintextPassword: "password123",
+			setupMocks: func() Manager {
+				return Manager{usersRepository: mockUsersRepository{err: errors.New("some error")}}
+			},
+			assertions: []func(t *testing.T, user User, err error){
+				func(t *testing.T, user User, err error) {
+					assert.Zero(t, user)
+					assert.ErrorContains(t, err, "get user for authenticate: some error")
+				},
+			},
+		},
+		"wrong password": {
+			email:             "test@example.com",
+			plaintextPassword: "wrongpassword",
+			setupMocks: func() Manager {
+				return Manager{
+					usersRepository: mockUsersRepository{user: User{Password: "hashedpassword"}},
+					passwordService: mockPasswordService{compareErr: errors.New("mismatch")},
+				}
+			},
+			assertions: []func(t *testing.T, user User, err error){
+				func(t *testing.T, user User, err error) {
+					assert.Zero(t, user)
+					assert.ErrorIs(t, err, ErrInvalidCredentials)
+				},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			manager := tt.setupMocks()
+
+			user, err := manager.Authenticate(context.Background(), tt.email, tt.plaintextPassword)
+
+			for _, assertion := range tt.assertions {
+				assertion(t, user, err)
+			}
+		})
+	}
+}
+
+func TestApiToken(t *testing.T) {
+	tests := map[string]struct {
+		usersRepository mockUsersRepository
+		assertions      []func(t *testing.T, manager Manager, token string, err error)
+	}{
+		"created token authenticates": {
+			usersRepository: mockUsersRepository{user: User{Email: "test@example.com"}},
+			assertions: []func(t *testing.T, manager Manager, token string, err error){
+				func(t *testing.T, manager Manager, token string, err error) {
+					assert.NoError(t, err)
+					assert.Len(t, token, 64)
+
+					user, err := manager.AuthenticateApiToken(context.Background(), token)
+					assert.NoError(t, err)
+					assert.Equal(t, "test@example.com", user.Email)
+				},
+				func(t *testing.T, manager Manager, token string, err error) {
+					_, err = manager.AuthenticateApiToken(context.Background(), token+"0")
+					assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+					_, err = manager.AuthenticateApiToken(context.Background(), "")
+					assert.ErrorIs(t, err, ErrInvalidCredentials)
+				},
+			},
+		},
+		"user not found": {
+			usersRepository: mockUsersRepository{err: ErrUserNotFound},
+			assertions: []func(t *testing.T, manager Manager, token string, err error){
+				func(t *testing.T, manager Manager, token string, err error) {
+					assert.Empty(t, token)
+					assert.ErrorContains(t, err, "User test@example.com not found")
+				},
+			},
+		},
+		"error on store": {
+			usersRepository: mockUsersRepository{tokenErr: errors.New("some error")},
+			assertions: []func(t *testing.T, manager Manager, token string, err error){
+				func(t *testing.T, manager Manager, token string, err error) {
+					assert.Empty(t, token)
+					assert.ErrorContains(t, err, "store api token: some error")
+				},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tokenHash := ""
+			tt.usersRepository.tokenHash = &tokenHash
+			manager := Manager{usersRepository: tt.usersRepository}
+
+			token, err := manager.CreateApiToken(context.Background(), "test@example.com")
+
+			for _, assertion := range tt.assertions {
+				assertion(t, manager, token, err)
+			}
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package users
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
@@ -8,6 +11,11 @@ import (
 	"github.com/spie/fskick/internal/players"
 )
 
+var (
+	ErrUserNotFound = db.ErrNotFound
+	ErrEmailExists  = errors.New("email is used by another user")
+)
+
 type User struct {
 	players.Player
 	Email    string `json:"email"`
@@ -22,17 +30,88 @@ func NewUsersRepository(conn db.Connection) UsersRepository {
 	return UsersRepository{conn: conn}
 }
 
-func (repo UsersRepository) CreateUser(user *User) error {
-	_, err := repo.conn.Exec(
-		"UPDATE players SET email = ?, password = ?, updated_at = ? WHERE id = ?",
+func (repo UsersRepository) CreateUser(ctx context.Context, user *User) error {
+	_, err := repo.conn.ExecContext(
+		ctx,
+		"UPDATE players SET email = $1, password = $2, updated_at = $3 WHERE id = $4",
 		user.Email,
 		user.Password,
 		time.Now(),
 		user.ID,
 	)
+	if db.IsUniqueViolation(err) {
+		return fmt.Errorf("Error update player to create user: %w", ErrEmailExists)
+	}
 	if err != nil {
 		return fmt.Errorf("Error update player to create user: %w", err)
 	}
 
 	return nil
 }
+
+func (repo UsersRepository) FindUserByEmail(ctx context.Context, email string) (User, error) {
+	row := repo.conn.QueryRowContext(
+		ctx,
+		fmt.Sprintf(
+			`SELECT %s, email, password
+			FROM players
+			WHERE email = $1 AND password IS NOT NULL`,
+			players.GetPlayerColumns(""),
+		),
+		email,
+	)
+
+	user, err := scanUser(row)
+	if err != nil {
+		return User{}, fmt.Errorf("query user by email: %w", err)
+	}
+
+	return user, nil
+}
+
+// SetApiToken stores the hash of the api token of the user, replacing the previous one.
+func (repo UsersRepository) SetApiToken(ctx context.Context, user *User, tokenHash string) error {
+	user.UpdatedAt = time.Now()
+	_, err := repo.conn.ExecContext(
+		ctx,
+		"UPDATE players SET api_token = $1, updated_at = $2 WHERE id = $3",
+		tokenHash,
+		user.UpdatedAt,
+		user.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("update player to set api token: %w", err)
+	}
+
+	return nil
+}
+
+func (repo UsersRepository) FindUserByApiToken(ctx context.Context, tokenHash string) (User, error) {
+	row := repo.conn.QueryRowContext(
+		ctx,
+		fmt.Sprintf(
+			`SELECT %s, email, password
+			FROM players
+			WHERE api_token = $1 AND password IS NOT NULL`,
+			players.GetPlayerColumns(""),
+		),
+		tokenHash,
+	)
+
+	user, err := scanUser(row)
+	if err != nil {
+		return User{}, fmt.Errorf("query user by api token: %w", err)
+	}
+
+	return user, nil
+}
+
+func scanUser(row *sql.Row) (User, error) {
+	var user User
+	err := row.Scan(append(players.GetPlayerScanFields(&user.Player), &user.Email, &user.Password)...)
+	if err != nil {
+		return User{}, err
+	}
+
+	return user, nil
+}
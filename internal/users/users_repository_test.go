@@ -1,6 +1,7 @@
 package users
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"testing"
@@ -20,17 +21,17 @@ type mockConnection struct {
 	expectedErr    error
 }
 
-func (conn mockConnection) Query(query string, args ...any) (*sql.Rows, error) {
+func (conn mockConnection) QueryContext(_ context.Context, query string, args ...any) (*sql.Rows, error) {
 	// TODO
 	return nil, nil
 }
 
-func (conn mockConnection) QueryRow(query string, args ...any) *sql.Row {
+func (conn mockConnection) QueryRowContext(_ context.Context, query string, args ...any) *sql.Row {
 	// TODO
 	return nil
 }
 
-func (conn *mockConnection) Exec(query string, args ...any) (sql.Result, error) {
+func (conn *mockConnection) ExecContext(_ context.Context, query string, args ...any) (sql.Result, error) {
 	conn.executedQueries = append(
 		conn.executedQueries,
 		struct {
@@ -45,7 +46,7 @@ func (conn *mockConnection) Exec(query string, args ...any) (sql.Result, error)
 	return conn.expectedResult, conn.expectedErr
 }
 
-func (conn mockConnection) Begin() (*sql.Tx, error) {
+func (conn mockConnection) BeginTx(_ context.Context, _ *sql.TxOptions) (*sql.Tx, error) {
 	// TODO
 	return nil, nil
 }
@@ -83,7 +84,7 @@ func TestUsersRepository_CreateUser(t *testing.T) {
 				func(t *testing.T, user User, conn *mockConnection, err error) {
 					assert.Equal(
 						t,
-						"UPDATE players SET email = ?, password = ?, updated_at = ? WHERE id = ?",
+						"UPDATE players SET email = $1, password = $2, updated_at = $3 WHERE id = $4",
 						conn.executedQueries[0].query,
 					)
 					assert.Equal(t, "email@example.com", conn.executedQueries[0].args[0])
@@ -121,7 +122,7 @@ func TestUsersRepository_CreateUser(t *testing.T) {
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
 			usersRepository, conn := tt.setUpMocks()
-			err := usersRepository.CreateUser(&tt.user)
+			err := usersRepository.CreateUser(context.Background(), &tt.user)
 
 			for _, assertion := range tt.assertions {
 				assertion(t, tt.user, conn, err)
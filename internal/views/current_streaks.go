@@ -15,5 +15,13 @@ func NewCurrentStreaks() CurrentStreaks {
 }
 
 func (view CurrentStreaks) Render(currentStreaks []streaks.Streak, ctx context.Context, w io.Writer) error {
-	return templates.CurrentStreaks(currentStreaks[:10]).Render(ctx, w)
+	return templates.CurrentStreaks(getCurrentStreaksOf10(currentStreaks)).Render(ctx, w)
+}
+
+func getCurrentStreaksOf10(currentStreaks []streaks.Streak) []streaks.Streak {
+	if len(currentStreaks) <= 10 {
+		return currentStreaks
+	}
+
+	return currentStreaks[:10]
 }
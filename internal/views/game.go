@@ -0,0 +1,19 @@
+package views
+
+import (
+	"context"
+	"io"
+
+	"github.com/spie/fskick/internal/games"
+	"github.com/spie/fskick/internal/templates"
+)
+
+type GameInfo struct{}
+
+func NewGameInfo() GameInfo {
+	return GameInfo{}
+}
+
+func (view GameInfo) Render(game games.Game, impacts []games.PlayerImpact, ctx context.Context, w io.Writer) error {
+	return templates.Game(game, impacts).Render(ctx, w)
+}
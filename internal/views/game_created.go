@@ -0,0 +1,26 @@
+package views
+
+import (
+	"context"
+	"io"
+
+	"github.com/spie/fskick/internal/games"
+	"github.com/spie/fskick/internal/seasons"
+	"github.com/spie/fskick/internal/templates"
+)
+
+type GameCreated struct{}
+
+func NewGameCreated() GameCreated {
+	return GameCreated{}
+}
+
+func (view GameCreated) Render(
+	season seasons.Season,
+	playerStats []games.PlayerStats,
+	gamesCount int,
+	ctx context.Context,
+	w io.Writer,
+) error {
+	return templates.GameCreated(season, playerStats, gamesCount).Render(ctx, w)
+}
@@ -0,0 +1,28 @@
+package views
+
+import (
+	"context"
+	"io"
+
+	"github.com/spie/fskick/internal/games"
+	"github.com/spie/fskick/internal/players"
+	"github.com/spie/fskick/internal/seasons"
+	"github.com/spie/fskick/internal/templates"
+)
+
+type GamesPage struct{}
+
+func NewGamesPage() GamesPage {
+	return GamesPage{}
+}
+
+func (view GamesPage) Render(
+	seasons []seasons.Season,
+	playersList []players.Player,
+	gamesPage games.GamesPage,
+	nextUrl string,
+	ctx context.Context,
+	w io.Writer,
+) error {
+	return templates.Games(seasons, playersList, gamesPage.Games, nextUrl).Render(ctx, w)
+}
@@ -0,0 +1,19 @@
+package views
+
+import (
+	"context"
+	"io"
+
+	"github.com/spie/fskick/internal/games"
+	"github.com/spie/fskick/internal/templates/components"
+)
+
+type GamesRows struct{}
+
+func NewGamesRows() GamesRows {
+	return GamesRows{}
+}
+
+func (view GamesRows) Render(gamesPage games.GamesPage, nextUrl string, ctx context.Context, w io.Writer) error {
+	return components.GamesRows(gamesPage.Games, nextUrl).Render(ctx, w)
+}
@@ -0,0 +1,19 @@
+package views
+
+import (
+	"context"
+	"io"
+
+	"github.com/spie/fskick/internal/players"
+	"github.com/spie/fskick/internal/templates"
+)
+
+type NewGame struct{}
+
+func NewNewGame() NewGame {
+	return NewGame{}
+}
+
+func (view NewGame) Render(playersList []players.Player, form templates.GameForm, ctx context.Context, w io.Writer) error {
+	return templates.NewGame(playersList, form).Render(ctx, w)
+}
@@ -0,0 +1,19 @@
+package views
+
+import (
+	"context"
+	"io"
+
+	"github.com/spie/fskick/internal/players"
+	"github.com/spie/fskick/internal/templates"
+)
+
+type NewGameForm struct{}
+
+func NewNewGameForm() NewGameForm {
+	return NewGameForm{}
+}
+
+func (view NewGameForm) Render(playersList []players.Player, form templates.GameForm, ctx context.Context, w io.Writer) error {
+	return templates.NewGameForm(playersList, form).Render(ctx, w)
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 
 	"github.com/spie/fskick/internal/games"
+	"github.com/spie/fskick/internal/players"
 	"github.com/spie/fskick/internal/streaks"
 	"github.com/spie/fskick/internal/templates"
 )
@@ -34,5 +35,28 @@ func (view PlayerInfo) Render(
 		longestLosingStreak,
 		getFavoriteTeamOf5(favoriteTeam),
 		getFavoriteTeamOf5(favoriteOponents),
+		getBestPartner(favoriteTeam, players.PositionDefense),
+		getBestPartner(favoriteTeam, players.PositionOffense),
 	).Render(ctx, w)
 }
+
+// getBestPartner returns the team mate with the best win ratio on the given position.
+func getBestPartner(favoriteTeam []games.PlayerStats, position players.Position) games.PlayerStats {
+	bestPartner := games.PlayerStats{}
+	for _, partner := range favoriteTeam {
+		switch position {
+		case players.PositionDefense:
+			if partner.DefenseGames > 0 && (bestPartner.DefenseGames == 0 ||
+				partner.DefenseWinRatio > bestPartner.DefenseWinRatio) {
+				bestPartner = partner
+			}
+		case players.PositionOffense:
+			if partner.OffenseGames > 0 && (bestPartner.OffenseGames == 0 ||
+				partner.OffenseWinRatio > bestPartner.OffenseWinRatio) {
+				bestPartner = partner
+			}
+		}
+	}
+
+	return bestPartner
+}
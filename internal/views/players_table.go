@@ -17,8 +17,9 @@ func NewPlayersTable() PlayersTable {
 func (view PlayersTable) Render(
 	playerStats []games.PlayerStats,
 	gamesCount int,
+	mode games.Mode,
 	ctx context.Context,
 	w io.Writer,
 ) error {
-	return templates.PlayersTable(playerStats, gamesCount).Render(ctx, w)
+	return templates.PlayersTable(playerStats, gamesCount, mode).Render(ctx, w)
 }
@@ -23,11 +23,10 @@ func (view PlayersTableUpdate) Render(
 	ctx context.Context,
 	w io.Writer,
 ) error {
-	playersTableEndpoint := "/table/players"
+	options := components.TableHtmxOptions{Endpoint: "/table/players", Include: "input[name='mode']"}
 	if playerUuid != "" {
-		playersTableEndpoint = fmt.Sprintf("%s/%s", playersTableEndpoint, playerUuid)
+		options = components.TableHtmxOptions{Endpoint: fmt.Sprintf("/table/players/%s", playerUuid)}
 	}
-	options := components.TableHtmxOptions{Endpoint: playersTableEndpoint}
 
 	return components.PlayerStatsTable(playerStats, gamesCount, sort, options).Render(ctx, w)
 }
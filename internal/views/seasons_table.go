@@ -20,8 +20,9 @@ func (view SeasonsTable) Render(
 	activeSeason seasons.Season,
 	playerStats []games.PlayerStats,
 	gamesCount int,
+	mode games.Mode,
 	ctx context.Context,
 	w io.Writer,
 ) error {
-	return templates.SeasonsTable(seasons, activeSeason, playerStats, gamesCount).Render(ctx, w)
+	return templates.SeasonsTable(seasons, activeSeason, playerStats, gamesCount, mode).Render(ctx, w)
 }
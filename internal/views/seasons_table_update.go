@@ -23,7 +23,7 @@ func (view SeasonsTableUpdate) Render(
 ) error {
 	options := components.TableHtmxOptions{
 		Endpoint: "/table/seasons",
-		Include:  "select[name='season']",
+		Include:  "select[name='season'], input[name='mode']",
 	}
 
 	return components.PlayerStatsTable(playerStats, gamesCount, sort, options).Render(ctx, w)
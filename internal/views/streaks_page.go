@@ -24,6 +24,6 @@ func (view StreaksPage) Render(
 	return templates.StreaksPage(
 		longestWiningStreak,
 		longestLosingStreak,
-		currentStreaks[:10],
+		getCurrentStreaksOf10(currentStreaks),
 	).Render(ctx, w)
 }
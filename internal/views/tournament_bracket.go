@@ -0,0 +1,19 @@
+package views
+
+import (
+	"context"
+	"io"
+
+	"github.com/spie/fskick/internal/templates"
+	"github.com/spie/fskick/internal/tournaments"
+)
+
+type TournamentBracket struct{}
+
+func NewTournamentBracket() TournamentBracket {
+	return TournamentBracket{}
+}
+
+func (view TournamentBracket) Render(tournament tournaments.Tournament, ctx context.Context, w io.Writer) error {
+	return templates.TournamentBracket(tournament).Render(ctx, w)
+}
@@ -0,0 +1,19 @@
+package views
+
+import (
+	"context"
+	"io"
+
+	"github.com/spie/fskick/internal/templates"
+	"github.com/spie/fskick/internal/tournaments"
+)
+
+type TournamentPage struct{}
+
+func NewTournamentPage() TournamentPage {
+	return TournamentPage{}
+}
+
+func (view TournamentPage) Render(tournament tournaments.Tournament, ctx context.Context, w io.Writer) error {
+	return templates.TournamentPage(tournament).Render(ctx, w)
+}
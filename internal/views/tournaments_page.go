@@ -0,0 +1,19 @@
+package views
+
+import (
+	"context"
+	"io"
+
+	"github.com/spie/fskick/internal/templates"
+	"github.com/spie/fskick/internal/tournaments"
+)
+
+type TournamentsPage struct{}
+
+func NewTournamentsPage() TournamentsPage {
+	return TournamentsPage{}
+}
+
+func (view TournamentsPage) Render(tournamentsList []tournaments.Tournament, ctx context.Context, w io.Writer) error {
+	return templates.TournamentsPage(tournamentsList).Render(ctx, w)
+}
@@ -1,6 +1,20 @@
 package migrations
 
-import "embed"
+import (
+	"embed"
 
-//go:embed *.sql
+	"github.com/spie/fskick/internal/db"
+)
+
+//go:embed sqlite/*.sql postgres/*.sql
 var FS embed.FS
+
+// Dir returns the directory of the driver's migrations. Both directories hold the same
+// migration versions, written in the driver's dialect.
+func Dir(driver string) string {
+	if driver == db.DriverPostgres {
+		return "postgres"
+	}
+
+	return "sqlite"
+}
@@ -0,0 +1,62 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS seasons (
+    id SERIAL NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    active BOOLEAN DEFAULT false NOT NULL,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ,
+    uuid TEXT NOT NULL UNIQUE,
+    PRIMARY KEY(id)
+);
+CREATE INDEX IF NOT EXISTS idx_seasons_deleted_at ON seasons(deleted_at);
+
+CREATE TABLE IF NOT EXISTS players (
+    id SERIAL NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    deleted_at TIMESTAMPTZ,
+    uuid TEXT NOT NULL UNIQUE,
+    PRIMARY KEY(id)
+);
+CREATE INDEX IF NOT EXISTS idx_players_deleted_at ON players(deleted_at);
+
+CREATE TABLE IF NOT EXISTS games (
+    id SERIAL NOT NULL,
+    season_id INTEGER NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    deleted_at TIMESTAMPTZ,
+    uuid TEXT NOT NULL UNIQUE,
+    played_at TIMESTAMPTZ,
+    PRIMARY KEY(id)
+);
+
+CREATE TABLE IF NOT EXISTS attendances (
+    id SERIAL NOT NULL,
+    game_id INTEGER NOT NULL,
+    player_id INTEGER NOT NULL,
+    win BOOLEAN NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    deleted_at TIMESTAMPTZ NULL DEFAULT NULL,
+    uuid TEXT NULL DEFAULT NULL,
+    PRIMARY KEY(id)
+);
+CREATE INDEX IF NOT EXISTS IDX_9C6B8FD4E48FD905 ON attendances (game_id);
+CREATE INDEX IF NOT EXISTS IDX_9C6B8FD499E6F5DF ON attendances (player_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS IDX_9C6B8FD4E48FD905;
+DROP INDEX IF EXISTS IDX_9C6B8FD499E6F5DF;
+DROP TABLE IF EXISTS attendances;
+DROP TABLE IF EXISTS games;
+DROP INDEX IF EXISTS idx_players_deleted_at;
+DROP TABLE IF EXISTS players;
+DROP INDEX IF EXISTS idx_seasons_deleted_at;
+DROP TABLE IF EXISTS seasons;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE players ADD COLUMN email TEXT NULL;
+ALTER TABLE players ADD COLUMN password TEXT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE players DROP COLUMN email;
+ALTER TABLE players DROP COLUMN password;
+-- +goose StatementEnd
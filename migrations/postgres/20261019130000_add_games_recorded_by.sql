@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE games ADD COLUMN recorded_by_id INTEGER NULL REFERENCES players(id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE games DROP COLUMN recorded_by_id;
+-- +goose StatementEnd
@@ -0,0 +1,99 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The rows violating the new constraints are repaired first. Attendances and seasons are
+-- repaired like fskick db check --fix does, games are never deleted.
+DELETE FROM attendances
+WHERE game_id NOT IN (SELECT id FROM games)
+    OR player_id NOT IN (SELECT id FROM players)
+    OR id NOT IN (SELECT MIN(id) FROM attendances GROUP BY game_id, player_id);
+
+UPDATE attendances SET uuid = gen_random_uuid()::TEXT WHERE uuid IS NULL;
+
+UPDATE seasons SET active = false
+WHERE active = true AND id != (SELECT MAX(id) FROM seasons WHERE active = true);
+
+UPDATE games SET recorded_by_id = NULL
+WHERE recorded_by_id IS NOT NULL AND recorded_by_id NOT IN (SELECT id FROM players);
+
+-- games of missing seasons are kept in a new inactive season
+INSERT INTO seasons (uuid, name, active, created_at, updated_at)
+SELECT gen_random_uuid()::TEXT, 'Recovered games', false, now(), now()
+WHERE EXISTS (SELECT 1 FROM games WHERE season_id NOT IN (SELECT id FROM seasons));
+
+UPDATE games SET season_id = (SELECT MAX(id) FROM seasons WHERE name = 'Recovered games')
+WHERE season_id NOT IN (SELECT id FROM seasons);
+
+-- later seasons and players with a taken name get their id appended, later users with a taken
+-- email lose their login
+UPDATE seasons SET name = name || ' (' || id || ')'
+WHERE id NOT IN (SELECT MIN(id) FROM seasons GROUP BY name);
+
+UPDATE players SET name = name || ' (' || id || ')'
+WHERE id NOT IN (SELECT MIN(id) FROM players GROUP BY name);
+
+UPDATE players SET email = NULL, password = NULL
+WHERE email IS NOT NULL AND id NOT IN (SELECT MIN(id) FROM players WHERE email IS NOT NULL GROUP BY email);
+
+UPDATE seasons
+SET created_at = COALESCE(created_at, updated_at, now()), updated_at = COALESCE(updated_at, created_at, now())
+WHERE created_at IS NULL OR updated_at IS NULL;
+
+UPDATE games SET played_at = created_at WHERE played_at IS NULL;
+
+ALTER TABLE seasons
+    ALTER COLUMN created_at SET NOT NULL,
+    ALTER COLUMN updated_at SET NOT NULL,
+    ADD CONSTRAINT seasons_name_key UNIQUE (name);
+
+ALTER TABLE players
+    ADD CONSTRAINT players_name_key UNIQUE (name),
+    ADD CONSTRAINT players_email_key UNIQUE (email);
+
+ALTER TABLE games
+    ALTER COLUMN played_at SET NOT NULL,
+    ADD CONSTRAINT games_season_id_fkey FOREIGN KEY (season_id) REFERENCES seasons(id);
+
+ALTER TABLE attendances
+    ALTER COLUMN uuid SET NOT NULL,
+    ADD CONSTRAINT attendances_uuid_key UNIQUE (uuid),
+    ADD CONSTRAINT attendances_game_id_fkey FOREIGN KEY (game_id) REFERENCES games(id),
+    ADD CONSTRAINT attendances_player_id_fkey FOREIGN KEY (player_id) REFERENCES players(id);
+
+DROP INDEX IF EXISTS IDX_9C6B8FD4E48FD905;
+DROP INDEX IF EXISTS IDX_9C6B8FD499E6F5DF;
+
+CREATE UNIQUE INDEX idx_seasons_active ON seasons(active) WHERE active = true;
+CREATE INDEX idx_games_season_id ON games(season_id);
+CREATE UNIQUE INDEX idx_attendances_game_id_player_id ON attendances(game_id, player_id);
+CREATE INDEX idx_attendances_player_id ON attendances(player_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_attendances_player_id;
+DROP INDEX IF EXISTS idx_attendances_game_id_player_id;
+DROP INDEX IF EXISTS idx_games_season_id;
+DROP INDEX IF EXISTS idx_seasons_active;
+
+CREATE INDEX IF NOT EXISTS IDX_9C6B8FD4E48FD905 ON attendances (game_id);
+CREATE INDEX IF NOT EXISTS IDX_9C6B8FD499E6F5DF ON attendances (player_id);
+
+ALTER TABLE attendances
+    DROP CONSTRAINT attendances_player_id_fkey,
+    DROP CONSTRAINT attendances_game_id_fkey,
+    DROP CONSTRAINT attendances_uuid_key,
+    ALTER COLUMN uuid DROP NOT NULL;
+
+ALTER TABLE games
+    DROP CONSTRAINT games_season_id_fkey,
+    ALTER COLUMN played_at DROP NOT NULL;
+
+ALTER TABLE players
+    DROP CONSTRAINT players_email_key,
+    DROP CONSTRAINT players_name_key;
+
+ALTER TABLE seasons
+    DROP CONSTRAINT seasons_name_key,
+    ALTER COLUMN updated_at DROP NOT NULL,
+    ALTER COLUMN created_at DROP NOT NULL;
+-- +goose StatementEnd
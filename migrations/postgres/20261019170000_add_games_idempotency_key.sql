@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- idempotency_key is sent by clients retrying the creation of a game, like the offline queue
+-- of the web ui.
+ALTER TABLE games ADD COLUMN idempotency_key TEXT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_games_idempotency_key ON games(idempotency_key);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_games_idempotency_key;
+ALTER TABLE games DROP COLUMN idempotency_key;
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+-- seeds are renumbered in their order and a player keeps only the first team of a tournament,
+-- registrations racing each other could store both before.
+UPDATE tournament_teams SET seed = (
+    SELECT COUNT(*) FROM tournament_teams other
+    WHERE other.tournament_id = tournament_teams.tournament_id
+        AND (other.seed < tournament_teams.seed OR (other.seed = tournament_teams.seed AND other.id <= tournament_teams.id))
+);
+CREATE UNIQUE INDEX idx_tournament_teams_tournament_id_seed ON tournament_teams(tournament_id, seed);
+
+ALTER TABLE tournament_team_players ADD COLUMN tournament_id INTEGER NULL REFERENCES tournaments(id);
+UPDATE tournament_team_players tp SET tournament_id = t.tournament_id
+FROM tournament_teams t
+WHERE t.id = tp.tournament_team_id;
+DELETE FROM tournament_team_players tp
+WHERE tp.tournament_team_id <> (
+    SELECT MIN(other.tournament_team_id) FROM tournament_team_players other
+    WHERE other.tournament_id = tp.tournament_id AND other.player_id = tp.player_id
+);
+ALTER TABLE tournament_team_players ALTER COLUMN tournament_id SET NOT NULL;
+ALTER TABLE tournament_team_players
+    ADD CONSTRAINT tournament_team_players_tournament_id_player_id_key UNIQUE(tournament_id, player_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE tournament_team_players DROP CONSTRAINT IF EXISTS tournament_team_players_tournament_id_player_id_key;
+ALTER TABLE tournament_team_players DROP COLUMN IF EXISTS tournament_id;
+DROP INDEX IF EXISTS idx_tournament_teams_tournament_id_seed;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE players ADD COLUMN display_name TEXT NULL;
+ALTER TABLE players ADD COLUMN preferred_position TEXT NULL;
+ALTER TABLE players ADD COLUMN favorite_side TEXT NULL;
+ALTER TABLE players ADD COLUMN joined_at DATETIME NULL;
+ALTER TABLE players ADD COLUMN avatar TEXT NULL;
+
+UPDATE players SET joined_at = created_at;
+
+CREATE TABLE IF NOT EXISTS "avatars" (
+    id INTEGER NOT NULL,
+    name TEXT NOT NULL UNIQUE,
+    content_type TEXT NOT NULL,
+    data BLOB NOT NULL,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY(id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "avatars";
+
+ALTER TABLE players DROP COLUMN display_name;
+ALTER TABLE players DROP COLUMN preferred_position;
+ALTER TABLE players DROP COLUMN favorite_side;
+ALTER TABLE players DROP COLUMN joined_at;
+ALTER TABLE players DROP COLUMN avatar;
+-- +goose StatementEnd
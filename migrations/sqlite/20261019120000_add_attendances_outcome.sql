@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE attendances ADD COLUMN team INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE attendances ADD COLUMN outcome TEXT NOT NULL DEFAULT 'loss';
+
+UPDATE attendances SET
+    team = CASE WHEN win THEN 1 ELSE 2 END,
+    outcome = CASE WHEN win THEN 'win' ELSE 'loss' END;
+
+ALTER TABLE attendances DROP COLUMN win;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE attendances ADD COLUMN win BOOLEAN NOT NULL DEFAULT false;
+
+UPDATE attendances SET win = outcome = 'win';
+
+ALTER TABLE attendances DROP COLUMN outcome;
+ALTER TABLE attendances DROP COLUMN team;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE games ADD COLUMN team1_score INTEGER NULL;
+ALTER TABLE games ADD COLUMN team2_score INTEGER NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE games DROP COLUMN team2_score;
+ALTER TABLE games DROP COLUMN team1_score;
+-- +goose StatementEnd
@@ -0,0 +1,327 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The rows violating the new constraints are repaired first. Attendances and seasons are
+-- repaired like fskick db check --fix does, games are never deleted.
+DELETE FROM attendances
+WHERE game_id NOT IN (SELECT id FROM games)
+    OR player_id NOT IN (SELECT id FROM players)
+    OR id NOT IN (SELECT MIN(id) FROM attendances GROUP BY game_id, player_id);
+
+UPDATE attendances
+SET uuid = lower(
+    hex(randomblob(4)) || '-' || hex(randomblob(2)) || '-4' || substr(hex(randomblob(2)), 2) || '-' ||
+    substr('89ab', 1 + abs(random()) % 4, 1) || substr(hex(randomblob(2)), 2) || '-' || hex(randomblob(6))
+)
+WHERE uuid IS NULL;
+
+UPDATE seasons SET active = false
+WHERE active = true AND id != (SELECT MAX(id) FROM seasons WHERE active = true);
+
+UPDATE games SET recorded_by_id = NULL
+WHERE recorded_by_id IS NOT NULL AND recorded_by_id NOT IN (SELECT id FROM players);
+
+-- games of missing seasons are kept in a new inactive season
+INSERT INTO seasons (uuid, name, active, created_at, updated_at)
+SELECT lower(
+    hex(randomblob(4)) || '-' || hex(randomblob(2)) || '-4' || substr(hex(randomblob(2)), 2) || '-' ||
+    substr('89ab', 1 + abs(random()) % 4, 1) || substr(hex(randomblob(2)), 2) || '-' || hex(randomblob(6))
+), 'Recovered games', false, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP
+WHERE EXISTS (SELECT 1 FROM games WHERE season_id NOT IN (SELECT id FROM seasons));
+
+UPDATE games SET season_id = (SELECT MAX(id) FROM seasons WHERE name = 'Recovered games')
+WHERE season_id NOT IN (SELECT id FROM seasons);
+
+-- later seasons and players with a taken name get their id appended, later users with a taken
+-- email lose their login
+UPDATE seasons SET name = name || ' (' || id || ')'
+WHERE id NOT IN (SELECT MIN(id) FROM seasons GROUP BY name);
+
+UPDATE players SET name = name || ' (' || id || ')'
+WHERE id NOT IN (SELECT MIN(id) FROM players GROUP BY name);
+
+UPDATE players SET email = NULL, password = NULL
+WHERE email IS NOT NULL AND id NOT IN (SELECT MIN(id) FROM players WHERE email IS NOT NULL GROUP BY email);
+
+-- sqlite3 can not add constraints to existing tables, so the tables are rebuilt. Renaming the
+-- new tables updates the references between them.
+CREATE TABLE "seasons_new" (
+    id INTEGER NOT NULL,
+    uuid TEXT NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    active BOOLEAN NOT NULL DEFAULT false,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    deleted_at DATETIME NULL,
+    PRIMARY KEY(id)
+);
+
+CREATE TABLE "players_new" (
+    id INTEGER NOT NULL,
+    uuid TEXT NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    display_name TEXT NULL,
+    preferred_position TEXT NULL,
+    favorite_side TEXT NULL,
+    joined_at DATETIME NULL,
+    avatar TEXT NULL,
+    email TEXT NULL UNIQUE,
+    password TEXT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    deleted_at DATETIME NULL,
+    PRIMARY KEY(id)
+);
+
+CREATE TABLE "games_new" (
+    id INTEGER NOT NULL,
+    uuid TEXT NOT NULL UNIQUE,
+    season_id INTEGER NOT NULL REFERENCES "seasons_new"(id),
+    played_at DATETIME NOT NULL,
+    recorded_by_id INTEGER NULL REFERENCES "players_new"(id),
+    team1_score INTEGER NULL,
+    team2_score INTEGER NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    deleted_at DATETIME NULL,
+    PRIMARY KEY(id)
+);
+
+CREATE TABLE "attendances_new" (
+    id INTEGER NOT NULL,
+    uuid TEXT NOT NULL UNIQUE,
+    game_id INTEGER NOT NULL REFERENCES "games_new"(id),
+    player_id INTEGER NOT NULL REFERENCES "players_new"(id),
+    team INTEGER NOT NULL DEFAULT 1,
+    outcome TEXT NOT NULL DEFAULT 'loss',
+    position TEXT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    deleted_at DATETIME NULL,
+    PRIMARY KEY(id)
+);
+
+INSERT INTO seasons_new (id, uuid, name, active, created_at, updated_at, deleted_at)
+SELECT
+    id,
+    uuid,
+    name,
+    active,
+    COALESCE(created_at, updated_at, CURRENT_TIMESTAMP),
+    COALESCE(updated_at, created_at, CURRENT_TIMESTAMP),
+    deleted_at
+FROM seasons;
+
+INSERT INTO players_new (
+    id,
+    uuid,
+    name,
+    display_name,
+    preferred_position,
+    favorite_side,
+    joined_at,
+    avatar,
+    email,
+    password,
+    created_at,
+    updated_at,
+    deleted_at
+)
+SELECT
+    id,
+    uuid,
+    name,
+    display_name,
+    preferred_position,
+    favorite_side,
+    joined_at,
+    avatar,
+    email,
+    password,
+    created_at,
+    updated_at,
+    deleted_at
+FROM players;
+
+INSERT INTO games_new (
+    id,
+    uuid,
+    season_id,
+    played_at,
+    recorded_by_id,
+    team1_score,
+    team2_score,
+    created_at,
+    updated_at,
+    deleted_at
+)
+SELECT
+    id,
+    uuid,
+    season_id,
+    COALESCE(played_at, created_at),
+    recorded_by_id,
+    team1_score,
+    team2_score,
+    created_at,
+    updated_at,
+    deleted_at
+FROM games;
+
+INSERT INTO attendances_new (id, uuid, game_id, player_id, team, outcome, position, created_at, updated_at, deleted_at)
+SELECT id, uuid, game_id, player_id, team, outcome, position, created_at, updated_at, deleted_at
+FROM attendances;
+
+DROP TABLE attendances;
+DROP TABLE games;
+DROP TABLE players;
+DROP TABLE seasons;
+
+ALTER TABLE seasons_new RENAME TO seasons;
+ALTER TABLE players_new RENAME TO players;
+ALTER TABLE games_new RENAME TO games;
+ALTER TABLE attendances_new RENAME TO attendances;
+
+CREATE INDEX idx_seasons_deleted_at ON seasons(deleted_at);
+CREATE UNIQUE INDEX idx_seasons_active ON seasons(active) WHERE active = true;
+CREATE INDEX idx_players_deleted_at ON players(deleted_at);
+CREATE INDEX idx_games_season_id ON games(season_id);
+CREATE UNIQUE INDEX idx_attendances_game_id_player_id ON attendances(game_id, player_id);
+CREATE INDEX idx_attendances_player_id ON attendances(player_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE TABLE "seasons_old" (
+    id INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    active BOOLEAN DEFAULT '0' NOT NULL,
+    updated_at datetime,
+    deleted_at datetime,
+    created_at datetime,
+    uuid text NOT NULL UNIQUE,
+    PRIMARY KEY(id)
+);
+
+CREATE TABLE "players_old" (
+    id INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    deleted_at datetime,
+    uuid text NOT NULL UNIQUE,
+    email TEXT NULL,
+    password TEXT NULL,
+    display_name TEXT NULL,
+    preferred_position TEXT NULL,
+    favorite_side TEXT NULL,
+    joined_at DATETIME NULL,
+    avatar TEXT NULL,
+    PRIMARY KEY(id)
+);
+
+CREATE TABLE "games_old" (
+    id INTEGER NOT NULL,
+    season_id INTEGER UNSIGNED NOT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    deleted_at datetime,
+    uuid text NOT NULL UNIQUE,
+    played_at datetime,
+    recorded_by_id INTEGER NULL REFERENCES "players_old"(id),
+    team1_score INTEGER NULL,
+    team2_score INTEGER NULL,
+    PRIMARY KEY(id)
+);
+
+CREATE TABLE "attendances_old" (
+    id INTEGER NOT NULL,
+    game_id INTEGER UNSIGNED NOT NULL,
+    player_id INTEGER UNSIGNED NOT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    deleted_at datetime null default null,
+    uuid text null default null,
+    position TEXT NULL,
+    team INTEGER NOT NULL DEFAULT 1,
+    outcome TEXT NOT NULL DEFAULT 'loss',
+    PRIMARY KEY(id)
+);
+
+INSERT INTO seasons_old (id, name, active, updated_at, deleted_at, created_at, uuid)
+SELECT id, name, active, updated_at, deleted_at, created_at, uuid FROM seasons;
+
+INSERT INTO players_old (
+    id,
+    name,
+    created_at,
+    updated_at,
+    deleted_at,
+    uuid,
+    email,
+    password,
+    display_name,
+    preferred_position,
+    favorite_side,
+    joined_at,
+    avatar
+)
+SELECT
+    id,
+    name,
+    created_at,
+    updated_at,
+    deleted_at,
+    uuid,
+    email,
+    password,
+    display_name,
+    preferred_position,
+    favorite_side,
+    joined_at,
+    avatar
+FROM players;
+
+INSERT INTO games_old (
+    id,
+    season_id,
+    created_at,
+    updated_at,
+    deleted_at,
+    uuid,
+    played_at,
+    recorded_by_id,
+    team1_score,
+    team2_score
+)
+SELECT
+    id,
+    season_id,
+    created_at,
+    updated_at,
+    deleted_at,
+    uuid,
+    played_at,
+    recorded_by_id,
+    team1_score,
+    team2_score
+FROM games;
+
+INSERT INTO attendances_old (id, game_id, player_id, created_at, updated_at, deleted_at, uuid, position, team, outcome)
+SELECT id, game_id, player_id, created_at, updated_at, deleted_at, uuid, position, team, outcome
+FROM attendances;
+
+DROP TABLE attendances;
+DROP TABLE games;
+DROP TABLE players;
+DROP TABLE seasons;
+
+ALTER TABLE seasons_old RENAME TO seasons;
+ALTER TABLE players_old RENAME TO players;
+ALTER TABLE games_old RENAME TO games;
+ALTER TABLE attendances_old RENAME TO attendances;
+
+CREATE INDEX IF NOT EXISTS `idx_seasons_deleted_at` ON `seasons`(`deleted_at`);
+CREATE INDEX IF NOT EXISTS `idx_players_deleted_at` ON `players`(`deleted_at`);
+CREATE INDEX IF NOT EXISTS IDX_9C6B8FD4E48FD905 ON "attendances" (game_id);
+CREATE INDEX IF NOT EXISTS IDX_9C6B8FD499E6F5DF ON "attendances" (player_id);
+-- +goose StatementEnd
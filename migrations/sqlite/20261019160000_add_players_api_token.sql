@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- api_token holds the sha256 hash of the token of a user for the api.
+ALTER TABLE players ADD COLUMN api_token TEXT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_players_api_token ON players(api_token);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_players_api_token;
+ALTER TABLE players DROP COLUMN api_token;
+-- +goose StatementEnd
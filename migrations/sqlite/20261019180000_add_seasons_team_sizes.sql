@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- team_sizes are the sizes of the teams allowed in the games of the season, 1v1, 2v2 or both.
+ALTER TABLE seasons ADD COLUMN team_sizes TEXT NOT NULL DEFAULT 'both';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE seasons DROP COLUMN team_sizes;
+-- +goose StatementEnd
@@ -0,0 +1,78 @@
+-- +goose Up
+-- +goose StatementBegin
+-- format is single, double or groups, status is registration, running or finished.
+CREATE TABLE IF NOT EXISTS "tournaments" (
+    id INTEGER NOT NULL,
+    uuid TEXT NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    format TEXT NOT NULL,
+    team_size INTEGER NOT NULL,
+    group_count INTEGER NOT NULL DEFAULT 0,
+    advancing INTEGER NOT NULL DEFAULT 0,
+    status TEXT NOT NULL DEFAULT 'registration',
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    deleted_at DATETIME NULL,
+    PRIMARY KEY(id)
+);
+
+CREATE TABLE IF NOT EXISTS "tournament_teams" (
+    id INTEGER NOT NULL,
+    uuid TEXT NOT NULL UNIQUE,
+    tournament_id INTEGER NOT NULL REFERENCES "tournaments"(id),
+    name VARCHAR(255) NOT NULL,
+    seed INTEGER NOT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    deleted_at DATETIME NULL,
+    PRIMARY KEY(id),
+    UNIQUE(tournament_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS "tournament_team_players" (
+    tournament_team_id INTEGER NOT NULL REFERENCES "tournament_teams"(id),
+    player_id INTEGER NOT NULL REFERENCES "players"(id),
+    PRIMARY KEY(tournament_team_id, player_id)
+);
+
+-- team1_source and team2_source tell where the teams come from, the winner (W3) or loser (L3)
+-- of a match or a rank in a group (G1:2). The score is seen from team 1.
+CREATE TABLE IF NOT EXISTS "tournament_matches" (
+    id INTEGER NOT NULL,
+    uuid TEXT NOT NULL UNIQUE,
+    tournament_id INTEGER NOT NULL REFERENCES "tournaments"(id),
+    number INTEGER NOT NULL,
+    stage TEXT NOT NULL,
+    round INTEGER NOT NULL,
+    group_number INTEGER NOT NULL DEFAULT 0,
+    team1_source TEXT NOT NULL DEFAULT '',
+    team2_source TEXT NOT NULL DEFAULT '',
+    team1_id INTEGER NULL REFERENCES "tournament_teams"(id),
+    team2_id INTEGER NULL REFERENCES "tournament_teams"(id),
+    winner_id INTEGER NULL REFERENCES "tournament_teams"(id),
+    loser_id INTEGER NULL REFERENCES "tournament_teams"(id),
+    draw BOOLEAN NOT NULL DEFAULT false,
+    team1_score INTEGER NULL,
+    team2_score INTEGER NULL,
+    game_id INTEGER NULL REFERENCES "games"(id) ON DELETE SET NULL,
+    done BOOLEAN NOT NULL DEFAULT false,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    deleted_at DATETIME NULL,
+    PRIMARY KEY(id),
+    UNIQUE(tournament_id, number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tournament_team_players_player_id ON tournament_team_players(player_id);
+CREATE INDEX IF NOT EXISTS idx_tournament_matches_game_id ON tournament_matches(game_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_tournament_matches_game_id;
+DROP INDEX IF EXISTS idx_tournament_team_players_player_id;
+DROP TABLE IF EXISTS "tournament_matches";
+DROP TABLE IF EXISTS "tournament_team_players";
+DROP TABLE IF EXISTS "tournament_teams";
+DROP TABLE IF EXISTS "tournaments";
+-- +goose StatementEnd
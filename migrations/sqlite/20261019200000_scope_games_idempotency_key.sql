@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- idempotency keys are only unique per recording user, the fingerprint of the sent game tells
+-- a retry apart from another game sent with the same key.
+ALTER TABLE games ADD COLUMN idempotency_fingerprint TEXT NULL;
+DROP INDEX IF EXISTS idx_games_idempotency_key;
+CREATE UNIQUE INDEX idx_games_recorded_by_id_idempotency_key ON games(COALESCE(recorded_by_id, 0), idempotency_key);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_games_recorded_by_id_idempotency_key;
+UPDATE games SET idempotency_key = NULL
+WHERE idempotency_key IS NOT NULL
+    AND id NOT IN (SELECT MIN(id) FROM games WHERE idempotency_key IS NOT NULL GROUP BY idempotency_key);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_games_idempotency_key ON games(idempotency_key);
+ALTER TABLE games DROP COLUMN idempotency_fingerprint;
+-- +goose StatementEnd
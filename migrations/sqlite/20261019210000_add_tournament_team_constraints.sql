@@ -0,0 +1,49 @@
+-- +goose Up
+-- +goose StatementBegin
+-- seeds are renumbered in their order and a player keeps only the first team of a tournament,
+-- registrations racing each other could store both before.
+UPDATE tournament_teams SET seed = (
+    SELECT COUNT(*) FROM tournament_teams other
+    WHERE other.tournament_id = tournament_teams.tournament_id
+        AND (other.seed < tournament_teams.seed OR (other.seed = tournament_teams.seed AND other.id <= tournament_teams.id))
+);
+CREATE UNIQUE INDEX idx_tournament_teams_tournament_id_seed ON tournament_teams(tournament_id, seed);
+
+CREATE TABLE "tournament_team_players_new" (
+    tournament_id INTEGER NOT NULL REFERENCES "tournaments"(id),
+    tournament_team_id INTEGER NOT NULL REFERENCES "tournament_teams"(id),
+    player_id INTEGER NOT NULL REFERENCES "players"(id),
+    PRIMARY KEY(tournament_team_id, player_id),
+    UNIQUE(tournament_id, player_id)
+);
+INSERT INTO tournament_team_players_new (tournament_id, tournament_team_id, player_id)
+SELECT t.tournament_id, tp.tournament_team_id, tp.player_id
+FROM tournament_team_players tp
+JOIN tournament_teams t ON t.id = tp.tournament_team_id
+WHERE tp.tournament_team_id = (
+    SELECT MIN(other.tournament_team_id) FROM tournament_team_players other
+    JOIN tournament_teams other_team ON other_team.id = other.tournament_team_id
+    WHERE other_team.tournament_id = t.tournament_id AND other.player_id = tp.player_id
+);
+DROP INDEX IF EXISTS idx_tournament_team_players_player_id;
+DROP TABLE tournament_team_players;
+ALTER TABLE tournament_team_players_new RENAME TO tournament_team_players;
+CREATE INDEX IF NOT EXISTS idx_tournament_team_players_player_id ON tournament_team_players(player_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE TABLE "tournament_team_players_old" (
+    tournament_team_id INTEGER NOT NULL REFERENCES "tournament_teams"(id),
+    player_id INTEGER NOT NULL REFERENCES "players"(id),
+    PRIMARY KEY(tournament_team_id, player_id)
+);
+INSERT INTO tournament_team_players_old (tournament_team_id, player_id)
+SELECT tournament_team_id, player_id FROM tournament_team_players;
+DROP INDEX IF EXISTS idx_tournament_team_players_player_id;
+DROP TABLE tournament_team_players;
+ALTER TABLE tournament_team_players_old RENAME TO tournament_team_players;
+CREATE INDEX IF NOT EXISTS idx_tournament_team_players_player_id ON tournament_team_players(player_id);
+
+DROP INDEX IF EXISTS idx_tournament_teams_tournament_id_seed;
+-- +goose StatementEnd